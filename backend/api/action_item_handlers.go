@@ -0,0 +1,29 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListOpenActionItems 按负责人列出当前工作区全部会议中未完成的待办事项
+func (h *Handler) ListOpenActionItems(c *fiber.Ctx) error {
+	var query OpenActionItemsQuery
+	if err := c.QueryParser(&query); err != nil {
+		return badRequest(fmt.Sprintf("解析查询参数失败: %v", err))
+	}
+	assignees, err := h.actionItems.OpenByAssignee(principal(c).WorkspaceID, query.Assignee)
+	if err != nil {
+		return err
+	}
+	return c.JSON(OpenActionItemsResponse{Assignees: assignees})
+}
+
+// GetActionItem 返回待办台账中的条目及其在每次会议中的变化
+func (h *Handler) GetActionItem(c *fiber.Ctx) error {
+	item, err := h.actionItems.Get(principal(c).WorkspaceID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(item)
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Ask 在当前工作区的会议记录中检索相关片段并回答问题，回答引用会议和片段的时间
+func (h *Handler) Ask(c *fiber.Ctx) error {
+	var request AskRequest
+	if err := c.BodyParser(&request); err != nil {
+		return badRequest(fmt.Sprintf("解析请求体失败: %v", err))
+	}
+	if request.TopK < 0 {
+		return badRequest("topK不能为负数")
+	}
+
+	set, err := h.servicesFor(c)
+	if err != nil {
+		return err
+	}
+	result, err := h.ask.Ask(c.UserContext(), set.DeepSeek, principal(c).WorkspaceID, request.Question, request.TopK)
+	if err != nil {
+		return err
+	}
+	return c.JSON(result)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"strings"
+
+	"meeting-mm/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sessionCookie 网页端登录后保存令牌的Cookie名称
+const sessionCookie = "mm_session"
+
+// principalKey 当前调用方在fiber.Ctx.Locals中的键
+const principalKey = "principal"
+
+// RequireAuth 校验请求携带的登录令牌或API密钥。
+// 凭据可以放在 Authorization: Bearer <令牌或密钥>、X-API-Key 请求头或登录Cookie中
+func (h *Handler) RequireAuth(c *fiber.Ctx) error {
+	principal, err := h.auth.Authenticate(credential(c))
+	if err != nil {
+		return services.ErrUnauthorized
+	}
+
+	c.Locals(principalKey, principal)
+	return c.Next()
+}
+
+// credential 从请求中取出凭据
+func credential(c *fiber.Ctx) string {
+	if auth := c.Get(fiber.HeaderAuthorization); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := c.Get("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+	return c.Cookies(sessionCookie)
+}
+
+// principal 返回当前调用方，未经过RequireAuth的路由返回空的调用方
+func principal(c *fiber.Ctx) *services.Principal {
+	if p, ok := c.Locals(principalKey).(*services.Principal); ok {
+		return p
+	}
+	return &services.Principal{}
+}
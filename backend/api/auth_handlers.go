@@ -0,0 +1,268 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"meeting-mm/models"
+	"meeting-mm/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UserResponse 返回给客户端的用户信息，不含密码哈希
+type UserResponse struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspaceId"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func newUserResponse(user *models.User) UserResponse {
+	return UserResponse{
+		ID:          user.ID,
+		WorkspaceID: user.WorkspaceID,
+		Email:       user.Email,
+		Name:        user.Name,
+		Role:        user.Role,
+		CreatedAt:   user.CreatedAt,
+	}
+}
+
+// APIKeyResponse 返回给客户端的API密钥信息，不含哈希；明文密钥只在创建时返回
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	UserID     string     `json:"userId"`
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+func newAPIKeyResponse(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		UserID:     key.UserID,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+// WorkspaceResponse 返回给客户端的工作区信息，密钥只返回是否已设置
+type WorkspaceResponse struct {
+	ID       string                    `json:"id"`
+	Name     string                    `json:"name"`
+	Settings WorkspaceSettingsResponse `json:"settings"`
+	// ServerCredentials 未设置工作区凭据时是否使用服务器配置的Notion和DeepSeek凭据
+	ServerCredentials bool      `json:"serverCredentials"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// WorkspaceSettingsResponse 返回给客户端的工作区设置
+type WorkspaceSettingsResponse struct {
+	NotionAPIKeySet      bool   `json:"notionApiKeySet"`
+	NotionDatabaseID     string `json:"notionDatabaseId"`
+	DeepSeekAPIKeySet    bool   `json:"deepseekApiKeySet"`
+	DeepSeekBaseURL      string `json:"deepseekBaseUrl"`
+	DeepSeekModel        string `json:"deepseekModel"`
+	SystemPrompt         string `json:"systemPrompt"`
+	AnalysisInstructions string `json:"analysisInstructions"`
+	WhisperModel         string `json:"whisperModel"`
+	WhisperLanguage      string `json:"whisperLanguage"`
+}
+
+func newWorkspaceResponse(workspace *models.Workspace) WorkspaceResponse {
+	settings := workspace.Settings
+	return WorkspaceResponse{
+		ID:   workspace.ID,
+		Name: workspace.Name,
+		Settings: WorkspaceSettingsResponse{
+			NotionAPIKeySet:      settings.NotionAPIKey != "",
+			NotionDatabaseID:     settings.NotionDatabaseID,
+			DeepSeekAPIKeySet:    settings.DeepSeekAPIKey != "",
+			DeepSeekBaseURL:      settings.DeepSeekBaseURL,
+			DeepSeekModel:        settings.DeepSeekModel,
+			SystemPrompt:         settings.SystemPrompt,
+			AnalysisInstructions: settings.AnalysisInstructions,
+			WhisperModel:         settings.WhisperModel,
+			WhisperLanguage:      settings.WhisperLanguage,
+		},
+		ServerCredentials: workspace.ServerCredentials,
+		CreatedAt:         workspace.CreatedAt,
+		UpdatedAt:         workspace.UpdatedAt,
+	}
+}
+
+// Register 注册用户并创建工作区
+func (h *Handler) Register(c *fiber.Ctx) error {
+	var request RegisterRequest
+	if err := c.BodyParser(&request); err != nil {
+		return badRequest(fmt.Sprintf("解析请求体失败: %v", err))
+	}
+
+	user, err := h.auth.Register(request.Email, request.Password, request.Name, request.WorkspaceName)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(newUserResponse(user))
+}
+
+// Login 使用邮箱和密码登录，返回登录令牌并写入Cookie
+func (h *Handler) Login(c *fiber.Ctx) error {
+	var request LoginRequest
+	if err := c.BodyParser(&request); err != nil {
+		return badRequest(fmt.Sprintf("解析请求体失败: %v", err))
+	}
+
+	token, expiresAt, user, err := h.auth.Login(request.Email, request.Password)
+	if err != nil {
+		return err
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.JSON(LoginResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		User:      newUserResponse(user),
+	})
+}
+
+// Logout 使当前用户已签发的登录令牌失效并清除登录Cookie
+func (h *Handler) Logout(c *fiber.Ctx) error {
+	if err := h.auth.Logout(credential(c)); err != nil {
+		return err
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.JSON(StatusResponse{Status: "success"})
+}
+
+// Me 返回当前用户及其工作区
+func (h *Handler) Me(c *fiber.Ctx) error {
+	p := principal(c)
+	user, err := h.store.Users.Get(p.UserID)
+	if err != nil {
+		return err
+	}
+	workspace, err := h.store.Workspaces.Get(p.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(CurrentUserResponse{
+		User:      newUserResponse(user),
+		Workspace: newWorkspaceResponse(workspace),
+	})
+}
+
+// ListAPIKeys 列出工作区的API密钥
+func (h *Handler) ListAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.auth.ListAPIKeys(principal(c).WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	result := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, newAPIKeyResponse(key))
+	}
+	return c.JSON(APIKeyListResponse{Keys: result})
+}
+
+// CreateAPIKey 创建API密钥，明文密钥只在本次响应中返回
+func (h *Handler) CreateAPIKey(c *fiber.Ctx) error {
+	var request CreateAPIKeyRequest
+	if err := c.BodyParser(&request); err != nil {
+		return badRequest(fmt.Sprintf("解析请求体失败: %v", err))
+	}
+	if request.Name == "" {
+		return badRequest("API密钥名称不能为空")
+	}
+
+	plaintext, key, err := h.auth.CreateAPIKey(principal(c), request.Name)
+	if err != nil {
+		return err
+	}
+
+	response := newAPIKeyResponse(key)
+	response.Key = plaintext
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+// RevokeAPIKey 吊销API密钥
+func (h *Handler) RevokeAPIKey(c *fiber.Ctx) error {
+	key, err := h.auth.RevokeAPIKey(principal(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(newAPIKeyResponse(key))
+}
+
+// GetWorkspace 返回当前工作区
+func (h *Handler) GetWorkspace(c *fiber.Ctx) error {
+	workspace, err := h.store.Workspaces.Get(principal(c).WorkspaceID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(newWorkspaceResponse(workspace))
+}
+
+// AddWorkspaceMember 在当前工作区中添加成员
+func (h *Handler) AddWorkspaceMember(c *fiber.Ctx) error {
+	var request AddMemberRequest
+	if err := c.BodyParser(&request); err != nil {
+		return badRequest(fmt.Sprintf("解析请求体失败: %v", err))
+	}
+
+	user, err := h.auth.AddMember(principal(c), request.Email, request.Password, request.Name)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(newUserResponse(user))
+}
+
+// GetWorkspaceSettings 返回当前工作区的设置，密钥只返回是否已设置
+func (h *Handler) GetWorkspaceSettings(c *fiber.Ctx) error {
+	workspace, err := h.store.Workspaces.Get(principal(c).WorkspaceID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(newWorkspaceResponse(workspace).Settings)
+}
+
+// UpdateWorkspaceSettings 更新当前工作区的设置，未提供的字段保持不变，空字符串表示改用服务器的默认配置
+func (h *Handler) UpdateWorkspaceSettings(c *fiber.Ctx) error {
+	var request services.WorkspaceSettingsUpdate
+	if err := c.BodyParser(&request); err != nil {
+		return badRequest(fmt.Sprintf("解析请求体失败: %v", err))
+	}
+
+	workspace, err := h.services.UpdateSettings(principal(c), request)
+	if err != nil {
+		return err
+	}
+	return c.JSON(newWorkspaceResponse(workspace).Settings)
+}
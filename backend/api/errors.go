@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+
+	"meeting-mm/apperr"
+	"meeting-mm/services"
+	"meeting-mm/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestIDKey 请求ID在fiber.Ctx.Locals中的键，与requestid中间件的默认值一致
+const requestIDKey = "requestid"
+
+// sentinelCodes 服务层哨兵错误对应的错误码，信息直接返回给客户端
+var sentinelCodes = []struct {
+	err  error
+	code apperr.Code
+}{
+	{services.ErrUnauthorized, apperr.CodeUnauthorized},
+	{services.ErrInvalidCredentials, apperr.CodeUnauthorized},
+	{services.ErrForbidden, apperr.CodeForbidden},
+	{services.ErrSignupClosed, apperr.CodeForbidden},
+	{services.ErrEmailTaken, apperr.CodeConflict},
+	{services.ErrInvalidEmail, apperr.CodeBadRequest},
+	{services.ErrWeakPassword, apperr.CodeBadRequest},
+	{services.ErrInvalidSettings, apperr.CodeBadRequest},
+	{services.ErrInvalidSyncState, apperr.CodeConflict},
+	{services.ErrInvalidProfile, apperr.CodeBadRequest},
+	{services.ErrProfileNotFound, apperr.CodeNotFound},
+	{services.ErrProfileExists, apperr.CodeConflict},
+	{services.ErrInvalidQuestion, apperr.CodeBadRequest},
+	{services.ErrActionItemNotFound, apperr.CodeNotFound},
+	{services.ErrShuttingDown, apperr.CodeUnavailable},
+	{storage.ErrNotFound, apperr.CodeNotFound},
+}
+
+// fiberCodes Fiber内置错误（路由不存在、请求体过大等）的状态码对应的错误码
+var fiberCodes = map[int]apperr.Code{
+	fiber.StatusUnauthorized:          apperr.CodeUnauthorized,
+	fiber.StatusForbidden:             apperr.CodeForbidden,
+	fiber.StatusNotFound:              apperr.CodeNotFound,
+	fiber.StatusConflict:              apperr.CodeConflict,
+	fiber.StatusRequestEntityTooLarge: apperr.CodePayloadTooLarge,
+	fiber.StatusUnsupportedMediaType:  apperr.CodeAudioUnsupported,
+	fiber.StatusServiceUnavailable:    apperr.CodeUnavailable,
+}
+
+// appError 将处理器返回的错误转换为应用错误
+func appError(err error) *apperr.Error {
+	if e, ok := apperr.As(err); ok {
+		return e
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code, ok := fiberCodes[fiberErr.Code]
+		if !ok {
+			code = apperr.CodeBadRequest
+			if fiberErr.Code >= fiber.StatusInternalServerError {
+				code = apperr.CodeInternal
+			}
+		}
+		e := apperr.New(code, fiberErr.Message)
+		e.Status = fiberErr.Code
+		return e
+	}
+
+	for _, sentinel := range sentinelCodes {
+		if errors.Is(err, sentinel.err) {
+			return apperr.New(sentinel.code, err.Error())
+		}
+	}
+	return apperr.From(err)
+}
+
+// badRequest 返回请求参数错误
+func badRequest(message string) error {
+	return apperr.New(apperr.CodeBadRequest, message)
+}
+
+// NewErrorResponse 生成错误响应，信息按Accept-Language本地化
+func NewErrorResponse(e *apperr.Error, acceptLanguage, requestID string) ErrorResponse {
+	return ErrorResponse{
+		Error:     e.Localize(acceptLanguage),
+		Code:      string(e.Code),
+		Status:    e.Status,
+		Retryable: e.Retryable,
+		RequestID: requestID,
+	}
+}
+
+// ErrorHandler 统一的错误处理器：处理器只需返回错误，由这里转换为带错误码和请求ID的JSON响应。
+// 服务端错误的原始错误只写入日志，不返回给客户端
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	e := appError(err)
+	requestID, _ := c.Locals(requestIDKey).(string)
+	if e.Status >= fiber.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), "请求失败", "method", c.Method(), "path", c.Path(), "code", e.Code, "error", err)
+	}
+	return c.Status(e.Status).JSON(NewErrorResponse(e, c.Get(fiber.HeaderAcceptLanguage), requestID))
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"meeting-mm/apperr"
+	"meeting-mm/export"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportMeeting 将会议导出为Markdown、HTML、Word或PDF文件下载
+func (h *Handler) ExportMeeting(c *fiber.Ctx) error {
+	var query ExportQuery
+	if err := c.QueryParser(&query); err != nil {
+		return badRequest(fmt.Sprintf("解析查询参数失败: %v", err))
+	}
+	format := strings.ToLower(strings.TrimSpace(query.Format))
+	if format == "" {
+		format = export.FormatMarkdown
+	}
+	if export.ContentType(format) == "" {
+		return badRequest(fmt.Sprintf("%s: %s（支持 %s）", export.ErrUnsupportedFormat.Error(), query.Format, strings.Join(export.Formats(), "、")))
+	}
+
+	meeting, err := h.getMeeting(c, c.Params("id"))
+	if err != nil {
+		return meetingError(err)
+	}
+
+	file, err := export.Render(format, meeting, export.Options{IncludeTranscript: query.Transcript})
+	if errors.Is(err, export.ErrUnsupportedFormat) {
+		return badRequest(err.Error())
+	}
+	if err != nil {
+		return apperr.Wrap(apperr.CodeInternal, "导出会议失败", err)
+	}
+
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderContentDisposition, contentDisposition(file.Name))
+	return c.Send(file.Data)
+}
+
+// ExportTranscript 将会议记录导出为SRT、WebVTT字幕、纯文本或JSON
+func (h *Handler) ExportTranscript(c *fiber.Ctx) error {
+	var query TranscriptQuery
+	if err := c.QueryParser(&query); err != nil {
+		return badRequest(fmt.Sprintf("解析查询参数失败: %v", err))
+	}
+	format := strings.ToLower(strings.TrimSpace(query.Format))
+	if format == "" {
+		format = export.TranscriptSRT
+	}
+	if export.TranscriptContentType(format) == "" {
+		return badRequest(fmt.Sprintf("%s: %s（支持 %s）", export.ErrUnsupportedFormat.Error(), query.Format, strings.Join(export.TranscriptFormats(), "、")))
+	}
+	if query.LineLength != 0 && (query.LineLength < export.MinLineLength || query.LineLength > export.MaxLineLength) {
+		return badRequest(fmt.Sprintf("lineLength必须在%d到%d之间", export.MinLineLength, export.MaxLineLength))
+	}
+
+	meeting, err := h.getMeeting(c, c.Params("id"))
+	if err != nil {
+		return meetingError(err)
+	}
+
+	file, err := export.RenderTranscript(format, meeting, export.TranscriptOptions{
+		LineLength: query.LineLength,
+		Offset:     time.Duration(query.OffsetMs) * time.Millisecond,
+	})
+	if errors.Is(err, export.ErrNoSegments) {
+		return apperr.New(apperr.CodeConflict, err.Error())
+	}
+	if err != nil {
+		return apperr.Wrap(apperr.CodeInternal, "导出会议记录失败", err)
+	}
+
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderContentDisposition, contentDisposition(file.Name))
+	return c.Send(file.Data)
+}
+
+// contentDisposition 生成附件下载头：filename为ASCII后备名，filename*为UTF-8原名（RFC 6266）
+func contentDisposition(name string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, name)
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback, url.PathEscape(name))
+}
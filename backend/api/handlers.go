@@ -1,16 +1,19 @@
 package api
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
-	"strconv"
+	"log/slog"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"meeting-mm/apperr"
+	"meeting-mm/config"
 	"meeting-mm/models"
 	"meeting-mm/services"
+	"meeting-mm/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -18,251 +21,409 @@ import (
 
 // Handler 处理API请求
 type Handler struct {
-	deepseekService *services.DeepSeekService
-	notionService   *services.NotionService
-	whisperService  *services.WhisperService
+	cfg          *config.Config
+	services     *services.WorkspaceServices
+	notionOutbox *services.NotionOutbox
+	auth         *services.AuthService
+	store        *storage.Store
+	health       *services.HealthService
+	reports      *services.ReportRenderer
+	calendar     *services.CalendarDirectory
+	profiles     *services.ProfileService
+	ask          *services.AskService
+	actionItems  *services.ActionItemService
 }
 
 // NewHandler 创建Handler实例
-func NewHandler(deepseekService *services.DeepSeekService, notionService *services.NotionService, whisperService *services.WhisperService) *Handler {
+func NewHandler(cfg *config.Config, workspaceServices *services.WorkspaceServices, notionOutbox *services.NotionOutbox, auth *services.AuthService, store *storage.Store, health *services.HealthService, reports *services.ReportRenderer, calendar *services.CalendarDirectory, profiles *services.ProfileService, ask *services.AskService, actionItems *services.ActionItemService) *Handler {
 	return &Handler{
-		deepseekService: deepseekService,
-		notionService:   notionService,
-		whisperService:  whisperService,
+		cfg:          cfg,
+		services:     workspaceServices,
+		notionOutbox: notionOutbox,
+		auth:         auth,
+		store:        store,
+		health:       health,
+		reports:      reports,
+		calendar:     calendar,
+		profiles:     profiles,
+		ask:          ask,
+		actionItems:  actionItems,
 	}
 }
 
-// HealthCheck 健康检查
+// servicesFor 返回调用方工作区使用的服务
+func (h *Handler) servicesFor(c *fiber.Ctx) (*services.ServiceSet, error) {
+	return h.services.For(principal(c).WorkspaceID)
+}
+
+// HealthCheck 健康检查，保留给旧客户端，与Live相同
 func (h *Handler) HealthCheck(c *fiber.Ctx) error {
-	return c.JSON(fiber.Map{
-		"status": "ok",
-		"time":   time.Now().Format(time.RFC3339),
+	return h.Live(c)
+}
+
+// Live 存活检查：进程能够处理请求即返回ok，不检查外部依赖
+func (h *Handler) Live(c *fiber.Ctx) error {
+	return c.JSON(HealthResponse{
+		Status: "ok",
+		Time:   time.Now().Format(time.RFC3339),
 	})
 }
 
+// Ready 就绪检查：逐项检查转录环境、DeepSeek、Notion、数据存储和磁盘空间，任一项失败时返回503
+func (h *Handler) Ready(c *fiber.Ctx) error {
+	report := h.health.Ready(c.UserContext())
+	if report.Status == services.HealthFail {
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+	return c.JSON(report)
+}
+
 // UploadAudio 上传音频文件
 func (h *Handler) UploadAudio(c *fiber.Ctx) error {
-	// 获取表单数据
+	// 获取表单数据，会议邀请可以提供标题、时间、参会人和议程
+	invite, err := h.formInvite(c)
+	if err != nil {
+		return err
+	}
 	title := c.FormValue("title")
+	if title == "" && invite != nil {
+		title = invite.Title
+	}
 	if title == "" {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "会议标题不能为空",
-		})
+		return badRequest("会议标题不能为空")
+	}
+
+	agenda, err := agendaItems(services.ParseAgenda(c.FormValue("agenda"), false), invite)
+	if err != nil {
+		return err
 	}
 
 	syncToNotion := c.FormValue("syncToNotion") == "true"
+	polishReport := c.FormValue("polishReport") == "true"
+	// 在耗时的转录和分析之前检查会议类型和模板
+	profile, reportTemplate, err := h.analysisProfile(c, c.FormValue("profile"), c.FormValue("reportTemplate"))
+	if err != nil {
+		return err
+	}
 
 	// 获取音频文件
 	file, err := c.FormFile("audio")
 	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("获取音频文件失败: %v", err),
-		})
+		return badRequest(fmt.Sprintf("获取音频文件失败: %v", err))
+	}
+	if !services.SupportedAudio(file.Filename) {
+		return apperr.New(apperr.CodeAudioUnsupported, fmt.Sprintf("不支持的音频格式: %s", filepath.Ext(file.Filename)))
 	}
 
 	// 打开文件
 	fileHandle, err := file.Open()
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("打开音频文件失败: %v", err),
-		})
+		return apperr.Wrap(apperr.CodeInternal, "打开音频文件失败", err)
 	}
 	defer fileHandle.Close()
 
 	// 读取文件内容
 	audioData, err := io.ReadAll(fileHandle)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("读取音频文件失败: %v", err),
-		})
+		return apperr.Wrap(apperr.CodeInternal, "读取音频文件失败", err)
 	}
 
-	// 转录音频
-	transcript, err := h.whisperService.TranscribeAudio(audioData)
+	set, err := h.servicesFor(c)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("转录音频失败: %v", err),
-		})
+		return err
 	}
 
-	// 分析转录内容
-	summary, todoItems, decisions, err := h.deepseekService.AnalyzeTranscript(title, transcript)
+	// 转录音频，Whisper的片段带时间戳，保存在会议中供导出字幕和同步带时间的会议记录
+	transcription, err := set.Whisper.Transcribe(c.UserContext(), audioData)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("分析转录内容失败: %v", err),
-		})
+		return err
 	}
 
-	// 创建会议对象
-	meeting := &models.Meeting{
-		ID:           uuid.New().String(),
-		Title:        title,
-		Date:         time.Now(),
-		Participants: []string{}, // 这里可以从请求中获取参与者信息
-		Transcript:   transcript,
-		Summary:      summary,
-		TodoItems:    make([]models.TodoItem, len(todoItems)),
-		Decisions:    make([]models.Decision, len(decisions)),
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+	// 分析转录内容
+	meeting, actionItems, err := h.analyzeMeeting(c, set, analysisInput{
+		title: title, transcript: transcription.Text, segments: transcription.Segments, invite: invite, agenda: agenda, profile: profile, series: c.FormValue("series"),
+	})
+	if err != nil {
+		return err
 	}
 
-	// 转换待办事项
-	for i, todo := range todoItems {
-		dueDate, err := time.Parse("2006-01-02", todo.DueDate)
-		if err != nil {
-			dueDate = time.Time{} // 如果解析失败，使用零值
-		}
-		meeting.TodoItems[i] = models.TodoItem{
-			ID:          todo.ID,
-			Description: todo.Description,
-			Assignee:    todo.Assignee,
-			DueDate:     dueDate,
-			Status:      todo.Status,
-		}
+	// 按模板生成Markdown报告
+	markdownReport, err := h.renderReport(c, set, meeting, reportTemplate, polishReport)
+	if err != nil {
+		return err
 	}
 
-	// 转换决策点
-	for i, decision := range decisions {
-		meeting.Decisions[i] = models.Decision{
-			ID:          decision.ID,
-			Description: decision.Description,
-			MadeBy:      decision.MadeBy,
+	// 保留原始录音，供回听和附加到Notion页面
+	if h.cfg.KeepAudio {
+		ext := strings.ToLower(filepath.Ext(file.Filename))
+		if ext == "" {
+			ext = ".mp3"
+		}
+		meeting.AudioFile = meeting.ID + ext
+		if err := h.store.Audio.Save(meeting.AudioFile, audioData); err != nil {
+			return apperr.Wrap(apperr.CodeStorageFailed, "保存录音失败", err)
 		}
 	}
 
-	// 生成Markdown报告
-	markdownReport, err := h.deepseekService.GenerateMarkdownReport(services.Meeting{
-		ID:           meeting.ID,
-		Title:        meeting.Title,
-		Date:         meeting.Date.Format("2006-01-02"),
-		Participants: meeting.Participants,
-		Transcript:   meeting.Transcript,
-		Summary:      meeting.Summary,
-		TodoItems:    todoItems,
-		Decisions:    decisions,
-		CreatedAt:    meeting.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:    meeting.UpdatedAt.Format(time.RFC3339),
-	})
-	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("生成Markdown报告失败: %v", err),
-		})
-	}
-
-	// 同步到Notion（如果需要）
+	// 同步到Notion（如果需要），写入发件箱由后台worker完成并重试
 	if syncToNotion {
-		err := h.notionService.SyncMeeting(meeting)
-		if err != nil {
-			// 不中断流程，只记录错误
-			fmt.Printf("同步到Notion失败: %v\n", err)
+		if _, err := h.notionOutbox.Enqueue(c.UserContext(), meeting); err != nil {
+			return apperr.Wrap(apperr.CodeStorageFailed, "创建Notion同步任务失败", err)
 		}
+	} else if err := h.store.Meetings.Put(meeting.ID, meeting); err != nil {
+		return apperr.Wrap(apperr.CodeStorageFailed, "保存会议失败", err)
 	}
+	h.saveActionItems(c, meeting, actionItems)
 
 	// 返回结果
-	return c.JSON(fiber.Map{
-		"meeting":        meeting,
-		"markdownReport": markdownReport,
+	return c.JSON(MeetingResponse{
+		Meeting:        meeting,
+		MarkdownReport: markdownReport,
 	})
 }
 
 // StreamAudio 流式处理音频
 func (h *Handler) StreamAudio(c *fiber.Ctx) error {
-	// 获取采样率
-	sampleRateStr := c.Query("sampleRate", "16000")
-	_, err := strconv.Atoi(sampleRateStr)
-	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("无效的采样率: %v", err),
-		})
+	// 获取采样率，默认16000
+	query := StreamAudioQuery{SampleRate: 16000}
+	if err := c.QueryParser(&query); err != nil || query.SampleRate <= 0 {
+		return badRequest(fmt.Sprintf("无效的采样率: %s", c.Query("sampleRate")))
 	}
 
 	// 读取请求体
 	audioStream := c.Request().BodyStream()
 	if audioStream == nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "请求体为空",
-		})
+		return badRequest("请求体为空")
+	}
+
+	set, err := h.servicesFor(c)
+	if err != nil {
+		return err
 	}
 
 	// 流式转录
-	resultChan, err := h.whisperService.StreamTranscribe(audioStream)
+	resultChan, err := set.Whisper.StreamTranscribe(c.UserContext(), audioStream)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("流式转录失败: %v", err),
-		})
+		return err
 	}
 
 	// 从通道读取结果
 	transcript := ""
 	for result := range resultChan {
-		if strings.HasPrefix(result, "错误:") {
-			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-				"error": result,
-			})
+		if detail, ok := strings.CutPrefix(result, "错误: "); ok {
+			return apperr.Wrap(apperr.CodeTranscriptionFailed, "流式转录失败", errors.New(detail))
 		}
 		transcript = result
 	}
 
 	// 返回结果
-	return c.JSON(fiber.Map{
-		"transcript": transcript,
-	})
+	return c.JSON(TranscriptResponse{Transcript: transcript})
 }
 
 // AnalyzeTranscript 分析会议转录并生成会议纪要
 func (h *Handler) AnalyzeTranscript(c *fiber.Ctx) error {
 	// 解析请求
-	var request struct {
-		Title      string `json:"title"`
-		Transcript string `json:"transcript"`
-	}
+	var request AnalyzeRequest
 
 	if err := c.BodyParser(&request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": fmt.Sprintf("解析请求体失败: %v", err),
-		})
+		return badRequest(fmt.Sprintf("解析请求体失败: %v", err))
+	}
+
+	invite, err := h.findInvite(c, request.CalendarEventUID)
+	if err != nil {
+		return err
 	}
 
 	title := request.Title
 	transcript := request.Transcript
+	if title == "" && invite != nil {
+		title = invite.Title
+	}
 
 	if title == "" {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "会议标题不能为空",
+		return badRequest("会议标题不能为空")
+	}
+
+	// 带时间戳的片段：没有转录全文时由片段拼接，分析时主题带有时间范围
+	segments := make([]models.TranscriptSegment, 0, len(request.Segments))
+	var texts []string
+	for _, segment := range request.Segments {
+		if segment.EndTime < segment.StartTime || segment.StartTime < 0 {
+			return badRequest(fmt.Sprintf("转录片段的时间无效: %v-%v", segment.StartTime, segment.EndTime))
+		}
+		segments = append(segments, models.TranscriptSegment{
+			ID:        uuid.New().String(),
+			StartTime: segment.StartTime,
+			EndTime:   segment.EndTime,
+			Speaker:   strings.TrimSpace(segment.Speaker),
+			Text:      strings.TrimSpace(segment.Text),
+			Timestamp: time.Now(),
 		})
+		texts = append(texts, strings.TrimSpace(segment.Text))
+	}
+	if transcript == "" {
+		transcript = strings.TrimSpace(strings.Join(texts, "\n"))
 	}
 
 	if transcript == "" {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "会议转录不能为空",
+		return badRequest("会议转录不能为空")
+	}
+
+	var requested []models.AgendaItem
+	for _, item := range request.Agenda {
+		requested = append(requested, models.AgendaItem{
+			ID:          uuid.New().String(),
+			Title:       strings.TrimSpace(item.Title),
+			Description: strings.TrimSpace(item.Description),
 		})
 	}
+	agenda, err := agendaItems(requested, invite)
+	if err != nil {
+		return err
+	}
+
+	profile, reportTemplate, err := h.analysisProfile(c, request.Profile, request.ReportTemplate)
+	if err != nil {
+		return err
+	}
+	polishReport := request.PolishReport
+
+	set, err := h.servicesFor(c)
+	if err != nil {
+		return err
+	}
 
 	// 分析转录内容
-	summary, todoItems, decisions, err := h.deepseekService.AnalyzeTranscript(title, transcript)
+	meeting, actionItems, err := h.analyzeMeeting(c, set, analysisInput{
+		title: title, transcript: transcript, segments: segments, invite: invite, agenda: agenda, profile: profile, series: request.Series,
+	})
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("分析转录内容失败: %v", err),
-		})
+		return err
+	}
+
+	// 按模板生成Markdown报告
+	markdownReport, err := h.renderReport(c, set, meeting, reportTemplate, polishReport)
+	if err != nil {
+		return err
+	}
+
+	// 保存会议
+	if err := h.store.Meetings.Put(meeting.ID, meeting); err != nil {
+		return apperr.Wrap(apperr.CodeStorageFailed, "保存会议失败", err)
+	}
+	h.saveActionItems(c, meeting, actionItems)
+
+	// 返回结果
+	return c.JSON(MeetingResponse{
+		Meeting:        meeting,
+		MarkdownReport: markdownReport,
+	})
+}
+
+// maxAgendaItems 一次会议的议程项数量上限
+const maxAgendaItems = 50
+
+// agendaItems 检查请求中的议程；请求中没有议程时从会议邀请的说明中识别带编号或项目符号的行
+func agendaItems(requested []models.AgendaItem, invite *services.Invite) ([]models.AgendaItem, error) {
+	if len(requested) == 0 && invite != nil {
+		requested = services.ParseAgenda(invite.Agenda, true)
+	}
+	if len(requested) > maxAgendaItems {
+		return nil, badRequest(fmt.Sprintf("议程项不能超过%d项", maxAgendaItems))
+	}
+	for _, item := range requested {
+		if item.Title == "" {
+			return nil, badRequest("议程项的标题不能为空")
+		}
+	}
+	return requested, nil
+}
+
+// analysisInput 分析会议的输入
+type analysisInput struct {
+	title      string
+	transcript string
+	segments   []models.TranscriptSegment // 带时间戳的转录片段，可以为空
+	invite     *services.Invite           // 会议邀请，提供时间、参会人和议程文本，可以为nil
+	agenda     []models.AgendaItem        // 结构化的议程，待办事项和决策按议程项归类
+	profile    *models.Profile            // 会议类型，可以为nil
+	series     string                     // 会议系列，为空时由标题得到
+}
+
+// analyzeMeeting 分析转录内容并创建会议。有转录片段时将带时间戳的会议记录交给模型，主题带有时间范围。
+// 同一系列之前会议中未完成的待办事项一并交给模型，返回会议保存后需要写入待办台账的条目
+func (h *Handler) analyzeMeeting(c *fiber.Ctx, set *services.ServiceSet, in analysisInput) (*models.Meeting, []*models.ActionItem, error) {
+	title, invite, agenda, profile := in.title, in.invite, in.agenda, in.profile
+	series := strings.TrimSpace(in.series)
+	if series == "" {
+		series = services.SeriesKey(title)
+	}
+	openItems, err := h.actionItems.OpenItems(principal(c).WorkspaceID, series)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	meetingContext := invite.Context()
+	meetingContext.AgendaItems = agenda
+	meetingContext.OpenItems = openItems
+	transcript := in.transcript
+	if len(in.segments) > 0 {
+		transcript = services.TimestampedTranscript(in.segments)
+	}
+	analysis, err := set.DeepSeek.AnalyzeTranscript(c.UserContext(), title, transcript, meetingContext, profile)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// 创建会议对象
 	meeting := &models.Meeting{
 		ID:           uuid.New().String(),
+		WorkspaceID:  principal(c).WorkspaceID,
+		CreatedBy:    principal(c).UserID,
 		Title:        title,
+		Series:       series,
 		Date:         time.Now(),
-		Participants: []string{}, // 这里可以从请求中获取参与者信息
-		Transcript:   transcript,
-		Summary:      summary,
-		TodoItems:    make([]models.TodoItem, len(todoItems)),
-		Decisions:    make([]models.Decision, len(decisions)),
+		Participants: []string{}, // 有会议邀请时由applyInvite填入
+		Transcript:   in.transcript,
+		Summary:      analysis.Summary,
+		TodoItems:    make([]models.TodoItem, len(analysis.TodoItems)),
+		Decisions:    make([]models.Decision, len(analysis.Decisions)),
+		ExtraFields:  analysis.Fields,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
+
+		OpenQuestions:   analysis.OpenQuestions,
+		Risks:           analysis.Risks,
+		Topics:          analysis.Topics,
+		Keywords:        analysis.Keywords,
+		SpeakerInsights: analysis.SpeakerInsights,
+	}
+	for i := range in.segments {
+		in.segments[i].MeetingID = meeting.ID
+	}
+	if len(in.segments) > 0 {
+		meeting.Segments = in.segments
+	}
+	applyInvite(meeting, invite)
+	if profile != nil {
+		meeting.Profile = profile.Name
+	}
+
+	// 议程项的讨论情况，分析结果中的议程编号转换为议程项ID
+	for i, item := range agenda {
+		item.Status = analysis.Agenda[i].Status
+		item.Summary = analysis.Agenda[i].Summary
+		meeting.AgendaItems = append(meeting.AgendaItems, item)
+	}
+	agendaID := func(index int) string {
+		if index == 0 {
+			return ""
+		}
+		return agenda[index-1].ID
 	}
 
 	// 转换待办事项
-	for i, todo := range todoItems {
+	for i, todo := range analysis.TodoItems {
 		dueDate, err := time.Parse("2006-01-02", todo.DueDate)
 		if err != nil {
 			dueDate = time.Time{} // 如果解析失败，使用零值
@@ -273,196 +434,54 @@ func (h *Handler) AnalyzeTranscript(c *fiber.Ctx) error {
 			Assignee:    todo.Assignee,
 			DueDate:     dueDate,
 			Status:      todo.Status,
+			AgendaID:    agendaID(todo.AgendaItem),
 		}
 	}
 
 	// 转换决策点
-	for i, decision := range decisions {
+	for i, decision := range analysis.Decisions {
 		meeting.Decisions[i] = models.Decision{
 			ID:          decision.ID,
 			Description: decision.Description,
 			MadeBy:      decision.MadeBy,
+			AgendaID:    agendaID(decision.AgendaItem),
 		}
 	}
+	return meeting, h.actionItems.Reconcile(c.UserContext(), set.DeepSeek, meeting, openItems, analysis), nil
+}
 
-	// 生成Markdown报告
-	markdownReport, err := h.deepseekService.GenerateMarkdownReport(services.Meeting{
-		ID:           meeting.ID,
-		Title:        meeting.Title,
-		Date:         meeting.Date.Format("2006-01-02"),
-		Participants: meeting.Participants,
-		Transcript:   meeting.Transcript,
-		Summary:      meeting.Summary,
-		TodoItems:    todoItems,
-		Decisions:    decisions,
-		CreatedAt:    meeting.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:    meeting.UpdatedAt.Format(time.RFC3339),
-	})
-	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("生成Markdown报告失败: %v", err),
-		})
+// saveActionItems 会议保存后写入待办台账。会议已经保存，失败时只记录日志，不影响本次请求
+func (h *Handler) saveActionItems(c *fiber.Ctx, meeting *models.Meeting, items []*models.ActionItem) {
+	if err := h.actionItems.Save(items); err != nil {
+		slog.ErrorContext(c.UserContext(), "更新待办台账失败", "meeting_id", meeting.ID, "error", err)
 	}
-
-	// 返回结果
-	return c.JSON(fiber.Map{
-		"meeting":        meeting,
-		"markdownReport": markdownReport,
-	})
 }
 
-// SyncToNotionHandler 处理将会议数据同步到Notion
-func (h *Handler) SyncToNotionHandler(c *fiber.Ctx) error {
-	// 打印原始请求体
-	requestBody := string(c.Body())
-	fmt.Printf("【调试】原始请求体: %s\n", requestBody)
-
-	var request struct {
-		Meeting        models.Meeting `json:"meeting"`
-		MarkdownReport string         `json:"markdownReport"`
+// syncMeeting 使用调用方工作区的Notion凭据同步会议
+func (h *Handler) syncMeeting(c *fiber.Ctx, meeting *models.Meeting) error {
+	meeting.WorkspaceID = principal(c).WorkspaceID
+	set, err := h.servicesFor(c)
+	if err != nil {
+		return err
 	}
+	return set.Notion.SyncMeeting(c.UserContext(), meeting)
+}
 
+// SyncToNotion 处理将会议数据同步到Notion
+func (h *Handler) SyncToNotion(c *fiber.Ctx) error {
+	var request SyncToNotionRequest
 	if err := c.BodyParser(&request); err != nil {
-		// 尝试解析旧格式的请求
-		var oldRequest struct {
-			ID           string   `json:"id"`
-			Title        string   `json:"title"`
-			Date         string   `json:"date"`
-			Participants []string `json:"participants"`
-			Transcript   string   `json:"transcript"`
-			Summary      string   `json:"summary"`
-			TodoItems    []struct {
-				Description string `json:"description"`
-				Assignee    string `json:"assignee"`
-				DueDate     string `json:"dueDate"`
-				Status      string `json:"status"`
-			} `json:"todo_items"`
-			Decisions []struct {
-				Description string `json:"description"`
-				MadeBy      string `json:"madeBy"`
-			} `json:"decisions"`
-			CreatedAt string `json:"created_at"`
-			UpdatedAt string `json:"updated_at"`
-		}
-
-		if err2 := c.BodyParser(&oldRequest); err2 != nil {
-			fmt.Printf("解析请求体失败: %v\n", err)
-			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-				"error": fmt.Sprintf("解析请求体失败: %v", err),
-			})
-		}
-
-		// 将旧格式转换为新格式
-		meeting := &models.Meeting{
-			ID:           oldRequest.ID,
-			Title:        oldRequest.Title,
-			Participants: oldRequest.Participants,
-			Transcript:   oldRequest.Transcript,
-			Summary:      oldRequest.Summary,
-		}
-
-		// 解析日期
-		if oldRequest.Date != "" {
-			date, err := time.Parse("2006-01-02", oldRequest.Date)
-			if err != nil {
-				fmt.Printf("解析日期失败: %v，使用当前日期\n", err)
-				meeting.Date = time.Now()
-			} else {
-				meeting.Date = date
-			}
-		} else {
-			meeting.Date = time.Now()
-		}
-
-		// 处理待办事项
-		if len(oldRequest.TodoItems) > 0 {
-			meeting.TodoItems = make([]models.TodoItem, len(oldRequest.TodoItems))
-			for i, item := range oldRequest.TodoItems {
-				todoItem := models.TodoItem{
-					ID:          uuid.New().String(),
-					Description: item.Description,
-					Assignee:    item.Assignee,
-					Status:      item.Status,
-				}
-
-				// 解析截止日期
-				if item.DueDate != "" {
-					dueDate, err := time.Parse("2006-01-02", item.DueDate)
-					if err == nil {
-						todoItem.DueDate = dueDate
-					}
-				}
-
-				meeting.TodoItems[i] = todoItem
-			}
-		}
-
-		// 处理决策事项
-		if len(oldRequest.Decisions) > 0 {
-			meeting.Decisions = make([]models.Decision, len(oldRequest.Decisions))
-			for i, item := range oldRequest.Decisions {
-				meeting.Decisions[i] = models.Decision{
-					ID:          uuid.New().String(),
-					Description: item.Description,
-					MadeBy:      item.MadeBy,
-				}
-			}
-		}
-
-		// 解析创建和更新时间
-		if oldRequest.CreatedAt != "" {
-			createdAt, err := time.Parse(time.RFC3339, oldRequest.CreatedAt)
-			if err == nil {
-				meeting.CreatedAt = createdAt
-			} else {
-				meeting.CreatedAt = time.Now()
-			}
-		} else {
-			meeting.CreatedAt = time.Now()
-		}
-
-		if oldRequest.UpdatedAt != "" {
-			updatedAt, err := time.Parse(time.RFC3339, oldRequest.UpdatedAt)
-			if err == nil {
-				meeting.UpdatedAt = updatedAt
-			} else {
-				meeting.UpdatedAt = time.Now()
-			}
-		} else {
-			meeting.UpdatedAt = time.Now()
-		}
-
-		// 记录接收到的请求数据
-		meetingBytes, _ := json.Marshal(meeting)
-		fmt.Printf("转换后的会议数据：%s\n", string(meetingBytes))
-
-		// 同步到Notion
-		err = h.notionService.SyncMeeting(meeting)
-		if err != nil {
-			fmt.Printf("同步到Notion失败: %v\n", err)
-			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-				"error": fmt.Sprintf("同步到Notion失败: %v", err),
-			})
-		}
-
-		return c.JSON(fiber.Map{
-			"status":       "success",
-			"notionPageId": meeting.NotionPageID,
-		})
+		return badRequest(fmt.Sprintf("解析请求体失败: %v", err))
 	}
 
-	// 新格式请求处理
 	meeting := &request.Meeting
 
-	// 记录接收到的请求数据
-	meetingBytes, _ := json.Marshal(meeting)
-	fmt.Printf("【调试】解析后的会议数据：%s\n", string(meetingBytes))
-	fmt.Printf("【调试】会议标题：'%s'，长度：%d\n", meeting.Title, len(meeting.Title))
+	// 会议内容默认不写入日志，开启LOG_CAPTURE_CONTENT后以debug级别记录
+	slog.DebugContext(c.UserContext(), "收到Notion同步请求", "title", meeting.Title, "body", c.Body())
 
 	// 如果日期为空，使用当前日期
 	if meeting.Date.IsZero() {
 		meeting.Date = time.Now()
-		fmt.Printf("日期为空，已设置为当前日期: %s\n", meeting.Date.Format("2006-01-02"))
 	}
 
 	// 确保待办事项和决策都有ID
@@ -479,103 +498,13 @@ func (h *Handler) SyncToNotionHandler(c *fiber.Ctx) error {
 	}
 
 	// 同步到Notion
-	err := h.notionService.SyncMeeting(meeting)
-	if err != nil {
-		fmt.Printf("同步到Notion失败: %v\n", err)
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("同步到Notion失败: %v", err),
-		})
-	}
-
-	return c.JSON(fiber.Map{
-		"status":       "success",
-		"notionPageId": meeting.NotionPageID,
-	})
-}
-
-// SyncToNotion 同步会议数据到Notion
-func (h *Handler) SyncToNotion(c *fiber.Ctx) error {
-	var request struct {
-		ID           string   `json:"id"`
-		Title        string   `json:"title"`
-		Date         string   `json:"date"`
-		Participants []string `json:"participants"`
-		Transcript   string   `json:"transcript"`
-		Summary      string   `json:"summary"`
-		TodoItems    []struct {
-			Description string `json:"description"`
-			Assignee    string `json:"assignee"`
-			DueDate     string `json:"dueDate"`
-			Status      string `json:"status"`
-		} `json:"todo_items"`
-		Decisions []struct {
-			Description string `json:"description"`
-			MadeBy      string `json:"madeBy"`
-		} `json:"decisions"`
-		CreatedAt string `json:"created_at"`
-		UpdatedAt string `json:"updated_at"`
-	}
-
-	if err := c.BodyParser(&request); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "无法解析请求数据",
-		})
-	}
-
-	// 解析日期
-	date, err := time.Parse("2006-01-02", request.Date)
-	if err != nil {
-		date = time.Now() // 如果解析失败，使用当前时间
-	}
-
-	// 构建会议对象
-	meeting := &models.Meeting{
-		ID:           request.ID,
-		Title:        request.Title,
-		Date:         date,
-		Participants: request.Participants,
-		Transcript:   request.Transcript,
-		Summary:      request.Summary,
-		TodoItems:    make([]models.TodoItem, len(request.TodoItems)),
-		Decisions:    make([]models.Decision, len(request.Decisions)),
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-
-	// 转换待办事项
-	for i, item := range request.TodoItems {
-		dueDate, err := time.Parse("2006-01-02", item.DueDate)
-		if err != nil {
-			dueDate = time.Time{} // 如果解析失败，使用零值
-		}
-		meeting.TodoItems[i] = models.TodoItem{
-			ID:          uuid.New().String(),
-			Description: item.Description,
-			Assignee:    item.Assignee,
-			DueDate:     dueDate,
-			Status:      item.Status,
-		}
-	}
-
-	// 转换决策点
-	for i, decision := range request.Decisions {
-		meeting.Decisions[i] = models.Decision{
-			ID:          uuid.New().String(),
-			Description: decision.Description,
-			MadeBy:      decision.MadeBy,
-		}
-	}
-
-	// 同步到Notion
-	err = h.notionService.SyncMeeting(meeting)
-	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": fmt.Sprintf("同步到Notion失败: %v", err),
-		})
+	if err := h.syncMeeting(c, meeting); err != nil {
+		return err
 	}
 
-	return c.JSON(fiber.Map{
-		"status":       "success",
-		"notionPageId": meeting.NotionPageID,
+	return c.JSON(NotionSyncResponse{
+		Status:           "success",
+		NotionPageID:     meeting.NotionPageID,
+		UnresolvedPeople: meeting.UnresolvedPeople,
 	})
 }
@@ -1,19 +0,0 @@
-package api
-
-import (
-	"encoding/json"
-	"net/http"
-)
-
-// 辅助函数，响应JSON数据
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	response, _ := json.Marshal(payload)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	w.Write(response)
-}
-
-// 辅助函数，响应错误
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
-}
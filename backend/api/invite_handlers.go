@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"meeting-mm/apperr"
+	"meeting-mm/models"
+	"meeting-mm/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxInviteSize 上传的会议邀请文件的大小上限
+const maxInviteSize = 1 << 20
+
+// ListCalendarEvents 列出日历目录中的会议，date不为空时只列出当天开始的会议
+func (h *Handler) ListCalendarEvents(c *fiber.Ctx) error {
+	var query CalendarEventsQuery
+	if err := c.QueryParser(&query); err != nil {
+		return badRequest(fmt.Sprintf("解析查询参数失败: %v", err))
+	}
+	var day time.Time
+	if query.Date != "" {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", query.Date, time.Local); err != nil {
+			return badRequest(fmt.Sprintf("无效的日期: %s", query.Date))
+		}
+	}
+
+	events, err := h.calendar.Events(principal(c).WorkspaceID)
+	if err != nil {
+		return calendarError(err)
+	}
+	response := CalendarEventListResponse{Events: []*services.Invite{}}
+	for _, event := range events {
+		start := event.Start.In(time.Local)
+		if day.IsZero() || (!start.Before(day) && start.Before(day.AddDate(0, 0, 1))) {
+			response.Events = append(response.Events, event)
+		}
+	}
+	return c.JSON(response)
+}
+
+// formInvite 读取上传表单中的会议邀请：invite为.ics文件，calendarEventUid为日历目录中的会议；
+// 同时提供时calendarEventUid从上传的文件中选择会议。都没有时返回nil
+func (h *Handler) formInvite(c *fiber.Ctx) (*services.Invite, error) {
+	uid := c.FormValue("calendarEventUid")
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, badRequest(fmt.Sprintf("解析表单失败: %v", err))
+	}
+	if len(form.File["invite"]) == 0 {
+		return h.findInvite(c, uid)
+	}
+	file := form.File["invite"][0]
+	if file.Size > maxInviteSize {
+		return nil, apperr.New(apperr.CodePayloadTooLarge, "会议邀请文件过大")
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInternal, "打开会议邀请失败", err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInternal, "读取会议邀请失败", err)
+	}
+
+	invite, err := services.ParseInvite(data, uid)
+	if err != nil {
+		return nil, calendarError(err)
+	}
+	return invite, nil
+}
+
+// findInvite 按UID在当前工作区的日历目录中查找会议邀请，uid为空时返回nil
+func (h *Handler) findInvite(c *fiber.Ctx, uid string) (*services.Invite, error) {
+	if uid == "" {
+		return nil, nil
+	}
+	invite, err := h.calendar.Find(principal(c).WorkspaceID, uid)
+	if err != nil {
+		return nil, calendarError(err)
+	}
+	return invite, nil
+}
+
+// applyInvite 用会议邀请填写会议的时间、参会人和议程，invite为nil时不做修改
+func applyInvite(meeting *models.Meeting, invite *services.Invite) {
+	if invite == nil {
+		return
+	}
+	if !invite.Start.IsZero() {
+		meeting.Date = invite.Start
+	}
+	meeting.Participants = append([]string{}, invite.Attendees...)
+	meeting.Agenda = invite.Agenda
+	meeting.CalendarUID = invite.UID
+}
+
+// calendarError 将读取会议邀请时的错误转换为应用错误
+func calendarError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidInvite), errors.Is(err, services.ErrCalendarNotConfigured):
+		return badRequest(err.Error())
+	case errors.Is(err, services.ErrInviteNotFound):
+		return apperr.New(apperr.CodeNotFound, err.Error())
+	}
+	return apperr.Wrap(apperr.CodeStorageFailed, "读取日历失败", err)
+}
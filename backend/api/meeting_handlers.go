@@ -0,0 +1,116 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"meeting-mm/apperr"
+	"meeting-mm/models"
+	"meeting-mm/services"
+	"meeting-mm/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListMeetings 列出当前工作区已保存的会议，按会议日期倒序
+func (h *Handler) ListMeetings(c *fiber.Ctx) error {
+	meetings, err := h.workspaceMeetings(principal(c).WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if meetings == nil {
+		meetings = []*models.Meeting{}
+	}
+
+	sort.Slice(meetings, func(i, j int) bool {
+		return meetings[i].Date.After(meetings[j].Date)
+	})
+
+	return c.JSON(MeetingListResponse{Meetings: meetings})
+}
+
+// GetMeeting 获取单个会议，包括其Notion同步状态
+func (h *Handler) GetMeeting(c *fiber.Ctx) error {
+	meeting, err := h.getMeeting(c, c.Params("id"))
+	if err != nil {
+		return meetingError(err)
+	}
+
+	return c.JSON(meeting)
+}
+
+// GetMeetingAudio 返回会议保留的原始录音
+func (h *Handler) GetMeetingAudio(c *fiber.Ctx) error {
+	meeting, err := h.getMeeting(c, c.Params("id"))
+	if err != nil {
+		return meetingError(err)
+	}
+	return h.sendMeetingAudio(c, meeting)
+}
+
+// GetSignedMeetingAudio 通过签名链接免登录返回会议录音，供Notion页面中的录音链接使用
+func (h *Handler) GetSignedMeetingAudio(c *fiber.Ctx) error {
+	var query SignedAudioQuery
+	if err := c.QueryParser(&query); err != nil {
+		return badRequest(fmt.Sprintf("解析查询参数失败: %v", err))
+	}
+
+	id := c.Params("id")
+	expected := services.AudioSignature(h.cfg.AuthSecret, id, query.Exp)
+	if subtle.ConstantTimeCompare([]byte(query.Sig), []byte(expected)) != 1 {
+		return apperr.New(apperr.CodeForbidden, "录音链接签名无效")
+	}
+	if time.Now().Unix() > query.Exp {
+		return apperr.New(apperr.CodeForbidden, "录音链接已过期，请重新同步会议以生成新的链接")
+	}
+
+	meeting, err := h.store.Meetings.Get(id)
+	if err != nil {
+		return meetingError(err)
+	}
+	return h.sendMeetingAudio(c, meeting)
+}
+
+// sendMeetingAudio 发送会议录音文件
+func (h *Handler) sendMeetingAudio(c *fiber.Ctx, meeting *models.Meeting) error {
+	if meeting.AudioFile == "" {
+		return apperr.New(apperr.CodeNotFound, "该会议没有保留录音")
+	}
+
+	path, err := h.store.Audio.Path(meeting.AudioFile)
+	if err == nil {
+		_, err = h.store.Audio.Stat(meeting.AudioFile)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return apperr.New(apperr.CodeNotFound, "录音文件不存在")
+		}
+		return apperr.Wrap(apperr.CodeStorageFailed, "读取录音失败", err)
+	}
+
+	c.Set(fiber.HeaderContentType, services.AudioContentType(meeting.AudioFile))
+	return c.SendFile(path)
+}
+
+// getMeeting 读取当前工作区的会议，其他工作区的会议视为不存在
+func (h *Handler) getMeeting(c *fiber.Ctx, id string) (*models.Meeting, error) {
+	meeting, err := h.store.Meetings.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if meeting.WorkspaceID != principal(c).WorkspaceID {
+		return nil, storage.ErrNotFound
+	}
+	return meeting, nil
+}
+
+// meetingError 将读取会议时的错误转换为应用错误
+func meetingError(err error) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return apperr.New(apperr.CodeNotFound, "会议不存在")
+	}
+	return apperr.Wrap(apperr.CodeStorageFailed, "读取会议失败", err)
+}
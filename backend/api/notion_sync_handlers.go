@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"meeting-mm/apperr"
+	"meeting-mm/models"
+	"meeting-mm/services"
+	"meeting-mm/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListNotionSyncs 列出Notion同步任务，可通过status参数筛选（pending、failed、synced、cancelled）
+func (h *Handler) ListNotionSyncs(c *fiber.Ctx) error {
+	var query NotionSyncListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return badRequest(fmt.Sprintf("解析查询参数失败: %v", err))
+	}
+
+	status := query.Status
+	switch status {
+	case "", models.SyncStatusPending, models.SyncStatusFailed, models.SyncStatusSynced, models.SyncStatusCancelled:
+	default:
+		return badRequest(fmt.Sprintf("无效的同步状态: %s", status))
+	}
+
+	jobs, err := h.notionOutbox.List(principal(c).WorkspaceID, status)
+	if err != nil {
+		return apperr.Wrap(apperr.CodeStorageFailed, "读取同步任务失败", err)
+	}
+	if jobs == nil {
+		jobs = []*models.NotionSync{}
+	}
+
+	return c.JSON(NotionSyncListResponse{Syncs: jobs})
+}
+
+// RetryNotionSync 重新排队一个失败或已取消的同步任务
+func (h *Handler) RetryNotionSync(c *fiber.Ctx) error {
+	job, err := h.notionOutbox.Retry(principal(c).WorkspaceID, c.Params("id"))
+	if err != nil {
+		return syncJobError(err)
+	}
+	return c.JSON(job)
+}
+
+// CancelNotionSync 取消一个尚未成功的同步任务
+func (h *Handler) CancelNotionSync(c *fiber.Ctx) error {
+	job, err := h.notionOutbox.Cancel(principal(c).WorkspaceID, c.Params("id"))
+	if err != nil {
+		return syncJobError(err)
+	}
+	return c.JSON(job)
+}
+
+// syncJobError 将发件箱错误转换为应用错误
+func syncJobError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return apperr.New(apperr.CodeNotFound, "同步任务不存在")
+	case errors.Is(err, services.ErrInvalidSyncState):
+		return apperr.New(apperr.CodeConflict, err.Error())
+	default:
+		return apperr.Wrap(apperr.CodeStorageFailed, "更新同步任务失败", err)
+	}
+}
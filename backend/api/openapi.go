@@ -0,0 +1,61 @@
+package api
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"meeting-mm/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// apiVersion OpenAPI文档中的API版本
+const apiVersion = "1.0.0"
+
+var (
+	specOnce sync.Once
+	spec     *openapi.Document
+	specErr  error
+)
+
+// OpenAPI 根据路由表生成OpenAPI文档
+func OpenAPI() (*openapi.Document, error) {
+	specOnce.Do(func() {
+		var routes []openapi.Route
+		for _, route := range Routes(&Handler{}) {
+			routes = append(routes, openapi.Route{
+				OperationID: operationID(route.Handler),
+				Method:      route.Method,
+				Path:        route.Path,
+				Summary:     route.Summary,
+				Tag:         strings.Split(strings.TrimPrefix(route.Path, "/"), "/")[0],
+				Public:      route.Public,
+				Query:       route.Query,
+				Request:     route.Request,
+				Response:    route.Response,
+				Status:      route.Status,
+				Responses:   route.Responses,
+			})
+		}
+		spec, specErr = openapi.Build(openapi.Info{Title: "Meeting-MM API", Version: apiVersion}, routes, ErrorResponse{})
+	})
+	return spec, specErr
+}
+
+// operationID 使用处理器的方法名作为operationId
+func operationID(handler fiber.Handler) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// OpenAPISpec 返回OpenAPI文档
+func (h *Handler) OpenAPISpec(c *fiber.Ctx) error {
+	doc, err := OpenAPI()
+	if err != nil {
+		return err
+	}
+	return c.JSON(doc)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+
+	"meeting-mm/models"
+	"meeting-mm/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListProfiles 列出当前工作区的会议类型
+func (h *Handler) ListProfiles(c *fiber.Ctx) error {
+	profiles, err := h.profiles.List(principal(c).WorkspaceID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(ProfileListResponse{Profiles: profiles})
+}
+
+// GetProfile 按名称返回会议类型
+func (h *Handler) GetProfile(c *fiber.Ctx) error {
+	profile, err := h.profiles.Get(principal(c).WorkspaceID, c.Params("name"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(profile)
+}
+
+// CreateProfile 创建会议类型，只有工作区所有者可以操作
+func (h *Handler) CreateProfile(c *fiber.Ctx) error {
+	input, err := h.profileInput(c)
+	if err != nil {
+		return err
+	}
+	profile, err := h.profiles.Create(principal(c), input)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(profile)
+}
+
+// UpdateProfile 整体替换会议类型，请求中的名称与路径不同时重命名
+func (h *Handler) UpdateProfile(c *fiber.Ctx) error {
+	input, err := h.profileInput(c)
+	if err != nil {
+		return err
+	}
+	profile, err := h.profiles.Update(principal(c), c.Params("name"), input)
+	if err != nil {
+		return err
+	}
+	return c.JSON(profile)
+}
+
+// DeleteProfile 删除会议类型，已分析的会议不受影响
+func (h *Handler) DeleteProfile(c *fiber.Ctx) error {
+	profile, err := h.profiles.Delete(principal(c), c.Params("name"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(profile)
+}
+
+// profileInput 解析会议类型请求并检查报告模板
+func (h *Handler) profileInput(c *fiber.Ctx) (services.ProfileInput, error) {
+	var input services.ProfileInput
+	if err := c.BodyParser(&input); err != nil {
+		return input, badRequest(fmt.Sprintf("解析请求体失败: %v", err))
+	}
+	if !h.reports.Has(input.ReportTemplate) {
+		return input, unknownReportTemplate(input.ReportTemplate)
+	}
+	return input, nil
+}
+
+// analysisProfile 查找分析请求指定的会议类型（name为空时返回nil），并确定报告模板：
+// 请求中的模板优先，其次是会议类型的模板。在耗时的转录和分析之前检查
+func (h *Handler) analysisProfile(c *fiber.Ctx, name, reportTemplate string) (*models.Profile, string, error) {
+	var profile *models.Profile
+	if name != "" {
+		var err error
+		if profile, err = h.profiles.Get(principal(c).WorkspaceID, name); err != nil {
+			return nil, "", err
+		}
+		if reportTemplate == "" {
+			reportTemplate = profile.ReportTemplate
+		}
+	}
+	if !h.reports.Has(reportTemplate) {
+		return nil, "", unknownReportTemplate(reportTemplate)
+	}
+	return profile, reportTemplate, nil
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"meeting-mm/apperr"
+	"meeting-mm/models"
+	"meeting-mm/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListReportTemplates 列出可用的报告模板
+func (h *Handler) ListReportTemplates(c *fiber.Ctx) error {
+	return c.JSON(ReportTemplateListResponse{
+		Default:   h.reports.DefaultTemplate(),
+		Templates: h.reports.Templates(),
+	})
+}
+
+// GetMeetingReport 按模板渲染已保存会议的Markdown报告，不调用模型；polish为true时再由模型润色
+func (h *Handler) GetMeetingReport(c *fiber.Ctx) error {
+	var query MeetingReportQuery
+	if err := c.QueryParser(&query); err != nil {
+		return badRequest(fmt.Sprintf("解析查询参数失败: %v", err))
+	}
+
+	meeting, err := h.getMeeting(c, c.Params("id"))
+	if err != nil {
+		return meetingError(err)
+	}
+	set, err := h.servicesFor(c)
+	if err != nil {
+		return err
+	}
+
+	markdown, err := h.renderReport(c, set, meeting, query.Template, query.Polish)
+	if err != nil {
+		return err
+	}
+	template := query.Template
+	if template == "" {
+		template = h.reports.DefaultTemplate()
+	}
+	return c.JSON(MeetingReportResponse{Template: template, Markdown: markdown})
+}
+
+// renderReport 按模板渲染会议报告，polish为true时再由模型润色
+func (h *Handler) renderReport(c *fiber.Ctx, set *services.ServiceSet, meeting *models.Meeting, template string, polish bool) (string, error) {
+	markdown, err := h.reports.Render(template, meeting)
+	if errors.Is(err, services.ErrUnknownReportTemplate) {
+		return "", unknownReportTemplate(template)
+	}
+	if err != nil {
+		return "", apperr.Wrap(apperr.CodeInternal, "渲染报告失败", err)
+	}
+	if !polish {
+		return markdown, nil
+	}
+	return set.DeepSeek.PolishReport(c.UserContext(), markdown)
+}
+
+// unknownReportTemplate 请求的报告模板不存在
+func unknownReportTemplate(name string) error {
+	return badRequest(fmt.Sprintf("%s: %s", services.ErrUnknownReportTemplate.Error(), name))
+}
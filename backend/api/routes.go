@@ -1,30 +1,129 @@
 package api
 
 import (
+	"meeting-mm/export"
+	"meeting-mm/models"
+	"meeting-mm/openapi"
+	"meeting-mm/services"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
-// RegisterRoutes 注册API路由
-func RegisterRoutes(app *fiber.App, handler *Handler) {
-	// 添加中间件
-	app.Use(cors.New())
-	app.Use(logger.New())
-	app.Use(recover.New())
+// audioResponse 录音文件响应
+var audioResponse = openapi.Binary{ContentType: "audio/*"}
+
+// exportResponse 导出文件响应，内容类型取决于导出格式
+var exportResponse = openapi.Binary{
+	ContentType:  "text/markdown",
+	Alternatives: []string{"text/html", export.ContentType(export.FormatDOCX), export.ContentType(export.FormatPDF)},
+}
+
+// calendarResponse iCalendar文件响应
+var calendarResponse = openapi.Binary{ContentType: "text/calendar"}
+
+// transcriptResponse 会议记录文件响应，内容类型取决于格式
+var transcriptResponse = openapi.Binary{
+	ContentType:  "application/x-subrip",
+	Alternatives: []string{"text/vtt", "text/plain", "application/json"},
+}
+
+// Route 路由表中的一条路由
+type Route struct {
+	Method   string
+	Path     string // 相对于/api的路径
+	Summary  string
+	Public   bool // 无需登录即可访问
+	Handler  fiber.Handler
+	Query    interface{} // 查询参数类型，用于OpenAPI文档
+	Request  interface{} // 请求体类型，用于OpenAPI文档
+	Response interface{} // 成功响应类型，用于OpenAPI文档
+	Status   int         // 成功响应的状态码，默认200
+	// Responses 其他状态码的响应类型，用于OpenAPI文档；未声明的错误状态码返回ErrorResponse
+	Responses map[int]interface{}
+}
+
+// Routes 返回全部API路由，是服务器唯一的路由表
+func Routes(handler *Handler) []Route {
+	return []Route{
+		// 健康检查
+		{Method: fiber.MethodGet, Path: "/health", Summary: "健康检查（同 /health/live）", Public: true, Handler: handler.HealthCheck, Response: HealthResponse{}},
+		{Method: fiber.MethodGet, Path: "/health/live", Summary: "存活检查", Public: true, Handler: handler.Live, Response: HealthResponse{}},
+		{Method: fiber.MethodGet, Path: "/health/ready", Summary: "就绪检查", Public: true, Handler: handler.Ready, Response: services.HealthReport{},
+			Responses: map[int]interface{}{fiber.StatusServiceUnavailable: services.HealthReport{}}},
+		{Method: fiber.MethodGet, Path: "/openapi.json", Summary: "OpenAPI文档", Public: true, Handler: handler.OpenAPISpec, Response: map[string]interface{}{}},
 
-	// API路由组
-	api := app.Group("/api")
+		// 认证
+		{Method: fiber.MethodPost, Path: "/auth/register", Summary: "注册用户", Public: true, Handler: handler.Register, Request: RegisterRequest{}, Response: UserResponse{}, Status: fiber.StatusCreated},
+		{Method: fiber.MethodPost, Path: "/auth/login", Summary: "登录", Public: true, Handler: handler.Login, Request: LoginRequest{}, Response: LoginResponse{}},
+		{Method: fiber.MethodPost, Path: "/auth/logout", Summary: "退出登录", Public: true, Handler: handler.Logout, Response: StatusResponse{}},
+		{Method: fiber.MethodGet, Path: "/auth/me", Summary: "当前用户", Handler: handler.Me, Response: CurrentUserResponse{}},
+		{Method: fiber.MethodGet, Path: "/auth/keys", Summary: "API密钥列表", Handler: handler.ListAPIKeys, Response: APIKeyListResponse{}},
+		{Method: fiber.MethodPost, Path: "/auth/keys", Summary: "创建API密钥", Handler: handler.CreateAPIKey, Request: CreateAPIKeyRequest{}, Response: APIKeyResponse{}, Status: fiber.StatusCreated},
+		{Method: fiber.MethodDelete, Path: "/auth/keys/:id", Summary: "吊销API密钥", Handler: handler.RevokeAPIKey, Response: APIKeyResponse{}},
 
-	// 健康检查
-	api.Get("/health", handler.HealthCheck)
+		// 工作区
+		{Method: fiber.MethodGet, Path: "/workspace", Summary: "当前工作区", Handler: handler.GetWorkspace, Response: WorkspaceResponse{}},
+		{Method: fiber.MethodPost, Path: "/workspace/members", Summary: "添加工作区成员", Handler: handler.AddWorkspaceMember, Request: AddMemberRequest{}, Response: UserResponse{}, Status: fiber.StatusCreated},
+		{Method: fiber.MethodGet, Path: "/workspace/settings", Summary: "工作区设置", Handler: handler.GetWorkspaceSettings, Response: WorkspaceSettingsResponse{}},
+		{Method: fiber.MethodPut, Path: "/workspace/settings", Summary: "更新工作区设置", Handler: handler.UpdateWorkspaceSettings, Request: services.WorkspaceSettingsUpdate{}, Response: WorkspaceSettingsResponse{}},
 
-	// 音频相关路由
-	api.Post("/audio/upload", handler.UploadAudio)
-	api.Post("/audio/stream", handler.StreamAudio)
+		// 音频相关路由
+		{Method: fiber.MethodPost, Path: "/audio/upload", Summary: "上传音频并生成会议纪要", Handler: handler.UploadAudio, Request: UploadAudioForm{}, Response: MeetingResponse{}},
+		{Method: fiber.MethodPost, Path: "/audio/stream", Summary: "流式处理音频", Handler: handler.StreamAudio, Query: StreamAudioQuery{}, Request: openapi.Binary{ContentType: "application/octet-stream"}, Response: TranscriptResponse{}},
+
+		// 会议相关路由
+		{Method: fiber.MethodPost, Path: "/meetings/analyze", Summary: "分析会议转录", Handler: handler.AnalyzeTranscript, Request: AnalyzeRequest{}, Response: MeetingResponse{}},
+		{Method: fiber.MethodPost, Path: "/meetings/sync-notion", Summary: "同步会议到Notion", Handler: handler.SyncToNotion, Request: SyncToNotionRequest{}, Response: NotionSyncResponse{}},
+		{Method: fiber.MethodGet, Path: "/meetings", Summary: "会议列表", Handler: handler.ListMeetings, Response: MeetingListResponse{}},
+		{Method: fiber.MethodGet, Path: "/meetings/:id", Summary: "会议详情", Handler: handler.GetMeeting, Response: models.Meeting{}},
+		{Method: fiber.MethodGet, Path: "/meetings/:id/report", Summary: "按模板渲染会议报告", Handler: handler.GetMeetingReport, Query: MeetingReportQuery{}, Response: MeetingReportResponse{}},
+		{Method: fiber.MethodGet, Path: "/meetings/:id/export", Summary: "导出会议", Handler: handler.ExportMeeting, Query: ExportQuery{}, Response: exportResponse},
+		{Method: fiber.MethodGet, Path: "/meetings/:id/transcript", Summary: "导出会议记录（字幕）", Handler: handler.ExportTranscript, Query: TranscriptQuery{}, Response: transcriptResponse},
+		{Method: fiber.MethodGet, Path: "/meetings/:id/todos.ics", Summary: "导出会议待办事项日历", Handler: handler.ExportMeetingCalendar, Query: CalendarQuery{}, Response: calendarResponse},
+		{Method: fiber.MethodGet, Path: "/meetings/:id/audio", Summary: "会议录音", Handler: handler.GetMeetingAudio, Response: audioResponse},
+		{Method: fiber.MethodGet, Path: "/public/meetings/:id/audio", Summary: "会议录音（签名链接）", Public: true, Handler: handler.GetSignedMeetingAudio, Query: SignedAudioQuery{}, Response: audioResponse},
+
+		// 待办日历订阅
+		{Method: fiber.MethodGet, Path: "/todos/feeds", Summary: "待办日历订阅列表", Handler: handler.ListTodoFeeds, Response: TodoFeedListResponse{}},
+		{Method: fiber.MethodPost, Path: "/todos/feeds/:assignee/rotate", Summary: "更换个人待办日历订阅链接", Handler: handler.RotateTodoFeed, Response: TodoFeed{}},
+		{Method: fiber.MethodDelete, Path: "/todos/feeds/:assignee", Summary: "停用个人待办日历订阅链接", Handler: handler.RevokeTodoFeed, Response: TodoFeed{}},
+		{Method: fiber.MethodGet, Path: "/todos/feed/:assignee.ics", Summary: "个人待办日历（登录或签名链接）", Public: true, Handler: handler.TodoFeed, Query: TodoFeedQuery{}, Response: calendarResponse},
+
+		// 日历
+		{Method: fiber.MethodGet, Path: "/calendar/events", Summary: "日历目录中的会议", Handler: handler.ListCalendarEvents, Query: CalendarEventsQuery{}, Response: CalendarEventListResponse{}},
+
+		// 报告模板
+		{Method: fiber.MethodGet, Path: "/reports/templates", Summary: "报告模板列表", Handler: handler.ListReportTemplates, Response: ReportTemplateListResponse{}},
+
+		// 会议类型
+		{Method: fiber.MethodGet, Path: "/profiles", Summary: "会议类型列表", Handler: handler.ListProfiles, Response: ProfileListResponse{}},
+		{Method: fiber.MethodPost, Path: "/profiles", Summary: "创建会议类型", Handler: handler.CreateProfile, Request: services.ProfileInput{}, Response: models.Profile{}, Status: fiber.StatusCreated},
+		{Method: fiber.MethodGet, Path: "/profiles/:name", Summary: "会议类型详情", Handler: handler.GetProfile, Response: models.Profile{}},
+		{Method: fiber.MethodPut, Path: "/profiles/:name", Summary: "修改会议类型", Handler: handler.UpdateProfile, Request: services.ProfileInput{}, Response: models.Profile{}},
+		{Method: fiber.MethodDelete, Path: "/profiles/:name", Summary: "删除会议类型", Handler: handler.DeleteProfile, Response: models.Profile{}},
+
+		// 待办台账
+		{Method: fiber.MethodGet, Path: "/action-items/open", Summary: "按负责人列出未完成的待办事项", Handler: handler.ListOpenActionItems, Query: OpenActionItemsQuery{}, Response: OpenActionItemsResponse{}},
+		{Method: fiber.MethodGet, Path: "/action-items/:id", Summary: "待办事项及其历史", Handler: handler.GetActionItem, Response: models.ActionItem{}},
+
+		// 会议问答
+		{Method: fiber.MethodPost, Path: "/ask", Summary: "根据会议记录回答问题", Handler: handler.Ask, Request: AskRequest{}, Response: services.AskResult{}},
+
+		// Notion同步发件箱
+		{Method: fiber.MethodGet, Path: "/notion/syncs", Summary: "Notion同步任务列表", Handler: handler.ListNotionSyncs, Query: NotionSyncListQuery{}, Response: NotionSyncListResponse{}},
+		{Method: fiber.MethodPost, Path: "/notion/syncs/:id/retry", Summary: "重试Notion同步任务", Handler: handler.RetryNotionSync, Response: models.NotionSync{}},
+		{Method: fiber.MethodPost, Path: "/notion/syncs/:id/cancel", Summary: "取消Notion同步任务", Handler: handler.CancelNotionSync, Response: models.NotionSync{}},
+	}
+}
 
-	// 会议相关路由
-	api.Post("/meetings/analyze", handler.AnalyzeTranscript)
-	api.Post("/meetings/sync-notion", handler.SyncToNotionHandler)
+// RegisterRoutes 将路由表注册到/api路由组，非公开路由需要先通过认证
+func RegisterRoutes(router fiber.Router, handler *Handler) {
+	api := router.Group("/api")
+	for _, route := range Routes(handler) {
+		if route.Public {
+			api.Add(route.Method, route.Path, route.Handler)
+		} else {
+			api.Add(route.Method, route.Path, handler.RequireAuth, route.Handler)
+		}
+	}
 }
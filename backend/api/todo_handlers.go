@@ -0,0 +1,305 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"meeting-mm/apperr"
+	"meeting-mm/export"
+	"meeting-mm/models"
+	"meeting-mm/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportMeetingCalendar 将会议的待办事项导出为iCalendar
+func (h *Handler) ExportMeetingCalendar(c *fiber.Ctx) error {
+	var query CalendarQuery
+	if err := c.QueryParser(&query); err != nil {
+		return badRequest(fmt.Sprintf("解析查询参数失败: %v", err))
+	}
+	component, err := calendarComponent(query.Component)
+	if err != nil {
+		return err
+	}
+
+	meeting, err := h.getMeeting(c, c.Params("id"))
+	if err != nil {
+		return meetingError(err)
+	}
+
+	data := export.RenderCalendar(export.MeetingCalendarItems(meeting), export.CalendarOptions{
+		Name:       meeting.Title + " 待办事项",
+		Component:  component,
+		MeetingURL: h.meetingURL,
+	})
+	return sendCalendar(c, strings.TrimSuffix(export.FileName(meeting, "ics"), ".ics")+"-todos.ics", data)
+}
+
+// TodoFeed 一个负责人在工作区全部会议中的待办事项，供日历应用订阅。
+// 携带 workspace 和 sig 时凭签名免登录访问，否则需要登录
+func (h *Handler) TodoFeed(c *fiber.Ctx) error {
+	var query TodoFeedQuery
+	if err := c.QueryParser(&query); err != nil {
+		return badRequest(fmt.Sprintf("解析查询参数失败: %v", err))
+	}
+	component, err := calendarComponent(query.Component)
+	if err != nil {
+		return err
+	}
+	assignee, err := feedAssignee(c)
+	if err != nil {
+		return err
+	}
+
+	workspaceID := query.Workspace
+	if query.Sig != "" {
+		if workspaceID == "" {
+			return apperr.New(apperr.CodeForbidden, "日历订阅链接签名无效")
+		}
+		token, err := h.auth.TodoFeedToken(workspaceID, assignee)
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			return apperr.New(apperr.CodeForbidden, "日历订阅链接已停用")
+		}
+		expected := services.TodoFeedSignature(h.cfg.AuthSecret, workspaceID, assignee, token)
+		if subtle.ConstantTimeCompare([]byte(query.Sig), []byte(expected)) != 1 {
+			return apperr.New(apperr.CodeForbidden, "日历订阅链接签名无效")
+		}
+	} else {
+		p, err := h.auth.Authenticate(credential(c))
+		if err != nil {
+			return services.ErrUnauthorized
+		}
+		workspaceID = p.WorkspaceID
+	}
+
+	all, err := h.feedItems(workspaceID)
+	if err != nil {
+		return err
+	}
+	var items []export.CalendarItem
+	for _, item := range all {
+		if sameAssignee(item.Todo.Assignee, assignee) {
+			items = append(items, item)
+		}
+	}
+
+	data := export.RenderCalendar(items, export.CalendarOptions{
+		Name:       assignee + "的待办事项",
+		Component:  component,
+		MeetingURL: h.meetingURL,
+	})
+	return sendCalendar(c, assignee+".ics", data)
+}
+
+// ListTodoFeeds 列出当前工作区每个负责人的待办日历订阅链接
+func (h *Handler) ListTodoFeeds(c *fiber.Ctx) error {
+	workspaceID := principal(c).WorkspaceID
+	items, err := h.feedItems(workspaceID)
+	if err != nil {
+		return err
+	}
+
+	// 负责人名称不区分大小写，显示第一次出现时的写法
+	feeds := map[string]*TodoFeed{}
+	var names []string
+	for _, item := range items {
+		name := strings.TrimSpace(item.Todo.Assignee)
+		if name == "" {
+			continue
+		}
+		key := strings.ToLower(name)
+		feed, ok := feeds[key]
+		if !ok {
+			feed = &TodoFeed{Assignee: name}
+			feeds[key] = feed
+			names = append(names, name)
+		}
+		if item.Todo.Status != "completed" {
+			feed.Todos++
+		}
+	}
+
+	tokens, err := h.auth.TodoFeedTokens(workspaceID, names)
+	if err != nil {
+		return err
+	}
+	for key, feed := range feeds {
+		h.setTodoFeedURL(feed, workspaceID, tokens[key])
+	}
+
+	response := TodoFeedListResponse{Feeds: []TodoFeed{}}
+	for _, feed := range feeds {
+		response.Feeds = append(response.Feeds, *feed)
+	}
+	sort.Slice(response.Feeds, func(i, j int) bool {
+		return response.Feeds[i].Assignee < response.Feeds[j].Assignee
+	})
+	return c.JSON(response)
+}
+
+// RotateTodoFeed 更换负责人的订阅链接，之前的链接失效；已停用的链接重新启用
+func (h *Handler) RotateTodoFeed(c *fiber.Ctx) error {
+	assignee, err := feedAssignee(c)
+	if err != nil {
+		return err
+	}
+	workspaceID := principal(c).WorkspaceID
+	token, err := h.auth.RotateTodoFeed(workspaceID, assignee)
+	if err != nil {
+		return err
+	}
+	return h.sendTodoFeed(c, workspaceID, assignee, token)
+}
+
+// RevokeTodoFeed 停用负责人的订阅链接，不影响其他负责人的链接
+func (h *Handler) RevokeTodoFeed(c *fiber.Ctx) error {
+	assignee, err := feedAssignee(c)
+	if err != nil {
+		return err
+	}
+	workspaceID := principal(c).WorkspaceID
+	if err := h.auth.RevokeTodoFeed(workspaceID, assignee); err != nil {
+		return err
+	}
+	return h.sendTodoFeed(c, workspaceID, assignee, "")
+}
+
+// sendTodoFeed 返回一个负责人的订阅
+func (h *Handler) sendTodoFeed(c *fiber.Ctx, workspaceID, assignee, token string) error {
+	items, err := h.feedItems(workspaceID)
+	if err != nil {
+		return err
+	}
+	feed := TodoFeed{Assignee: assignee}
+	for _, item := range items {
+		if sameAssignee(item.Todo.Assignee, assignee) && item.Todo.Status != "completed" {
+			feed.Todos++
+		}
+	}
+	h.setTodoFeedURL(&feed, workspaceID, token)
+	return c.JSON(feed)
+}
+
+// feedAssignee 取出路径中的负责人名称
+func feedAssignee(c *fiber.Ctx) (string, error) {
+	assignee, err := url.PathUnescape(c.Params("assignee"))
+	if err != nil || strings.TrimSpace(assignee) == "" {
+		return "", badRequest("无效的负责人")
+	}
+	return strings.TrimSpace(assignee), nil
+}
+
+// feedItems 返回工作区全部会议中的待办事项。属于待办台账条目的待办事项每个条目只保留最近一次会议中的一项，
+// 描述、负责人、截止日期和是否完成以台账为准，之前会议中的副本不再出现
+func (h *Handler) feedItems(workspaceID string) ([]export.CalendarItem, error) {
+	meetings, err := h.workspaceMeetings(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := h.actionItems.Ledger(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []export.CalendarItem
+	var ids []string
+	latest := map[string]export.CalendarItem{}
+	for _, meeting := range meetings {
+		for _, item := range export.MeetingCalendarItems(meeting) {
+			id := item.Todo.ActionItemID
+			if ledger[id] == nil {
+				items = append(items, item)
+				continue
+			}
+			previous, ok := latest[id]
+			if !ok {
+				ids = append(ids, id)
+			}
+			if !ok || meetingAfter(meeting, previous.Meeting) {
+				latest[id] = item
+			}
+		}
+	}
+	for _, id := range ids {
+		item, entry := latest[id], ledger[id]
+		item.Todo.Description = entry.Description
+		item.Todo.Assignee = entry.Assignee
+		item.Todo.DueDate = entry.DueDate
+		if entry.Status == models.ActionItemCompleted {
+			item.Todo.Status = "completed"
+		} else if item.Todo.Status == "completed" {
+			item.Todo.Status = "pending"
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// meetingAfter 判断会议a是否晚于会议b，会议日期相同时比较创建时间
+func meetingAfter(a, b *models.Meeting) bool {
+	if !a.Date.Equal(b.Date) {
+		return a.Date.After(b.Date)
+	}
+	return a.CreatedAt.After(b.CreatedAt)
+}
+
+// workspaceMeetings 读取工作区的全部会议
+func (h *Handler) workspaceMeetings(workspaceID string) ([]*models.Meeting, error) {
+	all, err := h.store.Meetings.List()
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeStorageFailed, "读取会议列表失败", err)
+	}
+	var meetings []*models.Meeting
+	for _, meeting := range all {
+		if meeting.WorkspaceID == workspaceID {
+			meetings = append(meetings, meeting)
+		}
+	}
+	return meetings, nil
+}
+
+// meetingURL 日历条目中指向会议的链接：会议的HTML导出页面
+func (h *Handler) meetingURL(meeting *models.Meeting) string {
+	return strings.TrimRight(h.cfg.PublicBaseURL, "/") + "/api/meetings/" + url.PathEscape(meeting.ID) + "/export?format=html"
+}
+
+// setTodoFeedURL 设置带签名的个人待办日历订阅链接，token为空表示链接已停用
+func (h *Handler) setTodoFeedURL(feed *TodoFeed, workspaceID, token string) {
+	if token == "" {
+		feed.Revoked = true
+		return
+	}
+	query := url.Values{}
+	query.Set("workspace", workspaceID)
+	query.Set("sig", services.TodoFeedSignature(h.cfg.AuthSecret, workspaceID, feed.Assignee, token))
+	feed.URL = strings.TrimRight(h.cfg.PublicBaseURL, "/") + "/api/todos/feed/" + url.PathEscape(feed.Assignee) + ".ics?" + query.Encode()
+}
+
+func sameAssignee(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// calendarComponent 校验日历组件类型，默认为VTODO
+func calendarComponent(component string) (string, error) {
+	switch component = strings.ToLower(strings.TrimSpace(component)); component {
+	case "":
+		return export.ComponentTodo, nil
+	case export.ComponentTodo, export.ComponentEvent:
+		return component, nil
+	}
+	return "", badRequest(fmt.Sprintf("无效的日历组件类型: %s（支持 %s、%s）", component, export.ComponentTodo, export.ComponentEvent))
+}
+
+// sendCalendar 发送iCalendar文件
+func sendCalendar(c *fiber.Ctx, name string, data []byte) error {
+	c.Set(fiber.HeaderContentType, export.CalendarContentType)
+	c.Set(fiber.HeaderContentDisposition, contentDisposition(name))
+	return c.Send(data)
+}
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"mime/multipart"
+	"time"
+
+	"meeting-mm/models"
+	"meeting-mm/services"
+)
+
+// 本文件中的类型同时用于处理器和OpenAPI文档，修改字段会同步反映到文档和生成的客户端中
+
+// ErrorResponse 错误响应，code为稳定的机器可读错误码，retryable表示稍后重试同一请求可能成功
+type ErrorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	Status    int    `json:"status"`
+	Retryable bool   `json:"retryable"`
+	RequestID string `json:"requestId"`
+}
+
+// HealthResponse 健康检查响应
+type HealthResponse struct {
+	Status string `json:"status"`
+	Time   string `json:"time"`
+}
+
+// StatusResponse 只包含状态的响应
+type StatusResponse struct {
+	Status string `json:"status"`
+}
+
+// RegisterRequest 注册请求
+type RegisterRequest struct {
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	Name          string `json:"name,omitempty"`
+	WorkspaceName string `json:"workspaceName,omitempty"`
+}
+
+// LoginRequest 登录请求
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginResponse 登录响应
+type LoginResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+	User      UserResponse `json:"user"`
+}
+
+// CurrentUserResponse 当前用户及其工作区
+type CurrentUserResponse struct {
+	User      UserResponse      `json:"user"`
+	Workspace WorkspaceResponse `json:"workspace"`
+}
+
+// CreateAPIKeyRequest 创建API密钥请求
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+// APIKeyListResponse API密钥列表
+type APIKeyListResponse struct {
+	Keys []APIKeyResponse `json:"keys"`
+}
+
+// AddMemberRequest 添加工作区成员请求
+type AddMemberRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name,omitempty"`
+}
+
+// UploadAudioForm 上传音频的multipart表单
+type UploadAudioForm struct {
+	Title          string                `form:"title,omitempty"` // 有会议邀请时可以为空
+	SyncToNotion   bool                  `form:"syncToNotion,omitempty"`
+	ReportTemplate string                `form:"reportTemplate,omitempty"` // 报告模板，为空时使用默认模板
+	PolishReport   bool                  `form:"polishReport,omitempty"`   // 让模型润色渲染出的报告
+	Audio          *multipart.FileHeader `form:"audio"`
+	// Invite 会议邀请（.ics），提供标题、时间、参会人和议程；标题为空时使用邀请中的标题
+	Invite *multipart.FileHeader `form:"invite,omitempty"`
+	// CalendarEventUID 日历目录中会议的UID；同时上传了会议邀请时从该文件中选择会议
+	CalendarEventUID string `form:"calendarEventUid,omitempty"`
+	// Agenda 会议议程，每行一项，行首的编号和项目符号会被去掉；为空时从会议邀请的说明中识别
+	Agenda string `form:"agenda,omitempty"`
+	// Profile 会议类型的名称，决定分析提示词、额外提取的内容、输出语言和默认报告模板
+	Profile string `form:"profile,omitempty"`
+	// Series 会议系列，同一系列之前会议中未完成的待办事项会被跟踪；为空时由标题得到（忽略其中的数字和标点）
+	Series string `form:"series,omitempty"`
+}
+
+// StreamAudioQuery 流式处理音频的查询参数
+type StreamAudioQuery struct {
+	SampleRate int `query:"sampleRate"`
+}
+
+// TranscriptResponse 转录结果
+type TranscriptResponse struct {
+	Transcript string `json:"transcript"`
+}
+
+// AnalyzeRequest 分析会议转录请求
+type AnalyzeRequest struct {
+	Title          string `json:"title,omitempty"`          // 指定了calendarEventUid时可以为空
+	Transcript     string `json:"transcript,omitempty"`     // 提供了segments时可以为空
+	ReportTemplate string `json:"reportTemplate,omitempty"` // 报告模板，为空时使用默认模板
+	PolishReport   bool   `json:"polishReport,omitempty"`   // 让模型润色渲染出的报告
+	// CalendarEventUID 日历目录中会议的UID，会议的标题、时间、参会人和议程从会议邀请中读取
+	CalendarEventUID string `json:"calendarEventUid,omitempty"`
+	// Agenda 会议议程，分析结果按议程项整理；为空时从会议邀请的说明中识别
+	Agenda []AgendaInput `json:"agenda,omitempty"`
+	// Profile 会议类型的名称，决定分析提示词、额外提取的内容、输出语言和默认报告模板
+	Profile string `json:"profile,omitempty"`
+	// Series 会议系列，同一系列之前会议中未完成的待办事项会被跟踪；为空时由标题得到（忽略其中的数字和标点）
+	Series string `json:"series,omitempty"`
+	// Segments 带时间戳的转录片段，提供时主题带有时间范围；transcript为空时由片段拼接
+	Segments []SegmentInput `json:"segments,omitempty"`
+}
+
+// SegmentInput 请求中的一个转录片段
+type SegmentInput struct {
+	StartTime float64 `json:"startTime"` // 以秒为单位
+	EndTime   float64 `json:"endTime"`
+	Speaker   string  `json:"speaker,omitempty"`
+	Text      string  `json:"text"`
+}
+
+// AgendaInput 请求中的一个议程项
+type AgendaInput struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// MeetingResponse 会议及其Markdown报告
+type MeetingResponse struct {
+	Meeting        *models.Meeting `json:"meeting"`
+	MarkdownReport string          `json:"markdownReport"`
+}
+
+// MeetingReportQuery 渲染会议报告的查询参数
+type MeetingReportQuery struct {
+	Template string `query:"template"`
+	Polish   bool   `query:"polish"`
+}
+
+// ExportQuery 导出会议的查询参数
+type ExportQuery struct {
+	Format     string `query:"format"`     // md、html、docx或pdf，默认为md
+	Transcript bool   `query:"transcript"` // 在文末附上会议记录
+}
+
+// TranscriptQuery 导出会议记录的查询参数
+type TranscriptQuery struct {
+	Format     string `query:"format"`     // srt、vtt、txt或json，默认为srt
+	LineLength int    `query:"lineLength"` // 字幕每行的最大显示宽度，中日韩文字占2列，默认42
+	OffsetMs   int    `query:"offsetMs"`   // 加到每个时间戳上的偏移（毫秒），可以为负
+}
+
+// CalendarQuery 导出待办日历的查询参数
+type CalendarQuery struct {
+	Component string `query:"component"` // vtodo（默认）或vevent
+}
+
+// TodoFeedQuery 个人待办日历订阅的查询参数；携带签名时无需登录
+type TodoFeedQuery struct {
+	Component string `query:"component"`
+	Workspace string `query:"workspace"`
+	Sig       string `query:"sig"`
+}
+
+// TodoFeed 一个负责人的待办日历订阅
+type TodoFeed struct {
+	Assignee string `json:"assignee"`
+	Todos    int    `json:"todos"`         // 未完成的待办事项数
+	URL      string `json:"url,omitempty"` // 带签名的订阅链接，可以直接添加到日历应用；链接停用时为空
+	Revoked  bool   `json:"revoked"`       // 订阅链接已停用，更换链接后重新启用
+}
+
+// TodoFeedListResponse 当前工作区的待办日历订阅列表
+type TodoFeedListResponse struct {
+	Feeds []TodoFeed `json:"feeds"`
+}
+
+// CalendarEventsQuery 列出日历会议的查询参数
+type CalendarEventsQuery struct {
+	Date string `query:"date"` // YYYY-MM-DD，只列出当天开始的会议
+}
+
+// CalendarEventListResponse 日历目录中的会议
+type CalendarEventListResponse struct {
+	Events []*services.Invite `json:"events"`
+}
+
+// MeetingReportResponse 会议的Markdown报告
+type MeetingReportResponse struct {
+	Template string `json:"template"`
+	Markdown string `json:"markdown"`
+}
+
+// AskRequest 会议问答的请求
+type AskRequest struct {
+	Question string `json:"question"`
+	TopK     int    `json:"topK,omitempty"` // 检索的片段数量，默认6，最多20
+}
+
+// OpenActionItemsQuery 未完成待办事项的查询参数
+type OpenActionItemsQuery struct {
+	Assignee string `query:"assignee"` // 只列出该负责人的条目，不区分大小写
+}
+
+// OpenActionItemsResponse 按负责人分组的未完成待办事项
+type OpenActionItemsResponse struct {
+	Assignees []services.AssigneeActionItems `json:"assignees"`
+}
+
+// ProfileListResponse 工作区的会议类型
+type ProfileListResponse struct {
+	Profiles []*models.Profile `json:"profiles"`
+}
+
+// ReportTemplateListResponse 可用的报告模板
+type ReportTemplateListResponse struct {
+	Default   string                        `json:"default"`
+	Templates []services.ReportTemplateInfo `json:"templates"`
+}
+
+// SyncToNotionRequest 同步会议到Notion请求
+type SyncToNotionRequest struct {
+	Meeting        models.Meeting `json:"meeting"`
+	MarkdownReport string         `json:"markdownReport,omitempty"`
+}
+
+// NotionSyncResponse 同步到Notion的结果
+type NotionSyncResponse struct {
+	Status           string   `json:"status"`
+	NotionPageID     string   `json:"notionPageId,omitempty"`
+	UnresolvedPeople []string `json:"unresolvedPeople,omitempty"`
+}
+
+// MeetingListResponse 会议列表
+type MeetingListResponse struct {
+	Meetings []*models.Meeting `json:"meetings"`
+}
+
+// SignedAudioQuery 签名录音链接的查询参数
+type SignedAudioQuery struct {
+	Exp int64  `query:"exp"` // 链接过期时间（Unix秒）
+	Sig string `query:"sig"`
+}
+
+// NotionSyncListQuery Notion同步任务列表的查询参数
+type NotionSyncListQuery struct {
+	Status string `query:"status"`
+}
+
+// NotionSyncListResponse Notion同步任务列表
+type NotionSyncListResponse struct {
+	Syncs []*models.NotionSync `json:"syncs"`
+}
@@ -0,0 +1,140 @@
+// Package apperr 定义带有稳定错误码的应用错误，由API的统一错误处理器转换为响应
+package apperr
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Code 机器可读的错误码，发布后保持不变
+type Code string
+
+// 错误码
+const (
+	CodeBadRequest           Code = "BAD_REQUEST"
+	CodeUnauthorized         Code = "UNAUTHORIZED"
+	CodeForbidden            Code = "FORBIDDEN"
+	CodeNotFound             Code = "NOT_FOUND"
+	CodeConflict             Code = "CONFLICT"
+	CodePayloadTooLarge      Code = "PAYLOAD_TOO_LARGE"
+	CodeAudioUnsupported     Code = "AUDIO_UNSUPPORTED"
+	CodeTranscriptionFailed  Code = "TRANSCRIPTION_FAILED"
+	CodeLLMUnavailable       Code = "LLM_UNAVAILABLE"
+	CodeLLMBadOutput         Code = "LLM_BAD_OUTPUT"
+	CodeNotionUnavailable    Code = "NOTION_UNAVAILABLE"
+	CodeNotionUnauthorized   Code = "NOTION_UNAUTHORIZED"
+	CodeNotionSchemaMismatch Code = "NOTION_SCHEMA_MISMATCH"
+	CodeNotionFailed         Code = "NOTION_FAILED"
+	CodeStorageFailed        Code = "STORAGE_FAILED"
+	CodeUnavailable          Code = "SERVICE_UNAVAILABLE"
+	CodeInternal             Code = "INTERNAL"
+)
+
+// codeInfo 错误码对应的HTTP状态码、是否可重试和英文默认信息
+type codeInfo struct {
+	status    int
+	retryable bool
+	english   string
+}
+
+var codes = map[Code]codeInfo{
+	CodeBadRequest:           {http.StatusBadRequest, false, "The request is invalid."},
+	CodeUnauthorized:         {http.StatusUnauthorized, false, "Authentication is required."},
+	CodeForbidden:            {http.StatusForbidden, false, "You do not have permission to perform this action."},
+	CodeNotFound:             {http.StatusNotFound, false, "The requested resource was not found."},
+	CodeConflict:             {http.StatusConflict, false, "The request conflicts with the current state."},
+	CodePayloadTooLarge:      {http.StatusRequestEntityTooLarge, false, "The request body is too large."},
+	CodeAudioUnsupported:     {http.StatusUnsupportedMediaType, false, "The audio format is not supported."},
+	CodeTranscriptionFailed:  {http.StatusBadGateway, true, "Transcription failed."},
+	CodeLLMUnavailable:       {http.StatusBadGateway, true, "The language model service is unavailable."},
+	CodeLLMBadOutput:         {http.StatusBadGateway, true, "The language model returned an invalid result."},
+	CodeNotionUnavailable:    {http.StatusBadGateway, true, "Notion is temporarily unavailable."},
+	CodeNotionUnauthorized:   {http.StatusBadGateway, false, "The Notion integration token is invalid or lacks access."},
+	CodeNotionSchemaMismatch: {http.StatusUnprocessableEntity, false, "The Notion database does not match the expected properties."},
+	CodeNotionFailed:         {http.StatusBadGateway, false, "The Notion request failed."},
+	CodeStorageFailed:        {http.StatusInternalServerError, true, "Failed to read or write data."},
+	CodeUnavailable:          {http.StatusServiceUnavailable, true, "The service is shutting down, please retry."},
+	CodeInternal:             {http.StatusInternalServerError, false, "An internal error occurred."},
+}
+
+// Error 应用错误
+type Error struct {
+	Code      Code
+	Status    int
+	Message   string // 面向用户的中文信息
+	Retryable bool
+	Err       error // 原始错误，只写入日志，不返回给客户端
+}
+
+// New 创建错误，状态码和是否可重试取错误码的默认值
+func New(code Code, message string) *Error {
+	info, ok := codes[code]
+	if !ok {
+		info = codes[CodeInternal]
+	}
+	return &Error{Code: code, Status: info.status, Message: message, Retryable: info.retryable}
+}
+
+// Wrap 创建包装原始错误的错误
+func Wrap(code Code, message string, err error) *Error {
+	e := New(code, message)
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is 相同错误码的错误视为相等，便于 errors.Is(err, storage.ErrNotFound) 之类的判断
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Err == nil && t.Code == e.Code && t.Message == e.Message
+}
+
+// As 取出错误链中的应用错误
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// From 将任意错误转换为应用错误，未知错误视为内部错误
+func From(err error) *Error {
+	if e, ok := As(err); ok {
+		return e
+	}
+	return Wrap(CodeInternal, "服务器内部错误", err)
+}
+
+// Permanent 错误是否为不可重试的应用错误，未知错误视为可重试
+func Permanent(err error) bool {
+	e, ok := As(err)
+	return ok && !e.Retryable
+}
+
+// Localize 按Accept-Language返回信息：偏好英文时返回错误码的英文默认信息，否则返回中文信息
+func (e *Error) Localize(acceptLanguage string) string {
+	if prefersEnglish(acceptLanguage) {
+		if info, ok := codes[e.Code]; ok {
+			return info.english
+		}
+	}
+	return e.Message
+}
+
+// prefersEnglish 判断Accept-Language的首选语言是否为英文
+func prefersEnglish(acceptLanguage string) bool {
+	first, _, _ := strings.Cut(acceptLanguage, ",")
+	first, _, _ = strings.Cut(first, ";")
+	first = strings.ToLower(strings.TrimSpace(first))
+	return first == "en" || strings.HasPrefix(first, "en-")
+}
@@ -0,0 +1,163 @@
+// Package client 是Meeting-MM API的Go客户端，供脚本和集成使用。
+// 类型和方法由OpenAPI文档生成（client_gen.go），修改API后运行 go generate ./client 重新生成
+package client
+
+//go:generate go run .. openapi -client client_gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client Meeting-MM API客户端
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	credential string
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithAPIKey 使用API密钥认证
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.credential = key }
+}
+
+// WithToken 使用登录令牌认证
+func WithToken(token string) Option {
+	return func(c *Client) { c.credential = token }
+}
+
+// WithHTTPClient 使用自定义的http.Client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// New 创建客户端，baseURL为服务器地址，例如 http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// File multipart表单中上传的文件
+type File struct {
+	Name    string
+	Content io.Reader
+}
+
+// APIError 服务器返回的错误，Code为机器可读的错误码，Retryable表示稍后重试可能成功
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Retryable  bool
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("API错误 %d %s: %s (请求ID: %s)", e.StatusCode, e.Code, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("API错误 %d: %s", e.StatusCode, e.Message)
+}
+
+// do 发送请求，out为*[]byte时返回原始响应体，否则按JSON解析
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string, out interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.credential != "" {
+		req.Header.Set("Authorization", "Bearer "+c.credential)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		var apiErr ErrorResponse
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Error == "" {
+			apiErr.Error = strings.TrimSpace(string(data))
+		}
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Code:       apiErr.Code,
+			Message:    apiErr.Error,
+			Retryable:  apiErr.Retryable,
+			RequestID:  apiErr.RequestID,
+		}
+	}
+
+	if raw, ok := out.(*[]byte); ok {
+		*raw = data
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
+
+// jsonBody 将请求体编码为JSON
+func jsonBody(body interface{}) (io.Reader, string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(data), "application/json", nil
+}
+
+// multipartBody 将字段和文件编码为multipart表单
+func multipartBody(fields map[string]string, files map[string]*File) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
+	}
+	for name, file := range files {
+		if file == nil {
+			continue
+		}
+		part, err := writer.CreateFormFile(name, file.Name)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := io.Copy(part, file.Content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, writer.FormDataContentType(), nil
+}
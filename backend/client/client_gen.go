@@ -0,0 +1,1078 @@
+// Code generated by "meeting-mm openapi"; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// APIKeyListResponse 由OpenAPI文档生成
+type APIKeyListResponse struct {
+	Keys []APIKeyResponse `json:"keys"`
+}
+
+// APIKeyResponse 由OpenAPI文档生成
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	UserID     string     `json:"userId"`
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// ActionItem 由OpenAPI文档生成
+type ActionItem struct {
+	ID             string            `json:"id"`
+	WorkspaceID    string            `json:"workspaceId"`
+	Series         string            `json:"series"`
+	Description    string            `json:"description"`
+	Assignee       string            `json:"assignee,omitempty"`
+	DueDate        time.Time         `json:"dueDate"`
+	Status         string            `json:"status"`
+	FirstMeetingID string            `json:"firstMeetingId"`
+	LastMeetingID  string            `json:"lastMeetingId"`
+	History        []ActionItemEvent `json:"history"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+}
+
+// ActionItemEvent 由OpenAPI文档生成
+type ActionItemEvent struct {
+	MeetingID    string    `json:"meetingId"`
+	MeetingTitle string    `json:"meetingTitle"`
+	Date         time.Time `json:"date"`
+	Kind         string    `json:"kind"`
+	Description  string    `json:"description"`
+}
+
+// AddMemberRequest 由OpenAPI文档生成
+type AddMemberRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name,omitempty"`
+}
+
+// AgendaInput 由OpenAPI文档生成
+type AgendaInput struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// AgendaItem 由OpenAPI文档生成
+type AgendaItem struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+}
+
+// AnalyzeRequest 由OpenAPI文档生成
+type AnalyzeRequest struct {
+	Title            string         `json:"title,omitempty"`
+	Transcript       string         `json:"transcript,omitempty"`
+	ReportTemplate   string         `json:"reportTemplate,omitempty"`
+	PolishReport     bool           `json:"polishReport,omitempty"`
+	CalendarEventUid string         `json:"calendarEventUid,omitempty"`
+	Agenda           []AgendaInput  `json:"agenda,omitempty"`
+	Profile          string         `json:"profile,omitempty"`
+	Series           string         `json:"series,omitempty"`
+	Segments         []SegmentInput `json:"segments,omitempty"`
+}
+
+// AskRequest 由OpenAPI文档生成
+type AskRequest struct {
+	Question string `json:"question"`
+	TopK     int    `json:"topK,omitempty"`
+}
+
+// AskResult 由OpenAPI文档生成
+type AskResult struct {
+	Answer    string     `json:"answer"`
+	Citations []Citation `json:"citations"`
+	Retrieval string     `json:"retrieval"`
+}
+
+// AssigneeActionItems 由OpenAPI文档生成
+type AssigneeActionItems struct {
+	Assignee string        `json:"assignee"`
+	Items    []*ActionItem `json:"items"`
+}
+
+// CalendarEventListResponse 由OpenAPI文档生成
+type CalendarEventListResponse struct {
+	Events []*Invite `json:"events"`
+}
+
+// Citation 由OpenAPI文档生成
+type Citation struct {
+	Index        int       `json:"index"`
+	MeetingID    string    `json:"meetingId"`
+	MeetingTitle string    `json:"meetingTitle"`
+	MeetingDate  time.Time `json:"meetingDate"`
+	StartTime    float64   `json:"startTime"`
+	EndTime      float64   `json:"endTime"`
+	Text         string    `json:"text"`
+}
+
+// ComponentHealth 由OpenAPI文档生成
+type ComponentHealth struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Code      string  `json:"code,omitempty"`
+	Message   string  `json:"message,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+// CreateAPIKeyRequest 由OpenAPI文档生成
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+// CurrentUserResponse 由OpenAPI文档生成
+type CurrentUserResponse struct {
+	User      UserResponse      `json:"user"`
+	Workspace WorkspaceResponse `json:"workspace"`
+}
+
+// Decision 由OpenAPI文档生成
+type Decision struct {
+	ID           string `json:"id"`
+	Description  string `json:"description"`
+	MadeBy       string `json:"madeBy,omitempty"`
+	AgendaItemID string `json:"agendaItemId,omitempty"`
+}
+
+// ErrorResponse 由OpenAPI文档生成
+type ErrorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	Status    int    `json:"status"`
+	Retryable bool   `json:"retryable"`
+	RequestID string `json:"requestId"`
+}
+
+// ExtraField 由OpenAPI文档生成
+type ExtraField struct {
+	Key   string   `json:"key"`
+	Label string   `json:"label"`
+	Items []string `json:"items"`
+}
+
+// HealthReport 由OpenAPI文档生成
+type HealthReport struct {
+	Status     string            `json:"status"`
+	Time       string            `json:"time"`
+	Components []ComponentHealth `json:"components"`
+}
+
+// HealthResponse 由OpenAPI文档生成
+type HealthResponse struct {
+	Status string `json:"status"`
+	Time   string `json:"time"`
+}
+
+// Invite 由OpenAPI文档生成
+type Invite struct {
+	Uid       string    `json:"uid"`
+	Title     string    `json:"title"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	Attendees []string  `json:"attendees"`
+	Agenda    string    `json:"agenda,omitempty"`
+}
+
+// LoginRequest 由OpenAPI文档生成
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginResponse 由OpenAPI文档生成
+type LoginResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+	User      UserResponse `json:"user"`
+}
+
+// Meeting 由OpenAPI文档生成
+type Meeting struct {
+	ID               string              `json:"id"`
+	WorkspaceID      string              `json:"workspaceId,omitempty"`
+	CreatedBy        string              `json:"createdBy,omitempty"`
+	Title            string              `json:"title"`
+	Date             time.Time           `json:"date"`
+	Participants     []string            `json:"participants"`
+	Agenda           string              `json:"agenda,omitempty"`
+	AgendaItems      []AgendaItem        `json:"agendaItems,omitempty"`
+	CalendarEventUid string              `json:"calendarEventUid,omitempty"`
+	Series           string              `json:"series,omitempty"`
+	Transcript       string              `json:"transcript"`
+	Segments         []TranscriptSegment `json:"segments,omitempty"`
+	Summary          string              `json:"summary"`
+	TodoItems        []TodoItem          `json:"todoItems"`
+	Decisions        []Decision          `json:"decisions"`
+	Profile          string              `json:"profile,omitempty"`
+	ExtraFields      []ExtraField        `json:"extraFields,omitempty"`
+	OpenQuestions    []string            `json:"openQuestions,omitempty"`
+	Risks            []Risk              `json:"risks,omitempty"`
+	Topics           []Topic             `json:"topics,omitempty"`
+	Keywords         []string            `json:"keywords,omitempty"`
+	SpeakerInsights  []SpeakerInsight    `json:"speakerInsights,omitempty"`
+	CreatedAt        time.Time           `json:"createdAt"`
+	UpdatedAt        time.Time           `json:"updatedAt"`
+	NotionPageID     string              `json:"notionPageId,omitempty"`
+	AudioFile        string              `json:"audioFile,omitempty"`
+	SyncStatus       string              `json:"syncStatus,omitempty"`
+	SyncError        string              `json:"syncError,omitempty"`
+	UnresolvedPeople []string            `json:"unresolvedPeople,omitempty"`
+}
+
+// MeetingListResponse 由OpenAPI文档生成
+type MeetingListResponse struct {
+	Meetings []*Meeting `json:"meetings"`
+}
+
+// MeetingReportResponse 由OpenAPI文档生成
+type MeetingReportResponse struct {
+	Template string `json:"template"`
+	Markdown string `json:"markdown"`
+}
+
+// MeetingResponse 由OpenAPI文档生成
+type MeetingResponse struct {
+	Meeting        *Meeting `json:"meeting"`
+	MarkdownReport string   `json:"markdownReport"`
+}
+
+// NotionSync 由OpenAPI文档生成
+type NotionSync struct {
+	ID            string    `json:"id"`
+	WorkspaceID   string    `json:"workspaceId,omitempty"`
+	MeetingID     string    `json:"meetingId"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"lastError,omitempty"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	NotionPageID  string    `json:"notionPageId,omitempty"`
+	TraceParent   string    `json:"traceParent,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// NotionSyncListResponse 由OpenAPI文档生成
+type NotionSyncListResponse struct {
+	Syncs []*NotionSync `json:"syncs"`
+}
+
+// NotionSyncResponse 由OpenAPI文档生成
+type NotionSyncResponse struct {
+	Status           string   `json:"status"`
+	NotionPageID     string   `json:"notionPageId,omitempty"`
+	UnresolvedPeople []string `json:"unresolvedPeople,omitempty"`
+}
+
+// OpenActionItemsResponse 由OpenAPI文档生成
+type OpenActionItemsResponse struct {
+	Assignees []AssigneeActionItems `json:"assignees"`
+}
+
+// Profile 由OpenAPI文档生成
+type Profile struct {
+	ID             string         `json:"id"`
+	WorkspaceID    string         `json:"workspaceId"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description,omitempty"`
+	SystemPrompt   string         `json:"systemPrompt,omitempty"`
+	Instructions   string         `json:"instructions,omitempty"`
+	Fields         []ProfileField `json:"fields,omitempty"`
+	Language       string         `json:"language,omitempty"`
+	ReportTemplate string         `json:"reportTemplate,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// ProfileField 由OpenAPI文档生成
+type ProfileField struct {
+	Key         string `json:"key"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+}
+
+// ProfileInput 由OpenAPI文档生成
+type ProfileInput struct {
+	Name           string         `json:"name"`
+	Description    string         `json:"description,omitempty"`
+	SystemPrompt   string         `json:"systemPrompt,omitempty"`
+	Instructions   string         `json:"instructions,omitempty"`
+	Fields         []ProfileField `json:"fields,omitempty"`
+	Language       string         `json:"language,omitempty"`
+	ReportTemplate string         `json:"reportTemplate,omitempty"`
+}
+
+// ProfileListResponse 由OpenAPI文档生成
+type ProfileListResponse struct {
+	Profiles []*Profile `json:"profiles"`
+}
+
+// RegisterRequest 由OpenAPI文档生成
+type RegisterRequest struct {
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	Name          string `json:"name,omitempty"`
+	WorkspaceName string `json:"workspaceName,omitempty"`
+}
+
+// ReportTemplateInfo 由OpenAPI文档生成
+type ReportTemplateInfo struct {
+	Name    string `json:"name"`
+	Builtin bool   `json:"builtin"`
+}
+
+// ReportTemplateListResponse 由OpenAPI文档生成
+type ReportTemplateListResponse struct {
+	Default   string               `json:"default"`
+	Templates []ReportTemplateInfo `json:"templates"`
+}
+
+// Risk 由OpenAPI文档生成
+type Risk struct {
+	Description string `json:"description"`
+	Kind        string `json:"kind"`
+	Owner       string `json:"owner,omitempty"`
+}
+
+// SegmentInput 由OpenAPI文档生成
+type SegmentInput struct {
+	StartTime float64 `json:"startTime"`
+	EndTime   float64 `json:"endTime"`
+	Speaker   string  `json:"speaker,omitempty"`
+	Text      string  `json:"text"`
+}
+
+// SpeakerInsight 由OpenAPI文档生成
+type SpeakerInsight struct {
+	Speaker    string `json:"speaker"`
+	Sentiment  string `json:"sentiment"`
+	Engagement string `json:"engagement"`
+	Note       string `json:"note,omitempty"`
+}
+
+// StatusResponse 由OpenAPI文档生成
+type StatusResponse struct {
+	Status string `json:"status"`
+}
+
+// SyncToNotionRequest 由OpenAPI文档生成
+type SyncToNotionRequest struct {
+	Meeting        Meeting `json:"meeting"`
+	MarkdownReport string  `json:"markdownReport,omitempty"`
+}
+
+// TodoFeed 由OpenAPI文档生成
+type TodoFeed struct {
+	Assignee string `json:"assignee"`
+	Todos    int    `json:"todos"`
+	URL      string `json:"url,omitempty"`
+	Revoked  bool   `json:"revoked"`
+}
+
+// TodoFeedListResponse 由OpenAPI文档生成
+type TodoFeedListResponse struct {
+	Feeds []TodoFeed `json:"feeds"`
+}
+
+// TodoItem 由OpenAPI文档生成
+type TodoItem struct {
+	ID           string    `json:"id"`
+	Description  string    `json:"description"`
+	Assignee     string    `json:"assignee"`
+	DueDate      time.Time `json:"dueDate,omitempty"`
+	Status       string    `json:"status"`
+	AgendaItemID string    `json:"agendaItemId,omitempty"`
+	ActionItemID string    `json:"actionItemId,omitempty"`
+	Continuity   string    `json:"continuity,omitempty"`
+}
+
+// Topic 由OpenAPI文档生成
+type Topic struct {
+	Title     string  `json:"title"`
+	Summary   string  `json:"summary,omitempty"`
+	StartTime float64 `json:"startTime"`
+	EndTime   float64 `json:"endTime"`
+}
+
+// TranscriptResponse 由OpenAPI文档生成
+type TranscriptResponse struct {
+	Transcript string `json:"transcript"`
+}
+
+// TranscriptSegment 由OpenAPI文档生成
+type TranscriptSegment struct {
+	ID        string    `json:"id"`
+	MeetingID string    `json:"meetingId"`
+	StartTime float64   `json:"startTime"`
+	EndTime   float64   `json:"endTime"`
+	Speaker   string    `json:"speaker,omitempty"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// UploadAudioForm 由OpenAPI文档生成
+type UploadAudioForm struct {
+	Title            string `json:"title,omitempty"`
+	SyncToNotion     bool   `json:"syncToNotion,omitempty"`
+	ReportTemplate   string `json:"reportTemplate,omitempty"`
+	PolishReport     bool   `json:"polishReport,omitempty"`
+	Audio            *File  `json:"audio"`
+	Invite           *File  `json:"invite,omitempty"`
+	CalendarEventUid string `json:"calendarEventUid,omitempty"`
+	Agenda           string `json:"agenda,omitempty"`
+	Profile          string `json:"profile,omitempty"`
+	Series           string `json:"series,omitempty"`
+}
+
+// UserResponse 由OpenAPI文档生成
+type UserResponse struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspaceId"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// WorkspaceResponse 由OpenAPI文档生成
+type WorkspaceResponse struct {
+	ID                string                    `json:"id"`
+	Name              string                    `json:"name"`
+	Settings          WorkspaceSettingsResponse `json:"settings"`
+	ServerCredentials bool                      `json:"serverCredentials"`
+	CreatedAt         time.Time                 `json:"createdAt"`
+	UpdatedAt         time.Time                 `json:"updatedAt"`
+}
+
+// WorkspaceSettingsResponse 由OpenAPI文档生成
+type WorkspaceSettingsResponse struct {
+	NotionAPIKeySet      bool   `json:"notionApiKeySet"`
+	NotionDatabaseID     string `json:"notionDatabaseId"`
+	DeepseekAPIKeySet    bool   `json:"deepseekApiKeySet"`
+	DeepseekBaseURL      string `json:"deepseekBaseUrl"`
+	DeepseekModel        string `json:"deepseekModel"`
+	SystemPrompt         string `json:"systemPrompt"`
+	AnalysisInstructions string `json:"analysisInstructions"`
+	WhisperModel         string `json:"whisperModel"`
+	WhisperLanguage      string `json:"whisperLanguage"`
+}
+
+// WorkspaceSettingsUpdate 由OpenAPI文档生成
+type WorkspaceSettingsUpdate struct {
+	NotionAPIKey         *string `json:"notionApiKey,omitempty"`
+	NotionDatabaseID     *string `json:"notionDatabaseId,omitempty"`
+	DeepseekAPIKey       *string `json:"deepseekApiKey,omitempty"`
+	DeepseekBaseURL      *string `json:"deepseekBaseUrl,omitempty"`
+	DeepseekModel        *string `json:"deepseekModel,omitempty"`
+	SystemPrompt         *string `json:"systemPrompt,omitempty"`
+	AnalysisInstructions *string `json:"analysisInstructions,omitempty"`
+	WhisperModel         *string `json:"whisperModel,omitempty"`
+	WhisperLanguage      *string `json:"whisperLanguage,omitempty"`
+}
+
+// ListOpenActionItemsParams ListOpenActionItems的查询参数
+type ListOpenActionItemsParams struct {
+	Assignee string
+}
+
+// StreamAudioParams StreamAudio的查询参数
+type StreamAudioParams struct {
+	SampleRate int
+}
+
+// ListCalendarEventsParams ListCalendarEvents的查询参数
+type ListCalendarEventsParams struct {
+	Date string
+}
+
+// ExportMeetingParams ExportMeeting的查询参数
+type ExportMeetingParams struct {
+	Format     string
+	Transcript bool
+}
+
+// GetMeetingReportParams GetMeetingReport的查询参数
+type GetMeetingReportParams struct {
+	Template string
+	Polish   bool
+}
+
+// ExportMeetingCalendarParams ExportMeetingCalendar的查询参数
+type ExportMeetingCalendarParams struct {
+	Component string
+}
+
+// ExportTranscriptParams ExportTranscript的查询参数
+type ExportTranscriptParams struct {
+	Format     string
+	LineLength int
+	OffsetMs   int
+}
+
+// ListNotionSyncsParams ListNotionSyncs的查询参数
+type ListNotionSyncsParams struct {
+	Status string
+}
+
+// GetSignedMeetingAudioParams GetSignedMeetingAudio的查询参数
+type GetSignedMeetingAudioParams struct {
+	Exp int
+	Sig string
+}
+
+// TodoFeedParams TodoFeed的查询参数
+type TodoFeedParams struct {
+	Component string
+	Workspace string
+	Sig       string
+}
+
+// ListOpenActionItems 按负责人列出未完成的待办事项
+func (c *Client) ListOpenActionItems(ctx context.Context, params *ListOpenActionItemsParams) (*OpenActionItemsResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Assignee != "" {
+			query.Set("assignee", params.Assignee)
+		}
+	}
+	var out OpenActionItemsResponse
+	if err := c.do(ctx, "GET", "/api/action-items/open", query, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetActionItem 待办事项及其历史
+func (c *Client) GetActionItem(ctx context.Context, id string) (*ActionItem, error) {
+	var out ActionItem
+	if err := c.do(ctx, "GET", "/api/action-items/"+url.PathEscape(id), nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Ask 根据会议记录回答问题
+func (c *Client) Ask(ctx context.Context, body *AskRequest) (*AskResult, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out AskResult
+	if err := c.do(ctx, "POST", "/api/ask", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StreamAudio 流式处理音频
+func (c *Client) StreamAudio(ctx context.Context, params *StreamAudioParams, body io.Reader) (*TranscriptResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.SampleRate != 0 {
+			query.Set("sampleRate", strconv.Itoa(params.SampleRate))
+		}
+	}
+	reqBody, contentType := body, "application/octet-stream"
+	var out TranscriptResponse
+	if err := c.do(ctx, "POST", "/api/audio/stream", query, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UploadAudio 上传音频并生成会议纪要
+func (c *Client) UploadAudio(ctx context.Context, form *UploadAudioForm) (*MeetingResponse, error) {
+	fields := map[string]string{}
+	files := map[string]*File{}
+	fields["title"] = form.Title
+	fields["syncToNotion"] = strconv.FormatBool(form.SyncToNotion)
+	fields["reportTemplate"] = form.ReportTemplate
+	fields["polishReport"] = strconv.FormatBool(form.PolishReport)
+	files["audio"] = form.Audio
+	files["invite"] = form.Invite
+	fields["calendarEventUid"] = form.CalendarEventUid
+	fields["agenda"] = form.Agenda
+	fields["profile"] = form.Profile
+	fields["series"] = form.Series
+	reqBody, contentType, err := multipartBody(fields, files)
+	if err != nil {
+		return nil, err
+	}
+	var out MeetingResponse
+	if err := c.do(ctx, "POST", "/api/audio/upload", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAPIKeys API密钥列表
+func (c *Client) ListAPIKeys(ctx context.Context) (*APIKeyListResponse, error) {
+	var out APIKeyListResponse
+	if err := c.do(ctx, "GET", "/api/auth/keys", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAPIKey 创建API密钥
+func (c *Client) CreateAPIKey(ctx context.Context, body *CreateAPIKeyRequest) (*APIKeyResponse, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out APIKeyResponse
+	if err := c.do(ctx, "POST", "/api/auth/keys", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeAPIKey 吊销API密钥
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) (*APIKeyResponse, error) {
+	var out APIKeyResponse
+	if err := c.do(ctx, "DELETE", "/api/auth/keys/"+url.PathEscape(id), nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Login 登录
+func (c *Client) Login(ctx context.Context, body *LoginRequest) (*LoginResponse, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out LoginResponse
+	if err := c.do(ctx, "POST", "/api/auth/login", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Logout 退出登录
+func (c *Client) Logout(ctx context.Context) (*StatusResponse, error) {
+	var out StatusResponse
+	if err := c.do(ctx, "POST", "/api/auth/logout", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Me 当前用户
+func (c *Client) Me(ctx context.Context) (*CurrentUserResponse, error) {
+	var out CurrentUserResponse
+	if err := c.do(ctx, "GET", "/api/auth/me", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Register 注册用户
+func (c *Client) Register(ctx context.Context, body *RegisterRequest) (*UserResponse, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out UserResponse
+	if err := c.do(ctx, "POST", "/api/auth/register", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListCalendarEvents 日历目录中的会议
+func (c *Client) ListCalendarEvents(ctx context.Context, params *ListCalendarEventsParams) (*CalendarEventListResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Date != "" {
+			query.Set("date", params.Date)
+		}
+	}
+	var out CalendarEventListResponse
+	if err := c.do(ctx, "GET", "/api/calendar/events", query, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HealthCheck 健康检查（同 /health/live）
+func (c *Client) HealthCheck(ctx context.Context) (*HealthResponse, error) {
+	var out HealthResponse
+	if err := c.do(ctx, "GET", "/api/health", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Live 存活检查
+func (c *Client) Live(ctx context.Context) (*HealthResponse, error) {
+	var out HealthResponse
+	if err := c.do(ctx, "GET", "/api/health/live", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Ready 就绪检查
+func (c *Client) Ready(ctx context.Context) (*HealthReport, error) {
+	var out HealthReport
+	if err := c.do(ctx, "GET", "/api/health/ready", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListMeetings 会议列表
+func (c *Client) ListMeetings(ctx context.Context) (*MeetingListResponse, error) {
+	var out MeetingListResponse
+	if err := c.do(ctx, "GET", "/api/meetings", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AnalyzeTranscript 分析会议转录
+func (c *Client) AnalyzeTranscript(ctx context.Context, body *AnalyzeRequest) (*MeetingResponse, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out MeetingResponse
+	if err := c.do(ctx, "POST", "/api/meetings/analyze", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SyncToNotion 同步会议到Notion
+func (c *Client) SyncToNotion(ctx context.Context, body *SyncToNotionRequest) (*NotionSyncResponse, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out NotionSyncResponse
+	if err := c.do(ctx, "POST", "/api/meetings/sync-notion", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMeeting 会议详情
+func (c *Client) GetMeeting(ctx context.Context, id string) (*Meeting, error) {
+	var out Meeting
+	if err := c.do(ctx, "GET", "/api/meetings/"+url.PathEscape(id), nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMeetingAudio 会议录音
+func (c *Client) GetMeetingAudio(ctx context.Context, id string) ([]byte, error) {
+	var out []byte
+	if err := c.do(ctx, "GET", "/api/meetings/"+url.PathEscape(id)+"/audio", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExportMeeting 导出会议
+func (c *Client) ExportMeeting(ctx context.Context, id string, params *ExportMeetingParams) ([]byte, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Format != "" {
+			query.Set("format", params.Format)
+		}
+		if params.Transcript {
+			query.Set("transcript", strconv.FormatBool(params.Transcript))
+		}
+	}
+	var out []byte
+	if err := c.do(ctx, "GET", "/api/meetings/"+url.PathEscape(id)+"/export", query, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetMeetingReport 按模板渲染会议报告
+func (c *Client) GetMeetingReport(ctx context.Context, id string, params *GetMeetingReportParams) (*MeetingReportResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Template != "" {
+			query.Set("template", params.Template)
+		}
+		if params.Polish {
+			query.Set("polish", strconv.FormatBool(params.Polish))
+		}
+	}
+	var out MeetingReportResponse
+	if err := c.do(ctx, "GET", "/api/meetings/"+url.PathEscape(id)+"/report", query, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportMeetingCalendar 导出会议待办事项日历
+func (c *Client) ExportMeetingCalendar(ctx context.Context, id string, params *ExportMeetingCalendarParams) ([]byte, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Component != "" {
+			query.Set("component", params.Component)
+		}
+	}
+	var out []byte
+	if err := c.do(ctx, "GET", "/api/meetings/"+url.PathEscape(id)+"/todos.ics", query, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExportTranscript 导出会议记录（字幕）
+func (c *Client) ExportTranscript(ctx context.Context, id string, params *ExportTranscriptParams) ([]byte, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Format != "" {
+			query.Set("format", params.Format)
+		}
+		if params.LineLength != 0 {
+			query.Set("lineLength", strconv.Itoa(params.LineLength))
+		}
+		if params.OffsetMs != 0 {
+			query.Set("offsetMs", strconv.Itoa(params.OffsetMs))
+		}
+	}
+	var out []byte
+	if err := c.do(ctx, "GET", "/api/meetings/"+url.PathEscape(id)+"/transcript", query, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListNotionSyncs Notion同步任务列表
+func (c *Client) ListNotionSyncs(ctx context.Context, params *ListNotionSyncsParams) (*NotionSyncListResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Status != "" {
+			query.Set("status", params.Status)
+		}
+	}
+	var out NotionSyncListResponse
+	if err := c.do(ctx, "GET", "/api/notion/syncs", query, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelNotionSync 取消Notion同步任务
+func (c *Client) CancelNotionSync(ctx context.Context, id string) (*NotionSync, error) {
+	var out NotionSync
+	if err := c.do(ctx, "POST", "/api/notion/syncs/"+url.PathEscape(id)+"/cancel", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RetryNotionSync 重试Notion同步任务
+func (c *Client) RetryNotionSync(ctx context.Context, id string) (*NotionSync, error) {
+	var out NotionSync
+	if err := c.do(ctx, "POST", "/api/notion/syncs/"+url.PathEscape(id)+"/retry", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OpenAPISpec OpenAPI文档
+func (c *Client) OpenAPISpec(ctx context.Context) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	if err := c.do(ctx, "GET", "/api/openapi.json", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListProfiles 会议类型列表
+func (c *Client) ListProfiles(ctx context.Context) (*ProfileListResponse, error) {
+	var out ProfileListResponse
+	if err := c.do(ctx, "GET", "/api/profiles", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateProfile 创建会议类型
+func (c *Client) CreateProfile(ctx context.Context, body *ProfileInput) (*Profile, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out Profile
+	if err := c.do(ctx, "POST", "/api/profiles", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteProfile 删除会议类型
+func (c *Client) DeleteProfile(ctx context.Context, name string) (*Profile, error) {
+	var out Profile
+	if err := c.do(ctx, "DELETE", "/api/profiles/"+url.PathEscape(name), nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetProfile 会议类型详情
+func (c *Client) GetProfile(ctx context.Context, name string) (*Profile, error) {
+	var out Profile
+	if err := c.do(ctx, "GET", "/api/profiles/"+url.PathEscape(name), nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateProfile 修改会议类型
+func (c *Client) UpdateProfile(ctx context.Context, name string, body *ProfileInput) (*Profile, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out Profile
+	if err := c.do(ctx, "PUT", "/api/profiles/"+url.PathEscape(name), nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSignedMeetingAudio 会议录音（签名链接）
+func (c *Client) GetSignedMeetingAudio(ctx context.Context, id string, params *GetSignedMeetingAudioParams) ([]byte, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Exp != 0 {
+			query.Set("exp", strconv.Itoa(params.Exp))
+		}
+		if params.Sig != "" {
+			query.Set("sig", params.Sig)
+		}
+	}
+	var out []byte
+	if err := c.do(ctx, "GET", "/api/public/meetings/"+url.PathEscape(id)+"/audio", query, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListReportTemplates 报告模板列表
+func (c *Client) ListReportTemplates(ctx context.Context) (*ReportTemplateListResponse, error) {
+	var out ReportTemplateListResponse
+	if err := c.do(ctx, "GET", "/api/reports/templates", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TodoFeed 个人待办日历（登录或签名链接）
+func (c *Client) TodoFeed(ctx context.Context, assignee string, params *TodoFeedParams) ([]byte, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Component != "" {
+			query.Set("component", params.Component)
+		}
+		if params.Workspace != "" {
+			query.Set("workspace", params.Workspace)
+		}
+		if params.Sig != "" {
+			query.Set("sig", params.Sig)
+		}
+	}
+	var out []byte
+	if err := c.do(ctx, "GET", "/api/todos/feed/"+url.PathEscape(assignee)+".ics", query, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListTodoFeeds 待办日历订阅列表
+func (c *Client) ListTodoFeeds(ctx context.Context) (*TodoFeedListResponse, error) {
+	var out TodoFeedListResponse
+	if err := c.do(ctx, "GET", "/api/todos/feeds", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeTodoFeed 停用个人待办日历订阅链接
+func (c *Client) RevokeTodoFeed(ctx context.Context, assignee string) (*TodoFeed, error) {
+	var out TodoFeed
+	if err := c.do(ctx, "DELETE", "/api/todos/feeds/"+url.PathEscape(assignee), nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RotateTodoFeed 更换个人待办日历订阅链接
+func (c *Client) RotateTodoFeed(ctx context.Context, assignee string) (*TodoFeed, error) {
+	var out TodoFeed
+	if err := c.do(ctx, "POST", "/api/todos/feeds/"+url.PathEscape(assignee)+"/rotate", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorkspace 当前工作区
+func (c *Client) GetWorkspace(ctx context.Context) (*WorkspaceResponse, error) {
+	var out WorkspaceResponse
+	if err := c.do(ctx, "GET", "/api/workspace", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AddWorkspaceMember 添加工作区成员
+func (c *Client) AddWorkspaceMember(ctx context.Context, body *AddMemberRequest) (*UserResponse, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out UserResponse
+	if err := c.do(ctx, "POST", "/api/workspace/members", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorkspaceSettings 工作区设置
+func (c *Client) GetWorkspaceSettings(ctx context.Context) (*WorkspaceSettingsResponse, error) {
+	var out WorkspaceSettingsResponse
+	if err := c.do(ctx, "GET", "/api/workspace/settings", nil, nil, "", &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateWorkspaceSettings 更新工作区设置
+func (c *Client) UpdateWorkspaceSettings(ctx context.Context, body *WorkspaceSettingsUpdate) (*WorkspaceSettingsResponse, error) {
+	reqBody, contentType, err := jsonBody(body)
+	if err != nil {
+		return nil, err
+	}
+	var out WorkspaceSettingsResponse
+	if err := c.do(ctx, "PUT", "/api/workspace/settings", nil, reqBody, contentType, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
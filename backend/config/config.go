@@ -1,77 +1,98 @@
 package config
 
 import (
-	"log"
-	"os"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
-// Config 存储应用程序配置
+// Config 存储应用程序配置。
+//
+// 每个字段的标签说明它的来源和性质：yaml为配置文件中的键名（也是命令行覆盖时使用的名称），
+// env为环境变量名，default为默认值，secret表示输出配置时需要遮盖，
+// reload表示收到SIGHUP后可以不重启直接生效
 type Config struct {
 	// 服务器配置
-	Port string
-	Env  string
+	Port    string `yaml:"port" env:"PORT" default:"8080"`
+	Env     string `yaml:"env" env:"ENV" default:"development"`
+	DataDir string `yaml:"data_dir" env:"DATA_DIR" default:"./data"`
+	// ShutdownTimeout 收到退出信号后等待进行中的请求和任务完成的时间，超时后取消它们
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" default:"30s" reload:"true"`
 
 	// DeepSeek配置
-	DeepSeekAPIKey  string
-	DeepSeekBaseURL string
+	DeepSeekAPIKey  string `yaml:"deepseek_api_key" env:"DEEPSEEK_API_KEY" secret:"true"`
+	DeepSeekBaseURL string `yaml:"deepseek_base_url" env:"DEEPSEEK_BASE_URL" default:"https://api.deepseek.com/v1"`
+	DeepSeekModel   string `yaml:"deepseek_model" env:"DEEPSEEK_MODEL" default:"deepseek-chat"`
+	EmbeddingModel  string `yaml:"embedding_model" env:"EMBEDDING_MODEL"` // 会议问答检索使用的向量模型（OpenAI兼容的/embeddings接口），为空时使用BM25检索
 
-	// Notion配置
-	NotionAPIKey     string
-	NotionDatabaseID string
+	// 分析提示词配置
+	AnalysisSystemPrompt string `yaml:"analysis_system_prompt" env:"ANALYSIS_SYSTEM_PROMPT" reload:"true"` // 替换默认的系统提示词，为空时使用内置提示词
+	AnalysisInstructions string `yaml:"analysis_instructions" env:"ANALYSIS_INSTRUCTIONS" reload:"true"`   // 追加在分析提示词末尾的额外要求
 
-	// Whisper配置
-	WhisperModelPath string
-	UseLocalWhisper  bool
-}
+	// 报告配置
+	ReportTemplateDir string `yaml:"report_template_dir" env:"REPORT_TEMPLATE_DIR" reload:"true"`           // 自定义报告模板目录，其中的*.md.tmpl文件按文件名作为模板名
+	ReportTemplate    string `yaml:"report_template" env:"REPORT_TEMPLATE" default:"default" reload:"true"` // 未指定模板时使用的报告模板
 
-// 全局配置实例
-var AppConfig Config
-
-// LoadConfig 从环境变量加载配置
-func LoadConfig(envFile string) error {
-	// 加载.env文件
-	var err error
-	if envFile != "" {
-		err = godotenv.Load(envFile)
-	} else {
-		err = godotenv.Load()
-	}
-
-	if err != nil {
-		log.Println("警告: .env文件未找到，使用环境变量")
-	}
-
-	// 服务器配置
-	AppConfig.Port = getEnv("PORT", "8080")
-	AppConfig.Env = getEnv("ENV", "development")
-
-	// DeepSeek配置
-	AppConfig.DeepSeekAPIKey = getEnv("DEEPSEEK_API_KEY", "")
-	AppConfig.DeepSeekBaseURL = getEnv("DEEPSEEK_BASE_URL", "https://api.deepseek.com/v1")
+	// 日历配置
+	CalendarDir string `yaml:"calendar_dir" env:"CALENDAR_DIR" reload:"true"` // 本地日历目录，代替CalDAV服务器提供会议邀请，每个工作区的*.ics文件放在以工作区ID命名的子目录中
 
 	// Notion配置
-	AppConfig.NotionAPIKey = getEnv("NOTION_API_KEY", "")
-	AppConfig.NotionDatabaseID = getEnv("NOTION_DATABASE_ID", "")
+	NotionAPIKey     string `yaml:"notion_api_key" env:"NOTION_API_KEY" secret:"true"`
+	NotionDatabaseID string `yaml:"notion_database_id" env:"NOTION_DATABASE_ID"`
+	NotionBaseURL    string `yaml:"notion_base_url" env:"NOTION_BASE_URL" default:"https://api.notion.com/v1"`
+	NotionLayoutFile string `yaml:"notion_layout_file" env:"NOTION_LAYOUT_FILE"`
+	// NotionUserAliases 人名到Notion用户（ID、邮箱或用户名）的别名映射
+	NotionUserAliases       map[string]string `yaml:"notion_user_aliases" env:"NOTION_USER_ALIASES" reload:"true"`
+	NotionAssigneesProperty string            `yaml:"notion_assignees_property" env:"NOTION_ASSIGNEES_PROPERTY" default:"Assignees" reload:"true"`
+
+	// Notion同步发件箱配置
+	NotionSyncMaxAttempts int           `yaml:"notion_sync_max_attempts" env:"NOTION_SYNC_MAX_ATTEMPTS" default:"5" reload:"true"`
+	NotionSyncRetryDelay  time.Duration `yaml:"notion_sync_retry_delay" env:"NOTION_SYNC_RETRY_DELAY" default:"30s" reload:"true"`
 
 	// Whisper配置
-	AppConfig.WhisperModelPath = getEnv("WHISPER_MODEL_PATH", "../whisper/models/ggml-base.bin")
-	AppConfig.UseLocalWhisper = getEnv("USE_LOCAL_WHISPER", "true") == "true"
-
-	return nil
+	WhisperModelPath string `yaml:"whisper_model_path" env:"WHISPER_MODEL_PATH" default:"../whisper/models/ggml-base.bin"`
+	UseLocalWhisper  bool   `yaml:"use_local_whisper" env:"USE_LOCAL_WHISPER" default:"true"`
+	WhisperModel     string `yaml:"whisper_model" env:"WHISPER_MODEL" default:"base"`      // Python Whisper使用的模型名称，如 base、small
+	WhisperLanguage  string `yaml:"whisper_language" env:"WHISPER_LANGUAGE" reload:"true"` // 识别语言，如 zh、en，为空时自动检测
+	WhisperPython    string `yaml:"whisper_python" env:"WHISPER_PYTHON"`                   // 运行Python Whisper的解释器路径，为空时自动查找
+
+	// 认证配置
+	AuthSecret       string        `yaml:"auth_secret" env:"AUTH_SECRET" secret:"true"`                                                       // 签发登录令牌和录音链接的密钥，为空时自动生成并保存在数据目录
+	EncryptionKey    string        `yaml:"encryption_key" env:"ENCRYPTION_KEY" secret:"true"`                                                 // 加密工作区凭据的密钥，为空时自动生成并保存在数据目录
+	AuthTokenTTL     time.Duration `yaml:"auth_token_ttl" env:"AUTH_TOKEN_TTL" default:"168h"`                                                // 登录令牌有效期
+	AuthAllowSignup  bool          `yaml:"auth_allow_signup" env:"AUTH_ALLOW_SIGNUP" default:"false"`                                         // 是否开放注册；未开放时只有第一个用户可以自行注册
+	CORSAllowOrigins string        `yaml:"cors_allow_origins" env:"CORS_ALLOW_ORIGINS" default:"http://localhost:3000,http://localhost:3001"` // 允许跨域访问的来源，多个用逗号分隔
+
+	// 录音保存配置
+	KeepAudio           bool          `yaml:"keep_audio" env:"KEEP_AUDIO" default:"false"`                                          // 保留上传的录音并附加到Notion页面
+	NotionAudioMaxBytes int           `yaml:"notion_audio_max_bytes" env:"NOTION_AUDIO_MAX_BYTES" default:"20971520" reload:"true"` // 上传到Notion的录音大小上限，超过时改为链接
+	PublicBaseURL       string        `yaml:"public_base_url" env:"PUBLIC_BASE_URL"`                                                // 外部访问本服务的地址，用于生成录音链接，默认为本机的PORT端口
+	AudioLinkTTL        time.Duration `yaml:"audio_link_ttl" env:"AUDIO_LINK_TTL" default:"720h" reload:"true"`                     // Notion页面中录音签名链接的有效期
+
+	// 可观测性配置，追踪相关的变量名与OpenTelemetry的约定一致
+	MetricsToken string `yaml:"metrics_token" env:"METRICS_TOKEN" secret:"true"`           // 访问 /metrics 需要的Bearer令牌，为空时不校验
+	OTLPEndpoint string `yaml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`           // OpenTelemetry Collector的OTLP/HTTP地址，如 http://localhost:4318，为空时不导出追踪
+	ServiceName  string `yaml:"service_name" env:"OTEL_SERVICE_NAME" default:"meeting-mm"` // 追踪数据中的服务名
+	// HealthMinFreeDiskMB 数据目录所在磁盘的最小剩余空间（MB），低于该值时就绪检查失败
+	HealthMinFreeDiskMB int `yaml:"health_min_free_disk_mb" env:"HEALTH_MIN_FREE_DISK_MB" default:"500" reload:"true"`
+
+	// 日志配置
+	LogLevel          string `yaml:"log_level" env:"LOG_LEVEL" default:"info" reload:"true"`        // debug、info、warn、error
+	LogFormat         string `yaml:"log_format" env:"LOG_FORMAT" default:"text"`                    // text或json
+	LogCaptureContent bool   `yaml:"log_capture_content" env:"LOG_CAPTURE_CONTENT" default:"false"` // 日志中保留转录、提示词等会议内容，只应在排查问题时临时开启
 }
 
-// GetConfig 获取全局配置实例
-func GetConfig() *Config {
-	return &AppConfig
+// LoadConfig 从.env文件和环境变量加载并校验配置。这里是服务器的默认配置，工作区可以覆盖其中的凭据和处理设置
+func LoadConfig(envFile string) (*Config, error) {
+	return Load(Options{EnvFile: envFile})
 }
 
-// getEnv 获取环境变量，如果不存在则返回默认值
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// Clone 返回配置的副本，映射类型的字段也会复制
+func (c *Config) Clone() *Config {
+	clone := *c
+	if c.NotionUserAliases != nil {
+		clone.NotionUserAliases = make(map[string]string, len(c.NotionUserAliases))
+		for k, v := range c.NotionUserAliases {
+			clone.NotionUserAliases[k] = v
+		}
 	}
-	return value
+	return &clone
 }
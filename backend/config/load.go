@@ -0,0 +1,237 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Options 加载配置的来源，优先级从低到高依次为：默认值、配置文件、.env文件、环境变量、Flags
+type Options struct {
+	// File YAML配置文件，为空时使用环境变量CONFIG_FILE指定的文件，都未指定时不读取
+	File string
+	// EnvFile .env文件，为空时读取当前目录下的.env（不存在时忽略）
+	EnvFile string
+	// Flags 命令行覆盖的配置项，键为配置文件中的键名，如 port
+	Flags map[string]string
+}
+
+// field 一个配置项
+type field struct {
+	index  int
+	name   string // 配置文件中的键名
+	env    string
+	def    string
+	secret bool
+	reload bool
+}
+
+// fields 按声明顺序列出全部配置项
+var fields = func() []field {
+	t := reflect.TypeOf(Config{})
+	list := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag
+		list = append(list, field{
+			index:  i,
+			name:   tag.Get("yaml"),
+			env:    tag.Get("env"),
+			def:    tag.Get("default"),
+			secret: tag.Get("secret") == "true",
+			reload: tag.Get("reload") == "true",
+		})
+	}
+	return list
+}()
+
+// Load 按层加载配置并校验，任何一层中无法解析的值或校验失败的项都会返回错误
+func Load(opts Options) (*Config, error) {
+	cfg := &Config{}
+	for _, f := range fields {
+		if err := cfg.set(f, f.def); err != nil {
+			return nil, fmt.Errorf("%s的默认值无效: %w", f.name, err)
+		}
+	}
+
+	file := opts.File
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	if file != "" {
+		if err := cfg.loadFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	// .env文件中的变量只作为环境变量的补充，不写入进程环境
+	dotenv, err := readEnvFile(opts.EnvFile)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, f := range fields {
+		value := os.Getenv(f.env)
+		if value == "" {
+			value = dotenv[f.env]
+		}
+		if value == "" {
+			continue
+		}
+		if err := cfg.set(f, value); err != nil {
+			problems = append(problems, fmt.Sprintf("环境变量%s: %v", f.env, err))
+		}
+	}
+
+	names := make([]string, 0, len(opts.Flags))
+	for name := range opts.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := opts.Flags[name]
+		f, ok := lookup(name)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("未知的配置项: %s", name))
+			continue
+		}
+		if err := cfg.set(f, value); err != nil {
+			problems = append(problems, fmt.Sprintf("参数%s: %v", name, err))
+		}
+	}
+
+	if cfg.PublicBaseURL == "" {
+		cfg.PublicBaseURL = "http://localhost:" + cfg.Port
+	}
+	// 无法解析的值与校验发现的问题一起返回
+	var invalid *ValidationError
+	if errors.As(cfg.Validate(), &invalid) {
+		problems = append(problems, invalid.Problems...)
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// loadFile 读取YAML配置文件，文件中出现未知的键时返回错误，避免拼写错误被忽略
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("解析配置文件%s失败: %w", path, err)
+	}
+	return nil
+}
+
+// readEnvFile 读取.env文件，未指定文件且当前目录下没有.env时返回空
+func readEnvFile(path string) (map[string]string, error) {
+	explicit := path != ""
+	if !explicit {
+		path = ".env"
+	}
+	values, err := godotenv.Read(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("读取%s失败: %w", path, err)
+	}
+	return values, nil
+}
+
+// lookup 按配置文件中的键名查找配置项
+func lookup(name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set 将字符串形式的值解析后写入配置项
+func (c *Config) set(f field, value string) error {
+	v := reflect.ValueOf(c).Elem().Field(f.index)
+	if v.Type() == durationType {
+		if value == "" {
+			v.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q不是有效的时间间隔（如 30s、5m）", value)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int:
+		if value == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q不是有效的整数", value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		if value == "" {
+			v.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q不是有效的布尔值（true或false）", value)
+		}
+		v.SetBool(b)
+	case reflect.Map:
+		m, err := parseMap(value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("不支持的配置类型%s", v.Type())
+	}
+	return nil
+}
+
+// parseMap 解析形如 "key1=value1,key2=value2" 的映射
+func parseMap(value string) (map[string]string, error) {
+	result := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q缺少“=”", pair)
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if k == "" || v == "" {
+			return nil, fmt.Errorf("%q的名称和值都不能为空", pair)
+		}
+		result[k] = v
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Masked 输出配置时替代密钥的内容
+const Masked = "******"
+
+// Redacted 返回遮盖了密钥的副本，用于打印和记录日志
+func (c *Config) Redacted() *Config {
+	clone := c.Clone()
+	v := reflect.ValueOf(clone).Elem()
+	for _, f := range fields {
+		if f.secret && v.Field(f.index).String() != "" {
+			v.Field(f.index).SetString(Masked)
+		}
+	}
+	return clone
+}
+
+// YAML 以配置文件的格式输出遮盖了密钥的配置，输出的内容可以直接作为配置文件使用（密钥需要重新填写）
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c.Redacted())
+}
+
+// Reload 比较启动时加载的配置loaded和重新加载的配置next，将可以热更新的变化应用到运行中配置running的副本上。
+// 返回更新后的配置、已应用的配置项和需要重启才能生效的配置项
+func Reload(running, loaded, next *Config) (updated *Config, applied, ignored []string) {
+	updated = running.Clone()
+	target := reflect.ValueOf(updated).Elem()
+	before := reflect.ValueOf(loaded).Elem()
+	after := reflect.ValueOf(next.Clone()).Elem()
+	for _, f := range fields {
+		if reflect.DeepEqual(before.Field(f.index).Interface(), after.Field(f.index).Interface()) {
+			continue
+		}
+		if !f.reload {
+			ignored = append(ignored, f.name)
+			continue
+		}
+		target.Field(f.index).Set(after.Field(f.index))
+		applied = append(applied, f.name)
+	}
+	return updated, applied, ignored
+}
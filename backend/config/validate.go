@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError 配置中存在的全部问题，启动时一次列出，避免逐个修改后反复重启
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "配置无效:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// validLogLevels 和 validLogFormats 与logging包支持的取值一致
+var (
+	validLogLevels  = []string{"debug", "info", "warn", "warning", "error"}
+	validLogFormats = []string{"text", "json"}
+)
+
+// Validate 检查配置项的取值，返回*ValidationError
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(env, format string, args ...interface{}) {
+		problems = append(problems, env+": "+fmt.Sprintf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		add("PORT", "%q不是有效的端口号（1-65535）", c.Port)
+	}
+	if strings.TrimSpace(c.DataDir) == "" {
+		add("DATA_DIR", "不能为空")
+	}
+
+	for _, u := range []struct {
+		env, value string
+		required   bool
+	}{
+		{"DEEPSEEK_BASE_URL", c.DeepSeekBaseURL, true},
+		{"NOTION_BASE_URL", c.NotionBaseURL, true},
+		{"PUBLIC_BASE_URL", c.PublicBaseURL, true},
+		{"OTEL_EXPORTER_OTLP_ENDPOINT", c.OTLPEndpoint, false},
+	} {
+		if u.value == "" && !u.required {
+			continue
+		}
+		if err := checkURL(u.value); err != nil {
+			add(u.env, "%v", err)
+		}
+	}
+
+	if c.NotionDatabaseID != "" && !isNotionID(c.NotionDatabaseID) {
+		add("NOTION_DATABASE_ID", "%q不是有效的Notion数据库ID（32位十六进制，可以带连字符）", c.NotionDatabaseID)
+	}
+	if c.NotionAPIKey != "" && c.NotionDatabaseID == "" {
+		add("NOTION_DATABASE_ID", "设置了NOTION_API_KEY时必须同时设置")
+	}
+
+	for _, n := range []struct {
+		env   string
+		value int
+	}{
+		{"NOTION_SYNC_MAX_ATTEMPTS", c.NotionSyncMaxAttempts},
+		{"NOTION_AUDIO_MAX_BYTES", c.NotionAudioMaxBytes},
+	} {
+		if n.value <= 0 {
+			add(n.env, "必须大于0，当前为%d", n.value)
+		}
+	}
+	if c.HealthMinFreeDiskMB < 0 {
+		add("HEALTH_MIN_FREE_DISK_MB", "不能为负数，当前为%d", c.HealthMinFreeDiskMB)
+	}
+
+	for _, d := range []struct {
+		env   string
+		value time.Duration
+	}{
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+		{"NOTION_SYNC_RETRY_DELAY", c.NotionSyncRetryDelay},
+		{"AUTH_TOKEN_TTL", c.AuthTokenTTL},
+	} {
+		if d.value <= 0 {
+			add(d.env, "必须大于0，当前为%s", d.value)
+		}
+	}
+
+	if !contains(validLogLevels, strings.ToLower(c.LogLevel)) {
+		add("LOG_LEVEL", "%q不是有效的日志级别（%s）", c.LogLevel, strings.Join(validLogLevels, "、"))
+	}
+	if !contains(validLogFormats, strings.ToLower(c.LogFormat)) {
+		add("LOG_FORMAT", "%q不是有效的日志格式（%s）", c.LogFormat, strings.Join(validLogFormats, "、"))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkURL 检查地址是否为完整的http或https地址
+func checkURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q不是有效的http(s)地址", value)
+	}
+	return nil
+}
+
+// isNotionID 检查是否为Notion的ID：32位十六进制字符，可以带连字符
+func isNotionID(id string) bool {
+	id = strings.ReplaceAll(id, "-", "")
+	if len(id) != 32 {
+		return false
+	}
+	for _, r := range id {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DOCX的中文字体：Word按w:eastAsia选择中日韩文字的字体，西文使用w:ascii。
+// 未安装宋体的系统上Word和LibreOffice会自动替换为其他中文字体
+const (
+	docxLatinFont = "Calibri"
+	docxCJKFont   = "宋体"
+)
+
+// docxParts 除正文外的固定部件
+var docxParts = []struct {
+	name, content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>
+</Relationships>`},
+	{"word/_rels/document.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`},
+	{"word/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults>
+<w:rPrDefault><w:rPr><w:rFonts w:ascii="` + docxLatinFont + `" w:hAnsi="` + docxLatinFont + `" w:eastAsia="` + docxCJKFont + `" w:cs="` + docxLatinFont + `"/><w:sz w:val="22"/><w:szCs w:val="22"/><w:lang w:val="en-US" w:eastAsia="zh-CN"/></w:rPr></w:rPrDefault>
+<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="300" w:lineRule="auto"/></w:pPr></w:pPrDefault>
+</w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:spacing w:after="240"/></w:pPr><w:rPr><w:b/><w:sz w:val="40"/><w:szCs w:val="40"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="360" w:after="120"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:sz w:val="30"/><w:szCs w:val="30"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Meta"><w:name w:val="Meta"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:after="40"/></w:pPr><w:rPr><w:color w:val="59636E"/></w:rPr></w:style>
+<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:tblPr><w:tblBorders><w:top w:val="single" w:sz="4" w:color="BFBFBF"/><w:left w:val="single" w:sz="4" w:color="BFBFBF"/><w:bottom w:val="single" w:sz="4" w:color="BFBFBF"/><w:right w:val="single" w:sz="4" w:color="BFBFBF"/><w:insideH w:val="single" w:sz="4" w:color="BFBFBF"/><w:insideV w:val="single" w:sz="4" w:color="BFBFBF"/></w:tblBorders><w:tblCellMar><w:left w:w="100" w:type="dxa"/><w:right w:w="100" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>
+</w:styles>`},
+}
+
+// docxColumnWidths 待办事项表格各列的宽度（twip），合计为A4版心宽度
+var docxColumnWidths = []int{4426, 1600, 1500, 1500}
+
+// renderDOCX 生成Word文档
+func renderDOCX(doc *document) ([]byte, error) {
+	var body docxBody
+	body.paragraph("Title", run{text: doc.Title})
+	for _, meta := range doc.Meta {
+		body.paragraph("Meta", run{text: meta.Label + "：", bold: true}, run{text: meta.Value})
+	}
+
+	body.paragraph("Heading1", run{text: headingSummary})
+	if doc.Summary == "" {
+		body.paragraph("", run{text: textNone})
+	}
+	for _, p := range paragraphs(doc.Summary) {
+		body.paragraph("", run{text: p})
+	}
+
+	body.paragraph("Heading1", run{text: headingTodos})
+	if len(doc.Todos) == 0 {
+		body.paragraph("", run{text: textNone})
+	} else {
+		body.table(todoColumns, doc.Todos)
+	}
+
+	body.paragraph("Heading1", run{text: headingDecisions})
+	if len(doc.Decisions) == 0 {
+		body.paragraph("", run{text: textNone})
+	}
+	for i, decision := range doc.Decisions {
+		body.paragraph("", run{text: fmt.Sprintf("%d. ", i+1)}, run{text: decision})
+	}
+
+	for _, list := range doc.Lists {
+		body.paragraph("Heading1", run{text: list.Heading})
+		for _, item := range list.Items {
+			body.paragraph("", run{text: "• "}, run{text: item})
+		}
+	}
+
+	if len(doc.Transcript) > 0 {
+		body.paragraph("Heading1", run{text: headingTranscript})
+		for _, line := range doc.Transcript {
+			for i, p := range paragraphs(line.Text) {
+				if prefix := transcriptPrefix(line); prefix != "" && i == 0 {
+					body.paragraph("", run{text: prefix + " ", bold: true}, run{text: p})
+				} else {
+					body.paragraph("", run{text: p})
+				}
+			}
+		}
+	}
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		body.String() +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="720" w:footer="720" w:gutter="0"/></w:sectPr></w:body></w:document>`
+
+	modified := doc.Modified.UTC()
+	if modified.IsZero() {
+		modified = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	core := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+		`<dc:title>` + escapeXML(doc.Title) + `</dc:title><dc:creator>meeting-mm</dc:creator>` +
+		`<dcterms:modified xsi:type="dcterms:W3CDTF">` + modified.Format(time.RFC3339) + `</dcterms:modified></cp:coreProperties>`
+
+	// 固定压缩包中的文件时间，相同的会议导出相同的文件
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name, content string) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(content))
+		return err
+	}
+	for _, part := range docxParts {
+		if err := write(part.name, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := write("word/document.xml", document); err != nil {
+		return nil, err
+	}
+	if err := write("docProps/core.xml", core); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// run 一段格式相同的文字
+type run struct {
+	text string
+	bold bool
+}
+
+// docxBody 正文的XML
+type docxBody struct {
+	strings.Builder
+}
+
+// paragraph 输出一个段落，style为空时使用正文样式
+func (b *docxBody) paragraph(style string, runs ...run) {
+	b.WriteString("<w:p>")
+	if style != "" {
+		b.WriteString(`<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`)
+	}
+	for _, r := range runs {
+		b.WriteString("<w:r>")
+		if r.bold {
+			b.WriteString("<w:rPr><w:b/></w:rPr>")
+		}
+		b.WriteString(`<w:t xml:space="preserve">` + escapeXML(r.text) + "</w:t></w:r>")
+	}
+	b.WriteString("</w:p>")
+}
+
+// table 输出带表头的表格，表头在每页重复
+func (b *docxBody) table(header []string, rows [][]string) {
+	b.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="0" w:type="auto"/><w:tblLayout w:type="fixed"/></w:tblPr><w:tblGrid>`)
+	for _, width := range docxColumnWidths {
+		fmt.Fprintf(b, `<w:gridCol w:w="%d"/>`, width)
+	}
+	b.WriteString("</w:tblGrid>")
+
+	b.WriteString(`<w:tr><w:trPr><w:tblHeader/></w:trPr>`)
+	for i, cell := range header {
+		b.cell(i, cell, true)
+	}
+	b.WriteString("</w:tr>")
+	for _, row := range rows {
+		b.WriteString(`<w:tr><w:trPr><w:cantSplit/></w:trPr>`)
+		for i, cell := range row {
+			b.cell(i, cell, false)
+		}
+		b.WriteString("</w:tr>")
+	}
+	b.WriteString("</w:tbl>")
+}
+
+func (b *docxBody) cell(column int, text string, header bool) {
+	fmt.Fprintf(b, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>`, docxColumnWidths[column])
+	if header {
+		b.WriteString(`<w:shd w:val="clear" w:color="auto" w:fill="F2F2F2"/>`)
+	}
+	b.WriteString("</w:tcPr>")
+	// 单元格中至少要有一个段落
+	b.paragraph("", run{text: text, bold: header})
+	b.WriteString("</w:tc>")
+}
+
+// escapeXML 转义XML文本，并去掉XML不允许的控制字符
+func escapeXML(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, text)
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
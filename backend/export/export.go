@@ -0,0 +1,281 @@
+// Package export 将会议导出为Markdown、HTML、Word（DOCX）和PDF文件。
+// 全部格式都由纯Go生成，不依赖外部程序；各格式共用同一份文档结构：
+// 基本信息、摘要、待办事项表格、决策事项，以及可选的会议记录附录
+package export
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"meeting-mm/models"
+)
+
+// 支持的导出格式
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+	FormatDOCX     = "docx"
+	FormatPDF      = "pdf"
+)
+
+// ErrUnsupportedFormat 不支持的导出格式
+var ErrUnsupportedFormat = errors.New("不支持的导出格式")
+
+// contentTypes 各格式的内容类型
+var contentTypes = map[string]string{
+	FormatMarkdown: "text/markdown; charset=utf-8",
+	FormatHTML:     "text/html; charset=utf-8",
+	FormatDOCX:     "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	FormatPDF:      "application/pdf",
+}
+
+// Formats 返回支持的导出格式
+func Formats() []string {
+	return []string{FormatMarkdown, FormatHTML, FormatDOCX, FormatPDF}
+}
+
+// ContentType 返回导出格式的内容类型
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Options 导出选项
+type Options struct {
+	// IncludeTranscript 在文末附上会议记录
+	IncludeTranscript bool
+}
+
+// File 导出的文件
+type File struct {
+	Name        string // 建议的文件名，如 周会-2025-03-14.docx
+	ContentType string
+	Data        []byte
+}
+
+// Render 按格式导出会议
+func Render(format string, meeting *models.Meeting, opts Options) (*File, error) {
+	doc := newDocument(meeting, opts)
+
+	var data []byte
+	var err error
+	switch format {
+	case FormatMarkdown:
+		data = renderMarkdown(doc)
+	case FormatHTML:
+		data, err = renderHTML(doc)
+	case FormatDOCX:
+		data, err = renderDOCX(doc)
+	case FormatPDF:
+		data, err = renderPDF(doc)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("导出%s失败: %w", format, err)
+	}
+	return &File{Name: FileName(meeting, format), ContentType: ContentType(format), Data: data}, nil
+}
+
+// 各格式共用的文字
+const (
+	labelDate         = "会议日期"
+	labelParticipants = "参与人员"
+	labelKeywords     = "关键词"
+	headingSummary    = "摘要"
+	headingTodos      = "待办事项"
+	headingDecisions  = "决策事项"
+	headingTopics     = "讨论主题"
+	headingQuestions  = "待解决的问题"
+	headingRisks      = "风险和阻塞项"
+	headingSpeakers   = "发言人"
+	headingTranscript = "附录：会议记录"
+	textNone          = "（无）"
+)
+
+// todoColumns 待办事项表格的列
+var todoColumns = []string{"事项", "负责人", "截止日期", "状态"}
+
+// statusLabels 待办状态的显示名称
+var statusLabels = map[string]string{
+	"pending":     "待办",
+	"in_progress": "进行中",
+	"completed":   "已完成",
+}
+
+// document 导出文件的内容
+type document struct {
+	Title      string
+	Meta       []metaLine
+	Modified   time.Time // 写入文件元数据的时间，使相同的会议导出相同的文件
+	Summary    string
+	Todos      [][]string // 每行依次为 todoColumns 的各列
+	Decisions  []string
+	Lists      []listSection    // 决策之后的主题、额外提取的内容、问题、风险和发言人，没有内容的不输出
+	Transcript []transcriptLine // 为空时不输出附录
+}
+
+// listSection 以列表形式输出的一节
+type listSection struct {
+	Heading string
+	Items   []string
+}
+
+type metaLine struct {
+	Label, Value string
+}
+
+// transcriptLine 会议记录的一段，没有分段时整篇转录为一段
+type transcriptLine struct {
+	Time    string // mm:ss，可能为空
+	Speaker string
+	Text    string
+}
+
+func newDocument(meeting *models.Meeting, opts Options) *document {
+	doc := &document{
+		Title:    meeting.Title,
+		Summary:  strings.TrimSpace(meeting.Summary),
+		Modified: meeting.UpdatedAt,
+	}
+	if doc.Title == "" {
+		doc.Title = "会议纪要"
+	}
+	if doc.Modified.IsZero() {
+		doc.Modified = meeting.Date
+	}
+	if !meeting.Date.IsZero() {
+		doc.Meta = append(doc.Meta, metaLine{labelDate, meeting.Date.Format("2006-01-02")})
+	}
+	if len(meeting.Participants) > 0 {
+		doc.Meta = append(doc.Meta, metaLine{labelParticipants, strings.Join(meeting.Participants, "、")})
+	}
+	if len(meeting.Keywords) > 0 {
+		doc.Meta = append(doc.Meta, metaLine{labelKeywords, strings.Join(meeting.Keywords, "、")})
+	}
+
+	for _, todo := range meeting.TodoItems {
+		due := ""
+		if !todo.DueDate.IsZero() {
+			due = todo.DueDate.Format("2006-01-02")
+		}
+		doc.Todos = append(doc.Todos, []string{todo.Description, todo.Assignee, due, label(statusLabels, todo.Status)})
+	}
+	for _, decision := range meeting.Decisions {
+		text := decision.Description
+		if decision.MadeBy != "" {
+			text += "（" + decision.MadeBy + "）"
+		}
+		doc.Decisions = append(doc.Decisions, text)
+	}
+
+	doc.Lists = listSections(meeting)
+
+	if opts.IncludeTranscript {
+		for _, segment := range meeting.Segments {
+			doc.Transcript = append(doc.Transcript, transcriptLine{
+				Time:    formatSeconds(segment.StartTime),
+				Speaker: segment.Speaker,
+				Text:    strings.TrimSpace(segment.Text),
+			})
+		}
+		if len(meeting.Segments) == 0 && strings.TrimSpace(meeting.Transcript) != "" {
+			doc.Transcript = []transcriptLine{{Text: strings.TrimSpace(meeting.Transcript)}}
+		}
+	}
+	return doc
+}
+
+// listSections 生成决策之后的各节，顺序为讨论主题、会议类型额外提取的内容、待解决的问题、风险和发言人
+func listSections(meeting *models.Meeting) []listSection {
+	var sections []listSection
+	add := func(heading string, items []string) {
+		if len(items) > 0 {
+			sections = append(sections, listSection{Heading: heading, Items: items})
+		}
+	}
+
+	var topics []string
+	for _, topic := range meeting.Topics {
+		text := topic.Title
+		if topic.HasTimeRange() {
+			text += fmt.Sprintf("（%s–%s）", formatSeconds(topic.StartTime), formatSeconds(topic.EndTime))
+		}
+		if topic.Summary != "" {
+			text += "：" + topic.Summary
+		}
+		topics = append(topics, text)
+	}
+	add(headingTopics, topics)
+
+	for _, field := range meeting.ExtraFields {
+		add(field.Label, field.Items)
+	}
+	add(headingQuestions, meeting.OpenQuestions)
+
+	var risks []string
+	for _, risk := range meeting.Risks {
+		text := "【" + models.RiskKindLabel(risk.Kind) + "】" + risk.Description
+		if risk.Owner != "" {
+			text += "（跟进：" + risk.Owner + "）"
+		}
+		risks = append(risks, text)
+	}
+	add(headingRisks, risks)
+
+	var speakers []string
+	for _, insight := range meeting.SpeakerInsights {
+		text := fmt.Sprintf("%s：%s，参与度%s", insight.Speaker, models.SentimentLabel(insight.Sentiment), models.EngagementLabel(insight.Engagement))
+		if insight.Note != "" {
+			text += "。" + insight.Note
+		}
+		speakers = append(speakers, text)
+	}
+	add(headingSpeakers, speakers)
+	return sections
+}
+
+// label 返回值的显示名称，没有对应名称时返回原值
+func label(labels map[string]string, value string) string {
+	if name, ok := labels[value]; ok {
+		return name
+	}
+	return value
+}
+
+// formatSeconds 将秒数格式化为mm:ss
+func formatSeconds(seconds float64) string {
+	total := int(seconds)
+	if total < 0 {
+		total = 0
+	}
+	return fmt.Sprintf("%02d:%02d", total/60, total%60)
+}
+
+// unsafeFileChars 文件名中不允许的字符
+var unsafeFileChars = regexp.MustCompile(`[\\/:*?"<>|\x00-\x1f]+`)
+
+// FileName 生成导出文件名：标题-日期.扩展名
+func FileName(meeting *models.Meeting, format string) string {
+	name := strings.TrimSpace(unsafeFileChars.ReplaceAllString(meeting.Title, "_"))
+	if name == "" {
+		name = "meeting"
+	}
+	if !meeting.Date.IsZero() {
+		name += "-" + meeting.Date.Format("2006-01-02")
+	}
+	return name + "." + format
+}
+
+// paragraphs 按换行拆分文本，去掉空行
+func paragraphs(text string) []string {
+	var result []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
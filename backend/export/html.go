@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// htmlTemplate 独立的HTML页面，样式内联，字体优先使用各平台的中文字体
+var htmlTemplate = template.Must(template.New("meeting").Funcs(template.FuncMap{
+	"paragraphs": paragraphs,
+	"prefix":     transcriptPrefix,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Hiragino Sans GB", "Microsoft YaHei", "Noto Sans CJK SC", "Source Han Sans SC", "WenQuanYi Micro Hei", sans-serif; line-height: 1.7; color: #1f2328; max-width: 820px; margin: 2rem auto; padding: 0 1.5rem; }
+h1 { font-size: 1.8rem; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+h2 { font-size: 1.3rem; margin-top: 2rem; }
+.meta { color: #59636e; padding: 0; list-style: none; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: .4em .7em; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+.none { color: #8c959f; }
+.transcript p { margin: .4em 0; }
+@media print { body { margin: 0; max-width: none; } h2 { break-after: avoid; } tr { break-inside: avoid; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Meta}}
+<ul class="meta">
+{{- range .Meta}}
+<li><strong>{{.Label}}</strong>：{{.Value}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<h2>` + headingSummary + `</h2>
+{{- range paragraphs .Summary}}
+<p>{{.}}</p>
+{{- else}}
+<p class="none">` + textNone + `</p>
+{{- end}}
+<h2>` + headingTodos + `</h2>
+{{- if .Todos}}
+<table>
+<thead><tr>{{range .TodoColumns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Todos}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p class="none">` + textNone + `</p>
+{{- end}}
+<h2>` + headingDecisions + `</h2>
+{{- if .Decisions}}
+<ol>
+{{- range .Decisions}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+{{- else}}
+<p class="none">` + textNone + `</p>
+{{- end}}
+{{- range .Lists}}
+<h2>{{.Heading}}</h2>
+<ul>
+{{- range .Items}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Transcript}}
+<h2>` + headingTranscript + `</h2>
+<div class="transcript">
+{{- range .Transcript}}
+<p>{{with prefix .}}<strong>{{.}}</strong> {{end}}{{.Text}}</p>
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+// renderHTML 生成独立的HTML页面，内容已转义
+func renderHTML(doc *document) ([]byte, error) {
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, struct {
+		*document
+		TodoColumns []string
+	}{doc, todoColumns})
+	return buf.Bytes(), err
+}
@@ -0,0 +1,175 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"meeting-mm/models"
+)
+
+// CalendarContentType iCalendar的内容类型
+const CalendarContentType = "text/calendar; charset=utf-8"
+
+// 待办事项在日历中的组件类型：VTODO出现在Apple提醒事项、Thunderbird等任务列表中；
+// 不支持任务的日历（如Google日历）使用VEVENT，在截止日期显示为全天事件
+const (
+	ComponentTodo  = "vtodo"
+	ComponentEvent = "vevent"
+)
+
+// icsStatus 待办状态对应的VTODO状态
+var icsStatus = map[string]string{
+	"pending":     "NEEDS-ACTION",
+	"in_progress": "IN-PROCESS",
+	"completed":   "COMPLETED",
+}
+
+// CalendarItem 日历中的一条待办事项及其所属会议
+type CalendarItem struct {
+	Meeting *models.Meeting
+	Todo    models.TodoItem
+	Index   int // 待办事项在会议中的序号，待办事项没有ID时用于生成UID
+}
+
+// CalendarOptions 日历导出选项
+type CalendarOptions struct {
+	// Name 日历名称，订阅时显示在日历应用中
+	Name string
+	// Component 为ComponentTodo或ComponentEvent，默认为ComponentTodo；VEVENT跳过没有截止日期的待办事项
+	Component string
+	// MeetingURL 返回会议的链接，写入URL和DESCRIPTION；为nil时不输出链接
+	MeetingURL func(*models.Meeting) string
+}
+
+// MeetingCalendarItems 返回会议的全部待办事项
+func MeetingCalendarItems(meeting *models.Meeting) []CalendarItem {
+	items := make([]CalendarItem, len(meeting.TodoItems))
+	for i, todo := range meeting.TodoItems {
+		items[i] = CalendarItem{Meeting: meeting, Todo: todo, Index: i}
+	}
+	return items
+}
+
+// RenderCalendar 将待办事项生成为iCalendar（RFC 5545），条目按截止日期排序，没有截止日期的排在最后
+func RenderCalendar(items []CalendarItem, opts CalendarOptions) []byte {
+	if opts.Component == "" {
+		opts.Component = ComponentTodo
+	}
+	items = append([]CalendarItem(nil), items...)
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Todo.DueDate, items[j].Todo.DueDate
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+
+	var w icsWriter
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//meeting-mm//Meeting Todos//ZH")
+	w.line("CALSCALE", "GREGORIAN")
+	if opts.Name != "" {
+		w.line("X-WR-CALNAME", icsText(opts.Name))
+	}
+	for _, item := range items {
+		if opts.Component == ComponentEvent && item.Todo.DueDate.IsZero() {
+			continue
+		}
+		w.item(item, opts)
+	}
+	w.line("END", "VCALENDAR")
+	return []byte(w.String())
+}
+
+// icsWriter 按RFC 5545输出内容行：以CRLF结尾，超过75字节时折行
+type icsWriter struct {
+	strings.Builder
+}
+
+func (w *icsWriter) item(item CalendarItem, opts CalendarOptions) {
+	meeting, todo := item.Meeting, item.Todo
+	component := strings.ToUpper(opts.Component)
+	// 待办台账中的条目在各次会议中对应同一个日历条目
+	uid := todo.ActionItemID
+	if uid == "" {
+		uid = todo.ID
+	}
+	if uid == "" {
+		uid = fmt.Sprintf("%s-%d", meeting.ID, item.Index)
+	}
+	stamp := meeting.UpdatedAt
+	if stamp.IsZero() {
+		stamp = meeting.CreatedAt
+	}
+
+	w.line("BEGIN", component)
+	w.line("UID", icsText(uid+"@meeting-mm"))
+	w.line("DTSTAMP", stamp.UTC().Format("20060102T150405Z"))
+	w.line("SUMMARY", icsText(todo.Description))
+
+	var description []string
+	if todo.Assignee != "" {
+		description = append(description, "负责人："+todo.Assignee)
+	}
+	source := "会议：" + meeting.Title
+	if !meeting.Date.IsZero() {
+		source += "（" + meeting.Date.Format("2006-01-02") + "）"
+	}
+	description = append(description, source)
+	link := ""
+	if opts.MeetingURL != nil {
+		link = opts.MeetingURL(meeting)
+		description = append(description, link)
+	}
+	w.line("DESCRIPTION", icsText(strings.Join(description, "\n")))
+	if link != "" {
+		w.line("URL", link)
+	}
+
+	due := todo.DueDate
+	if component == "VTODO" {
+		if !due.IsZero() {
+			w.line("DUE;VALUE=DATE", calendarDate(due))
+		}
+		if status := icsStatus[todo.Status]; status != "" {
+			w.line("STATUS", status)
+		}
+	} else {
+		w.line("DTSTART;VALUE=DATE", calendarDate(due))
+		w.line("DTEND;VALUE=DATE", calendarDate(due.AddDate(0, 0, 1)))
+		w.line("TRANSP", "TRANSPARENT")
+	}
+	w.line("END", component)
+}
+
+// line 输出一个内容行，超过75字节时在字符边界折行，续行以空格开头
+func (w *icsWriter) line(name, value string) {
+	content := name + ":" + value
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		w.WriteString(content[:cut] + "\r\n ")
+		content = content[cut:]
+		limit = 74 // 续行开头的空格占1字节
+	}
+	w.WriteString(content + "\r\n")
+}
+
+// icsEscape 转义TEXT类型的值
+var icsEscape = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func icsText(text string) string {
+	return icsEscape.Replace(text)
+}
+
+// calendarDate 日历中使用的日期，与时区无关
+func calendarDate(t time.Time) string {
+	return t.Format("20060102")
+}
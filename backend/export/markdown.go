@@ -0,0 +1,88 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// markdownCell 转义表格单元格中的竖线和换行
+var markdownCell = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+// renderMarkdown 生成Markdown，待办事项为表格
+func renderMarkdown(doc *document) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", doc.Title)
+	for _, meta := range doc.Meta {
+		fmt.Fprintf(&buf, "- **%s**：%s\n", meta.Label, meta.Value)
+	}
+	if len(doc.Meta) > 0 {
+		buf.WriteString("\n")
+	}
+
+	fmt.Fprintf(&buf, "## %s\n\n", headingSummary)
+	if doc.Summary == "" {
+		buf.WriteString(textNone + "\n\n")
+	}
+	for _, p := range paragraphs(doc.Summary) {
+		buf.WriteString(p + "\n\n")
+	}
+
+	fmt.Fprintf(&buf, "## %s\n\n", headingTodos)
+	if len(doc.Todos) == 0 {
+		buf.WriteString(textNone + "\n\n")
+	} else {
+		buf.WriteString("| " + strings.Join(todoColumns, " | ") + " |\n")
+		buf.WriteString(strings.Repeat("| --- ", len(todoColumns)) + "|\n")
+		for _, row := range doc.Todos {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = markdownCell.Replace(cell)
+			}
+			buf.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+		buf.WriteString("\n")
+	}
+
+	fmt.Fprintf(&buf, "## %s\n\n", headingDecisions)
+	if len(doc.Decisions) == 0 {
+		buf.WriteString(textNone + "\n\n")
+	}
+	for i, decision := range doc.Decisions {
+		fmt.Fprintf(&buf, "%d. %s\n", i+1, decision)
+	}
+	if len(doc.Decisions) > 0 {
+		buf.WriteString("\n")
+	}
+
+	for _, list := range doc.Lists {
+		fmt.Fprintf(&buf, "## %s\n\n", list.Heading)
+		for _, item := range list.Items {
+			fmt.Fprintf(&buf, "- %s\n", item)
+		}
+		buf.WriteString("\n")
+	}
+
+	if len(doc.Transcript) > 0 {
+		fmt.Fprintf(&buf, "## %s\n\n", headingTranscript)
+		for _, line := range doc.Transcript {
+			if prefix := transcriptPrefix(line); prefix != "" {
+				fmt.Fprintf(&buf, "**%s** ", prefix)
+			}
+			buf.WriteString(line.Text + "\n\n")
+		}
+	}
+	return append(bytes.TrimRight(buf.Bytes(), "\n"), '\n')
+}
+
+// transcriptPrefix 会议记录段落的前缀，如“[01:05] 张三:”
+func transcriptPrefix(line transcriptLine) string {
+	var parts []string
+	if line.Time != "" {
+		parts = append(parts, "["+line.Time+"]")
+	}
+	if line.Speaker != "" {
+		parts = append(parts, line.Speaker+":")
+	}
+	return strings.Join(parts, " ")
+}
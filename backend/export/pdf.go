@@ -0,0 +1,340 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// PDF使用Adobe预定义的中文字体STSong-Light（Adobe-GB1字符集，UniGB-UCS2-H编码）。
+// 预定义字体不需要嵌入字形，阅读器使用本机的中文字体显示，生成的文件很小；
+// 文字以UCS-2编码写入，基本多文种平面之外的字符（如emoji）替换为“?”
+const (
+	pdfFontName = "STSong-Light"
+	pdfEncoding = "UniGB-UCS2-H"
+)
+
+// 页面尺寸（A4，单位为点）和版式
+const (
+	pdfPageWidth    = 595.28
+	pdfPageHeight   = 841.89
+	pdfMargin       = 56.0
+	pdfContentWidth = pdfPageWidth - 2*pdfMargin
+	pdfFooterY      = 30.0
+
+	pdfTitleSize   = 20.0
+	pdfHeadingSize = 14.0
+	pdfBodySize    = 10.5
+	pdfSmallSize   = 9.0
+	pdfLineHeight  = 1.5 // 行高相对字号的倍数
+	pdfCellPadding = 4.0
+)
+
+// pdfColumnRatios 待办事项表格各列宽度占版心宽度的比例
+var pdfColumnRatios = []float64{0.48, 0.18, 0.17, 0.17}
+
+// runeWidth 字符宽度（以字号为单位）：ASCII字符为半角，其他为全角，与字体的W数组一致
+func runeWidth(r rune) float64 {
+	if r >= 0x20 && r <= 0x7e {
+		return 0.5
+	}
+	return 1
+}
+
+func textWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		width += runeWidth(r)
+	}
+	return width * size
+}
+
+// wrapText 按宽度折行：中日韩文字可以在任意位置折行，连续的西文字母和数字尽量不拆开
+func wrapText(text string, size, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines = append(lines, wrapLine(strings.TrimRight(paragraph, " \r\t"), size, maxWidth)...)
+	}
+	return lines
+}
+
+func wrapLine(text string, size, maxWidth float64) []string {
+	if text == "" {
+		return []string{""}
+	}
+
+	var lines []string
+	var line []rune
+	width := 0.0
+	flush := func() {
+		lines = append(lines, strings.TrimRight(string(line), " "))
+		line, width = nil, 0
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		// 一个折行单位：一段连续的西文单词，或一个其他字符
+		j := i + 1
+		if isWordRune(runes[i]) {
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+		}
+		token := runes[i:j]
+		tokenWidth := textWidth(string(token), size)
+
+		switch {
+		case width+tokenWidth <= maxWidth:
+			line = append(line, token...)
+			width += tokenWidth
+			i = j
+		case len(line) > 0 && tokenWidth <= maxWidth:
+			// 当前行放不下，换行后再放
+			flush()
+			if token[0] == ' ' {
+				i = j
+			}
+		default:
+			// 单词比整行还宽时按字符拆分
+			w := textWidth(string(runes[i]), size)
+			if width+w > maxWidth && len(line) > 0 {
+				flush()
+			}
+			line = append(line, runes[i])
+			width += w
+			i++
+		}
+	}
+	if len(line) > 0 {
+		flush()
+	}
+	return lines
+}
+
+func isWordRune(r rune) bool {
+	return r > ' ' && r <= '~'
+}
+
+// pdfWriter 排版并生成PDF
+type pdfWriter struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // 当前位置到页面底部的距离
+}
+
+func (w *pdfWriter) newPage() {
+	w.page = new(bytes.Buffer)
+	w.pages = append(w.pages, w.page)
+	w.y = pdfPageHeight - pdfMargin
+}
+
+// ensure 剩余空间不足height时换页，返回是否换页
+func (w *pdfWriter) ensure(height float64) bool {
+	if w.page == nil || w.y-height < pdfMargin {
+		w.newPage()
+		return true
+	}
+	return false
+}
+
+// text 在(x, y)处输出一行文字，y为基线位置
+func (w *pdfWriter) text(x, y float64, text string, size float64, bold bool) {
+	if text == "" {
+		return
+	}
+	// 预定义字体没有粗体，粗体通过描边模拟
+	mode := "0 Tr"
+	if bold {
+		mode = fmt.Sprintf("2 Tr %.2f w", size/30)
+	}
+	fmt.Fprintf(w.page, "BT /F1 %.2f Tf %s %.2f %.2f Td <%s> Tj ET\n", size, mode, x, y, encodeUCS2(text))
+}
+
+// lines 输出折行后的段落，必要时换页
+func (w *pdfWriter) lines(text string, x, size float64, bold bool, spaceAfter float64) {
+	lineHeight := size * pdfLineHeight
+	for _, line := range wrapText(text, size, pdfContentWidth-(x-pdfMargin)) {
+		w.ensure(lineHeight)
+		w.y -= lineHeight
+		w.text(x, w.y+(lineHeight-size)/2+size*0.12, line, size, bold)
+	}
+	w.y -= spaceAfter
+}
+
+// heading 输出小节标题，标题后至少能再放下两行正文，避免标题单独留在页尾
+func (w *pdfWriter) heading(text string) {
+	w.y -= pdfHeadingSize * 0.6
+	w.ensure(pdfHeadingSize*pdfLineHeight + 2*pdfBodySize*pdfLineHeight)
+	w.lines(text, pdfMargin, pdfHeadingSize, true, pdfBodySize*0.4)
+}
+
+// table 输出带边框的表格，跨页时在新页面重复表头
+func (w *pdfWriter) table(header []string, rows [][]string) {
+	widths := make([]float64, len(pdfColumnRatios))
+	for i, ratio := range pdfColumnRatios {
+		widths[i] = pdfContentWidth * ratio
+	}
+
+	lineHeight := pdfBodySize * pdfLineHeight
+	layout := func(row []string) ([][]string, float64) {
+		cells := make([][]string, len(row))
+		height := 0.0
+		for i, cell := range row {
+			cells[i] = wrapText(cell, pdfBodySize, widths[i]-2*pdfCellPadding)
+			if h := float64(len(cells[i]))*lineHeight + 2*pdfCellPadding; h > height {
+				height = h
+			}
+		}
+		return cells, height
+	}
+
+	drawRow := func(cells [][]string, height float64, bold bool) {
+		top := w.y
+		if bold {
+			fmt.Fprintf(w.page, "0.95 g %.2f %.2f %.2f %.2f re f 0 g\n", pdfMargin, top-height, pdfContentWidth, height)
+		}
+		x := pdfMargin
+		for i, lines := range cells {
+			for j, line := range lines {
+				baseline := top - pdfCellPadding - float64(j+1)*lineHeight + (lineHeight-pdfBodySize)/2 + pdfBodySize*0.12
+				w.text(x+pdfCellPadding, baseline, line, pdfBodySize, bold)
+			}
+			fmt.Fprintf(w.page, "0.75 G 0.5 w %.2f %.2f %.2f %.2f re S 0 G\n", x, top-height, widths[i], height)
+			x += widths[i]
+		}
+		w.y -= height
+	}
+
+	headerCells, headerHeight := layout(header)
+	w.ensure(headerHeight + lineHeight + 2*pdfCellPadding)
+	drawRow(headerCells, headerHeight, true)
+	for _, row := range rows {
+		cells, height := layout(row)
+		if w.ensure(height) {
+			drawRow(headerCells, headerHeight, true)
+		}
+		drawRow(cells, height, false)
+	}
+	w.y -= pdfBodySize
+}
+
+// renderPDF 生成PDF文档
+func renderPDF(doc *document) ([]byte, error) {
+	w := &pdfWriter{}
+	w.newPage()
+
+	w.lines(doc.Title, pdfMargin, pdfTitleSize, true, pdfBodySize*0.5)
+	for _, meta := range doc.Meta {
+		w.lines(meta.Label+"："+meta.Value, pdfMargin, pdfBodySize, false, 0)
+	}
+
+	w.heading(headingSummary)
+	if doc.Summary == "" {
+		w.lines(textNone, pdfMargin, pdfBodySize, false, pdfBodySize*0.4)
+	}
+	for _, p := range paragraphs(doc.Summary) {
+		w.lines(p, pdfMargin, pdfBodySize, false, pdfBodySize*0.4)
+	}
+
+	w.heading(headingTodos)
+	if len(doc.Todos) == 0 {
+		w.lines(textNone, pdfMargin, pdfBodySize, false, pdfBodySize*0.4)
+	} else {
+		w.table(todoColumns, doc.Todos)
+	}
+
+	w.heading(headingDecisions)
+	if len(doc.Decisions) == 0 {
+		w.lines(textNone, pdfMargin, pdfBodySize, false, pdfBodySize*0.4)
+	}
+	for i, decision := range doc.Decisions {
+		w.lines(fmt.Sprintf("%d. %s", i+1, decision), pdfMargin, pdfBodySize, false, pdfBodySize*0.2)
+	}
+
+	for _, list := range doc.Lists {
+		w.heading(list.Heading)
+		for _, item := range list.Items {
+			w.lines("• "+item, pdfMargin, pdfBodySize, false, pdfBodySize*0.2)
+		}
+	}
+
+	if len(doc.Transcript) > 0 {
+		w.heading(headingTranscript)
+		for _, line := range doc.Transcript {
+			text := line.Text
+			if prefix := transcriptPrefix(line); prefix != "" {
+				text = prefix + " " + text
+			}
+			w.lines(text, pdfMargin, pdfBodySize, false, pdfBodySize*0.3)
+		}
+	}
+
+	// 页脚页码
+	for i, page := range w.pages {
+		w.page = page
+		footer := fmt.Sprintf("第 %d / %d 页", i+1, len(w.pages))
+		w.text((pdfPageWidth-textWidth(footer, pdfSmallSize))/2, pdfFooterY, footer, pdfSmallSize, false)
+	}
+	return w.assemble(doc.Title)
+}
+
+// assemble 组装PDF对象、交叉引用表和文件尾
+func (w *pdfWriter) assemble(title string) ([]byte, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// 对象编号：1目录 2页面树 3字体 4后代字体 5字体描述 6文档信息，之后每页依次为页面和内容流
+	const firstPage = 7
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(w.pages))
+	for i := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+	object(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s-%s /Encoding /%s /DescendantFonts [4 0 R] >>", pdfFontName, pdfEncoding, pdfEncoding))
+	// Adobe-GB1中CID 1-95为ASCII可见字符，按半角宽度排版，其他字符为全角
+	object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>", pdfFontName))
+	object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>", pdfFontName))
+	object(fmt.Sprintf("<< /Title <%s> /Producer (meeting-mm) >>", "FEFF"+encodeUCS2(title)))
+
+	for i, page := range w.pages {
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
+
+// encodeUCS2 将文字编码为UCS-2大端序的十六进制字符串，基本多文种平面之外的字符和控制字符替换为“?”
+func encodeUCS2(text string) string {
+	var buf strings.Builder
+	for _, r := range text {
+		if r > 0xffff || utf16.IsSurrogate(r) || r < 0x20 {
+			r = '?'
+		}
+		fmt.Fprintf(&buf, "%04X", r)
+	}
+	return buf.String()
+}
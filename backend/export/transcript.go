@@ -0,0 +1,222 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"meeting-mm/models"
+)
+
+// 会议记录（字幕）的导出格式
+const (
+	TranscriptSRT  = "srt"
+	TranscriptVTT  = "vtt"
+	TranscriptText = "txt"
+	TranscriptJSON = "json"
+)
+
+// 字幕折行宽度：按显示宽度计算，西文字符占1列，中日韩文字占2列
+const (
+	DefaultLineLength = 42
+	MinLineLength     = 10
+	MaxLineLength     = 200
+)
+
+// maxCueLines 每条字幕最多的行数，更长的片段按文字长度拆成多条字幕
+const maxCueLines = 2
+
+// ErrNoSegments 会议没有带时间戳的转录片段，无法生成字幕
+var ErrNoSegments = errors.New("会议没有带时间戳的转录片段")
+
+// transcriptContentTypes 会议记录各格式的内容类型
+var transcriptContentTypes = map[string]string{
+	TranscriptSRT:  "application/x-subrip; charset=utf-8",
+	TranscriptVTT:  "text/vtt; charset=utf-8",
+	TranscriptText: "text/plain; charset=utf-8",
+	TranscriptJSON: "application/json",
+}
+
+// TranscriptFormats 返回支持的会议记录格式
+func TranscriptFormats() []string {
+	return []string{TranscriptSRT, TranscriptVTT, TranscriptText, TranscriptJSON}
+}
+
+// TranscriptContentType 返回会议记录格式的内容类型
+func TranscriptContentType(format string) string {
+	return transcriptContentTypes[format]
+}
+
+// TranscriptOptions 会议记录导出选项
+type TranscriptOptions struct {
+	// LineLength 字幕每行的最大显示宽度，为0时使用DefaultLineLength
+	LineLength int
+	// Offset 加到每个时间戳上的偏移，可以为负；偏移后结束时间不大于0的片段被丢弃
+	Offset time.Duration
+}
+
+// Cue 一条带时间的会议记录
+type Cue struct {
+	Start   float64 `json:"start"` // 以秒为单位
+	End     float64 `json:"end"`
+	Speaker string  `json:"speaker,omitempty"`
+	Text    string  `json:"text"`
+}
+
+// TranscriptDocument json格式的会议记录
+type TranscriptDocument struct {
+	Title    string `json:"title"`
+	Segments []Cue  `json:"segments"`
+}
+
+// RenderTranscript 按格式导出会议记录：srt和vtt为字幕，txt为纯文本，json为带时间的片段
+func RenderTranscript(format string, meeting *models.Meeting, opts TranscriptOptions) (*File, error) {
+	if TranscriptContentType(format) == "" {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	if opts.LineLength == 0 {
+		opts.LineLength = DefaultLineLength
+	}
+	cues := shiftCues(meeting.Segments, opts.Offset)
+
+	var data []byte
+	switch format {
+	case TranscriptSRT, TranscriptVTT:
+		if len(meeting.Segments) == 0 {
+			return nil, ErrNoSegments
+		}
+		data = renderSubtitles(format, cues, opts.LineLength)
+	case TranscriptText:
+		data = renderTranscriptText(meeting, cues)
+	case TranscriptJSON:
+		doc := TranscriptDocument{Title: meeting.Title, Segments: cues}
+		if doc.Segments == nil {
+			doc.Segments = []Cue{}
+		}
+		var err error
+		if data, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			return nil, fmt.Errorf("导出%s失败: %w", format, err)
+		}
+	}
+	return &File{Name: FileName(meeting, format), ContentType: TranscriptContentType(format), Data: data}, nil
+}
+
+// shiftCues 将转录片段加上偏移转换为字幕，开始时间不早于0
+func shiftCues(segments []models.TranscriptSegment, offset time.Duration) []Cue {
+	var cues []Cue
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		start := segment.StartTime + offset.Seconds()
+		end := math.Max(segment.EndTime, segment.StartTime) + offset.Seconds()
+		if text == "" || end <= 0 {
+			continue
+		}
+		cues = append(cues, Cue{Start: math.Max(start, 0), End: end, Speaker: segment.Speaker, Text: text})
+	}
+	return cues
+}
+
+// splitCues 将折行后超过maxCueLines行的字幕拆成多条，时间按各条的文字宽度分配
+func splitCues(cues []Cue, lineLength int) []Cue {
+	var result []Cue
+	for _, cue := range cues {
+		lines := wrapColumns(cue.Text, lineLength)
+		if len(lines) <= maxCueLines {
+			result = append(result, cue)
+			continue
+		}
+
+		total := 0.0
+		for _, line := range lines {
+			total += textWidth(line, 1)
+		}
+		start, done := cue.Start, 0.0
+		for i := 0; i < len(lines); i += maxCueLines {
+			chunk := lines[i:min(i+maxCueLines, len(lines))]
+			for _, line := range chunk {
+				done += textWidth(line, 1)
+			}
+			end := cue.Start + (cue.End-cue.Start)*done/total
+			result = append(result, Cue{Start: start, End: end, Speaker: cue.Speaker, Text: strings.Join(chunk, "\n")})
+			start = end
+		}
+	}
+	return result
+}
+
+// wrapColumns 按显示宽度折行并去掉空行（空行会结束字幕）：runeWidth中半角为0.5，字号取2时正好为1列
+func wrapColumns(text string, columns int) []string {
+	var lines []string
+	for _, line := range wrapText(text, 2, float64(columns)) {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// vttEscape WebVTT字幕文字中需要转义的字符
+var vttEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// renderSubtitles 生成SRT或WebVTT字幕。WebVTT用声音标签<v 说话人>标注说话人，
+// SRT没有说话人标签，在文字前加“说话人: ”
+func renderSubtitles(format string, cues []Cue, lineLength int) []byte {
+	var buf strings.Builder
+	if format == TranscriptVTT {
+		buf.WriteString("WEBVTT\n\n")
+	} else {
+		// 说话人计入折行宽度，拆分后只出现在第一条字幕中
+		prefixed := make([]Cue, len(cues))
+		for i, cue := range cues {
+			if cue.Speaker != "" {
+				cue.Text, cue.Speaker = cue.Speaker+": "+cue.Text, ""
+			}
+			prefixed[i] = cue
+		}
+		cues = prefixed
+	}
+	for i, cue := range splitCues(cues, lineLength) {
+		start, end := subtitleTime(cue.Start, format), subtitleTime(cue.End, format)
+		fmt.Fprintf(&buf, "%d\n%s --> %s\n", i+1, start, end)
+
+		text := strings.Join(wrapColumns(cue.Text, lineLength), "\n")
+		if format == TranscriptVTT {
+			text = vttEscape.Replace(text)
+			if cue.Speaker != "" {
+				text = "<v " + vttEscape.Replace(strings.Join(strings.Fields(cue.Speaker), " ")) + ">" + text + "</v>"
+			}
+		}
+		buf.WriteString(text + "\n\n")
+	}
+	return []byte(buf.String())
+}
+
+// subtitleTime 格式化字幕时间：SRT为00:01:02,345，WebVTT为00:01:02.345
+func subtitleTime(seconds float64, format string) string {
+	ms := int64(math.Round(seconds * 1000))
+	separator := "."
+	if format == TranscriptSRT {
+		separator = ","
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, separator, ms%1000)
+}
+
+// renderTranscriptText 生成纯文本会议记录，每个片段一行：[00:01:02] 说话人: 文字；
+// 没有分段时输出完整转录
+func renderTranscriptText(meeting *models.Meeting, cues []Cue) []byte {
+	if len(meeting.Segments) == 0 {
+		return []byte(strings.TrimSpace(meeting.Transcript) + "\n")
+	}
+	var buf strings.Builder
+	for _, cue := range cues {
+		fmt.Fprintf(&buf, "[%s] ", subtitleTime(cue.Start, TranscriptVTT)[:8])
+		if cue.Speaker != "" {
+			buf.WriteString(cue.Speaker + ": ")
+		}
+		buf.WriteString(strings.ReplaceAll(cue.Text, "\n", " ") + "\n")
+	}
+	return []byte(buf.String())
+}
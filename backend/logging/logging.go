@@ -0,0 +1,120 @@
+// Package logging 配置全局的结构化日志（log/slog）：级别和格式来自配置，
+// 日志自动带上请求ID和追踪ID，并在输出前遮盖密钥、令牌和会议内容
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"meeting-mm/tracing"
+)
+
+// Options 日志配置
+type Options struct {
+	Level          string // debug、info、warn、error
+	Format         string // text或json
+	CaptureContent bool   // 是否在日志中保留转录、提示词等会议内容，只应在排查问题时临时开启
+}
+
+// defaultLevel 全局日志记录器的级别，可以通过SetLevel在运行时修改
+var defaultLevel slog.LevelVar
+
+// New 按配置创建日志记录器
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	return newLogger(w, opts, new(slog.LevelVar))
+}
+
+func newLogger(w io.Writer, opts Options, levelVar *slog.LevelVar) (*slog.Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	levelVar.Set(level)
+
+	handlerOpts := &slog.HandlerOptions{Level: levelVar}
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, handlerOpts)
+	case "json":
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	default:
+		return nil, fmt.Errorf("未知的日志格式: %q", opts.Format)
+	}
+	return slog.New(&contextHandler{next: NewRedactHandler(handler, opts.CaptureContent)}), nil
+}
+
+// Setup 创建日志记录器并设为全局默认，标准库log的输出也经过它
+func Setup(w io.Writer, opts Options) error {
+	logger, err := newLogger(w, opts, &defaultLevel)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// SetLevel 修改全局日志记录器的级别，重新加载配置时使用
+func SetLevel(level string) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	defaultLevel.Set(l)
+	return nil
+}
+
+// ParseLevel 解析日志级别，为空时为info
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("未知的日志级别: %q", level)
+	}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID 在ctx中记录请求ID，之后使用该ctx记录的日志都会带上request_id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// contextHandler 从ctx中取出请求ID和追踪ID加入日志
+type contextHandler struct {
+	next slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if requestID, ok := ctx.Value(requestIDKey{}).(string); ok && requestID != "" {
+			r.AddAttrs(slog.String("request_id", requestID))
+		}
+		if span := tracing.FromContext(ctx); span != nil {
+			r.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Masked 替换密钥类字段值的占位文本
+const Masked = "[已遮盖]"
+
+// secretKeys 值总是被遮盖的字段名（不区分大小写），以 _token、_secret、_password、_key 结尾的字段同样遮盖
+var secretKeys = map[string]bool{
+	"api_key":       true,
+	"apikey":        true,
+	"key":           true,
+	"token":         true,
+	"password":      true,
+	"secret":        true,
+	"authorization": true,
+	"cookie":        true,
+}
+
+var secretSuffixes = []string{"_token", "_secret", "_password", "_key"}
+
+// contentKeys 保存会议内容的字段名，未开启内容采集时只记录长度
+var contentKeys = map[string]bool{
+	"transcript":    true,
+	"content":       true,
+	"body":          true,
+	"prompt":        true,
+	"request_body":  true,
+	"response_body": true,
+}
+
+// secretPatterns 出现在任意文本（包括日志消息和错误）中的凭据，第1个分组为保留的前缀
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`),
+	regexp.MustCompile(`\b(secret_|ntn_|sk-|mm_)[A-Za-z0-9_-]{8,}`),
+	regexp.MustCompile(`()\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+}
+
+// RedactString 遮盖文本中的API密钥、Notion令牌、Bearer凭据和JWT
+func RedactString(s string) string {
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}***")
+	}
+	return s
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	if secretKeys[key] {
+		return true
+	}
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHandler 在写出日志前遮盖密钥和会议内容
+type RedactHandler struct {
+	next           slog.Handler
+	captureContent bool
+}
+
+// NewRedactHandler 包装handler，captureContent为true时保留会议内容（密钥仍然遮盖）
+func NewRedactHandler(next slog.Handler, captureContent bool) *RedactHandler {
+	return &RedactHandler{next: next, captureContent: captureContent}
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, RedactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redact(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redact(a)
+	}
+	return &RedactHandler{next: h.next.WithAttrs(redacted), captureContent: h.captureContent}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{next: h.next.WithGroup(name), captureContent: h.captureContent}
+}
+
+func (h *RedactHandler) redact(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = h.redact(attr)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	}
+
+	if isSecretKey(a.Key) {
+		return slog.String(a.Key, Masked)
+	}
+	if !h.captureContent && contentKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, fmt.Sprintf("[已隐藏 %d 字]", utf8.RuneCountInString(valueText(a.Value))))
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactString(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			return slog.String(a.Key, RedactString(v.Error()))
+		case []byte:
+			return slog.String(a.Key, RedactString(string(v)))
+		case fmt.Stringer:
+			return slog.String(a.Key, RedactString(v.String()))
+		}
+	}
+	return a
+}
+
+// valueText 返回值的文本形式，用于统计被隐藏内容的长度
+func valueText(v slog.Value) string {
+	if b, ok := v.Any().([]byte); ok && v.Kind() == slog.KindAny {
+		return string(b)
+	}
+	return v.String()
+}
@@ -1,72 +1,232 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"meeting-mm/api"
 	"meeting-mm/config"
-	"meeting-mm/services"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
+	"meeting-mm/logging"
+	"meeting-mm/openapi"
+	"meeting-mm/server"
 )
 
+const usage = `用法: meeting-mm <命令> [参数]
+
+命令:
+  serve    启动API服务器（默认命令）
+  config   校验配置并输出生效的配置（密钥已遮盖）
+  routes   列出全部API路由
+  openapi  输出OpenAPI文档并生成Go客户端
+  help     显示帮助
+
+serve 参数:
+  -transport string   服务方式: fiber 或 http（默认 fiber）
+  -port string        监听端口（默认使用配置中的PORT）
+  -config string      YAML配置文件（默认使用环境变量CONFIG_FILE）
+  -set key=value      覆盖配置项，键名与配置文件相同，可以重复使用
+
+config 参数:
+  -config、-set       与serve相同
+
+openapi 参数:
+  -o string           OpenAPI文档的输出文件（默认输出到标准输出）
+  -client string      生成的Go客户端文件，例如 client/client_gen.go
+`
+
 func main() {
-	// 加载配置
-	if err := config.LoadConfig(""); err != nil {
-		log.Fatalf("加载配置失败: %v", err)
+	command := "serve"
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		command, args = args[0], args[1:]
 	}
 
-	cfg := config.GetConfig()
-
-	// 初始化服务
-	deepseekService := services.NewDeepSeekService(cfg)
-	notionService := services.NewNotionService(cfg)
-	whisperService := services.NewWhisperService(cfg)
+	switch command {
+	case "serve":
+		serve(args)
+	case "config":
+		printConfig(args)
+	case "routes":
+		printRoutes()
+	case "openapi":
+		generateOpenAPI(args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n\n%s", command, usage)
+		os.Exit(2)
+	}
+}
 
-	// 创建API处理器
-	handler := api.NewHandler(deepseekService, notionService, whisperService)
+// configFlags 加载配置相关的参数
+type configFlags struct {
+	file      string
+	overrides map[string]string
+}
 
-	// 创建Fiber应用
-	app := fiber.New(fiber.Config{
-		BodyLimit: 50 * 1024 * 1024, // 50MB
+// register 在flags上注册-config和-set参数
+func (c *configFlags) register(flags *flag.FlagSet) {
+	c.overrides = map[string]string{}
+	flags.StringVar(&c.file, "config", "", "YAML配置文件")
+	flags.Func("set", "覆盖配置项，格式为 key=value", func(value string) error {
+		key, v, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("%q的格式应为 key=value", value)
+		}
+		c.overrides[key] = v
+		return nil
 	})
+}
+
+// load 按层加载配置：默认值、配置文件、.env文件、环境变量，最后是命令行参数
+func (c *configFlags) load() (*config.Config, error) {
+	return config.Load(config.Options{File: c.file, Flags: c.overrides})
+}
+
+// serve 启动API服务器
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	transport := flags.String("transport", server.TransportFiber, "服务方式: fiber 或 http")
+	port := flags.String("port", "", "监听端口")
+	var cfgFlags configFlags
+	cfgFlags.register(flags)
+	flags.Parse(args)
+	if *port != "" {
+		cfgFlags.overrides["port"] = *port
+	}
 
-	// 添加中间件
-	app.Use(logger.New())
-	app.Use(recover.New())
-	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
-		AllowMethods: "GET, POST, PUT, DELETE",
-	}))
+	// 加载配置，配置无效时列出全部问题后退出
+	cfg, err := cfgFlags.load()
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
 
-	// 注册路由
-	api.RegisterRoutes(app, handler)
+	// 之后的日志（包括标准库log的输出）都经过结构化日志和脱敏处理
+	if err := logging.Setup(os.Stderr, logging.Options{
+		Level:          cfg.LogLevel,
+		Format:         cfg.LogFormat,
+		CaptureContent: cfg.LogCaptureContent,
+	}); err != nil {
+		log.Fatalf("配置日志失败: %v", err)
+	}
+	if cfg.LogCaptureContent {
+		slog.Warn("已开启LOG_CAPTURE_CONTENT，日志中会包含转录等会议内容")
+	}
+	if data, err := cfg.YAML(); err == nil {
+		slog.Debug("生效的配置", "config", string(data))
+	}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		fatal("创建服务器失败", err)
+	}
+
+	// 收到SIGINT或SIGTERM后优雅关闭；关闭期间再次收到信号时立即退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// 收到SIGHUP后重新加载配置，配置无效时保留当前配置
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for range hup {
+			next, err := cfgFlags.load()
+			if err != nil {
+				slog.Error("重新加载配置失败，继续使用当前配置", "error", err)
+				continue
+			}
+			srv.Reload(next)
+		}
+	}()
 
 	// 启动服务器
-	port := cfg.Port
-	log.Printf("服务器启动在 http://localhost:%s", port)
-	if err := app.Listen(fmt.Sprintf(":%s", port)); err != nil {
-		log.Fatalf("启动服务器失败: %v", err)
+	if err := srv.ListenAndServe(ctx, *transport, ":"+cfg.Port); err != nil {
+		fatal("启动服务器失败", err)
 	}
 }
 
-// 自定义错误处理程序
-func customErrorHandler(c *fiber.Ctx, err error) error {
-	// 默认状态码为500
-	code := fiber.StatusInternalServerError
+// printConfig 校验配置并以YAML格式输出，密钥已遮盖
+func printConfig(args []string) {
+	flags := flag.NewFlagSet("config", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	var cfgFlags configFlags
+	cfgFlags.register(flags)
+	flags.Parse(args)
 
-	// 检查是否为Fiber错误
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
+	cfg, err := cfgFlags.load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	data, err := cfg.YAML()
+	if err != nil {
+		log.Fatalf("输出配置失败: %v", err)
 	}
+	os.Stdout.Write(data)
+}
 
-	// 返回JSON错误响应
-	return c.Status(code).JSON(fiber.Map{
-		"error": err.Error(),
-	})
+// fatal 记录错误日志后退出
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// printRoutes 打印路由表
+func printRoutes() {
+	for _, route := range api.Routes(&api.Handler{}) {
+		fmt.Printf("%-6s /api%-28s %s\n", route.Method, route.Path, route.Summary)
+	}
+}
+
+// generateOpenAPI 输出OpenAPI文档，并按需生成Go客户端
+func generateOpenAPI(args []string) {
+	flags := flag.NewFlagSet("openapi", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	output := flags.String("o", "", "OpenAPI文档的输出文件")
+	clientFile := flags.String("client", "", "生成的Go客户端文件")
+	flags.Parse(args)
+
+	doc, err := api.OpenAPI()
+	if err != nil {
+		log.Fatalf("生成OpenAPI文档失败: %v", err)
+	}
+
+	if *clientFile != "" {
+		source, err := openapi.GenerateClient(doc, "client")
+		if err != nil {
+			log.Fatalf("生成客户端失败: %v", err)
+		}
+		if err := os.WriteFile(*clientFile, source, 0644); err != nil {
+			log.Fatalf("写入客户端失败: %v", err)
+		}
+		if *output == "" {
+			return
+		}
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("序列化OpenAPI文档失败: %v", err)
+	}
+	if *output == "" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(*output, append(data, '\n'), 0644); err != nil {
+		log.Fatalf("写入OpenAPI文档失败: %v", err)
+	}
 }
@@ -0,0 +1,54 @@
+package metrics
+
+import "time"
+
+// Default 服务导出的全部指标所在的注册表
+var Default = NewRegistry()
+
+// 会议处理流水线的阶段
+const (
+	StageNormalize  = "normalize"  // 解码并重采样音频
+	StageTranscribe = "transcribe" // Whisper转录（含音频解码和模型加载）
+	StageAnalyze    = "analyze"    // DeepSeek分析转录
+	StageReport     = "report"     // 按模板渲染Markdown报告
+	StagePolish     = "polish"     // DeepSeek润色Markdown报告（可选）
+	StageNotion     = "notion"     // 同步到Notion
+	StageAsk        = "ask"        // 检索会议记录并回答问题
+)
+
+// stageBuckets 流水线阶段耗时的桶（秒），转录长录音可能需要数十分钟
+var stageBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800}
+
+var (
+	// HTTPRequestDuration 按方法、路由和状态码统计的请求耗时
+	HTTPRequestDuration = Default.NewHistogram("mm_http_request_duration_seconds",
+		"HTTP请求耗时（秒）",
+		[]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+		"method", "route", "status")
+
+	// PipelineStageDuration 流水线各阶段耗时
+	PipelineStageDuration = Default.NewHistogram("mm_pipeline_stage_duration_seconds",
+		"会议处理流水线各阶段耗时（秒）", stageBuckets, "stage", "outcome")
+
+	// LLMTokens DeepSeek API返回的token用量，kind为prompt或completion
+	LLMTokens = Default.NewCounter("mm_llm_tokens_total",
+		"大模型API消耗的token数", "model", "kind")
+
+	// WhisperRealTimeFactor 转录耗时与音频时长之比，小于1表示快于实时
+	WhisperRealTimeFactor = Default.NewHistogram("mm_whisper_realtime_factor",
+		"Whisper转录耗时与音频时长之比",
+		[]float64{0.05, 0.1, 0.25, 0.5, 1, 2, 4, 8, 16}, "model")
+
+	// NotionRequests Notion API请求数，outcome为ok、network或Notion返回的错误码
+	NotionRequests = Default.NewCounter("mm_notion_requests_total",
+		"Notion API请求数", "outcome")
+)
+
+// ObserveStage 记录流水线阶段从start开始的耗时，err为nil时outcome为ok
+func ObserveStage(stage string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	PipelineStageDuration.Observe(time.Since(start).Seconds(), stage, outcome)
+}
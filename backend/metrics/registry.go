@@ -0,0 +1,250 @@
+// Package metrics 以Prometheus文本格式导出指标，只实现本服务用到的计数器和直方图
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry 指标注册表
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+// metric 可以写出为Prometheus文本格式的指标
+type metric interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// NewRegistry 创建空的注册表
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[m.name()] {
+		panic("指标重复注册: " + m.name())
+	}
+	r.names[m.name()] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteTo 按Prometheus文本格式（0.0.4）写出全部指标
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	counter := &countingWriter{w: w}
+	buf := bufio.NewWriter(counter)
+	for _, m := range metrics {
+		m.write(buf)
+	}
+	err := buf.Flush()
+	return counter.n, err
+}
+
+// ContentType Prometheus文本格式的Content-Type
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// series 一组标签值对应的时间序列
+type series struct {
+	labels []string
+	value  float64  // 计数器的值
+	counts []uint64 // 直方图各桶（不含+Inf）的计数
+	sum    float64  // 直方图观测值之和
+	count  uint64   // 直方图观测次数
+}
+
+// vec 按标签值保存时间序列
+type vec struct {
+	metricName string
+	help       string
+	labelNames []string
+	mu         sync.Mutex
+	series     map[string]*series
+}
+
+func newVec(name, help string, labels []string) vec {
+	return vec{metricName: name, help: help, labelNames: labels, series: map[string]*series{}}
+}
+
+func (v *vec) name() string { return v.metricName }
+
+// get 返回标签值对应的时间序列，调用方需持有锁
+func (v *vec) get(values []string, init func(*series)) *series {
+	if len(values) != len(v.labelNames) {
+		panic(fmt.Sprintf("指标%s需要%d个标签值，实际为%d个", v.metricName, len(v.labelNames), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		// 复制标签值：Fiber的c.Method()等返回的字符串引用会被复用的请求缓冲区
+		s = &series{labels: make([]string, len(values))}
+		for i, value := range values {
+			s.labels[i] = strings.Clone(value)
+		}
+		if init != nil {
+			init(s)
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+// sorted 返回按标签值排序的时间序列，保证输出稳定
+func (v *vec) sorted() []*series {
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]*series, len(keys))
+	for i, key := range keys {
+		result[i] = v.series[key]
+	}
+	return result
+}
+
+func (v *vec) header(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.metricName, escapeHelp(v.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.metricName, kind)
+}
+
+// labelString 生成 {a="1",b="2"} 形式的标签，extra为额外的标签名和值（如le）
+func (v *vec) labelString(values []string, extra ...string) string {
+	var parts []string
+	for i, name := range v.labelNames {
+		parts = append(parts, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		parts = append(parts, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Counter 只增不减的计数器
+type Counter struct {
+	vec
+}
+
+// NewCounter 注册计数器，labels为标签名
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{vec: newVec(name, help, labels)}
+	r.register(c)
+	return c
+}
+
+// Inc 计数加一
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 计数增加v，v不能为负数
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("计数器不能减少: " + c.metricName)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.get(labelValues, nil).value += v
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.header(w, "counter")
+	for _, s := range c.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, c.labelString(s.labels), formatFloat(s.value))
+	}
+}
+
+// Histogram 直方图
+type Histogram struct {
+	vec
+	buckets []float64
+}
+
+// NewHistogram 注册直方图，buckets为递增的桶上界（不含+Inf）
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic("直方图的桶必须递增: " + name)
+	}
+	h := &Histogram{vec: newVec(name, help, labels), buckets: buckets}
+	r.register(h)
+	return h
+}
+
+// Observe 记录一次观测值
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.get(labelValues, func(s *series) { s.counts = make([]uint64, len(h.buckets)) })
+	for i, upper := range h.buckets {
+		if v <= upper {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w, "histogram")
+	for _, s := range h.sorted() {
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, h.labelString(s.labels, "le", formatFloat(upper)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, h.labelString(s.labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, h.labelString(s.labels), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, h.labelString(s.labels), s.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+// countingWriter 统计写出的字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
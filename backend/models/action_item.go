@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// 待办台账条目的状态
+const (
+	ActionItemOpen      = "open"
+	ActionItemCompleted = "completed"
+)
+
+// 会议中的待办事项与台账条目的关系，也是条目历史记录的类型
+const (
+	ContinuityNew         = "new"          // 第一次出现，新建条目
+	ContinuityCarriedOver = "carried_over" // 之前会议中的条目，本次会议仍未完成
+	ContinuityUpdated     = "updated"      // 之前会议中的条目，本次会议修改了内容、负责人或截止日期
+	ContinuityCompleted   = "completed"    // 之前会议中的条目，本次会议确认已完成
+)
+
+// ActionItem 待办台账中的条目：同一系列会议中反复提到的待办事项合并为一个条目，记录每次会议中的变化
+type ActionItem struct {
+	ID             string            `json:"id"`
+	WorkspaceID    string            `json:"workspaceId"`
+	Series         string            `json:"series"`
+	Description    string            `json:"description"` // 最近一次会议中的描述
+	Assignee       string            `json:"assignee,omitempty"`
+	DueDate        time.Time         `json:"dueDate"` // 零值表示没有截止日期
+	Status         string            `json:"status"`  // "open", "completed"
+	FirstMeetingID string            `json:"firstMeetingId"`
+	LastMeetingID  string            `json:"lastMeetingId"`
+	History        []ActionItemEvent `json:"history"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+}
+
+// ActionItemEvent 条目在一次会议中的变化
+type ActionItemEvent struct {
+	MeetingID    string    `json:"meetingId"`
+	MeetingTitle string    `json:"meetingTitle"`
+	Date         time.Time `json:"date"`        // 会议日期
+	Kind         string    `json:"kind"`        // new、carried_over、updated、completed
+	Description  string    `json:"description"` // 该次会议中的描述
+}
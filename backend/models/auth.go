@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+)
+
+// 用户角色
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+)
+
+// User 表示一个可以登录网页端的用户。
+// User、Workspace和APIKey中包含凭据或其哈希，接口返回时需使用不含这些字段的视图
+type User struct {
+	ID           string    `json:"id"`
+	WorkspaceID  string    `json:"workspaceId"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	Role         string    `json:"role"` // "owner", "member"
+	PasswordHash string    `json:"passwordHash"`
+	TokenVersion int       `json:"tokenVersion,omitempty"` // 退出登录时递增，之前签发的登录令牌随之失效
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Workspace 表示一个团队工作区，会议、同步任务、集成凭据和处理设置都归属于工作区
+type Workspace struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Settings WorkspaceSettings `json:"settings"`
+	// ServerCredentials 未设置工作区凭据时是否使用服务器配置的Notion和DeepSeek凭据。
+	// 只有第一个工作区（部署者自己的工作区）默认使用，其他工作区必须配置自己的凭据
+	ServerCredentials bool `json:"serverCredentials,omitempty"`
+	// TodoFeedTokens 个人待办日历订阅链接的令牌，按负责人名称（小写）索引，签名中包含令牌，更换后旧链接失效；
+	// 值为空表示该负责人的订阅链接已停用
+	TodoFeedTokens map[string]string `json:"todoFeedTokens,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+}
+
+// WorkspaceSettings 工作区的集成凭据和处理设置，空值表示使用服务器的默认配置
+// （Notion和DeepSeek凭据只有ServerCredentials的工作区才沿用服务器配置）。
+// 其中的API密钥以加密形式保存
+type WorkspaceSettings struct {
+	NotionAPIKey     string `json:"notionApiKey,omitempty"`
+	NotionDatabaseID string `json:"notionDatabaseId,omitempty"`
+
+	DeepSeekAPIKey  string `json:"deepseekApiKey,omitempty"`
+	DeepSeekBaseURL string `json:"deepseekBaseUrl,omitempty"`
+	DeepSeekModel   string `json:"deepseekModel,omitempty"`
+
+	// 分析提示词
+	SystemPrompt         string `json:"systemPrompt,omitempty"`
+	AnalysisInstructions string `json:"analysisInstructions,omitempty"`
+
+	// Whisper设置
+	WhisperModel    string `json:"whisperModel,omitempty"`
+	WhisperLanguage string `json:"whisperLanguage,omitempty"`
+}
+
+// APIKey 表示供脚本和集成使用的API密钥，只保存密钥的哈希
+type APIKey struct {
+	ID          string     `json:"id"`
+	WorkspaceID string     `json:"workspaceId"`
+	UserID      string     `json:"userId"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"` // 密钥开头几位，便于识别
+	Hash        string     `json:"hash"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+}
@@ -6,17 +6,36 @@ import (
 
 // Meeting 表示一个会议记录
 type Meeting struct {
-	ID           string     `json:"id"`
-	Title        string     `json:"title"`
-	Date         time.Time  `json:"date"`
-	Participants []string   `json:"participants"`
-	Transcript   string     `json:"transcript"`
-	Summary      string     `json:"summary"`
-	TodoItems    []TodoItem `json:"todoItems"`
-	Decisions    []Decision `json:"decisions"`
-	CreatedAt    time.Time  `json:"createdAt"`
-	UpdatedAt    time.Time  `json:"updatedAt"`
-	NotionPageID string     `json:"notionPageId,omitempty"`
+	ID              string              `json:"id"`
+	WorkspaceID     string              `json:"workspaceId,omitempty"` // 所属工作区
+	CreatedBy       string              `json:"createdBy,omitempty"`   // 创建者的用户ID
+	Title           string              `json:"title"`
+	Date            time.Time           `json:"date"`
+	Participants    []string            `json:"participants"`
+	Agenda          string              `json:"agenda,omitempty"`           // 会议邀请中的议程，分析时作为上下文
+	AgendaItems     []AgendaItem        `json:"agendaItems,omitempty"`      // 结构化的议程，待办事项和决策按议程项归类
+	CalendarUID     string              `json:"calendarEventUid,omitempty"` // 导入的会议邀请的UID
+	Series          string              `json:"series,omitempty"`           // 会议系列，同一系列的会议之间跟踪待办事项
+	Transcript      string              `json:"transcript"`
+	Segments        []TranscriptSegment `json:"segments,omitempty"`
+	Summary         string              `json:"summary"`
+	TodoItems       []TodoItem          `json:"todoItems"`
+	Decisions       []Decision          `json:"decisions"`
+	Profile         string              `json:"profile,omitempty"`         // 分析时使用的会议类型
+	ExtraFields     []ExtraField        `json:"extraFields,omitempty"`     // 按会议类型额外提取的内容
+	OpenQuestions   []string            `json:"openQuestions,omitempty"`   // 提出但没有结论的问题
+	Risks           []Risk              `json:"risks,omitempty"`           // 风险和阻塞项
+	Topics          []Topic             `json:"topics,omitempty"`          // 按讨论顺序划分的主题
+	Keywords        []string            `json:"keywords,omitempty"`        // 关键词，同步到Notion时作为标签
+	SpeakerInsights []SpeakerInsight    `json:"speakerInsights,omitempty"` // 每位发言人的态度和参与度
+	CreatedAt       time.Time           `json:"createdAt"`
+	UpdatedAt       time.Time           `json:"updatedAt"`
+	NotionPageID    string              `json:"notionPageId,omitempty"`
+	AudioFile       string              `json:"audioFile,omitempty"`  // 保留的原始录音文件名
+	SyncStatus      string              `json:"syncStatus,omitempty"` // "pending", "synced", "failed"
+	SyncError       string              `json:"syncError,omitempty"`
+	// UnresolvedPeople 同步到Notion时未能匹配到Notion用户的人名
+	UnresolvedPeople []string `json:"unresolvedPeople,omitempty"`
 }
 
 // TodoItem 表示从会议中提取的待办事项
@@ -25,7 +44,11 @@ type TodoItem struct {
 	Description string    `json:"description"`
 	Assignee    string    `json:"assignee"`
 	DueDate     time.Time `json:"dueDate,omitempty"`
-	Status      string    `json:"status"` // "pending", "completed", "in_progress"
+	Status      string    `json:"status"`                 // "pending", "completed", "in_progress"
+	AgendaID    string    `json:"agendaItemId,omitempty"` // 所属的议程项，与议程无关时为空
+	// ActionItemID 待办台账中对应的条目，同一系列会议中反复出现的待办事项对应同一条目
+	ActionItemID string `json:"actionItemId,omitempty"`
+	Continuity   string `json:"continuity,omitempty"` // 与之前会议中条目的关系：new、carried_over、updated、completed
 }
 
 // Decision 表示从会议中提取的决策点
@@ -33,6 +56,142 @@ type Decision struct {
 	ID          string `json:"id"`
 	Description string `json:"description"`
 	MadeBy      string `json:"madeBy,omitempty"`
+	AgendaID    string `json:"agendaItemId,omitempty"` // 所属的议程项，与议程无关时为空
+}
+
+// 议程项的讨论状态
+const (
+	AgendaCovered  = "covered"  // 会议中已讨论
+	AgendaDeferred = "deferred" // 未讨论或推迟到以后
+)
+
+// AgendaItem 表示会议议程中的一项及其讨论结果
+type AgendaItem struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status,omitempty"`  // "covered", "deferred"，分析之前为空
+	Summary     string `json:"summary,omitempty"` // 该议程项的讨论摘要
+}
+
+// hasAgendaItem 判断id是否是会议中的议程项
+func (m *Meeting) hasAgendaItem(id string) bool {
+	for _, item := range m.AgendaItems {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// AgendaTodos 返回属于议程项id的待办事项；id为空时返回不属于任何议程项的待办事项
+func (m *Meeting) AgendaTodos(id string) []TodoItem {
+	var todos []TodoItem
+	for _, todo := range m.TodoItems {
+		if todo.AgendaID == id || id == "" && !m.hasAgendaItem(todo.AgendaID) {
+			todos = append(todos, todo)
+		}
+	}
+	return todos
+}
+
+// AgendaDecisions 返回属于议程项id的决策；id为空时返回不属于任何议程项的决策
+func (m *Meeting) AgendaDecisions(id string) []Decision {
+	var decisions []Decision
+	for _, decision := range m.Decisions {
+		if decision.AgendaID == id || id == "" && !m.hasAgendaItem(decision.AgendaID) {
+			decisions = append(decisions, decision)
+		}
+	}
+	return decisions
+}
+
+// 风险的类型
+const (
+	RiskKindRisk    = "risk"    // 可能发生的问题
+	RiskKindBlocker = "blocker" // 已经阻碍进度的问题
+)
+
+// riskKindLabels 风险类型的显示文字
+var riskKindLabels = map[string]string{
+	RiskKindRisk:    "风险",
+	RiskKindBlocker: "阻塞",
+}
+
+// RiskKindLabel 返回风险类型的显示文字，未知的类型原样返回
+func RiskKindLabel(kind string) string {
+	return displayLabel(riskKindLabels, kind)
+}
+
+// Risk 表示会议中提到的风险或阻塞项
+type Risk struct {
+	Description string `json:"description"`
+	Kind        string `json:"kind"`            // "risk", "blocker"
+	Owner       string `json:"owner,omitempty"` // 负责跟进的人
+}
+
+// Topic 表示会议中讨论的一个主题
+type Topic struct {
+	Title     string  `json:"title"`
+	Summary   string  `json:"summary,omitempty"`
+	StartTime float64 `json:"startTime"` // 以秒为单位；会议记录没有时间戳时与EndTime均为0
+	EndTime   float64 `json:"endTime"`   // 以秒为单位
+}
+
+// HasTimeRange 判断主题是否有时间范围
+func (t Topic) HasTimeRange() bool {
+	return t.EndTime > t.StartTime
+}
+
+// 发言人的态度和参与度
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+
+	EngagementHigh   = "high"
+	EngagementMedium = "medium"
+	EngagementLow    = "low"
+)
+
+// sentimentLabels 发言人态度的显示文字
+var sentimentLabels = map[string]string{
+	SentimentPositive: "积极",
+	SentimentNeutral:  "中立",
+	SentimentNegative: "消极",
+}
+
+// engagementLabels 发言人参与度的显示文字
+var engagementLabels = map[string]string{
+	EngagementHigh:   "高",
+	EngagementMedium: "中",
+	EngagementLow:    "低",
+}
+
+// SentimentLabel 返回发言人态度的显示文字，未知的值原样返回
+func SentimentLabel(sentiment string) string {
+	return displayLabel(sentimentLabels, sentiment)
+}
+
+// EngagementLabel 返回发言人参与度的显示文字，未知的值原样返回
+func EngagementLabel(engagement string) string {
+	return displayLabel(engagementLabels, engagement)
+}
+
+// displayLabel 返回值的显示文字，没有对应文字时返回原值
+func displayLabel(labels map[string]string, value string) string {
+	if text, ok := labels[value]; ok {
+		return text
+	}
+	return value
+}
+
+// SpeakerInsight 表示一位发言人在会议中的态度和参与度
+type SpeakerInsight struct {
+	Speaker    string `json:"speaker"`
+	Sentiment  string `json:"sentiment"`      // "positive", "neutral", "negative"
+	Engagement string `json:"engagement"`     // "high", "medium", "low"
+	Note       string `json:"note,omitempty"` // 简要说明，如主要关注点
 }
 
 // TranscriptSegment 表示语音转文字的一个片段
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Notion同步状态
+const (
+	SyncStatusPending   = "pending"
+	SyncStatusSynced    = "synced"
+	SyncStatusFailed    = "failed"
+	SyncStatusCancelled = "cancelled"
+)
+
+// NotionSync 表示发件箱中的一条Notion同步任务
+type NotionSync struct {
+	ID            string    `json:"id"`
+	WorkspaceID   string    `json:"workspaceId,omitempty"`
+	MeetingID     string    `json:"meetingId"`
+	Status        string    `json:"status"` // "pending", "synced", "failed", "cancelled"
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"lastError,omitempty"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	NotionPageID  string    `json:"notionPageId,omitempty"`
+	TraceParent   string    `json:"traceParent,omitempty"` // 创建任务的请求的W3C traceparent，同步时的span挂在该请求的追踪下
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// Profile 表示一种会议类型（站会、销售拜访、设计评审等）的分析配置，归属于工作区，按名称选择
+type Profile struct {
+	ID          string `json:"id"`
+	WorkspaceID string `json:"workspaceId"`
+	Name        string `json:"name"` // 工作区内唯一，上传和分析时用profile参数引用
+	Description string `json:"description,omitempty"`
+	// SystemPrompt 分析时的系统提示词，为空时使用工作区或服务器的设置
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+	// Instructions 追加在分析提示词末尾的额外要求，在工作区的额外要求之后
+	Instructions string `json:"instructions,omitempty"`
+	// Fields 在摘要、待办和决策之外额外提取的内容
+	Fields []ProfileField `json:"fields,omitempty"`
+	// Language 摘要、待办等内容的输出语言，如“English”；为空时使用中文
+	Language string `json:"language,omitempty"`
+	// ReportTemplate 默认的报告模板，请求中指定的模板优先
+	ReportTemplate string    `json:"reportTemplate,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// ProfileField 会议类型额外提取的一项内容，提取结果为字符串列表
+type ProfileField struct {
+	Key         string `json:"key"`                   // 模型输出的JSON键名，如 risks
+	Label       string `json:"label"`                 // 报告中的标题，如“风险”
+	Description string `json:"description,omitempty"` // 提示模型提取什么
+}
+
+// ExtraField 按会议类型额外提取的内容
+type ExtraField struct {
+	Key   string   `json:"key"`
+	Label string   `json:"label"`
+	Items []string `json:"items"`
+}
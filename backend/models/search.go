@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// SearchIndex 一个会议的会议记录片段向量，按会议ID保存，会议问答检索时使用。
+// 片段由会议记录确定地切分而来，不单独保存；Hash不一致说明会议记录已变化，需要重新计算
+type SearchIndex struct {
+	MeetingID   string      `json:"meetingId"`
+	WorkspaceID string      `json:"workspaceId"`
+	Model       string      `json:"model"`   // 计算向量使用的模型，更换模型后重新计算
+	Hash        string      `json:"hash"`    // 全部片段文本的摘要
+	Vectors     [][]float32 `json:"vectors"` // 与片段一一对应
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
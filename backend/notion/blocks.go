@@ -0,0 +1,151 @@
+package notion
+
+// maxTextLength Notion单个富文本片段允许的最大字符数
+const maxTextLength = 2000
+
+// Text 创建纯文本富文本，超过长度限制的内容会被拆分为多个片段
+func Text(content string) []RichText {
+	runes := []rune(content)
+	if len(runes) == 0 {
+		return []RichText{plainText("")}
+	}
+
+	var parts []RichText
+	for start := 0; start < len(runes); start += maxTextLength {
+		end := start + maxTextLength
+		if end > len(runes) {
+			end = len(runes)
+		}
+		parts = append(parts, plainText(string(runes[start:end])))
+	}
+	return parts
+}
+
+// StyledText 创建带样式的单个富文本片段
+func StyledText(content string, annotations *Annotations) RichText {
+	rt := plainText(content)
+	rt.Annotations = annotations
+	return rt
+}
+
+// UserMention 创建@用户提及，Notion会通知被提及的用户
+func UserMention(userID string) RichText {
+	return RichText{
+		Type:    "mention",
+		Mention: &Mention{Type: "user", User: &User{Object: "user", ID: userID}},
+	}
+}
+
+func plainText(content string) RichText {
+	return RichText{
+		Type: "text",
+		Text: &TextContent{Content: content},
+	}
+}
+
+// Heading2 创建二级标题块
+func Heading2(text string) Block {
+	return Block{Object: "block", Type: "heading_2", Heading2: &TextBlock{RichText: Text(text)}}
+}
+
+// Heading3 创建三级标题块
+func Heading3(text string) Block {
+	return Block{Object: "block", Type: "heading_3", Heading3: &TextBlock{RichText: Text(text)}}
+}
+
+// Paragraph 创建段落块
+func Paragraph(richText ...RichText) Block {
+	return Block{Object: "block", Type: "paragraph", Paragraph: &TextBlock{RichText: richText}}
+}
+
+// Paragraphs 将长文本拆分为多个段落块，避免单个块超出富文本片段数量限制
+func Paragraphs(text string) []Block {
+	var blocks []Block
+	for _, rt := range Text(text) {
+		blocks = append(blocks, Paragraph(rt))
+	}
+	return blocks
+}
+
+// BulletedListItem 创建无序列表项
+func BulletedListItem(richText ...RichText) Block {
+	return Block{Object: "block", Type: "bulleted_list_item", BulletedListItem: &TextBlock{RichText: richText}}
+}
+
+// NumberedListItem 创建有序列表项
+func NumberedListItem(richText ...RichText) Block {
+	return Block{Object: "block", Type: "numbered_list_item", NumberedListItem: &TextBlock{RichText: richText}}
+}
+
+// ToDo 创建待办块
+func ToDo(checked bool, richText ...RichText) Block {
+	return Block{Object: "block", Type: "to_do", ToDo: &ToDoBlock{RichText: richText, Checked: checked}}
+}
+
+// Toggle 创建折叠块，children为折叠内容
+func Toggle(text string, children ...Block) Block {
+	return Block{Object: "block", Type: "toggle", Toggle: &TextBlock{RichText: Text(text), Children: children}}
+}
+
+// CalloutBlock 创建标注块，emoji为空时不设置图标
+func CalloutBlock(emoji, color string, richText []RichText, children ...Block) Block {
+	callout := &Callout{RichText: richText, Color: color, Children: children}
+	if emoji != "" {
+		callout.Icon = &Icon{Type: "emoji", Emoji: emoji}
+	}
+	return Block{Object: "block", Type: "callout", Callout: callout}
+}
+
+// AudioUpload 创建引用已上传文件的音频块
+func AudioUpload(fileUploadID string, caption string) Block {
+	audio := &FileBlock{Type: "file_upload", FileUpload: &FileUploadRef{ID: fileUploadID}}
+	if caption != "" {
+		audio.Caption = Text(caption)
+	}
+	return Block{Object: "block", Type: "audio", Audio: audio}
+}
+
+// LinkText 创建带链接的富文本
+func LinkText(content, url string) RichText {
+	rt := plainText(content)
+	rt.Text.Link = &Link{URL: url}
+	return rt
+}
+
+// Divider 创建分割线
+func Divider() Block {
+	return Block{Object: "block", Type: "divider", Divider: &struct{}{}}
+}
+
+// TitleProperty 创建标题属性值
+func TitleProperty(text string) PropertyValue {
+	return PropertyValue{Title: Text(text)}
+}
+
+// RichTextProperty 创建文本属性值
+func RichTextProperty(text string) PropertyValue {
+	return PropertyValue{RichText: Text(text)}
+}
+
+// DateProperty 创建日期属性值，日期格式为YYYY-MM-DD或ISO 8601
+func DateProperty(start string) PropertyValue {
+	return PropertyValue{Date: &DateValue{Start: start}}
+}
+
+// MultiSelectProperty 创建多选属性值
+func MultiSelectProperty(names ...string) PropertyValue {
+	options := make([]SelectOption, 0, len(names))
+	for _, name := range names {
+		options = append(options, SelectOption{Name: name})
+	}
+	return PropertyValue{MultiSelect: options}
+}
+
+// PeopleProperty 创建人员属性值
+func PeopleProperty(userIDs ...string) PropertyValue {
+	people := make([]User, 0, len(userIDs))
+	for _, id := range userIDs {
+		people = append(people, User{Object: "user", ID: id})
+	}
+	return PropertyValue{People: people}
+}
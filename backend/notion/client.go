@@ -0,0 +1,351 @@
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"meeting-mm/metrics"
+)
+
+const (
+	// DefaultBaseURL Notion官方API地址
+	DefaultBaseURL = "https://api.notion.com/v1"
+	// APIVersion 请求头中使用的Notion-Version
+	APIVersion = "2022-06-28"
+	// maxChildrenPerRequest Notion单次请求最多允许附加的子块数量
+	maxChildrenPerRequest = 100
+)
+
+// Client Notion API客户端
+type Client struct {
+	apiKey  string
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient 创建Notion客户端，baseURL为空时使用官方地址，可指向本地模拟服务
+func NewClient(apiKey, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// BaseURL 返回客户端使用的API地址
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// HasToken 返回是否配置了集成令牌
+func (c *Client) HasToken() bool {
+	return c.apiKey != ""
+}
+
+// APIError 表示Notion返回的错误响应
+type APIError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Notion API请求失败，状态码: %d，错误码: %s，信息: %s", e.Status, e.Code, e.Message)
+}
+
+// CreatePage 在数据库或页面下创建新页面，超过单次上限的子块（包括嵌套子块）会分批追加
+func (c *Client) CreatePage(req *CreatePageRequest) (*Page, error) {
+	children := req.Children
+	first := *req
+	if len(children) > maxChildrenPerRequest {
+		first.Children = children[:maxChildrenPerRequest]
+	}
+	var overflow map[int][]Block
+	first.Children, overflow = splitNested(first.Children)
+
+	var page Page
+	if err := c.do(http.MethodPost, "/pages", &first, &page); err != nil {
+		return nil, err
+	}
+
+	// 创建页面的响应不包含子块ID，需要查询后再追加被截断的嵌套子块
+	if len(overflow) > 0 {
+		created, err := c.RetrieveBlockChildren(page.ID)
+		if err != nil {
+			return &page, fmt.Errorf("追加页面内容失败: %w", err)
+		}
+		if err := c.appendOverflow(created, overflow); err != nil {
+			return &page, fmt.Errorf("追加页面内容失败: %w", err)
+		}
+	}
+
+	if len(children) > maxChildrenPerRequest {
+		if _, err := c.AppendBlockChildren(page.ID, children[maxChildrenPerRequest:]); err != nil {
+			return &page, fmt.Errorf("追加页面内容失败: %w", err)
+		}
+	}
+	return &page, nil
+}
+
+// RetrievePage 获取页面
+func (c *Client) RetrievePage(pageID string) (*Page, error) {
+	var page Page
+	if err := c.do(http.MethodGet, "/pages/"+pageID, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// UpdatePageProperties 更新页面属性
+func (c *Client) UpdatePageProperties(pageID string, properties map[string]PropertyValue) (*Page, error) {
+	body := map[string]interface{}{
+		"properties": properties,
+	}
+	var page Page
+	if err := c.do(http.MethodPatch, "/pages/"+pageID, body, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AppendBlockChildren 向块（或页面）追加子块，自动按100个一批发送
+func (c *Client) AppendBlockChildren(blockID string, children []Block) ([]Block, error) {
+	var appended []Block
+	for start := 0; start < len(children); start += maxChildrenPerRequest {
+		end := start + maxChildrenPerRequest
+		if end > len(children) {
+			end = len(children)
+		}
+
+		batch, overflow := splitNested(children[start:end])
+		body := map[string]interface{}{
+			"children": batch,
+		}
+		var resp BlockList
+		if err := c.do(http.MethodPatch, "/blocks/"+blockID+"/children", body, &resp); err != nil {
+			return appended, err
+		}
+		appended = append(appended, resp.Results...)
+
+		if err := c.appendOverflow(resp.Results, overflow); err != nil {
+			return appended, err
+		}
+	}
+	return appended, nil
+}
+
+// appendOverflow 将被截断的嵌套子块追加到已创建的对应块下
+func (c *Client) appendOverflow(created []Block, overflow map[int][]Block) error {
+	for i, extra := range overflow {
+		if i >= len(created) {
+			return fmt.Errorf("无法定位第%d个子块", i+1)
+		}
+		if _, err := c.AppendBlockChildren(created[i].ID, extra); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitNested 截断超过单次上限的嵌套子块，返回截断后的块和按索引记录的剩余子块
+func splitNested(blocks []Block) ([]Block, map[int][]Block) {
+	var overflow map[int][]Block
+	result := blocks
+	for i, block := range blocks {
+		nested := block.nestedChildren()
+		if len(nested) <= maxChildrenPerRequest {
+			continue
+		}
+		if overflow == nil {
+			overflow = map[int][]Block{}
+			result = append([]Block(nil), blocks...)
+		}
+		result[i] = block.withChildren(nested[:maxChildrenPerRequest])
+		overflow[i] = nested[maxChildrenPerRequest:]
+	}
+	return result, overflow
+}
+
+// DeleteBlock 删除块（移入回收站）
+func (c *Client) DeleteBlock(blockID string) error {
+	return c.do(http.MethodDelete, "/blocks/"+blockID, nil, nil)
+}
+
+// RetrieveBlockChildren 获取块的全部子块
+func (c *Client) RetrieveBlockChildren(blockID string) ([]Block, error) {
+	var blocks []Block
+	cursor := ""
+	for {
+		path := "/blocks/" + blockID + "/children?page_size=100"
+		if cursor != "" {
+			path += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+
+		var resp BlockList
+		if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, resp.Results...)
+
+		if !resp.HasMore || resp.NextCursor == "" {
+			return blocks, nil
+		}
+		cursor = resp.NextCursor
+	}
+}
+
+// RetrieveDatabase 获取数据库结构
+func (c *Client) RetrieveDatabase(databaseID string) (*Database, error) {
+	var db Database
+	if err := c.do(http.MethodGet, "/databases/"+databaseID, nil, &db); err != nil {
+		return nil, err
+	}
+	return &db, nil
+}
+
+// QueryDatabase 查询数据库中的页面
+func (c *Client) QueryDatabase(databaseID string, req *QueryDatabaseRequest) (*PageList, error) {
+	if req == nil {
+		req = &QueryDatabaseRequest{}
+	}
+	var resp PageList
+	if err := c.do(http.MethodPost, "/databases/"+databaseID+"/query", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListUsers 获取工作区中的全部用户
+func (c *Client) ListUsers() ([]User, error) {
+	var users []User
+	cursor := ""
+	for {
+		path := "/users?page_size=100"
+		if cursor != "" {
+			path += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+
+		var resp UserList
+		if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+			return nil, err
+		}
+		users = append(users, resp.Results...)
+
+		if !resp.HasMore || resp.NextCursor == "" {
+			return users, nil
+		}
+		cursor = resp.NextCursor
+	}
+}
+
+// UploadFile 通过单次上传模式上传文件，返回可在块中引用的文件上传对象
+func (c *Client) UploadFile(filename, contentType string, data []byte) (*FileUpload, error) {
+	var upload FileUpload
+	createBody := map[string]string{
+		"mode":         "single_part",
+		"filename":     filename,
+		"content_type": contentType,
+	}
+	if err := c.do(http.MethodPost, "/file_uploads", createBody, &upload); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, fmt.Errorf("创建上传表单失败: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, fmt.Errorf("写入上传内容失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("创建上传表单失败: %w", err)
+	}
+
+	var sent FileUpload
+	if err := c.send(http.MethodPost, "/file_uploads/"+upload.ID+"/send", &buf, writer.FormDataContentType(), &sent); err != nil {
+		return nil, err
+	}
+	return &sent, nil
+}
+
+// do 发送JSON请求并解析响应
+func (c *Client) do(method, path string, body interface{}, out interface{}) error {
+	if body == nil {
+		return c.send(method, path, nil, "", out)
+	}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %w", err)
+	}
+	return c.send(method, path, bytes.NewReader(jsonBody), "application/json", out)
+}
+
+// send 发送请求并解析响应
+func (c *Client) send(method, path string, body io.Reader, contentType string, out interface{}) error {
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Notion-Version", APIVersion)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		metrics.NotionRequests.Inc("network")
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		metrics.NotionRequests.Inc("network")
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{Status: resp.StatusCode}
+		if json.Unmarshal(respBody, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = string(respBody)
+		}
+		apiErr.Status = resp.StatusCode
+		outcome := apiErr.Code
+		if outcome == "" {
+			outcome = fmt.Sprintf("http_%d", resp.StatusCode)
+		}
+		metrics.NotionRequests.Inc(outcome)
+		return apiErr
+	}
+
+	metrics.NotionRequests.Inc("ok")
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
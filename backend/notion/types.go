@@ -0,0 +1,307 @@
+package notion
+
+// RichText 表示Notion富文本片段
+type RichText struct {
+	Type        string       `json:"type"`
+	Text        *TextContent `json:"text,omitempty"`
+	Mention     *Mention     `json:"mention,omitempty"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+	PlainText   string       `json:"plain_text,omitempty"`
+}
+
+// TextContent 富文本中的文本内容
+type TextContent struct {
+	Content string `json:"content"`
+	Link    *Link  `json:"link,omitempty"`
+}
+
+// Mention 富文本中的提及
+type Mention struct {
+	Type string `json:"type"`
+	User *User  `json:"user,omitempty"`
+}
+
+// User Notion工作区用户
+type User struct {
+	Object    string  `json:"object"`
+	ID        string  `json:"id"`
+	Type      string  `json:"type,omitempty"` // "person" 或 "bot"
+	Name      string  `json:"name,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Person    *Person `json:"person,omitempty"`
+}
+
+// Person 真人用户的附加信息
+type Person struct {
+	Email string `json:"email,omitempty"`
+}
+
+// UserList 分页的用户列表
+type UserList struct {
+	Results    []User `json:"results"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Link 文本链接
+type Link struct {
+	URL string `json:"url"`
+}
+
+// Annotations 富文本样式
+type Annotations struct {
+	Bold          bool   `json:"bold,omitempty"`
+	Italic        bool   `json:"italic,omitempty"`
+	Strikethrough bool   `json:"strikethrough,omitempty"`
+	Underline     bool   `json:"underline,omitempty"`
+	Code          bool   `json:"code,omitempty"`
+	Color         string `json:"color,omitempty"`
+}
+
+// Parent 页面或数据库的父级
+type Parent struct {
+	Type       string `json:"type,omitempty"`
+	DatabaseID string `json:"database_id,omitempty"`
+	PageID     string `json:"page_id,omitempty"`
+}
+
+// DateValue 日期属性值
+type DateValue struct {
+	Start string  `json:"start"`
+	End   *string `json:"end"`
+}
+
+// SelectOption 单选/多选选项
+type SelectOption struct {
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// PropertyValue 页面属性值，每次只设置与属性类型对应的一个字段
+type PropertyValue struct {
+	ID          string         `json:"id,omitempty"`
+	Type        string         `json:"type,omitempty"`
+	Title       []RichText     `json:"title,omitempty"`
+	RichText    []RichText     `json:"rich_text,omitempty"`
+	Date        *DateValue     `json:"date,omitempty"`
+	Select      *SelectOption  `json:"select,omitempty"`
+	MultiSelect []SelectOption `json:"multi_select,omitempty"`
+	People      []User         `json:"people,omitempty"`
+	URL         *string        `json:"url,omitempty"`
+	Checkbox    *bool          `json:"checkbox,omitempty"`
+}
+
+// Page Notion页面
+type Page struct {
+	Object     string                   `json:"object"`
+	ID         string                   `json:"id"`
+	URL        string                   `json:"url,omitempty"`
+	Parent     Parent                   `json:"parent"`
+	Archived   bool                     `json:"archived,omitempty"`
+	Properties map[string]PropertyValue `json:"properties,omitempty"`
+}
+
+// PageList 分页的页面列表
+type PageList struct {
+	Results    []Page `json:"results"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// CreatePageRequest 创建页面请求
+type CreatePageRequest struct {
+	Parent     Parent                   `json:"parent"`
+	Properties map[string]PropertyValue `json:"properties"`
+	Children   []Block                  `json:"children,omitempty"`
+}
+
+// QueryDatabaseRequest 查询数据库请求
+type QueryDatabaseRequest struct {
+	Filter      interface{} `json:"filter,omitempty"`
+	Sorts       interface{} `json:"sorts,omitempty"`
+	StartCursor string      `json:"start_cursor,omitempty"`
+	PageSize    int         `json:"page_size,omitempty"`
+}
+
+// DatabaseProperty 数据库属性定义
+type DatabaseProperty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Database Notion数据库
+type Database struct {
+	Object     string                      `json:"object"`
+	ID         string                      `json:"id"`
+	Title      []RichText                  `json:"title,omitempty"`
+	Properties map[string]DatabaseProperty `json:"properties"`
+}
+
+// PropertyType 返回指定属性的类型，属性不存在时返回空字符串
+func (d *Database) PropertyType(name string) string {
+	if prop, ok := d.Properties[name]; ok {
+		return prop.Type
+	}
+	return ""
+}
+
+// TitleProperty 返回数据库中标题属性的名称
+func (d *Database) TitleProperty() string {
+	for name, prop := range d.Properties {
+		if prop.Type == "title" {
+			return name
+		}
+	}
+	return ""
+}
+
+// Block Notion内容块，每次只设置与Type对应的一个字段
+type Block struct {
+	Object           string     `json:"object,omitempty"`
+	ID               string     `json:"id,omitempty"`
+	Type             string     `json:"type"`
+	HasChildren      bool       `json:"has_children,omitempty"`
+	Heading1         *TextBlock `json:"heading_1,omitempty"`
+	Heading2         *TextBlock `json:"heading_2,omitempty"`
+	Heading3         *TextBlock `json:"heading_3,omitempty"`
+	Paragraph        *TextBlock `json:"paragraph,omitempty"`
+	BulletedListItem *TextBlock `json:"bulleted_list_item,omitempty"`
+	NumberedListItem *TextBlock `json:"numbered_list_item,omitempty"`
+	ToDo             *ToDoBlock `json:"to_do,omitempty"`
+	Toggle           *TextBlock `json:"toggle,omitempty"`
+	Callout          *Callout   `json:"callout,omitempty"`
+	Audio            *FileBlock `json:"audio,omitempty"`
+	Divider          *struct{}  `json:"divider,omitempty"`
+}
+
+// nestedChildren 返回块中嵌套的子块
+func (b Block) nestedChildren() []Block {
+	switch {
+	case b.ToDo != nil:
+		return b.ToDo.Children
+	case b.Callout != nil:
+		return b.Callout.Children
+	default:
+		if tb := b.textBlock(); tb != nil {
+			return tb.Children
+		}
+	}
+	return nil
+}
+
+// withChildren 返回替换了嵌套子块的块副本，不修改原块
+func (b Block) withChildren(children []Block) Block {
+	switch {
+	case b.ToDo != nil:
+		inner := *b.ToDo
+		inner.Children = children
+		b.ToDo = &inner
+	case b.Callout != nil:
+		inner := *b.Callout
+		inner.Children = children
+		b.Callout = &inner
+	default:
+		if tb := b.textBlock(); tb != nil {
+			inner := *tb
+			inner.Children = children
+			switch b.Type {
+			case "heading_1":
+				b.Heading1 = &inner
+			case "heading_2":
+				b.Heading2 = &inner
+			case "heading_3":
+				b.Heading3 = &inner
+			case "paragraph":
+				b.Paragraph = &inner
+			case "bulleted_list_item":
+				b.BulletedListItem = &inner
+			case "numbered_list_item":
+				b.NumberedListItem = &inner
+			case "toggle":
+				b.Toggle = &inner
+			}
+		}
+	}
+	return b
+}
+
+func (b Block) textBlock() *TextBlock {
+	switch b.Type {
+	case "heading_1":
+		return b.Heading1
+	case "heading_2":
+		return b.Heading2
+	case "heading_3":
+		return b.Heading3
+	case "paragraph":
+		return b.Paragraph
+	case "bulleted_list_item":
+		return b.BulletedListItem
+	case "numbered_list_item":
+		return b.NumberedListItem
+	case "toggle":
+		return b.Toggle
+	}
+	return nil
+}
+
+// TextBlock 标题、段落、列表项等纯文本块的内容
+type TextBlock struct {
+	RichText []RichText `json:"rich_text"`
+	Color    string     `json:"color,omitempty"`
+	Children []Block    `json:"children,omitempty"`
+}
+
+// ToDoBlock 待办块的内容
+type ToDoBlock struct {
+	RichText []RichText `json:"rich_text"`
+	Checked  bool       `json:"checked"`
+	Color    string     `json:"color,omitempty"`
+	Children []Block    `json:"children,omitempty"`
+}
+
+// Callout 标注块的内容
+type Callout struct {
+	RichText []RichText `json:"rich_text"`
+	Icon     *Icon      `json:"icon,omitempty"`
+	Color    string     `json:"color,omitempty"`
+	Children []Block    `json:"children,omitempty"`
+}
+
+// Icon 页面或标注块的图标
+type Icon struct {
+	Type  string `json:"type"`
+	Emoji string `json:"emoji,omitempty"`
+}
+
+// FileBlock 音频、文件等媒体块的内容
+type FileBlock struct {
+	Type       string         `json:"type"` // "file_upload"、"external" 或 "file"
+	FileUpload *FileUploadRef `json:"file_upload,omitempty"`
+	External   *Link          `json:"external,omitempty"`
+	Caption    []RichText     `json:"caption,omitempty"`
+}
+
+// FileUploadRef 引用通过文件上传接口上传的文件
+type FileUploadRef struct {
+	ID string `json:"id"`
+}
+
+// FileUpload 文件上传对象
+type FileUpload struct {
+	Object      string `json:"object"`
+	ID          string `json:"id"`
+	Status      string `json:"status"` // "pending"、"uploaded"、"expired" 或 "failed"
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	UploadURL   string `json:"upload_url,omitempty"`
+}
+
+// BlockList 分页的块列表
+type BlockList struct {
+	Results    []Block `json:"results"`
+	HasMore    bool    `json:"has_more"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,385 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateClient 根据文档生成Go客户端的类型和方法，生成的代码依赖同一包中手写的Client、File和请求辅助函数
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	g := &clientGenerator{doc: doc, imports: map[string]bool{}}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := g.structType(name, doc.Components.Schemas[name]); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	for _, path := range doc.SortedPaths() {
+		item := *doc.Paths[path]
+		methods := make([]string, 0, len(item))
+		for method := range item {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			if err := g.method(strings.ToUpper(method), path, item[method]); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by \"meeting-mm openapi\"; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			imports = append(imports, strconv.Quote(path))
+		}
+		sort.Strings(imports)
+		fmt.Fprintf(&out, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	out.Write(g.types.Bytes())
+	out.Write(g.methods.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成的代码失败: %w", err)
+	}
+	return formatted, nil
+}
+
+type clientGenerator struct {
+	doc     *Document
+	imports map[string]bool
+	types   bytes.Buffer
+	methods bytes.Buffer
+}
+
+// structType 为components中的对象生成结构体
+func (g *clientGenerator) structType(name string, schema *Schema) error {
+	fmt.Fprintf(&g.types, "// %s 由OpenAPI文档生成\ntype %s struct {\n", name, name)
+	for _, property := range schema.Properties {
+		typ, err := g.goType(property.Schema)
+		if err != nil {
+			return fmt.Errorf("%s: %w", property.Name, err)
+		}
+		tag := property.Name
+		if !contains(schema.Required, property.Name) {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&g.types, "\t%s %s `json:%q`\n", goName(property.Name), typ, tag)
+	}
+	g.types.WriteString("}\n\n")
+	return nil
+}
+
+// goType 返回Schema对应的Go类型
+func (g *clientGenerator) goType(schema *Schema) (string, error) {
+	if schema.Ref != "" {
+		return strings.TrimPrefix(schema.Ref, refPrefix), nil
+	}
+	if len(schema.AllOf) == 1 {
+		inner, err := g.goType(schema.AllOf[0])
+		if err != nil {
+			return "", err
+		}
+		return "*" + inner, nil
+	}
+
+	// 可为null的标量使用指针，数组和map本身可以为nil
+	if schema.Nullable && schema.Format != "binary" {
+		switch schema.Type {
+		case "string", "integer", "number", "boolean":
+			inner := *schema
+			inner.Nullable = false
+			typ, err := g.goType(&inner)
+			return "*" + typ, err
+		}
+	}
+
+	switch schema.Type {
+	case "":
+		return "interface{}", nil
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			g.imports["time"] = true
+			return "time.Time", nil
+		case "binary":
+			return "*File", nil
+		case "byte":
+			return "[]byte", nil
+		}
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		items, err := g.goType(schema.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + items, nil
+	case "object":
+		values, ok := schema.AdditionalProperties.(*Schema)
+		if !ok {
+			return "", fmt.Errorf("不支持内联的对象类型")
+		}
+		typ, err := g.goType(values)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + typ, nil
+	}
+	return "", fmt.Errorf("不支持的类型: %s", schema.Type)
+}
+
+// method 为一个操作生成客户端方法
+func (g *clientGenerator) method(method, path string, op *Operation) error {
+	g.imports["context"] = true
+	name := op.OperationID
+	args := []string{"ctx context.Context"}
+
+	// 路径参数
+	pathExpr := strconv.Quote("/api" + path)
+	var query []*Parameter
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			g.imports["net/url"] = true
+			arg := lowerFirst(goName(param.Name))
+			args = append(args, arg+" string")
+			pathExpr = strings.Replace(pathExpr, "{"+param.Name+"}", `" + url.PathEscape(`+arg+`) + "`, 1)
+		case "query":
+			query = append(query, param)
+		}
+	}
+	pathExpr = strings.TrimSuffix(strings.TrimPrefix(pathExpr, `"" + `), ` + ""`)
+
+	var body bytes.Buffer
+	queryExpr := "nil"
+	if len(query) > 0 {
+		if err := g.queryParams(name, query, &body); err != nil {
+			return err
+		}
+		args = append(args, "params *"+name+"Params")
+		queryExpr = "query"
+	}
+
+	bodyExpr, contentType, checkErr := "nil", `""`, false
+	if op.RequestBody != nil {
+		contentType, bodyExpr = "contentType", "reqBody"
+		arg, encodes, err := g.requestBody(op.RequestBody, &body)
+		if err != nil {
+			return err
+		}
+		args = append(args, arg)
+		checkErr = encodes
+	}
+
+	// 成功响应
+	var success *Response
+	for status, response := range op.Responses {
+		if code, err := strconv.Atoi(status); err == nil && code < 400 {
+			success = response
+		}
+	}
+	if success == nil {
+		return fmt.Errorf("缺少成功响应")
+	}
+	result, zero, outExpr, err := g.response(success)
+	if err != nil {
+		return err
+	}
+
+	if op.Summary != "" {
+		fmt.Fprintf(&g.methods, "// %s %s\n", name, op.Summary)
+	}
+	fmt.Fprintf(&g.methods, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), result)
+	g.methods.Write(body.Bytes())
+	if checkErr {
+		fmt.Fprintf(&g.methods, "if err != nil {\nreturn %s, err\n}\n", zero)
+	}
+	fmt.Fprintf(&g.methods, "var out %s\n", strings.TrimPrefix(result, "*"))
+	fmt.Fprintf(&g.methods, "if err := c.do(ctx, %q, %s, %s, %s, %s, %s); err != nil {\nreturn %s, err\n}\n",
+		methodConst(method), pathExpr, queryExpr, bodyExpr, contentType, "&out", zero)
+	fmt.Fprintf(&g.methods, "return %s, nil\n}\n\n", outExpr)
+	return nil
+}
+
+// queryParams 生成查询参数结构体和拼接查询字符串的代码
+func (g *clientGenerator) queryParams(name string, params []*Parameter, body *bytes.Buffer) error {
+	g.imports["net/url"] = true
+	fmt.Fprintf(&g.types, "// %sParams %s的查询参数\ntype %sParams struct {\n", name, name, name)
+	body.WriteString("query := url.Values{}\nif params != nil {\n")
+	for _, param := range params {
+		field := goName(param.Name)
+		switch param.Schema.Type {
+		case "string":
+			fmt.Fprintf(&g.types, "\t%s string\n", field)
+			fmt.Fprintf(body, "if params.%s != \"\" {\nquery.Set(%q, params.%s)\n}\n", field, param.Name, field)
+		case "integer":
+			g.imports["strconv"] = true
+			fmt.Fprintf(&g.types, "\t%s int\n", field)
+			fmt.Fprintf(body, "if params.%s != 0 {\nquery.Set(%q, strconv.Itoa(params.%s))\n}\n", field, param.Name, field)
+		case "boolean":
+			g.imports["strconv"] = true
+			fmt.Fprintf(&g.types, "\t%s bool\n", field)
+			fmt.Fprintf(body, "if params.%s {\nquery.Set(%q, strconv.FormatBool(params.%s))\n}\n", field, param.Name, field)
+		default:
+			return fmt.Errorf("不支持的查询参数类型: %s", param.Schema.Type)
+		}
+	}
+	g.types.WriteString("}\n\n")
+	body.WriteString("}\n")
+	return nil
+}
+
+// requestBody 生成请求体参数和编码请求体的代码，返回参数声明以及编码是否可能出错
+func (g *clientGenerator) requestBody(request *RequestBody, body *bytes.Buffer) (string, bool, error) {
+	for contentType, media := range request.Content {
+		switch {
+		case media.Schema.Format == "binary":
+			g.imports["io"] = true
+			fmt.Fprintf(body, "reqBody, contentType := body, %q\n", contentType)
+			return "body io.Reader", false, nil
+		case contentType == "multipart/form-data":
+			typ, err := g.goType(media.Schema)
+			if err != nil {
+				return "", false, err
+			}
+			if err := g.formFields(g.doc.Resolve(media.Schema), body); err != nil {
+				return "", false, err
+			}
+			return "form *" + typ, true, nil
+		case contentType == "application/json":
+			typ, err := g.goType(media.Schema)
+			if err != nil {
+				return "", false, err
+			}
+			body.WriteString("reqBody, contentType, err := jsonBody(body)\n")
+			if media.Schema.Ref != "" {
+				typ = "*" + typ
+			}
+			return "body " + typ, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("不支持的请求体")
+}
+
+// formFields 生成编码multipart表单的代码
+func (g *clientGenerator) formFields(schema *Schema, body *bytes.Buffer) error {
+	body.WriteString("fields := map[string]string{}\nfiles := map[string]*File{}\n")
+	for _, property := range schema.Properties {
+		field := goName(property.Name)
+		switch property.Schema.Type {
+		case "string":
+			if property.Schema.Format == "binary" {
+				fmt.Fprintf(body, "files[%q] = form.%s\n", property.Name, field)
+			} else {
+				fmt.Fprintf(body, "fields[%q] = form.%s\n", property.Name, field)
+			}
+		case "boolean":
+			g.imports["strconv"] = true
+			fmt.Fprintf(body, "fields[%q] = strconv.FormatBool(form.%s)\n", property.Name, field)
+		case "integer":
+			g.imports["strconv"] = true
+			fmt.Fprintf(body, "fields[%q] = strconv.Itoa(form.%s)\n", property.Name, field)
+		default:
+			return fmt.Errorf("不支持的表单字段类型: %s", property.Schema.Type)
+		}
+	}
+	body.WriteString("reqBody, contentType, err := multipartBody(fields, files)\n")
+	return nil
+}
+
+// response 返回结果类型、零值和返回表达式
+func (g *clientGenerator) response(response *Response) (result, zero, out string, err error) {
+	for _, media := range response.Content {
+		if media.Schema.Format == "binary" {
+			return "[]byte", "nil", "out", nil
+		}
+		typ, err := g.goType(media.Schema)
+		if err != nil {
+			return "", "", "", err
+		}
+		if media.Schema.Ref != "" {
+			return "*" + typ, "nil", "&out", nil
+		}
+		return typ, "nil", "out", nil
+	}
+	return "", "", "", fmt.Errorf("响应缺少内容")
+}
+
+// methodConst 返回HTTP方法名，校验是否为标准方法
+func methodConst(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return method
+	}
+	panic("不支持的HTTP方法: " + method)
+}
+
+// initialisms 生成Go名称时全部大写的缩写
+var initialisms = map[string]bool{"id": true, "url": true, "api": true, "http": true, "json": true}
+
+// goName 将JSON属性名转换为导出的Go名称，例如 notionApiKeySet -> NotionAPIKeySet
+func goName(name string) string {
+	var words []string
+	start := 0
+	runes := []rune(name)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || unicode.IsUpper(runes[i]) || runes[i] == '_' {
+			if word := strings.Trim(string(runes[start:i]), "_"); word != "" {
+				words = append(words, word)
+			}
+			start = i
+		}
+	}
+
+	var b strings.Builder
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// lowerFirst 将Go名称转换为参数名
+func lowerFirst(name string) string {
+	if strings.ToUpper(name) == name {
+		return strings.ToLower(name)
+	}
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package openapi
+
+import (
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// refPrefix 组件引用的前缀
+const refPrefix = "#/components/schemas/"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+)
+
+// builder 通过反射生成Schema，具名结构体放入components并以$ref引用
+type builder struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+}
+
+func (b *builder) schema(t reflect.Type) (*Schema, error) {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		inner, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(inner), nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items, Nullable: t.Kind() == reflect.Slice}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("不支持非字符串键的map: %s", t)
+		}
+		values, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values, Nullable: true}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return b.ref(t)
+	default:
+		return nil, fmt.Errorf("不支持的类型: %s", t)
+	}
+}
+
+// ref 将具名结构体放入components，同名的不同类型视为错误
+func (b *builder) ref(t reflect.Type) (*Schema, error) {
+	name := t.Name()
+	ref := &Schema{Ref: refPrefix + name}
+	if existing, ok := b.types[name]; ok {
+		if existing != t {
+			return nil, fmt.Errorf("类型名冲突: %s 与 %s", existing, t)
+		}
+		return ref, nil
+	}
+
+	// 先占位，支持递归引用
+	b.types[name] = t
+	b.schemas[name] = &Schema{}
+	object, err := b.object(t)
+	if err != nil {
+		return nil, err
+	}
+	*b.schemas[name] = *object
+	return ref, nil
+}
+
+// object 生成结构体的Schema，不允许出现未声明的属性
+func (b *builder) object(t reflect.Type) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: Properties{}, AdditionalProperties: false}
+	if err := b.fields(t, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// fields 收集结构体字段，嵌入的结构体字段展开到外层
+func (b *builder) fields(t reflect.Type, schema *Schema) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, optional, skip := fieldName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && name == "" {
+			if err := b.fields(field.Type, schema); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := b.schema(field.Type)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		schema.Properties = append(schema.Properties, Property{Name: name, Schema: property})
+		if !optional {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+// fieldName 从json或form标签中取出属性名，以及是否可省略、是否跳过
+func fieldName(field reflect.StructField) (name string, optional, skip bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		tag, ok = field.Tag.Lookup("form")
+	}
+	if !ok {
+		return "", false, false
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(","+options+",", ",omitempty,"), false
+}
+
+// nullable 返回允许null的Schema，引用需要包在allOf中
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+	}
+	copied := *schema
+	copied.Nullable = true
+	return &copied
+}
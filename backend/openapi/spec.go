@@ -0,0 +1,372 @@
+// Package openapi 根据路由表中声明的Go请求和响应类型生成OpenAPI 3文档，
+// 并提供按文档校验请求和响应的方法以及Go客户端生成器
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Version 生成的OpenAPI版本
+const Version = "3.0.3"
+
+// Document OpenAPI文档
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info 文档信息
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem 一个路径下的全部操作，键为小写的HTTP方法
+type PathItem map[string]*Operation
+
+// Operation 一个API操作
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+// Parameter 路径或查询参数
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // "path" 或 "query"
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType 某种内容类型的请求体或响应体
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components 可复用的组件
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme 认证方式
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// Schema JSON Schema的OpenAPI子集
+type Schema struct {
+	Ref                  string      `json:"$ref,omitempty"`
+	Type                 string      `json:"type,omitempty"`
+	Format               string      `json:"format,omitempty"`
+	Nullable             bool        `json:"nullable,omitempty"`
+	Items                *Schema     `json:"items,omitempty"`
+	Properties           Properties  `json:"properties,omitempty"`
+	Required             []string    `json:"required,omitempty"`
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"` // false或*Schema
+	AllOf                []*Schema   `json:"allOf,omitempty"`
+}
+
+// Property 对象的一个属性
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties 按Go结构体字段顺序排列的属性，序列化为JSON对象
+type Properties []Property
+
+// Get 按名称查找属性
+func (p Properties) Get(name string) (*Schema, bool) {
+	for _, property := range p {
+		if property.Name == name {
+			return property.Schema, true
+		}
+	}
+	return nil, false
+}
+
+// MarshalJSON 按字段顺序输出JSON对象
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, property := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(property.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(schema)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Binary 声明非JSON的二进制请求体或响应体
+type Binary struct {
+	ContentType string
+	// Alternatives 同一响应可能的其他内容类型，例如按参数导出的不同文件格式
+	Alternatives []string
+}
+
+// Route 生成文档所需的路由信息
+type Route struct {
+	OperationID string
+	Method      string
+	Path        string // Fiber风格的路径，参数写作 :id
+	Summary     string
+	Tag         string
+	Public      bool        // 无需认证
+	Query       interface{} // 查询参数结构体，字段使用query标签
+	Request     interface{} // 请求体：JSON结构体、使用form标签的multipart表单或Binary
+	Response    interface{} // 成功响应：JSON类型或Binary
+	Status      int         // 成功响应的状态码，默认200
+	// Responses 其他状态码的响应类型（如就绪检查失败时的503），未声明的错误状态码使用错误响应类型
+	Responses map[int]interface{}
+}
+
+// 认证方式名称
+const (
+	securityBearer  = "bearerAuth"
+	securityAPIKey  = "apiKeyAuth"
+	securityCookie  = "cookieAuth"
+	errorSchemaName = "ErrorResponse"
+)
+
+// Build 根据路由生成OpenAPI文档，errorType为错误响应的类型
+func Build(info Info, routes []Route, errorType interface{}) (*Document, error) {
+	b := &builder{schemas: map[string]*Schema{}, types: map[string]reflect.Type{}}
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: b.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				securityBearer: {Type: "http", Scheme: "bearer"},
+				securityAPIKey: {Type: "apiKey", In: "header", Name: "X-API-Key"},
+				securityCookie: {Type: "apiKey", In: "cookie", Name: "mm_session"},
+			},
+		},
+	}
+
+	errorSchema, err := b.schema(reflect.TypeOf(errorType))
+	if err != nil {
+		return nil, err
+	}
+	if errorSchema.Ref != refPrefix+errorSchemaName {
+		return nil, fmt.Errorf("错误响应类型必须命名为%s", errorSchemaName)
+	}
+
+	seen := map[string]bool{}
+	for _, route := range routes {
+		if route.OperationID == "" {
+			return nil, fmt.Errorf("%s %s 缺少operationId", route.Method, route.Path)
+		}
+		if seen[route.OperationID] {
+			return nil, fmt.Errorf("operationId重复: %s", route.OperationID)
+		}
+		seen[route.OperationID] = true
+
+		path, params := convertPath(route.Path)
+		op, err := b.operation(route, params, errorSchema)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", route.Method, route.Path, err)
+		}
+
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(route.Method)] = op
+	}
+	return doc, nil
+}
+
+// convertPath 将 /meetings/:id 转换为 /meetings/{id}，并返回路径参数；
+// 参数后可以带固定的扩展名，如 /feed/:name.ics 转换为 /feed/{name}.ics
+func convertPath(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			name, ext, _ := strings.Cut(name, ".")
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+			if ext != "" {
+				segments[i] += "." + ext
+			}
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func (b *builder) operation(route Route, pathParams []string, errorSchema *Schema) (*Operation, error) {
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Responses:   map[string]*Response{},
+		Security:    []map[string][]string{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if !route.Public {
+		op.Security = []map[string][]string{
+			{securityBearer: {}},
+			{securityAPIKey: {}},
+			{securityCookie: {}},
+		}
+	}
+
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	if route.Query != nil {
+		params, err := b.queryParameters(reflect.TypeOf(route.Query))
+		if err != nil {
+			return nil, err
+		}
+		op.Parameters = append(op.Parameters, params...)
+	}
+
+	if route.Request != nil {
+		body, err := b.requestBody(route.Request)
+		if err != nil {
+			return nil, err
+		}
+		op.RequestBody = body
+	}
+
+	if route.Response == nil {
+		return nil, fmt.Errorf("未声明响应类型")
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	content, err := b.content(route.Response, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	op.Responses[fmt.Sprint(status)] = &Response{Description: http.StatusText(status), Content: content}
+	for status, response := range route.Responses {
+		content, err := b.content(response, "application/json")
+		if err != nil {
+			return nil, err
+		}
+		op.Responses[fmt.Sprint(status)] = &Response{Description: http.StatusText(status), Content: content}
+	}
+	op.Responses["default"] = &Response{
+		Description: "错误",
+		Content:     map[string]*MediaType{"application/json": {Schema: errorSchema}},
+	}
+	return op, nil
+}
+
+// requestBody 生成请求体：Binary、使用form标签的multipart表单或JSON
+func (b *builder) requestBody(request interface{}) (*RequestBody, error) {
+	t := reflect.TypeOf(request)
+	contentType := "application/json"
+	if t.Kind() == reflect.Struct && isForm(t) {
+		contentType = "multipart/form-data"
+	}
+	content, err := b.content(request, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestBody{Required: true, Content: content}, nil
+}
+
+func (b *builder) content(value interface{}, contentType string) (map[string]*MediaType, error) {
+	if binary, ok := value.(Binary); ok {
+		content := map[string]*MediaType{}
+		for _, contentType := range append([]string{binary.ContentType}, binary.Alternatives...) {
+			content[contentType] = &MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+		return content, nil
+	}
+	schema, err := b.schema(reflect.TypeOf(value))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]*MediaType{contentType: {Schema: schema}}, nil
+}
+
+// queryParameters 将使用query标签的结构体转换为查询参数
+func (b *builder) queryParameters(t reflect.Type) ([]*Parameter, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("查询参数必须是结构体: %s", t)
+	}
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("query"), ",")
+		if name == "" || !field.IsExported() {
+			continue
+		}
+		schema, err := b.schema(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, &Parameter{Name: name, In: "query", Schema: schema})
+	}
+	return params, nil
+}
+
+// isForm 结构体是否使用form标签声明multipart表单
+func isForm(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("form"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SortedPaths 返回按字母顺序排列的路径
+func (d *Document) SortedPaths() []string {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
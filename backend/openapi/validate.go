@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FindOperation 根据HTTP方法和实际请求路径找到文档中的操作，静态路径优先于带参数的路径
+func (d *Document) FindOperation(method, path string) (*Operation, bool) {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	var best *Operation
+	bestParams := math.MaxInt
+	for template, item := range d.Paths {
+		op, ok := (*item)[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+		params, ok := matchPath(strings.Split(template, "/"), segments)
+		if ok && params < bestParams {
+			best, bestParams = op, params
+		}
+	}
+	return best, best != nil
+}
+
+// matchPath 匹配路径模板，返回匹配到的参数个数
+func matchPath(template, segments []string) (int, bool) {
+	if len(template) != len(segments) {
+		return 0, false
+	}
+	params := 0
+	for i, segment := range template {
+		if strings.HasPrefix(segment, "{") {
+			// 参数后可能带固定的扩展名，如 {name}.ics
+			_, ext, _ := strings.Cut(segment, "}")
+			value, ok := strings.CutSuffix(segments[i], ext)
+			if !ok || value == "" {
+				return 0, false
+			}
+			params++
+		} else if segment != segments[i] {
+			return 0, false
+		}
+	}
+	return params, true
+}
+
+// ValidateRequest 校验JSON请求体是否符合文档
+func (d *Document) ValidateRequest(op *Operation, contentType string, body []byte) error {
+	if op.RequestBody == nil {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return fmt.Errorf("%s 未声明请求体", op.OperationID)
+		}
+		return nil
+	}
+	media, ok := op.RequestBody.Content[mediaType(contentType)]
+	if !ok {
+		return fmt.Errorf("%s 不接受内容类型 %q", op.OperationID, contentType)
+	}
+	return d.validateJSON(contentType, media, body)
+}
+
+// ValidateResponse 校验响应状态码和JSON响应体是否符合文档
+func (d *Document) ValidateResponse(op *Operation, status int, contentType string, body []byte) error {
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		if status < 400 {
+			return fmt.Errorf("%s 未声明状态码 %d", op.OperationID, status)
+		}
+		response = op.Responses["default"]
+	}
+	media, ok := response.Content[mediaType(contentType)]
+	if !ok {
+		return fmt.Errorf("%s 的 %d 响应未声明内容类型 %q", op.OperationID, status, contentType)
+	}
+	return d.validateJSON(contentType, media, body)
+}
+
+// validateJSON 只校验JSON内容，表单和二进制内容（包括声明为二进制的JSON文件）不校验
+func (d *Document) validateJSON(contentType string, media *MediaType, body []byte) error {
+	if mediaType(contentType) != "application/json" || media.Schema == nil || media.Schema.Format == "binary" {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("无效的JSON: %w", err)
+	}
+	return d.validate(media.Schema, value, "$")
+}
+
+// mediaType 去掉Content-Type中的参数，例如charset
+func mediaType(contentType string) string {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return parsed
+}
+
+// Resolve 解析$ref引用
+func (d *Document) Resolve(schema *Schema) *Schema {
+	for schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, refPrefix)]
+	}
+	return schema
+}
+
+// validate 按Schema校验JSON值，path用于错误信息
+func (d *Document) validate(schema *Schema, value interface{}, path string) error {
+	schema = d.Resolve(schema)
+	if value == nil {
+		if schema.Nullable || schema.Type == "" && len(schema.AllOf) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%s 不能为null", path)
+	}
+	for _, inner := range schema.AllOf {
+		if err := d.validate(inner, value, path); err != nil {
+			return err
+		}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s 应为字符串", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s 应为布尔值", path)
+		}
+	case "number", "integer":
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s 应为数字", path)
+		}
+		if schema.Type == "integer" && number != math.Trunc(number) {
+			return fmt.Errorf("%s 应为整数", path)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s 应为数组", path)
+		}
+		for i, item := range items {
+			if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s 应为对象", path)
+		}
+		return d.validateObject(schema, object, path)
+	}
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, object map[string]interface{}, path string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s 缺少属性 %s", path, name)
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := schema.Properties.Get(name)
+		if !ok {
+			additional, isSchema := schema.AdditionalProperties.(*Schema)
+			if !isSchema {
+				return fmt.Errorf("%s 包含未声明的属性 %s", path, name)
+			}
+			property = additional
+		}
+		if err := d.validate(property, object[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"meeting-mm/apperr"
+	"meeting-mm/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// abortGrace 关闭超时取消任务后，等待它们结束子进程、删除临时文件的时间
+const abortGrace = 5 * time.Second
+
+// track 将请求登记为进行中的任务，关闭时等待其完成；开始关闭后到达的请求返回503
+func (s *Server) track(c *fiber.Ctx) error {
+	ctx, done, err := s.jobs.Start(c.UserContext())
+	if err != nil {
+		return err
+	}
+	defer done()
+	c.SetUserContext(ctx)
+
+	err = c.Next()
+	if err != nil && errors.Is(context.Cause(ctx), services.ErrShuttingDown) {
+		// 关闭超时被取消的请求可以稍后重试
+		return apperr.Wrap(apperr.CodeUnavailable, services.ErrShuttingDown.Error(), err)
+	}
+	return err
+}
+
+// ListenAndServe 启动后台任务，并通过指定的方式监听addr。
+// ctx结束（如收到SIGTERM）后优雅关闭：停止接受新请求，等待进行中的请求和同步任务完成，
+// 超过ShutdownTimeout后取消它们，最后发送剩余的追踪数据
+func (s *Server) ListenAndServe(ctx context.Context, transport, addr string) error {
+	var serve func() error
+	var stopListener func(context.Context) error
+	switch transport {
+	case TransportFiber:
+		serve = func() error { return s.app.Listen(addr) }
+		stopListener = s.app.ShutdownWithContext
+	case TransportHTTP:
+		httpServer := &http.Server{Addr: addr, Handler: s.Handler()}
+		serve = func() error {
+			if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		}
+		stopListener = httpServer.Shutdown
+	default:
+		return fmt.Errorf("未知的服务方式: %q", transport)
+	}
+
+	// 清理上次运行被中断时遗留的转录临时文件
+	if removed, err := services.SweepWhisperTemp(services.StaleTempAge); err != nil {
+		slog.Warn("清理转录临时文件失败", "error", err)
+	} else if removed > 0 {
+		slog.Info("已清理遗留的转录临时文件", "count", removed)
+	}
+
+	// 后台任务使用独立的ctx，关闭时按顺序停止：先同步发件箱，最后追踪数据导出
+	outboxCtx, stopOutbox := context.WithCancel(context.Background())
+	defer stopOutbox()
+	outboxDone := make(chan struct{})
+	go func() {
+		defer close(outboxDone)
+		s.outbox.Run(outboxCtx)
+	}()
+
+	tracesCtx, stopTraces := context.WithCancel(context.Background())
+	defer stopTraces()
+	tracesDone := make(chan struct{})
+	go func() {
+		defer close(tracesDone)
+		if s.traces != nil {
+			s.traces.Run(tracesCtx)
+		}
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- serve() }()
+	slog.Info("服务器启动", "address", "http://localhost"+addr, "transport", transport)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	timeout := s.cfg.Load().ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	slog.Info("开始关闭服务器", "timeout", timeout, "running", s.jobs.Running())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 停止接受新连接和新的同步任务，等待进行中的请求完成，超时后取消它们
+	stopOutbox()
+	listenerDone := make(chan error, 1)
+	go func() { listenerDone <- stopListener(shutdownCtx) }()
+	if err := s.jobs.Drain(shutdownCtx, abortGrace); err != nil {
+		slog.Warn("等待请求完成超时，已取消剩余请求", "error", err)
+	}
+	<-listenerDone
+	if err := <-serveErr; err != nil {
+		slog.Warn("停止监听失败", "error", err)
+	}
+
+	// 同步任务在发件箱中持久化，未完成的任务下次启动后继续
+	if !waitDone(shutdownCtx, outboxDone, abortGrace) {
+		slog.Warn("Notion同步任务未在关闭期限内完成，下次启动后重试")
+	}
+
+	stopTraces()
+	if !waitDone(context.Background(), tracesDone, abortGrace) {
+		slog.Warn("发送剩余追踪数据超时")
+	}
+	slog.Info("服务器已关闭")
+	return nil
+}
+
+// waitDone 等待done关闭，ctx结束后最多再等待grace，返回done是否已关闭
+func waitDone(ctx context.Context, done <-chan struct{}, grace time.Duration) bool {
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+	}
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
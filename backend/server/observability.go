@@ -0,0 +1,65 @@
+package server
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"meeting-mm/apperr"
+	"meeting-mm/logging"
+	"meeting-mm/metrics"
+	"meeting-mm/tracing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// observe 为每个请求开始服务端span，记录请求耗时和访问日志。
+// 处理器返回的错误在这里交给统一错误处理器，以便记录最终的状态码
+func observe(c *fiber.Ctx) error {
+	start := time.Now()
+	requestID, _ := c.Locals("requestid").(string)
+	ctx := logging.WithRequestID(c.UserContext(), requestID)
+	ctx = tracing.WithTraceparent(ctx, c.Get("traceparent"))
+	ctx, span := tracing.StartKind(ctx, c.Method()+" "+c.Path(), tracing.KindServer)
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	if err := c.Next(); err != nil {
+		span.RecordError(err)
+		if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	// 路由匹配后使用路由模式命名，避免路径中的ID导致指标的标签过多
+	route := c.Route().Path
+	status := c.Response().StatusCode()
+	span.SetName(c.Method() + " " + route)
+	span.SetAttribute("http.method", c.Method())
+	span.SetAttribute("http.route", route)
+	span.SetAttribute("http.status_code", status)
+	if requestID != "" {
+		span.SetAttribute("request.id", requestID)
+	}
+	latency := time.Since(start)
+	metrics.HTTPRequestDuration.Observe(latency.Seconds(), c.Method(), route, strconv.Itoa(status))
+	slog.InfoContext(ctx, "请求完成", "method", c.Method(), "path", c.Path(), "status", status, "latency", latency)
+	return nil
+}
+
+// metricsHandler 以Prometheus文本格式返回指标，配置了令牌时需要携带 Authorization: Bearer <令牌>
+func metricsHandler(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token != "" {
+			provided, _ := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				return apperr.New(apperr.CodeUnauthorized, "指标访问令牌无效")
+			}
+		}
+		c.Set(fiber.HeaderContentType, metrics.ContentType)
+		_, err := metrics.Default.WriteTo(c)
+		return err
+	}
+}
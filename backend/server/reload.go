@@ -0,0 +1,46 @@
+package server
+
+import (
+	"log/slog"
+	"strings"
+
+	"meeting-mm/config"
+	"meeting-mm/logging"
+)
+
+// Reload 应用重新加载的配置：提示词、超时和限额等可以热更新的配置项立即生效，
+// 进行中的请求继续使用原来的配置；其余配置项的变化记录警告，重启后才生效。
+// next应当已经通过校验
+func (s *Server) Reload(next *config.Config) (applied, ignored []string) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	updated, applied, ignored := config.Reload(s.cfg.Load(), s.loaded, next)
+	s.loaded = next.Clone()
+	if len(ignored) > 0 {
+		slog.Warn("以下配置项需要重启才能生效", "keys", strings.Join(ignored, ","))
+	}
+	if len(applied) == 0 {
+		slog.Info("重新加载配置：没有可以立即生效的变化")
+		return applied, ignored
+	}
+
+	s.cfg.Store(updated)
+	s.services.Reload(updated)
+	s.outbox.Reload(updated)
+	s.health.Reload(updated)
+	s.calendar.Reload(updated)
+	if err := s.reports.Reload(updated); err != nil {
+		slog.Error("重新加载报告模板失败，继续使用原来的模板", "error", err)
+	}
+	if err := logging.SetLevel(updated.LogLevel); err != nil {
+		slog.Warn("修改日志级别失败", "error", err)
+	}
+	slog.Info("已重新加载配置", "keys", strings.Join(applied, ","))
+	return applied, ignored
+}
+
+// Config 返回运行中的配置
+func (s *Server) Config() *config.Config {
+	return s.cfg.Load()
+}
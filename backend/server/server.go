@@ -0,0 +1,177 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"meeting-mm/api"
+	"meeting-mm/apperr"
+	"meeting-mm/config"
+	"meeting-mm/services"
+	"meeting-mm/storage"
+	"meeting-mm/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/google/uuid"
+)
+
+// 对外提供服务的方式
+const (
+	TransportFiber = "fiber" // Fiber（fasthttp）监听
+	TransportHTTP  = "http"  // 标准库net/http监听
+)
+
+// bodyLimit 请求体大小上限
+const bodyLimit = 50 * 1024 * 1024 // 50MB
+
+// Server 服务器核心：持有全部服务、中间件栈和路由表，可以通过Fiber或net/http对外提供服务
+type Server struct {
+	cfg      atomic.Pointer[config.Config] // 运行中的配置，重新加载后替换
+	store    *storage.Store
+	services *services.WorkspaceServices
+	outbox   *services.NotionOutbox
+	health   *services.HealthService
+	reports  *services.ReportRenderer
+	calendar *services.CalendarDirectory
+	traces   *tracing.Exporter // 未配置OTLP地址时为nil
+	jobs     *services.Jobs
+	app      *fiber.App
+
+	// reloadMu保证重新加载依次进行；loaded为上次加载的配置，用于判断哪些配置项发生了变化
+	reloadMu sync.Mutex
+	loaded   *config.Config
+}
+
+// New 根据配置创建服务器
+func New(cfg *config.Config) (*Server, error) {
+	loaded := cfg.Clone()
+
+	// 打开数据存储
+	store, err := storage.Open(cfg.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据存储失败: %w", err)
+	}
+
+	// 未配置认证密钥时使用数据目录中保存的随机密钥，重启后登录令牌和录音链接仍然有效
+	if cfg.AuthSecret == "" {
+		if cfg.AuthSecret, err = storage.LoadOrCreateSecret(cfg.DataDir, "auth_secret"); err != nil {
+			return nil, err
+		}
+	}
+
+	// 工作区凭据的加密密钥，未配置时同样使用数据目录中保存的随机密钥
+	encryptionKey := cfg.EncryptionKey
+	if encryptionKey == "" {
+		if encryptionKey, err = storage.LoadOrCreateSecret(cfg.DataDir, "encryption_key"); err != nil {
+			return nil, err
+		}
+	}
+	box, err := storage.NewSecretBox(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// 初始化服务
+	workspaceServices := services.NewWorkspaceServices(cfg, store, box)
+	notionOutbox := services.NewNotionOutbox(cfg, store, workspaceServices)
+	authService := services.NewAuthService(cfg, store)
+
+	reports, err := services.NewReportRenderer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("加载报告模板失败: %w", err)
+	}
+
+	// 创建API处理器
+	jobs := services.NewJobs()
+	healthService := services.NewHealthService(cfg, workspaceServices, jobs)
+	calendar := services.NewCalendarDirectory(cfg)
+	handler := api.NewHandler(cfg, workspaceServices, notionOutbox, authService, store, healthService, reports, calendar, services.NewProfileService(store), services.NewAskService(store), services.NewActionItemService(store))
+
+	// 创建Fiber应用
+	app := fiber.New(fiber.Config{
+		BodyLimit:    bodyLimit,
+		ErrorHandler: api.ErrorHandler,
+	})
+	srv := &Server{
+		store:    store,
+		services: workspaceServices,
+		outbox:   notionOutbox,
+		health:   healthService,
+		reports:  reports,
+		calendar: calendar,
+		jobs:     jobs,
+		app:      app,
+		loaded:   loaded,
+	}
+	srv.cfg.Store(cfg)
+
+	// 添加中间件，请求ID最先生成，日志和错误响应中都会带上；访问日志由observe记录，
+	// track登记进行中的请求以便关闭时等待
+	app.Use(requestid.New())
+	app.Use(observe)
+	app.Use(srv.track)
+	app.Use(recover.New())
+	app.Use(cors.New(cors.Config{
+		AllowOrigins:     cfg.CORSAllowOrigins,
+		AllowHeaders:     "Origin, Content-Type, Accept, Accept-Language, Authorization, X-API-Key, X-Request-ID",
+		ExposeHeaders:    "X-Request-ID",
+		AllowMethods:     "GET, POST, PUT, DELETE",
+		AllowCredentials: cfg.CORSAllowOrigins != "" && cfg.CORSAllowOrigins != "*",
+	}))
+
+	// Prometheus指标不属于API，不在路由表和OpenAPI文档中
+	app.Get("/metrics", metricsHandler(cfg.MetricsToken))
+
+	// 注册路由
+	api.RegisterRoutes(app, handler)
+
+	// 配置了OTLP地址时导出追踪数据
+	if cfg.OTLPEndpoint != "" {
+		srv.traces = tracing.NewExporter(cfg.OTLPEndpoint, cfg.ServiceName)
+	}
+	tracing.SetExporter(srv.traces)
+
+	return srv, nil
+}
+
+// App 返回Fiber应用，可用于app.Test
+func (s *Server) App() *fiber.App {
+	return s.app
+}
+
+// Handler 返回net/http形式的处理器，与Fiber共享同一套中间件和路由
+func (s *Server) Handler() http.Handler {
+	handler := adaptor.FiberApp(s.app)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 适配器会完整读取请求体，超出上限时与Fiber一样返回413
+		if r.ContentLength > bodyLimit {
+			writeTooLarge(w, r)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, bodyLimit)
+		handler(w, r)
+	})
+}
+
+// writeTooLarge 以与统一错误处理器相同的格式返回413，此时请求尚未进入Fiber
+func writeTooLarge(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get(fiber.HeaderXRequestID)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	body, _ := json.Marshal(api.NewErrorResponse(
+		apperr.New(apperr.CodePayloadTooLarge, "请求体过大"),
+		r.Header.Get(fiber.HeaderAcceptLanguage), requestID))
+
+	w.Header().Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	w.Header().Set(fiber.HeaderXRequestID, requestID)
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	w.Write(body)
+}
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"meeting-mm/apperr"
+	"meeting-mm/models"
+	"meeting-mm/storage"
+
+	"github.com/google/uuid"
+)
+
+// ErrActionItemNotFound 待办台账中没有该条目
+var ErrActionItemNotFound = errors.New("待办事项不存在")
+
+const (
+	// maxPromptOpenItems 分析时提供给模型的未完成条目数量上限，超过时只提供最近更新的条目
+	maxPromptOpenItems = 30
+	// minCarryOverSimilarity 模型没有标注且未配置向量模型时，按描述的检索词相似度认定为同一条目的下限
+	minCarryOverSimilarity = 0.5
+	// minCarryOverCosine 模型没有标注时，按描述向量的余弦相似度认定为同一条目的下限
+	minCarryOverCosine = 0.85
+)
+
+// continuityLabels 报告和Notion页面中延续关系的显示文字，新的待办事项不显示
+var continuityLabels = map[string]string{
+	models.ContinuityCarriedOver: "延续",
+	models.ContinuityUpdated:     "已更新",
+	models.ContinuityCompleted:   "已完成",
+}
+
+// openItemsPrompt 有之前会议中未完成的待办事项时追加在分析提示词中的输出要求
+const openItemsPrompt = `
+
+本次会议之前还有未完成的待办事项，请在上述JSON中增加：
+- "previousItems"：数组，本次会议中提到的每个之前的待办事项一项，格式为 {"item": 编号, "status": "completed（已完成）、updated（内容、负责人或截止日期有变化）或carried_over（仍未完成）"}，没有提到的不用列出
+- 每个待办事项增加 "previousItem"：与之前的待办事项是同一件事时为其编号，是新的待办事项时为0`
+
+// previousOutput 模型返回的一个之前的待办事项的情况
+type previousOutput struct {
+	Item   agendaRef `json:"item"`
+	Status string    `json:"status"`
+}
+
+// openItemsPromptList 返回提示词中编号的未完成待办事项列表
+func openItemsPromptList(items []*models.ActionItem) string {
+	var b strings.Builder
+	for i, item := range items {
+		fmt.Fprintf(&b, "%d. %s", i+1, item.Description)
+		if item.Assignee != "" {
+			fmt.Fprintf(&b, "（负责人：%s）", item.Assignee)
+		}
+		if !item.DueDate.IsZero() {
+			fmt.Fprintf(&b, "（截止：%s）", item.DueDate.Format("2006-01-02"))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// previousResults 将模型返回的情况按未完成条目的顺序排列，没有提到的为空，状态无法识别时视为仍未完成
+func previousResults(count int, results []previousOutput) []string {
+	if count == 0 {
+		return nil
+	}
+	statuses := make([]string, count)
+	for _, result := range results {
+		i := int(result.Item) - 1
+		if i < 0 || i >= count {
+			continue
+		}
+		status := strings.ToLower(strings.TrimSpace(result.Status))
+		switch status {
+		case models.ContinuityCompleted, models.ContinuityUpdated, models.ContinuityCarriedOver:
+		default:
+			status = models.ContinuityCarriedOver
+		}
+		statuses[i] = status
+	}
+	return statuses
+}
+
+// SeriesKey 由会议标题得到默认的会议系列：去掉数字、空白和标点后转为小写，
+// 使“产品周会 3/14”和“产品周会 3/21”属于同一系列
+func SeriesKey(title string) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, title)
+	if key == "" {
+		return strings.ToLower(strings.TrimSpace(title))
+	}
+	return key
+}
+
+// ActionItemService 维护待办台账：同一系列会议中反复提取的待办事项合并为一个条目，
+// 按每次会议的讨论标记为延续、更新或完成
+type ActionItemService struct {
+	store *storage.Store
+}
+
+// NewActionItemService 创建ActionItemService实例
+func NewActionItemService(store *storage.Store) *ActionItemService {
+	return &ActionItemService{store: store}
+}
+
+// list 读取工作区的全部条目
+func (s *ActionItemService) list(workspaceID string) ([]*models.ActionItem, error) {
+	all, err := s.store.ActionItems.List()
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeStorageFailed, "读取待办台账失败", err)
+	}
+	var items []*models.ActionItem
+	for _, item := range all {
+		if item.WorkspaceID == workspaceID {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// OpenItems 返回会议系列中未完成的条目，按更新时间倒序，最多maxPromptOpenItems项
+func (s *ActionItemService) OpenItems(workspaceID, series string) ([]*models.ActionItem, error) {
+	items, err := s.list(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	var open []*models.ActionItem
+	for _, item := range items {
+		if item.Series == series && item.Status == models.ActionItemOpen {
+			open = append(open, item)
+		}
+	}
+	sort.SliceStable(open, func(i, j int) bool { return open[i].UpdatedAt.After(open[j].UpdatedAt) })
+	if len(open) > maxPromptOpenItems {
+		open = open[:maxPromptOpenItems]
+	}
+	return open, nil
+}
+
+// Reconcile 将会议的待办事项与系列中之前未完成的条目对应起来，在待办事项上标注所属条目和延续关系，
+// 返回需要保存的新建和修改的条目。open为分析时提供给模型的条目，analysis为分析结果。
+//
+// 模型标注的对应关系在负责人一致（或一方为空）时采用，模型认为条目有更新时允许更换负责人；
+// 模型没有标注时，负责人相同且描述足够相似的未完成条目视为同一条目，llm配置了向量模型时按描述向量比较，
+// 换了说法的同一件事也能对应上。没有对应待办事项的条目按模型给出的情况记录为完成、更新或延续
+func (s *ActionItemService) Reconcile(ctx context.Context, llm *DeepSeekService, meeting *models.Meeting, open []*models.ActionItem, analysis *Analysis) []*models.ActionItem {
+	now := time.Now()
+	similarity, threshold := descriptionSimilarity(ctx, llm, open, meeting.TodoItems)
+	matched := make([]bool, len(open))
+	status := func(j int) string {
+		if j < len(analysis.PreviousItems) {
+			return analysis.PreviousItems[j]
+		}
+		return ""
+	}
+
+	var changed []*models.ActionItem
+	for i := range meeting.TodoItems {
+		todo := &meeting.TodoItems[i]
+		j := -1
+		if i < len(analysis.TodoItems) {
+			if ref := analysis.TodoItems[i].PreviousItem - 1; ref >= 0 && ref < len(open) && !matched[ref] &&
+				(compatibleAssignee(open[ref].Assignee, todo.Assignee) || status(ref) == models.ContinuityUpdated) {
+				j = ref
+			}
+		}
+		if j < 0 {
+			j = similarOpenItem(open, matched, todo, threshold, func(j int) float64 { return similarity(j, i) })
+		}
+
+		if j < 0 {
+			item := &models.ActionItem{
+				ID:             uuid.New().String(),
+				WorkspaceID:    meeting.WorkspaceID,
+				Series:         meeting.Series,
+				Description:    todo.Description,
+				Assignee:       todo.Assignee,
+				DueDate:        todo.DueDate,
+				Status:         models.ActionItemOpen,
+				FirstMeetingID: meeting.ID,
+				CreatedAt:      now,
+			}
+			if todo.Status == "completed" {
+				item.Status = models.ActionItemCompleted
+			}
+			recordEvent(item, meeting, models.ContinuityNew, todo.Description, now)
+			todo.ActionItemID, todo.Continuity = item.ID, models.ContinuityNew
+			changed = append(changed, item)
+			continue
+		}
+
+		matched[j] = true
+		item := *open[j]
+		kind := status(j)
+		if todo.Status == "completed" {
+			kind = models.ContinuityCompleted
+		} else if kind == "" {
+			kind = models.ContinuityCarriedOver
+		}
+		item.Description = todo.Description
+		if todo.Assignee != "" {
+			item.Assignee = todo.Assignee
+		}
+		if !todo.DueDate.IsZero() {
+			item.DueDate = todo.DueDate
+		}
+		if kind == models.ContinuityCompleted {
+			item.Status = models.ActionItemCompleted
+			todo.Status = "completed"
+		}
+		recordEvent(&item, meeting, kind, todo.Description, now)
+		todo.ActionItemID, todo.Continuity = item.ID, kind
+		changed = append(changed, &item)
+	}
+
+	// 本次会议提到但没有再次列为待办事项的条目
+	for j, previous := range open {
+		kind := status(j)
+		if matched[j] || kind == "" {
+			continue
+		}
+		item := *previous
+		if kind == models.ContinuityCompleted {
+			item.Status = models.ActionItemCompleted
+		}
+		recordEvent(&item, meeting, kind, item.Description, now)
+		changed = append(changed, &item)
+	}
+	return changed
+}
+
+// Save 保存Reconcile返回的条目。已有的条目在存储锁内合并本次会议的变化，
+// 同一系列的会议同时分析时不会覆盖彼此追加的历史记录；条目一旦完成不会因其他会议重新打开
+func (s *ActionItemService) Save(items []*models.ActionItem) error {
+	for _, item := range items {
+		_, err := s.store.ActionItems.Update(item.ID, func(current *models.ActionItem) error {
+			mergeActionItem(current, item)
+			return nil
+		})
+		if errors.Is(err, storage.ErrNotFound) {
+			err = s.store.ActionItems.Put(item.ID, item)
+		}
+		if err != nil {
+			return apperr.Wrap(apperr.CodeStorageFailed, "保存待办台账失败", err)
+		}
+	}
+	return nil
+}
+
+// mergeActionItem 将Reconcile修改后的条目副本合并到当前保存的条目：追加副本最后一条历史记录并更新描述等内容
+func mergeActionItem(current, changed *models.ActionItem) {
+	if len(changed.History) > 0 {
+		current.History = append(current.History, changed.History[len(changed.History)-1])
+	}
+	current.Description = changed.Description
+	current.Assignee = changed.Assignee
+	current.DueDate = changed.DueDate
+	if changed.Status == models.ActionItemCompleted {
+		current.Status = models.ActionItemCompleted
+	}
+	current.LastMeetingID = changed.LastMeetingID
+	current.UpdatedAt = changed.UpdatedAt
+}
+
+// Get 读取工作区中的条目及其历史记录
+func (s *ActionItemService) Get(workspaceID, id string) (*models.ActionItem, error) {
+	item, err := s.store.ActionItems.Get(id)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && item.WorkspaceID != workspaceID) {
+		return nil, ErrActionItemNotFound
+	}
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeStorageFailed, "读取待办台账失败", err)
+	}
+	return item, nil
+}
+
+// Ledger 返回工作区的全部条目（包括已完成的），按条目ID索引
+func (s *ActionItemService) Ledger(workspaceID string) (map[string]*models.ActionItem, error) {
+	items, err := s.list(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	ledger := make(map[string]*models.ActionItem, len(items))
+	for _, item := range items {
+		ledger[item.ID] = item
+	}
+	return ledger, nil
+}
+
+// AssigneeActionItems 一个负责人未完成的条目
+type AssigneeActionItems struct {
+	Assignee string               `json:"assignee"` // 为空表示没有负责人的条目
+	Items    []*models.ActionItem `json:"items"`
+}
+
+// OpenByAssignee 按负责人列出工作区全部会议中未完成的条目，负责人按名称排序，没有负责人的条目在最后；
+// 每个负责人的条目按截止日期排序，没有截止日期的在后。assignee不为空时只列出该负责人（不区分大小写）
+func (s *ActionItemService) OpenByAssignee(workspaceID, assignee string) ([]AssigneeActionItems, error) {
+	items, err := s.list(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]*AssigneeActionItems{}
+	for _, item := range items {
+		if item.Status != models.ActionItemOpen {
+			continue
+		}
+		name := strings.TrimSpace(item.Assignee)
+		if assignee != "" && !strings.EqualFold(name, strings.TrimSpace(assignee)) {
+			continue
+		}
+		key := strings.ToLower(name)
+		if groups[key] == nil {
+			groups[key] = &AssigneeActionItems{Assignee: name}
+		}
+		groups[key].Items = append(groups[key].Items, item)
+	}
+
+	result := make([]AssigneeActionItems, 0, len(groups))
+	for _, group := range groups {
+		sort.SliceStable(group.Items, func(i, j int) bool {
+			a, b := group.Items[i], group.Items[j]
+			if a.DueDate.IsZero() != b.DueDate.IsZero() {
+				return b.DueDate.IsZero()
+			}
+			if !a.DueDate.Equal(b.DueDate) {
+				return a.DueDate.Before(b.DueDate)
+			}
+			return a.CreatedAt.Before(b.CreatedAt)
+		})
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if (result[i].Assignee == "") != (result[j].Assignee == "") {
+			return result[j].Assignee == ""
+		}
+		return result[i].Assignee < result[j].Assignee
+	})
+	return result, nil
+}
+
+// recordEvent 在条目的历史记录中追加一次会议中的变化
+func recordEvent(item *models.ActionItem, meeting *models.Meeting, kind, description string, now time.Time) {
+	item.History = append(append([]models.ActionItemEvent{}, item.History...), models.ActionItemEvent{
+		MeetingID:    meeting.ID,
+		MeetingTitle: meeting.Title,
+		Date:         meeting.Date,
+		Kind:         kind,
+		Description:  description,
+	})
+	item.LastMeetingID = meeting.ID
+	item.UpdatedAt = now
+}
+
+// descriptionSimilarity 返回未完成条目open[j]与待办事项todos[i]描述的相似度函数，以及认定为同一条目的下限。
+// llm配置了向量模型时使用描述向量的余弦相似度；未配置或向量接口失败时使用检索词的Dice系数
+func descriptionSimilarity(ctx context.Context, llm *DeepSeekService, open []*models.ActionItem, todos []models.TodoItem) (func(j, i int) float64, float64) {
+	lexical := func(j, i int) float64 { return textSimilarity(open[j].Description, todos[i].Description) }
+	if llm == nil || llm.EmbeddingModel() == "" || len(open) == 0 || len(todos) == 0 {
+		return lexical, minCarryOverSimilarity
+	}
+
+	texts := make([]string, 0, len(open)+len(todos))
+	for _, item := range open {
+		texts = append(texts, item.Description)
+	}
+	for _, todo := range todos {
+		texts = append(texts, todo.Description)
+	}
+	vectors, err := llm.Embed(ctx, texts)
+	if err != nil {
+		slog.WarnContext(ctx, "计算待办事项向量失败，按文本相似度对应之前的条目", "error", err)
+		return lexical, minCarryOverSimilarity
+	}
+	return func(j, i int) float64 { return cosine(vectors[j], vectors[len(open)+i]) }, minCarryOverCosine
+}
+
+// similarOpenItem 返回负责人相同且描述最相似（score不低于threshold）的未匹配条目的下标，没有足够相似的条目时返回-1
+func similarOpenItem(open []*models.ActionItem, matched []bool, todo *models.TodoItem, threshold float64, score func(j int) float64) int {
+	best, bestScore := -1, threshold
+	for j, item := range open {
+		if matched[j] || !strings.EqualFold(strings.TrimSpace(item.Assignee), strings.TrimSpace(todo.Assignee)) {
+			continue
+		}
+		if s := score(j); s >= bestScore {
+			best, bestScore = j, s
+		}
+	}
+	return best
+}
+
+// compatibleAssignee 两个负责人相同（不区分大小写）或其中一个为空
+func compatibleAssignee(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	return a == "" || b == "" || strings.EqualFold(a, b)
+}
+
+// textSimilarity 两段文本检索词集合的Dice系数，取值0到1
+func textSimilarity(a, b string) float64 {
+	set := func(text string) map[string]bool {
+		terms := map[string]bool{}
+		for _, term := range tokenize(text) {
+			terms[term] = true
+		}
+		return terms
+	}
+	x, y := set(a), set(b)
+	if len(x)+len(y) == 0 {
+		return 0
+	}
+	common := 0
+	for term := range x {
+		if y[term] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(x)+len(y))
+}
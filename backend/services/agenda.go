@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"meeting-mm/models"
+
+	"github.com/google/uuid"
+)
+
+// agendaMarker 议程列表项的编号或项目符号，如“1.”“2、”“(3)”“-”“•”
+var agendaMarker = regexp.MustCompile(`^\s*(?:[(（]?\d{1,2}[.、)）]|[-*•·])\s*`)
+
+// ParseAgenda 将议程文本拆分为议程项，每行一项，去掉行首的编号和项目符号。
+// listOnly为true时只取带编号或项目符号的行，用于从会议邀请的说明中识别议程
+func ParseAgenda(text string, listOnly bool) []models.AgendaItem {
+	var items []models.AgendaItem
+	for _, line := range strings.Split(text, "\n") {
+		marker := agendaMarker.FindString(line)
+		if listOnly && marker == "" {
+			continue
+		}
+		if title := strings.TrimSpace(line[len(marker):]); title != "" {
+			items = append(items, models.AgendaItem{ID: uuid.New().String(), Title: title})
+		}
+	}
+	return items
+}
+
+// AgendaResult 一个议程项的分析结果
+type AgendaResult struct {
+	Status  string `json:"status"` // models.AgendaCovered 或 models.AgendaDeferred
+	Summary string `json:"summary"`
+}
+
+// agendaPrompt 有议程时追加在分析提示词中的输出要求
+const agendaPrompt = `
+
+本次会议有议程，请按议程整理分析结果，在上述JSON中增加：
+- "agenda"：数组，每个议程项一项，格式为 {"item": 议程编号, "status": "covered（已讨论）或deferred（未讨论或推迟）", "summary": "该议程项的讨论摘要，推迟时说明原因"}
+- 每个待办事项和决策点增加 "agendaItem"：所属的议程编号，与任何议程项都无关时为0`
+
+// agendaRef 模型返回的议程编号，兼容数字、数字字符串和null
+type agendaRef int
+
+func (r *agendaRef) UnmarshalJSON(data []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		var s string
+		if json.Unmarshal(data, &s) != nil {
+			return err
+		}
+		n = json.Number(strings.TrimSpace(s))
+	}
+	i, err := strconv.Atoi(n.String())
+	if err != nil {
+		i = 0 // null、空字符串或无法识别的编号视为与议程无关
+	}
+	*r = agendaRef(i)
+	return nil
+}
+
+// agendaOutput 模型返回的一个议程项的结果
+type agendaOutput struct {
+	Item    agendaRef `json:"item"`
+	Status  string    `json:"status"`
+	Summary string    `json:"summary"`
+}
+
+// agendaPromptItems 返回提示词中编号的议程列表
+func agendaPromptItems(items []models.AgendaItem) string {
+	var b strings.Builder
+	for i, item := range items {
+		fmt.Fprintf(&b, "%d. %s", i+1, item.Title)
+		if item.Description != "" {
+			fmt.Fprintf(&b, "：%s", item.Description)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// agendaResults 将模型返回的议程结果按议程顺序排列。模型没有提到的议程项视为推迟，
+// 状态无法识别时按是否有讨论摘要判断
+func agendaResults(count int, results []agendaOutput) []AgendaResult {
+	if count == 0 {
+		return nil
+	}
+	agenda := make([]AgendaResult, count)
+	for i := range agenda {
+		agenda[i].Status = models.AgendaDeferred
+	}
+	for _, result := range results {
+		i := int(result.Item) - 1
+		if i < 0 || i >= count {
+			continue
+		}
+		status := strings.ToLower(strings.TrimSpace(result.Status))
+		if status != models.AgendaCovered && status != models.AgendaDeferred {
+			status = models.AgendaDeferred
+			if strings.TrimSpace(result.Summary) != "" {
+				status = models.AgendaCovered
+			}
+		}
+		agenda[i] = AgendaResult{Status: status, Summary: strings.TrimSpace(result.Summary)}
+	}
+	return agenda
+}
@@ -0,0 +1,441 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"meeting-mm/apperr"
+	"meeting-mm/metrics"
+	"meeting-mm/models"
+	"meeting-mm/storage"
+	"meeting-mm/tracing"
+)
+
+// 会议问答的检索方式
+const (
+	RetrievalEmbedding = "embedding" // 按向量相似度检索
+	RetrievalBM25      = "bm25"      // 未配置向量模型或向量接口不可用时按关键词检索
+)
+
+const (
+	// DefaultAskTopK 默认检索的片段数量
+	DefaultAskTopK = 6
+	// MaxAskTopK 检索的片段数量上限
+	MaxAskTopK = 20
+	// maxChunkRunes 一个片段的长度上限（字符），单个转录片段超过时不再拆分
+	maxChunkRunes = 500
+)
+
+// ErrInvalidQuestion 问题为空或过长
+var ErrInvalidQuestion = errors.New("无效的问题")
+
+// noAnswer 没有检索到相关片段时的回答
+const noAnswer = "没有在会议记录中找到相关内容。"
+
+// Citation 回答引用的会议片段
+type Citation struct {
+	Index        int       `json:"index"` // 回答中的引用编号，如[1]
+	MeetingID    string    `json:"meetingId"`
+	MeetingTitle string    `json:"meetingTitle"`
+	MeetingDate  time.Time `json:"meetingDate"`
+	StartTime    float64   `json:"startTime"` // 以秒为单位；会议记录没有时间戳时与EndTime均为0
+	EndTime      float64   `json:"endTime"`
+	Text         string    `json:"text"`
+}
+
+// AskResult 会议问答的结果
+type AskResult struct {
+	Answer    string     `json:"answer"`
+	Citations []Citation `json:"citations"` // 回答中实际引用的片段，按编号排序
+	Retrieval string     `json:"retrieval"` // embedding或bm25
+}
+
+// transcriptChunk 检索的单位：一段连续的会议记录
+type transcriptChunk struct {
+	meeting   *models.Meeting
+	startTime float64
+	endTime   float64
+	text      string
+}
+
+// AskService 在工作区保存的会议记录中检索相关片段，由模型根据片段回答问题。
+// 片段的向量保存在search_index集合中，会议记录变化或更换向量模型后在下次提问时重新计算
+type AskService struct {
+	store *storage.Store
+}
+
+// NewAskService 创建AskService实例
+func NewAskService(store *storage.Store) *AskService {
+	return &AskService{store: store}
+}
+
+// Ask 回答关于工作区会议的问题。配置了向量模型时按向量检索，向量接口失败时退回BM25检索；
+// 回答中的[n]对应Citations中的片段
+func (s *AskService) Ask(ctx context.Context, llm *DeepSeekService, workspaceID, question string, topK int) (result *AskResult, err error) {
+	ctx, span := tracing.Start(ctx, "AskService.Ask")
+	defer span.Finish(&err)
+	defer func(start time.Time) { metrics.ObserveStage(metrics.StageAsk, start, err) }(time.Now())
+
+	question = strings.TrimSpace(question)
+	if question == "" || len([]rune(question)) > 500 {
+		return nil, fmt.Errorf("%w: 问题不能为空且不能超过500字", ErrInvalidQuestion)
+	}
+	if topK <= 0 {
+		topK = DefaultAskTopK
+	}
+	topK = min(topK, MaxAskTopK)
+
+	all, err := s.store.Meetings.List()
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeStorageFailed, "读取会议列表失败", err)
+	}
+	var meetings []*models.Meeting
+	for _, meeting := range all {
+		if meeting.WorkspaceID == workspaceID {
+			meetings = append(meetings, meeting)
+		}
+	}
+
+	result = &AskResult{Citations: []Citation{}, Retrieval: RetrievalBM25}
+	var ranked []transcriptChunk
+	if llm.EmbeddingModel() != "" {
+		ranked, err = s.searchEmbedding(ctx, llm, workspaceID, meetings, question, topK)
+		if err == nil {
+			result.Retrieval = RetrievalEmbedding
+		} else {
+			slog.WarnContext(ctx, "向量检索失败，改用BM25检索", "error", err)
+		}
+	}
+	if result.Retrieval == RetrievalBM25 {
+		var chunks []transcriptChunk
+		for _, meeting := range meetings {
+			chunks = append(chunks, chunkMeeting(meeting)...)
+		}
+		ranked = searchBM25(chunks, question, topK)
+	}
+	span.SetAttribute("ask.retrieval", result.Retrieval)
+	span.SetAttribute("ask.chunks", len(ranked))
+
+	if len(ranked) == 0 {
+		result.Answer = noAnswer
+		return result, nil
+	}
+
+	answer, err := llm.chat(ctx, "你是一个会议知识库助手，只根据提供的会议记录片段回答问题，不编造片段中没有的内容。", askPrompt(question, ranked), 1500)
+	if err != nil {
+		return nil, err
+	}
+	result.Answer = strings.TrimSpace(answer)
+	for _, n := range citedSources(result.Answer, len(ranked)) {
+		chunk := ranked[n-1]
+		result.Citations = append(result.Citations, Citation{
+			Index:        n,
+			MeetingID:    chunk.meeting.ID,
+			MeetingTitle: chunk.meeting.Title,
+			MeetingDate:  chunk.meeting.Date,
+			StartTime:    chunk.startTime,
+			EndTime:      chunk.endTime,
+			Text:         chunk.text,
+		})
+	}
+	return result, nil
+}
+
+// searchEmbedding 按与问题向量的余弦相似度排序片段，缺少或过期的会议向量先计算并保存
+func (s *AskService) searchEmbedding(ctx context.Context, llm *DeepSeekService, workspaceID string, meetings []*models.Meeting, question string, topK int) ([]transcriptChunk, error) {
+	query, err := llm.Embed(ctx, []string{question})
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		chunk transcriptChunk
+		score float64
+	}
+	var results []scored
+	for _, meeting := range meetings {
+		chunks := chunkMeeting(meeting)
+		if len(chunks) == 0 {
+			continue
+		}
+		vectors, err := s.meetingVectors(ctx, llm, workspaceID, meeting, chunks)
+		if err != nil {
+			return nil, err
+		}
+		for i, chunk := range chunks {
+			results = append(results, scored{chunk, cosine(query[0], vectors[i])})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	ranked := make([]transcriptChunk, 0, topK)
+	for _, result := range results {
+		if len(ranked) == topK || result.score <= 0 {
+			break
+		}
+		ranked = append(ranked, result.chunk)
+	}
+	return ranked, nil
+}
+
+// meetingVectors 返回会议片段的向量：索引中的向量仍然有效时直接使用，否则重新计算并保存
+func (s *AskService) meetingVectors(ctx context.Context, llm *DeepSeekService, workspaceID string, meeting *models.Meeting, chunks []transcriptChunk) ([][]float32, error) {
+	hash := chunksHash(chunks)
+	index, err := s.store.SearchIndex.Get(meeting.ID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, apperr.Wrap(apperr.CodeStorageFailed, "读取检索索引失败", err)
+	}
+	if index != nil && index.Hash == hash && index.Model == llm.EmbeddingModel() && len(index.Vectors) == len(chunks) {
+		return index.Vectors, nil
+	}
+
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.text
+	}
+	vectors, err := llm.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	index = &models.SearchIndex{
+		MeetingID:   meeting.ID,
+		WorkspaceID: workspaceID,
+		Model:       llm.EmbeddingModel(),
+		Hash:        hash,
+		Vectors:     vectors,
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.store.SearchIndex.Put(meeting.ID, index); err != nil {
+		slog.WarnContext(ctx, "保存检索索引失败", "meeting_id", meeting.ID, "error", err)
+	}
+	return vectors, nil
+}
+
+// chunkMeeting 将会议记录切分为片段：有转录片段时按时间顺序合并相邻片段，片段带有起止时间；
+// 否则按段落合并全文，片段没有时间
+func chunkMeeting(meeting *models.Meeting) []transcriptChunk {
+	var chunks []transcriptChunk
+	var current *transcriptChunk
+	add := func(text string, start, end float64) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		if current != nil && len([]rune(current.text))+len([]rune(text)) > maxChunkRunes {
+			chunks = append(chunks, *current)
+			current = nil
+		}
+		if current == nil {
+			current = &transcriptChunk{meeting: meeting, startTime: start, endTime: end, text: text}
+			return
+		}
+		current.text += "\n" + text
+		current.endTime = end
+	}
+
+	if len(meeting.Segments) > 0 {
+		for _, segment := range meeting.Segments {
+			text := strings.TrimSpace(segment.Text)
+			if segment.Speaker != "" && text != "" {
+				text = segment.Speaker + ": " + text
+			}
+			add(text, segment.StartTime, segment.EndTime)
+		}
+	} else {
+		for _, paragraph := range strings.Split(meeting.Transcript, "\n") {
+			runes := []rune(strings.TrimSpace(paragraph))
+			for len(runes) > maxChunkRunes {
+				add(string(runes[:maxChunkRunes]), 0, 0)
+				runes = runes[maxChunkRunes:]
+			}
+			add(string(runes), 0, 0)
+		}
+	}
+	if current != nil {
+		chunks = append(chunks, *current)
+	}
+	return chunks
+}
+
+// chunksHash 全部片段文本的摘要，用于判断保存的向量是否过期
+func chunksHash(chunks []transcriptChunk) string {
+	h := sha256.New()
+	for _, chunk := range chunks {
+		h.Write([]byte(chunk.text))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cosine 两个向量的余弦相似度，长度不同或为零向量时返回0
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// BM25的参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// searchBM25 按BM25得分排序片段，只返回与问题有共同词的片段
+func searchBM25(chunks []transcriptChunk, question string, topK int) []transcriptChunk {
+	if len(chunks) == 0 {
+		return nil
+	}
+	terms := map[string]bool{}
+	for _, term := range tokenize(question) {
+		terms[term] = true
+	}
+
+	docs := make([]map[string]int, len(chunks))
+	lengths := make([]int, len(chunks))
+	docFreq := map[string]int{}
+	total := 0
+	for i, chunk := range chunks {
+		tokens := tokenize(chunk.text)
+		docs[i] = map[string]int{}
+		for _, token := range tokens {
+			docs[i][token]++
+		}
+		for term := range docs[i] {
+			docFreq[term]++
+		}
+		lengths[i] = len(tokens)
+		total += len(tokens)
+	}
+	avgLength := float64(total) / float64(len(chunks))
+	if avgLength == 0 {
+		return nil
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	var results []scored
+	for i, doc := range docs {
+		score := 0.0
+		for term := range terms {
+			tf := float64(doc[term])
+			if tf == 0 {
+				continue
+			}
+			n := float64(docFreq[term])
+			idf := math.Log(1 + (float64(len(chunks))-n+0.5)/(n+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(lengths[i])/avgLength))
+		}
+		if score > 0 {
+			results = append(results, scored{i, score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	ranked := make([]transcriptChunk, 0, min(topK, len(results)))
+	for _, result := range results[:min(topK, len(results))] {
+		ranked = append(ranked, chunks[result.index])
+	}
+	return ranked
+}
+
+// tokenize 将文本切分为检索用的词：字母和数字按单词切分并转为小写，汉字等按相邻两字切分，单独的汉字作为一个词
+func tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var han []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushHan := func() {
+		if len(han) == 1 {
+			tokens = append(tokens, string(han))
+		}
+		for i := 0; i+1 < len(han); i++ {
+			tokens = append(tokens, string(han[i:i+2]))
+		}
+		han = han[:0]
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return tokens
+}
+
+// askPrompt 问答提示词：编号的会议片段和问题
+func askPrompt(question string, chunks []transcriptChunk) string {
+	var b strings.Builder
+	b.WriteString("以下是从会议记录中检索到的片段：\n\n")
+	for i, chunk := range chunks {
+		fmt.Fprintf(&b, "[%d] 会议《%s》（%s", i+1, chunk.meeting.Title, chunk.meeting.Date.Format("2006-01-02"))
+		if chunk.endTime > chunk.startTime {
+			fmt.Fprintf(&b, "，%s–%s", formatSegmentTime(chunk.startTime), formatSegmentTime(chunk.endTime))
+		}
+		fmt.Fprintf(&b, "）\n%s\n\n", chunk.text)
+	}
+	fmt.Fprintf(&b, `请根据以上片段回答问题：%s
+
+要求：
+1. 只使用片段中的信息，片段中没有答案时直接说明没有找到
+2. 在引用片段内容的句子后用[编号]标注来源，如[1]或[2][3]
+3. 回答简洁，使用与问题相同的语言`, question)
+	return b.String()
+}
+
+// citationMarker 回答中的引用标记，如[1]、[1,3]、[2、4]
+var citationMarker = regexp.MustCompile(`\[(\d+(?:\s*[,，、]\s*\d+)*)\]`)
+
+// citedSources 回答中引用的片段编号，去重后升序，忽略超出范围的编号
+func citedSources(answer string, count int) []int {
+	seen := map[int]bool{}
+	var cited []int
+	for _, match := range citationMarker.FindAllStringSubmatch(answer, -1) {
+		for _, part := range strings.FieldsFunc(match[1], func(r rune) bool { return r == ',' || r == '，' || r == '、' || r == ' ' }) {
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 1 || n > count || seen[n] {
+				continue
+			}
+			seen[n] = true
+			cited = append(cited, n)
+		}
+	}
+	sort.Ints(cited)
+	return cited
+}
@@ -0,0 +1,534 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"meeting-mm/config"
+	"meeting-mm/models"
+	"meeting-mm/storage"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// APIKeyPrefix API密钥的固定前缀，用于区分API密钥和登录令牌
+const APIKeyPrefix = "mm_"
+
+// 认证相关错误
+var (
+	ErrUnauthorized       = errors.New("未登录或凭据无效")
+	ErrInvalidCredentials = errors.New("邮箱或密码错误")
+	ErrSignupClosed       = errors.New("未开放注册，请联系工作区管理员添加账号")
+	ErrEmailTaken         = errors.New("该邮箱已注册")
+	ErrForbidden          = errors.New("没有权限执行该操作")
+	ErrInvalidEmail       = errors.New("无效的邮箱")
+	ErrWeakPassword       = errors.New("密码至少需要8个字符")
+)
+
+// Principal 通过认证的调用方
+type Principal struct {
+	UserID      string
+	WorkspaceID string
+	Role        string
+	APIKeyID    string // 使用API密钥认证时的密钥ID
+}
+
+// AuthService 管理用户、登录令牌和API密钥
+type AuthService struct {
+	store       *storage.Store
+	secret      []byte
+	tokenTTL    time.Duration
+	allowSignup bool
+
+	mu sync.Mutex // 保证邮箱唯一
+
+	// dummyHash 邮箱不存在时用于比较的密码哈希，使登录的响应时间不暴露邮箱是否已注册
+	dummyOnce sync.Once
+	dummyHash []byte
+}
+
+// NewAuthService 创建AuthService实例，cfg.AuthSecret不能为空
+func NewAuthService(cfg *config.Config, store *storage.Store) *AuthService {
+	ttl := cfg.AuthTokenTTL
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+	s := &AuthService{
+		store:       store,
+		secret:      []byte(cfg.AuthSecret),
+		tokenTTL:    ttl,
+		allowSignup: cfg.AuthAllowSignup,
+	}
+	if err := s.migrateAPIKeys(); err != nil {
+		slog.Error("迁移API密钥失败，之前创建的密钥可能无法使用", "error", err)
+	}
+	return s
+}
+
+// Register 注册用户并为其创建工作区。未开放注册时只允许注册第一个用户
+func (s *AuthService) Register(email, password, name, workspaceName string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.store.Users.List()
+	if err != nil {
+		return nil, err
+	}
+	if !s.allowSignup && len(users) > 0 {
+		return nil, ErrSignupClosed
+	}
+
+	if workspaceName == "" {
+		workspaceName = name + "的工作区"
+	}
+	now := time.Now()
+	// 第一个工作区属于部署者，可以使用服务器配置的凭据；之后注册的工作区只能使用自己的凭据
+	workspace := &models.Workspace{
+		ID:                uuid.New().String(),
+		Name:              workspaceName,
+		ServerCredentials: len(users) == 0,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	user, err := s.newUser(users, workspace.ID, email, password, name, models.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Workspaces.Put(workspace.ID, workspace); err != nil {
+		return nil, err
+	}
+	if err := s.store.Users.Put(user.ID, user); err != nil {
+		return nil, err
+	}
+
+	// 启用认证之前的会议和同步任务没有归属，归入第一个工作区
+	if len(users) == 0 {
+		if err := s.claimUnscoped(workspace.ID); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// claimUnscoped 将没有工作区的会议和同步任务归入workspaceID
+func (s *AuthService) claimUnscoped(workspaceID string) error {
+	meetings, err := s.store.Meetings.List()
+	if err != nil {
+		return err
+	}
+	for _, meeting := range meetings {
+		if meeting.WorkspaceID != "" {
+			continue
+		}
+		meeting.WorkspaceID = workspaceID
+		if err := s.store.Meetings.Put(meeting.ID, meeting); err != nil {
+			return err
+		}
+	}
+
+	jobs, err := s.store.NotionSyncs.List()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.WorkspaceID != "" {
+			continue
+		}
+		job.WorkspaceID = workspaceID
+		if err := s.store.NotionSyncs.Put(job.ID, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddMember 在工作区中添加成员，只有工作区所有者可以添加
+func (s *AuthService) AddMember(p *Principal, email, password, name string) (*models.User, error) {
+	if p.Role != models.RoleOwner {
+		return nil, ErrForbidden
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.store.Users.List()
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.newUser(users, p.WorkspaceID, email, password, name, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Users.Put(user.ID, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// newUser 校验并构建新用户，users为现有用户，用于检查邮箱是否重复
+func (s *AuthService) newUser(users []*models.User, workspaceID, email, password, name, role string) (*models.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+	if len(password) < 8 {
+		return nil, ErrWeakPassword
+	}
+	for _, user := range users {
+		if user.Email == email {
+			return nil, ErrEmailTaken
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("生成密码哈希失败: %w", err)
+	}
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	return &models.User{
+		ID:           uuid.New().String(),
+		WorkspaceID:  workspaceID,
+		Email:        email,
+		Name:         strings.TrimSpace(name),
+		Role:         role,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// Login 校验邮箱和密码，返回登录令牌及其过期时间
+func (s *AuthService) Login(email, password string) (string, time.Time, *models.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	users, err := s.store.Users.List()
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+
+	var user *models.User
+	for _, u := range users {
+		if u.Email == email {
+			user = u
+			break
+		}
+	}
+	// 邮箱不存在时同样计算一次bcrypt，使响应时间与密码错误时相同
+	if user == nil {
+		bcrypt.CompareHashAndPassword(s.dummyPasswordHash(), []byte(password))
+		return "", time.Time{}, nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return "", time.Time{}, nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.tokenTTL)
+	token, err := signToken(tokenClaims{
+		Subject:     user.ID,
+		WorkspaceID: user.WorkspaceID,
+		Role:        user.Role,
+		Version:     user.TokenVersion,
+		IssuedAt:    now.Unix(),
+		ExpiresAt:   expiresAt.Unix(),
+	}, s.secret)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+	return token, expiresAt, user, nil
+}
+
+// dummyPasswordHash 返回邮箱不存在时用于比较的密码哈希，第一次使用时生成
+func (s *AuthService) dummyPasswordHash() []byte {
+	s.dummyOnce.Do(func() {
+		buf := make([]byte, 16)
+		rand.Read(buf)
+		s.dummyHash, _ = bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(buf)), bcrypt.DefaultCost)
+	})
+	return s.dummyHash
+}
+
+// Logout 使用户之前签发的全部登录令牌失效。credential不是有效的登录令牌（如API密钥）时不做任何操作
+func (s *AuthService) Logout(credential string) error {
+	claims, err := parseToken(credential, s.secret, time.Now())
+	if err != nil {
+		return nil
+	}
+	_, err = s.store.Users.Update(claims.Subject, func(user *models.User) error {
+		if user.TokenVersion == claims.Version {
+			user.TokenVersion++
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Authenticate 校验登录令牌或API密钥，返回调用方
+func (s *AuthService) Authenticate(credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrUnauthorized
+	}
+	if strings.HasPrefix(credential, APIKeyPrefix) {
+		return s.authenticateAPIKey(credential)
+	}
+
+	claims, err := parseToken(credential, s.secret, time.Now())
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	// 用户被删除或已退出登录后令牌随之失效；工作区和角色以保存的用户为准，角色变更立即生效
+	user, err := s.store.Users.Get(claims.Subject)
+	if err != nil || user.TokenVersion != claims.Version {
+		return nil, ErrUnauthorized
+	}
+	return &Principal{
+		UserID:      user.ID,
+		WorkspaceID: user.WorkspaceID,
+		Role:        user.Role,
+	}, nil
+}
+
+// authenticateAPIKey 按哈希读取未吊销的API密钥，并记录最近使用时间
+func (s *AuthService) authenticateAPIKey(key string) (*Principal, error) {
+	hash := hashAPIKey(key)
+	apiKey, err := s.store.APIKeys.Get(apiKeyID(hash))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	if apiKey.Hash != hash || apiKey.RevokedAt != nil {
+		return nil, ErrUnauthorized
+	}
+	user, err := s.store.Users.Get(apiKey.UserID)
+	if err != nil || user.WorkspaceID != apiKey.WorkspaceID {
+		return nil, ErrUnauthorized
+	}
+
+	// 最近使用时间精确到分钟即可，避免每个请求都写文件
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > time.Minute {
+		s.store.APIKeys.Update(apiKey.ID, func(k *models.APIKey) error {
+			k.LastUsedAt = &now
+			return nil
+		})
+	}
+
+	return &Principal{
+		UserID:      user.ID,
+		WorkspaceID: apiKey.WorkspaceID,
+		Role:        user.Role,
+		APIKeyID:    apiKey.ID,
+	}, nil
+}
+
+// migrateAPIKeys 将之前以随机ID保存的API密钥改为以哈希派生的ID保存，使认证时只需读取一个文件
+func (s *AuthService) migrateAPIKeys() error {
+	keys, err := s.store.APIKeys.List()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		id := apiKeyID(key.Hash)
+		if key.ID == id {
+			continue
+		}
+		oldID := key.ID
+		key.ID = id
+		if err := s.store.APIKeys.Put(id, key); err != nil {
+			return err
+		}
+		if err := s.store.APIKeys.Delete(oldID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateAPIKey 为调用方创建API密钥，返回的明文密钥只在创建时可见
+func (s *AuthService) CreateAPIKey(p *Principal, name string) (string, *models.APIKey, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("生成API密钥失败: %w", err)
+	}
+	key := APIKeyPrefix + hex.EncodeToString(buf)
+
+	hash := hashAPIKey(key)
+	apiKey := &models.APIKey{
+		ID:          apiKeyID(hash),
+		WorkspaceID: p.WorkspaceID,
+		UserID:      p.UserID,
+		Name:        strings.TrimSpace(name),
+		Prefix:      key[:len(APIKeyPrefix)+6],
+		Hash:        hash,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.store.APIKeys.Put(apiKey.ID, apiKey); err != nil {
+		return "", nil, err
+	}
+	return key, apiKey, nil
+}
+
+// ListAPIKeys 列出工作区的全部API密钥（包括已吊销的）
+func (s *AuthService) ListAPIKeys(workspaceID string) ([]*models.APIKey, error) {
+	keys, err := s.store.APIKeys.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*models.APIKey
+	for _, key := range keys {
+		if key.WorkspaceID == workspaceID {
+			result = append(result, key)
+		}
+	}
+	return result, nil
+}
+
+// RevokeAPIKey 吊销API密钥，只有密钥的创建者或工作区所有者可以吊销
+func (s *AuthService) RevokeAPIKey(p *Principal, id string) (*models.APIKey, error) {
+	return s.store.APIKeys.Update(id, func(key *models.APIKey) error {
+		if key.WorkspaceID != p.WorkspaceID {
+			return storage.ErrNotFound
+		}
+		if key.UserID != p.UserID && p.Role != models.RoleOwner {
+			return ErrForbidden
+		}
+		if key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+		}
+		return nil
+	})
+}
+
+// AudioSignature 生成会议录音公开链接的签名，Notion页面中的录音链接凭此签名在expires（Unix秒）之前免登录访问
+func AudioSignature(secret, meetingID string, expires int64) string {
+	return hmacSHA256([]byte(secret), "audio:"+meetingID+":"+strconv.FormatInt(expires, 10))
+}
+
+// TodoFeedSignature 生成个人待办日历订阅链接的签名，日历应用凭此签名免登录订阅。
+// token为工作区为该负责人保存的订阅令牌；负责人名称不区分大小写
+func TodoFeedSignature(secret, workspaceID, assignee, token string) string {
+	return hmacSHA256([]byte(secret), "todos:"+workspaceID+":"+todoFeedKey(assignee)+":"+token)
+}
+
+// todoFeedKey 订阅令牌按负责人名称（去掉首尾空白并转为小写）保存
+func todoFeedKey(assignee string) string {
+	return strings.ToLower(strings.TrimSpace(assignee))
+}
+
+// TodoFeedTokens 返回工作区中各负责人的订阅令牌，按负责人名称（小写）索引；
+// 还没有令牌的负责人生成新的令牌，已停用的负责人令牌为空
+func (s *AuthService) TodoFeedTokens(workspaceID string, assignees []string) (map[string]string, error) {
+	workspace, err := s.store.Workspaces.Get(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	missing := false
+	for _, assignee := range assignees {
+		if _, ok := workspace.TodoFeedTokens[todoFeedKey(assignee)]; !ok {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return workspace.TodoFeedTokens, nil
+	}
+
+	workspace, err = s.store.Workspaces.Update(workspaceID, func(workspace *models.Workspace) error {
+		for _, assignee := range assignees {
+			key := todoFeedKey(assignee)
+			if _, ok := workspace.TodoFeedTokens[key]; ok {
+				continue
+			}
+			token, err := newTodoFeedToken()
+			if err != nil {
+				return err
+			}
+			if workspace.TodoFeedTokens == nil {
+				workspace.TodoFeedTokens = map[string]string{}
+			}
+			workspace.TodoFeedTokens[key] = token
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workspace.TodoFeedTokens, nil
+}
+
+// TodoFeedToken 返回负责人当前的订阅令牌，没有令牌或已停用时返回空字符串
+func (s *AuthService) TodoFeedToken(workspaceID, assignee string) (string, error) {
+	workspace, err := s.store.Workspaces.Get(workspaceID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return workspace.TodoFeedTokens[todoFeedKey(assignee)], nil
+}
+
+// RotateTodoFeed 为负责人生成新的订阅令牌，之前的订阅链接失效；已停用的链接重新启用
+func (s *AuthService) RotateTodoFeed(workspaceID, assignee string) (string, error) {
+	token, err := newTodoFeedToken()
+	if err != nil {
+		return "", err
+	}
+	return token, s.setTodoFeedToken(workspaceID, assignee, token)
+}
+
+// RevokeTodoFeed 停用负责人的订阅链接，直到再次调用RotateTodoFeed
+func (s *AuthService) RevokeTodoFeed(workspaceID, assignee string) error {
+	return s.setTodoFeedToken(workspaceID, assignee, "")
+}
+
+func (s *AuthService) setTodoFeedToken(workspaceID, assignee, token string) error {
+	_, err := s.store.Workspaces.Update(workspaceID, func(workspace *models.Workspace) error {
+		if workspace.TodoFeedTokens == nil {
+			workspace.TodoFeedTokens = map[string]string{}
+		}
+		workspace.TodoFeedTokens[todoFeedKey(assignee)] = token
+		return nil
+	})
+	return err
+}
+
+// newTodoFeedToken 生成随机的订阅令牌
+func newTodoFeedToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成订阅令牌失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashAPIKey 计算API密钥的SHA-256哈希。密钥本身是高熵随机串，无需加盐
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyID 由密钥哈希派生API密钥的ID（哈希的前32位），认证时按ID直接读取密钥记录
+func apiKeyID(hash string) string {
+	if len(hash) > 32 {
+		return hash[:32]
+	}
+	return hash
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// errInvalidToken 令牌格式、签名或有效期不正确
+var errInvalidToken = errors.New("无效的登录令牌")
+
+// tokenClaims 登录令牌（HS256签名的JWT）中的声明
+type tokenClaims struct {
+	Subject     string `json:"sub"` // 用户ID
+	WorkspaceID string `json:"ws"`
+	Role        string `json:"role"`
+	Version     int    `json:"ver,omitempty"` // 签发时用户的TokenVersion
+	IssuedAt    int64  `json:"iat"`
+	ExpiresAt   int64  `json:"exp"`
+}
+
+// jwtHeader 固定的JWT头部
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// signToken 签发JWT
+func signToken(claims tokenClaims, secret []byte) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + hmacSHA256(secret, unsigned), nil
+}
+
+// parseToken 校验JWT签名和有效期并返回声明
+func parseToken(token string, secret []byte, now time.Time) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, errInvalidToken
+	}
+
+	expected := hmacSHA256(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, errInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errInvalidToken
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errInvalidToken
+	}
+	if claims.Subject == "" || now.Unix() >= claims.ExpiresAt {
+		return nil, errInvalidToken
+	}
+	return &claims, nil
+}
+
+// hmacSHA256 计算base64url编码的HMAC-SHA256
+func hmacSHA256(secret []byte, message string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -2,26 +2,39 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"meeting-mm/apperr"
 	"meeting-mm/config"
+	"meeting-mm/metrics"
+	"meeting-mm/models"
+	"meeting-mm/tracing"
 
 	"github.com/google/uuid"
 )
 
 // DeepSeekService 提供DeepSeek API调用功能
 type DeepSeekService struct {
-	apiKey  string
-	apiBase string
-	client  *http.Client
+	apiKey       string
+	apiBase      string
+	model        string
+	embedding    string // 计算向量使用的模型，为空时不支持Embed
+	systemPrompt string // 分析时使用的系统提示词
+	instructions string // 追加在分析提示词末尾的额外要求
+	client       *http.Client
 }
 
+// defaultAnalysisSystemPrompt 内置的分析系统提示词
+const defaultAnalysisSystemPrompt = "你是一个专业的会议纪要助手，擅长分析会议记录并提取关键信息。"
+
 // TodoItem 表示待办事项
 type TodoItem struct {
 	ID          string `json:"id"`
@@ -29,6 +42,9 @@ type TodoItem struct {
 	Assignee    string `json:"assignee"`
 	DueDate     string `json:"dueDate"`
 	Status      string `json:"status"`
+	AgendaItem  int    `json:"agendaItem,omitempty"` // 所属议程项的编号（从1开始），与议程无关时为0
+	// PreviousItem 对应的之前会议中未完成条目的编号（从1开始），新的待办事项为0
+	PreviousItem int `json:"previousItem,omitempty"`
 }
 
 // Decision 表示决策点
@@ -36,28 +52,77 @@ type Decision struct {
 	ID          string `json:"id"`
 	Description string `json:"description"`
 	MadeBy      string `json:"madeBy"`
+	AgendaItem  int    `json:"agendaItem,omitempty"` // 所属议程项的编号（从1开始），与议程无关时为0
+}
+
+// Analysis 会议分析结果
+type Analysis struct {
+	Summary   string
+	TodoItems []TodoItem
+	Decisions []Decision
+	Agenda    []AgendaResult      // 与MeetingContext.AgendaItems一一对应，没有议程时为空
+	Fields    []models.ExtraField // 会议类型额外提取的内容，与Profile.Fields一一对应
+	// PreviousItems 之前会议中未完成条目在本次会议中的情况，与MeetingContext.OpenItems一一对应，没有提到的为空
+	PreviousItems []string
+
+	OpenQuestions   []string
+	Risks           []models.Risk
+	Topics          []models.Topic // 会议记录没有时间戳时没有时间范围
+	Keywords        []string
+	SpeakerInsights []models.SpeakerInsight
+}
+
+// MeetingContext 会议邀请等来源提供的背景信息，分析时加入提示词
+type MeetingContext struct {
+	Date         time.Time
+	Participants []string
+	Agenda       string               // 议程文本，有AgendaItems时不使用
+	AgendaItems  []models.AgendaItem  // 结构化的议程，分析结果按议程项归类
+	OpenItems    []*models.ActionItem // 同一系列之前会议中未完成的待办事项，分析结果标注延续关系
 }
 
-// Meeting 表示会议
-type Meeting struct {
-	ID           string     `json:"id"`
-	Title        string     `json:"title"`
-	Date         string     `json:"date"`
-	Participants []string   `json:"participants"`
-	Transcript   string     `json:"transcript"`
-	Summary      string     `json:"summary"`
-	TodoItems    []TodoItem `json:"todoItems"`
-	Decisions    []Decision `json:"decisions"`
-	CreatedAt    string     `json:"createdAt"`
-	UpdatedAt    string     `json:"updatedAt"`
-	NotionPageID string     `json:"notionPageId,omitempty"`
+// prompt 返回提示词中的背景信息部分，没有背景信息时为空
+func (m MeetingContext) prompt() string {
+	var b strings.Builder
+	if !m.Date.IsZero() {
+		fmt.Fprintf(&b, "会议时间：%s\n", m.Date.Format("2006-01-02 15:04"))
+	}
+	if len(m.Participants) > 0 {
+		fmt.Fprintf(&b, "参会人员：%s\n", strings.Join(m.Participants, "、"))
+	}
+	if len(m.AgendaItems) > 0 {
+		fmt.Fprintf(&b, "会议议程：\n%s", agendaPromptItems(m.AgendaItems))
+	} else if agenda := strings.TrimSpace(m.Agenda); agenda != "" {
+		fmt.Fprintf(&b, "会议议程：\n%s\n", agenda)
+	}
+	if len(m.OpenItems) > 0 {
+		fmt.Fprintf(&b, "之前会议中未完成的待办事项：\n%s", openItemsPromptList(m.OpenItems))
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	b.WriteString("\n待办事项的负责人尽量使用参会人员中的姓名，相对日期（如“下周五”）以会议时间为准换算。\n")
+	return b.String()
 }
 
 // NewDeepSeekService 创建DeepSeekService实例
 func NewDeepSeekService(cfg *config.Config) *DeepSeekService {
+	model := cfg.DeepSeekModel
+	if model == "" {
+		model = "deepseek-chat"
+	}
+	systemPrompt := cfg.AnalysisSystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = defaultAnalysisSystemPrompt
+	}
+
 	return &DeepSeekService{
-		apiKey:  cfg.DeepSeekAPIKey,
-		apiBase: cfg.DeepSeekBaseURL,
+		apiKey:       cfg.DeepSeekAPIKey,
+		apiBase:      cfg.DeepSeekBaseURL,
+		model:        model,
+		embedding:    cfg.EmbeddingModel,
+		systemPrompt: systemPrompt,
+		instructions: strings.TrimSpace(cfg.AnalysisInstructions),
 		client: &http.Client{
 			Timeout: 60 * time.Second,
 		},
@@ -99,13 +164,22 @@ type ChatResponse struct {
 	} `json:"usage"`
 }
 
-// AnalyzeTranscript 分析会议记录，提取待办事项和决策点
-func (s *DeepSeekService) AnalyzeTranscript(title, transcript string) (summary string, todoItems []TodoItem, decisions []Decision, err error) {
+// AnalyzeTranscript 分析会议记录，提取摘要、待办事项、决策点、未解决的问题、风险、主题、关键词和发言人的态度；
+// transcript中每行以[mm:ss]开头时主题带有时间范围。meeting为会议邀请提供的时间、参会人和议程，可以为空。
+// 有结构化议程时同时返回每个议程项的讨论情况，待办事项和决策标注所属议程项；
+// 有之前会议中未完成的待办事项时返回它们在本次会议中的情况，待办事项标注对应的之前条目。
+// profile为会议类型，提供系统提示词、额外要求、额外提取的内容和输出语言，可以为nil
+func (s *DeepSeekService) AnalyzeTranscript(ctx context.Context, title, transcript string, meeting MeetingContext, profile *models.Profile) (analysis *Analysis, err error) {
+	ctx, span := tracing.Start(ctx, "DeepSeekService.AnalyzeTranscript")
+	defer span.Finish(&err)
+	defer func(start time.Time) { metrics.ObserveStage(metrics.StageAnalyze, start, err) }(time.Now())
+	span.SetAttribute("transcript.length", len(transcript))
+
 	// 构建提示词
 	prompt := fmt.Sprintf(`你是一个专业的会议纪要助手，请分析以下会议记录，提取关键信息：
 
 会议标题：%s
-
+%s
 会议记录：
 %s
 
@@ -113,6 +187,11 @@ func (s *DeepSeekService) AnalyzeTranscript(title, transcript string) (summary s
 1. 会议摘要（不超过200字）
 2. 待办事项列表（包括负责人和截止日期，如果有的话）
 3. 决策点列表（包括决策者，如果有的话）
+4. 提出但没有结论的问题
+5. 风险和阻塞项
+6. 按讨论顺序划分的主题（会议记录带有[mm:ss]时间戳时给出时间范围）
+7. 关键词（3到8个）
+8. 每位发言人的态度和参与度
 
 请以JSON格式返回，格式如下：
 {
@@ -129,66 +208,64 @@ func (s *DeepSeekService) AnalyzeTranscript(title, transcript string) (summary s
       "description": "决策点描述",
       "madeBy": "决策者（如果没有则为null）"
     }
+  ],
+  "openQuestions": ["未解决的问题"],
+  "risks": [
+    {
+      "description": "风险或阻塞项描述",
+      "kind": "risk（可能发生的问题）或blocker（已经阻碍进度的问题）",
+      "owner": "跟进人（如果没有则为null）"
+    }
+  ],
+  "topics": [
+    {
+      "title": "主题",
+      "summary": "一句话概括",
+      "start": "开始时间（mm:ss，会议记录没有时间戳时为null）",
+      "end": "结束时间（mm:ss，会议记录没有时间戳时为null）"
+    }
+  ],
+  "keywords": ["关键词"],
+  "speakers": [
+    {
+      "speaker": "发言人",
+      "sentiment": "positive、neutral或negative",
+      "engagement": "high、medium或low",
+      "note": "简要说明其主要观点或关注点"
+    }
   ]
 }
 
-只返回JSON格式的结果，不要有其他文字。`, title, transcript)
-
-	// 构建请求
-	messages := []Message{
-		{
-			Role:    "system",
-			Content: "你是一个专业的会议纪要助手，擅长分析会议记录并提取关键信息。",
-		},
-		{
-			Role:    "user",
-			Content: prompt,
-		},
+只返回JSON格式的结果，不要有其他文字。`, title, meeting.prompt(), transcript)
+	if len(meeting.AgendaItems) > 0 {
+		prompt += agendaPrompt
 	}
-
-	chatReq := ChatRequest{
-		Model:       "deepseek-chat",
-		Messages:    messages,
-		Temperature: 0.3,
-		MaxTokens:   2000,
+	if len(meeting.OpenItems) > 0 {
+		prompt += openItemsPrompt
 	}
-
-	reqBody, err := json.Marshal(chatReq)
-	if err != nil {
-		return "", nil, nil, err
+	prompt += profilePrompt(profile)
+
+	// 工作区或服务器配置的额外要求，之后是会议类型的额外要求
+	systemPrompt, instructions := s.systemPrompt, s.instructions
+	if profile != nil {
+		span.SetAttribute("analysis.profile", profile.Name)
+		if profile.SystemPrompt != "" {
+			systemPrompt = profile.SystemPrompt
+		}
+		if profile.Instructions != "" {
+			instructions = strings.TrimSpace(instructions + "\n" + profile.Instructions)
+		}
 	}
-
-	// 发送请求
-	req, err := http.NewRequest("POST", s.apiBase+"/chat/completions", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return "", nil, nil, err
+	if instructions != "" {
+		prompt += "\n\n额外要求：\n" + instructions
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+s.apiKey)
-
-	resp, err := s.client.Do(req)
+	content, err := s.chat(ctx, systemPrompt, prompt, 3000)
 	if err != nil {
-		return "", nil, nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", nil, nil, fmt.Errorf("API请求失败，状态码：%d，响应：%s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var chatResp ChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return "", nil, nil, err
-	}
-
-	if len(chatResp.Choices) == 0 {
-		return "", nil, nil, errors.New("API返回结果为空")
+		return nil, err
 	}
 
 	// 解析JSON响应
-	content := chatResp.Choices[0].Message.Content
 	content = strings.TrimSpace(content)
 
 	// 如果返回的内容被包裹在```json和```之间，去掉这些标记
@@ -206,96 +283,229 @@ func (s *DeepSeekService) AnalyzeTranscript(title, transcript string) (summary s
 	var result struct {
 		Summary   string `json:"summary"`
 		TodoItems []struct {
-			Description string `json:"description"`
-			Assignee    string `json:"assignee"`
-			DueDate     string `json:"dueDate"`
+			Description string    `json:"description"`
+			Assignee    string    `json:"assignee"`
+			DueDate     string    `json:"dueDate"`
+			AgendaItem  agendaRef `json:"agendaItem"`
+			Previous    agendaRef `json:"previousItem"`
 		} `json:"todoItems"`
 		Decisions []struct {
-			Description string `json:"description"`
-			MadeBy      string `json:"madeBy"`
+			Description string    `json:"description"`
+			MadeBy      string    `json:"madeBy"`
+			AgendaItem  agendaRef `json:"agendaItem"`
 		} `json:"decisions"`
+		Agenda        []agendaOutput   `json:"agenda"`
+		PreviousItems []previousOutput `json:"previousItems"`
+		insightsOutput
 	}
+	var fields map[string]json.RawMessage
 
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		return "", nil, nil, fmt.Errorf("解析API响应失败：%v，内容：%s", err, content)
+	err = json.Unmarshal([]byte(content), &result)
+	if err == nil {
+		err = json.Unmarshal([]byte(content), &fields)
+	}
+	if err != nil {
+		// 模型输出可能包含会议内容，只在日志中记录，不放进会返回给客户端和写入同步记录的错误
+		slog.DebugContext(ctx, "无法解析模型返回的分析结果", "content", content, "error", err)
+		return nil, apperr.Wrap(apperr.CodeLLMBadOutput, "无法解析模型返回的分析结果", err)
 	}
 
-	// 转换为返回格式
-	todoItems = make([]TodoItem, len(result.TodoItems))
+	// 转换为返回格式，超出范围的议程编号视为与议程无关
+	agendaItem := func(ref agendaRef) int {
+		if int(ref) < 1 || int(ref) > len(meeting.AgendaItems) {
+			return 0
+		}
+		return int(ref)
+	}
+	analysis = &Analysis{
+		Summary:   result.Summary,
+		TodoItems: make([]TodoItem, len(result.TodoItems)),
+		Decisions: make([]Decision, len(result.Decisions)),
+		Agenda:    agendaResults(len(meeting.AgendaItems), result.Agenda),
+		Fields:    extraFields(profile, fields),
+
+		PreviousItems: previousResults(len(meeting.OpenItems), result.PreviousItems),
+	}
+	result.insightsOutput.apply(analysis)
 	for i, item := range result.TodoItems {
-		todoItems[i] = TodoItem{
+		analysis.TodoItems[i] = TodoItem{
 			ID:          uuid.New().String(),
 			Description: item.Description,
 			Assignee:    item.Assignee,
 			DueDate:     item.DueDate,
 			Status:      "pending",
+			AgendaItem:  agendaItem(item.AgendaItem),
+		}
+		if int(item.Previous) >= 1 && int(item.Previous) <= len(meeting.OpenItems) {
+			analysis.TodoItems[i].PreviousItem = int(item.Previous)
 		}
 	}
 
-	decisions = make([]Decision, len(result.Decisions))
 	for i, decision := range result.Decisions {
-		decisions[i] = Decision{
+		analysis.Decisions[i] = Decision{
 			ID:          uuid.New().String(),
 			Description: decision.Description,
 			MadeBy:      decision.MadeBy,
+			AgendaItem:  agendaItem(decision.AgendaItem),
 		}
 	}
 
-	return result.Summary, todoItems, decisions, nil
+	return analysis, nil
 }
 
-// GenerateMarkdownReport 生成Markdown格式的会议纪要
-func (s *DeepSeekService) GenerateMarkdownReport(meeting Meeting) (string, error) {
-	// 构建提示词
-	prompt := fmt.Sprintf(`请根据以下会议信息，生成一份完整的Markdown格式会议纪要：
+// PolishReport 让模型润色模板渲染的Markdown报告：调整措辞、合并重复内容，保留结构和事实。
+// 润色是可选的，结果每次可能不同
+func (s *DeepSeekService) PolishReport(ctx context.Context, markdown string) (polished string, err error) {
+	ctx, span := tracing.Start(ctx, "DeepSeekService.PolishReport")
+	defer span.Finish(&err)
+	defer func(start time.Time) { metrics.ObserveStage(metrics.StagePolish, start, err) }(time.Now())
 
-会议标题：%s
-会议日期：%s
-参与人员：%s
-会议摘要：%s
+	prompt := `请润色以下Markdown格式的会议纪要：使语句通顺、简洁，合并重复的内容。
+保持标题层级、列表和复选框格式不变，不要增加原文中没有的事实、人名或日期。
+只返回润色后的Markdown，不要有其他文字。
 
-待办事项：
-%s
+` + markdown
 
-决策点：
-%s
+	polished, err = s.chat(ctx, "你是一个专业的会议纪要助手，擅长整理格式清晰的Markdown会议纪要。", prompt, 4000)
+	if err != nil {
+		return "", err
+	}
+	polished = strings.TrimSpace(polished)
 
-会议记录：
-%s
+	// 如果返回的内容被包裹在```markdown和```之间，去掉这些标记
+	if strings.HasPrefix(polished, "```markdown") {
+		polished = strings.TrimPrefix(polished, "```markdown")
+		polished = strings.TrimSuffix(polished, "```")
+	} else if strings.HasPrefix(polished, "```") {
+		polished = strings.TrimPrefix(polished, "```")
+		polished = strings.TrimSuffix(polished, "```")
+	}
 
-请生成一份专业的Markdown格式会议纪要，包括以下部分：
-1. 标题和基本信息
-2. 摘要
-3. 待办事项（使用复选框格式）
-4. 决策点
-5. 详细会议记录
-
-只返回Markdown格式的结果，不要有其他文字。`,
-		meeting.Title,
-		meeting.Date,
-		strings.Join(meeting.Participants, "、"),
-		meeting.Summary,
-		formatTodoItems(meeting.TodoItems),
-		formatDecisions(meeting.Decisions),
-		meeting.Transcript)
-
-	// 构建请求
-	messages := []Message{
-		{
-			Role:    "system",
-			Content: "你是一个专业的会议纪要助手，擅长生成格式清晰的Markdown会议纪要。",
-		},
-		{
-			Role:    "user",
-			Content: prompt,
-		},
+	return strings.TrimSpace(polished) + "\n", nil
+}
+
+// Ping 检查DeepSeek API可以访问且密钥有效。请求模型列表，不消耗token
+func (s *DeepSeekService) Ping(ctx context.Context) error {
+	if s.apiKey == "" {
+		err := apperr.New(apperr.CodeLLMUnavailable, "未配置DeepSeek API密钥")
+		err.Retryable = false
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.apiBase+"/models", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return apperr.Wrap(apperr.CodeLLMUnavailable, "无法访问DeepSeek API", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		err := apperr.Wrap(apperr.CodeLLMUnavailable, "DeepSeek API密钥无效", fmt.Errorf("状态码：%d", resp.StatusCode))
+		err.Retryable = false
+		return err
+	case resp.StatusCode != http.StatusOK:
+		return llmStatusError(resp)
+	}
+	return nil
+}
+
+// ErrEmbeddingDisabled 未配置向量模型
+var ErrEmbeddingDisabled = errors.New("未配置向量模型")
+
+// maxEmbeddingBatch 一次请求计算向量的文本数量上限
+const maxEmbeddingBatch = 64
+
+// EmbeddingModel 返回计算向量使用的模型，为空表示未配置
+func (s *DeepSeekService) EmbeddingModel() string {
+	return s.embedding
+}
+
+// Embed 调用OpenAI兼容的/embeddings接口计算文本的向量，结果与texts一一对应；
+// 未配置向量模型时返回ErrEmbeddingDisabled
+func (s *DeepSeekService) Embed(ctx context.Context, texts []string) (vectors [][]float32, err error) {
+	if s.embedding == "" {
+		return nil, ErrEmbeddingDisabled
 	}
+	ctx, span := tracing.StartKind(ctx, "DeepSeek embeddings", tracing.KindClient)
+	defer span.Finish(&err)
+	span.SetAttribute("llm.model", s.embedding)
+	span.SetAttribute("embedding.count", len(texts))
+
+	for start := 0; start < len(texts); start += maxEmbeddingBatch {
+		batch := texts[start:min(start+maxEmbeddingBatch, len(texts))]
+		reqBody, err := json.Marshal(map[string]interface{}{"model": s.embedding, "input": batch})
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", s.apiBase+"/embeddings", bytes.NewBuffer(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+		req.Header.Set("traceparent", span.Traceparent())
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, apperr.Wrap(apperr.CodeLLMUnavailable, "调用向量接口失败", err)
+		}
+		var result struct {
+			Data []struct {
+				Index     int       `json:"index"`
+				Embedding []float32 `json:"embedding"`
+			} `json:"data"`
+			Usage struct {
+				PromptTokens int `json:"prompt_tokens"`
+			} `json:"usage"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := llmStatusError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, apperr.Wrap(apperr.CodeLLMBadOutput, "向量接口响应格式无效", err)
+		}
+		metrics.LLMTokens.Add(float64(result.Usage.PromptTokens), s.embedding, "prompt")
+
+		batchVectors := make([][]float32, len(batch))
+		for _, item := range result.Data {
+			if item.Index >= 0 && item.Index < len(batch) {
+				batchVectors[item.Index] = item.Embedding
+			}
+		}
+		for _, vector := range batchVectors {
+			if len(vector) == 0 {
+				return nil, apperr.New(apperr.CodeLLMBadOutput, "向量接口返回的结果数量不匹配")
+			}
+		}
+		vectors = append(vectors, batchVectors...)
+	}
+	return vectors, nil
+}
+
+// chat 调用聊天接口并返回第一条回复，记录token用量
+func (s *DeepSeekService) chat(ctx context.Context, systemPrompt, prompt string, maxTokens int) (string, error) {
+	ctx, span := tracing.StartKind(ctx, "DeepSeek chat/completions", tracing.KindClient)
+	defer span.End()
+	span.SetAttribute("llm.model", s.model)
 
 	chatReq := ChatRequest{
-		Model:       "deepseek-chat",
-		Messages:    messages,
+		Model: s.model,
+		Messages: []Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt},
+		},
 		Temperature: 0.3,
-		MaxTokens:   4000,
+		MaxTokens:   maxTokens,
 	}
 
 	reqBody, err := json.Marshal(chatReq)
@@ -304,76 +514,51 @@ func (s *DeepSeekService) GenerateMarkdownReport(meeting Meeting) (string, error
 	}
 
 	// 发送请求
-	req, err := http.NewRequest("POST", s.apiBase+"/chat/completions", bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiBase+"/chat/completions", bytes.NewBuffer(reqBody))
 	if err != nil {
 		return "", err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("traceparent", span.Traceparent())
 
 	resp, err := s.client.Do(req)
 	if err != nil {
+		err = apperr.Wrap(apperr.CodeLLMUnavailable, "调用DeepSeek API失败", err)
+		span.RecordError(err)
 		return "", err
 	}
 	defer resp.Body.Close()
+	span.SetAttribute("http.status_code", resp.StatusCode)
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API请求失败，状态码：%d，响应：%s", resp.StatusCode, string(bodyBytes))
+		err := llmStatusError(resp)
+		span.RecordError(err)
+		return "", err
 	}
 
 	var chatResp ChatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return "", err
-	}
-
-	if len(chatResp.Choices) == 0 {
-		return "", errors.New("API返回结果为空")
+		return "", apperr.Wrap(apperr.CodeLLMBadOutput, "DeepSeek API响应格式无效", err)
 	}
 
-	// 获取Markdown内容
-	markdown := chatResp.Choices[0].Message.Content
-	markdown = strings.TrimSpace(markdown)
+	metrics.LLMTokens.Add(float64(chatResp.Usage.PromptTokens), s.model, "prompt")
+	metrics.LLMTokens.Add(float64(chatResp.Usage.CompletionTokens), s.model, "completion")
+	span.SetAttribute("llm.prompt_tokens", chatResp.Usage.PromptTokens)
+	span.SetAttribute("llm.completion_tokens", chatResp.Usage.CompletionTokens)
 
-	// 如果返回的内容被包裹在```markdown和```之间，去掉这些标记
-	if strings.HasPrefix(markdown, "```markdown") {
-		markdown = strings.TrimPrefix(markdown, "```markdown")
-		markdown = strings.TrimSuffix(markdown, "```")
-	} else if strings.HasPrefix(markdown, "```") {
-		markdown = strings.TrimPrefix(markdown, "```")
-		markdown = strings.TrimSuffix(markdown, "```")
-	}
-
-	return strings.TrimSpace(markdown), nil
-}
-
-// 格式化待办事项列表
-func formatTodoItems(items []TodoItem) string {
-	var result strings.Builder
-	for _, item := range items {
-		if item.DueDate != "" && item.Assignee != "" {
-			result.WriteString(fmt.Sprintf("- %s（负责人：%s，截止日期：%s）\n", item.Description, item.Assignee, item.DueDate))
-		} else if item.Assignee != "" {
-			result.WriteString(fmt.Sprintf("- %s（负责人：%s）\n", item.Description, item.Assignee))
-		} else if item.DueDate != "" {
-			result.WriteString(fmt.Sprintf("- %s（截止日期：%s）\n", item.Description, item.DueDate))
-		} else {
-			result.WriteString(fmt.Sprintf("- %s\n", item.Description))
-		}
+	if len(chatResp.Choices) == 0 {
+		return "", apperr.New(apperr.CodeLLMBadOutput, "DeepSeek API返回结果为空")
 	}
-	return result.String()
+	return chatResp.Choices[0].Message.Content, nil
 }
 
-// 格式化决策点列表
-func formatDecisions(decisions []Decision) string {
-	var result strings.Builder
-	for _, decision := range decisions {
-		if decision.MadeBy != "" {
-			result.WriteString(fmt.Sprintf("- %s（决策者：%s）\n", decision.Description, decision.MadeBy))
-		} else {
-			result.WriteString(fmt.Sprintf("- %s\n", decision.Description))
-		}
-	}
-	return result.String()
+// llmStatusError 将DeepSeek API的非200响应转换为应用错误，只有限流和服务端错误可以重试
+func llmStatusError(resp *http.Response) error {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	err := apperr.Wrap(apperr.CodeLLMUnavailable, "DeepSeek API请求失败",
+		fmt.Errorf("状态码：%d，响应：%s", resp.StatusCode, string(bodyBytes)))
+	err.Retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return err
 }
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"meeting-mm/apperr"
+	"meeting-mm/config"
+	"meeting-mm/storage"
+)
+
+// 组件和整体的健康状态
+const (
+	HealthOK       = "ok"       // 正常
+	HealthDegraded = "degraded" // 可以工作但需要关注，不影响就绪
+	HealthFail     = "fail"     // 不可用，服务未就绪
+)
+
+const (
+	// healthCheckTimeout 单个组件检查的超时时间
+	healthCheckTimeout = 10 * time.Second
+	// transcriberCheckTTL 转录环境检查通过后缓存结果的时间，避免每次探测都启动Python导入模型库
+	transcriberCheckTTL = 5 * time.Minute
+	// externalCheckTTL DeepSeek和Notion检查结果（包括失败）的缓存时间。就绪检查无需登录，
+	// 缓存避免频繁探测消耗服务器凭据的额度或触发限流
+	externalCheckTTL = time.Minute
+)
+
+// ComponentHealth 单个组件的检查结果
+type ComponentHealth struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Code      string  `json:"code,omitempty"` // 失败时的错误码
+	Message   string  `json:"message,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+// HealthReport 就绪检查结果
+type HealthReport struct {
+	Status     string            `json:"status"`
+	Time       string            `json:"time"`
+	Components []ComponentHealth `json:"components"`
+}
+
+// HealthService 检查服务依赖的各个组件：转录环境、DeepSeek、Notion、数据存储和磁盘空间。
+// 检查使用服务器配置的凭据，工作区自己的凭据不在检查范围内
+type HealthService struct {
+	cfg         *config.Config
+	services    *WorkspaceServices
+	jobs        *Jobs
+	minFreeDisk atomic.Uint64 // 字节数，可以在重新加载配置时修改
+
+	mu                sync.Mutex
+	transcriberOKTime time.Time
+	llm               cachedCheck
+	notion            cachedCheck
+}
+
+// cachedCheck 缓存一个组件的检查结果，并发的检查等待同一次调用的结果
+type cachedCheck struct {
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+// run 缓存未过期时返回缓存的结果，否则执行检查并缓存
+func (c *cachedCheck) run(ctx context.Context, check func(ctx context.Context) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checked.IsZero() && time.Since(c.checked) < externalCheckTTL {
+		return c.err
+	}
+	c.err = check(ctx)
+	c.checked = time.Now()
+	return c.err
+}
+
+// reset 清除缓存的结果
+func (c *cachedCheck) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked = time.Time{}
+}
+
+// NewHealthService 创建HealthService实例，jobs用于在关闭期间报告未就绪
+func NewHealthService(cfg *config.Config, services *WorkspaceServices, jobs *Jobs) *HealthService {
+	h := &HealthService{
+		cfg:      cfg,
+		services: services,
+		jobs:     jobs,
+	}
+	h.Reload(cfg)
+	return h
+}
+
+// Reload 更新磁盘剩余空间的下限，并清除DeepSeek和Notion的检查缓存以便按新的凭据检查
+func (h *HealthService) Reload(cfg *config.Config) {
+	h.minFreeDisk.Store(uint64(cfg.HealthMinFreeDiskMB) * 1024 * 1024)
+	h.llm.reset()
+	h.notion.reset()
+}
+
+// degradedError 表示组件可以工作但需要关注
+type degradedError struct {
+	err error
+}
+
+func (e degradedError) Error() string { return e.err.Error() }
+func (e degradedError) Unwrap() error { return e.err }
+
+// healthCheck 一个组件的检查，返回nil表示正常，成功时可以通过message附带说明
+type healthCheck struct {
+	name string
+	run  func(ctx context.Context) (message string, err error)
+}
+
+// Ready 并发检查全部组件，任一组件失败时整体状态为fail
+func (h *HealthService) Ready(ctx context.Context) *HealthReport {
+	checks := []healthCheck{
+		{"server", h.checkServer},
+		{"storage", h.checkStorage},
+		{"disk", h.checkDisk},
+		{"transcriber", h.checkTranscriber},
+		{"llm", h.checkLLM},
+		{"notion", h.checkNotion},
+	}
+
+	report := &HealthReport{
+		Status:     HealthOK,
+		Time:       time.Now().Format(time.RFC3339),
+		Components: make([]ComponentHealth, len(checks)),
+	}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check healthCheck) {
+			defer wg.Done()
+			report.Components[i] = runHealthCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		switch component.Status {
+		case HealthFail:
+			report.Status = HealthFail
+		case HealthDegraded:
+			if report.Status == HealthOK {
+				report.Status = HealthDegraded
+			}
+		}
+	}
+	return report
+}
+
+// runHealthCheck 在超时时间内执行检查并记录耗时。Notion客户端等不支持ctx的调用超时后在后台继续执行
+func runHealthCheck(ctx context.Context, check healthCheck) ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	type result struct {
+		message string
+		err     error
+	}
+	start := time.Now()
+	done := make(chan result, 1)
+	go func() {
+		message, err := check.run(ctx)
+		done <- result{message, err}
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		r.err = fmt.Errorf("检查超时（%s）", healthCheckTimeout)
+	}
+
+	component := ComponentHealth{
+		Name:      check.name,
+		Status:    HealthOK,
+		Message:   r.message,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if r.err == nil {
+		return component
+	}
+
+	component.Status = HealthFail
+	var degraded degradedError
+	if errors.As(r.err, &degraded) {
+		component.Status = HealthDegraded
+	}
+	component.Message = r.err.Error()
+	if e, ok := apperr.As(r.err); ok {
+		// 就绪检查无需登录即可访问，只返回面向用户的信息，原始错误写入日志
+		component.Code = string(e.Code)
+		component.Message = e.Message
+	}
+	if component.Status == HealthFail {
+		slog.WarnContext(ctx, "就绪检查失败", "component", check.name, "error", r.err)
+	}
+	return component
+}
+
+func (h *HealthService) checkServer(ctx context.Context) (string, error) {
+	if h.jobs.Draining() {
+		return "", ErrShuttingDown
+	}
+	return "", nil
+}
+
+func (h *HealthService) checkStorage(ctx context.Context) (string, error) {
+	return "", storage.Probe(h.cfg.DataDir)
+}
+
+func (h *HealthService) checkDisk(ctx context.Context) (string, error) {
+	free, err := storage.FreeSpace(h.cfg.DataDir)
+	if err != nil {
+		return "", degradedError{fmt.Errorf("无法获取磁盘剩余空间: %w", err)}
+	}
+	message := fmt.Sprintf("剩余%s", formatBytes(free))
+	minFree := h.minFreeDisk.Load()
+	switch {
+	case free < minFree:
+		return "", fmt.Errorf("磁盘剩余空间不足：%s，下限为%s", formatBytes(free), formatBytes(minFree))
+	case free < 2*minFree:
+		return "", degradedError{fmt.Errorf("磁盘剩余空间较少：%s", formatBytes(free))}
+	}
+	return message, nil
+}
+
+func (h *HealthService) checkTranscriber(ctx context.Context) (string, error) {
+	h.mu.Lock()
+	cached := time.Since(h.transcriberOKTime) < transcriberCheckTTL
+	h.mu.Unlock()
+	if cached {
+		return "", nil
+	}
+
+	set, err := h.services.For("")
+	if err != nil {
+		return "", err
+	}
+	err = set.Whisper.CheckTranscriber(ctx)
+	if errors.Is(err, ErrWhisperModelMissing) {
+		return "", degradedError{err}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	h.mu.Lock()
+	h.transcriberOKTime = time.Now()
+	h.mu.Unlock()
+	return "", nil
+}
+
+func (h *HealthService) checkLLM(ctx context.Context) (string, error) {
+	return "", h.llm.run(ctx, func(ctx context.Context) error {
+		set, err := h.services.For("")
+		if err != nil {
+			return err
+		}
+		return set.DeepSeek.Ping(ctx)
+	})
+}
+
+func (h *HealthService) checkNotion(ctx context.Context) (string, error) {
+	return "", h.notion.run(ctx, func(ctx context.Context) error {
+		set, err := h.services.For("")
+		if err != nil {
+			return err
+		}
+		return set.Notion.Ping(ctx)
+	})
+}
+
+// formatBytes 以MB或GB显示字节数
+func formatBytes(n uint64) string {
+	const mb = 1024 * 1024
+	if n >= 1024*mb {
+		return fmt.Sprintf("%.1f GB", float64(n)/(1024*mb))
+	}
+	return fmt.Sprintf("%d MB", n/mb)
+}
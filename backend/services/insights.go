@@ -0,0 +1,146 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"meeting-mm/models"
+)
+
+// maxKeywords 保留的关键词数量上限
+const maxKeywords = 10
+
+// TimestampedTranscript 将转录片段拼接为带时间戳的会议记录，每段一行，如“[01:05] 张三: 开始讨论”，
+// 分析时模型据此给出主题的时间范围
+func TimestampedTranscript(segments []models.TranscriptSegment) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		fmt.Fprintf(&b, "[%s]", formatSegmentTime(segment.StartTime))
+		if segment.Speaker != "" {
+			fmt.Fprintf(&b, " %s:", segment.Speaker)
+		}
+		fmt.Fprintf(&b, " %s\n", strings.TrimSpace(segment.Text))
+	}
+	return b.String()
+}
+
+// clockTime 模型返回的时间点，兼容“mm:ss”“hh:mm:ss”、秒数和null，以秒为单位
+type clockTime float64
+
+func (t *clockTime) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if json.Unmarshal(data, &seconds) == nil {
+		*t = clockTime(seconds)
+		return nil
+	}
+	var text string
+	if json.Unmarshal(data, &text) != nil {
+		*t = 0 // null或无法识别的值视为没有时间
+		return nil
+	}
+	total := 0
+	for _, part := range strings.Split(strings.Trim(strings.TrimSpace(text), "[]"), ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			*t = 0
+			return nil
+		}
+		total = total*60 + n
+	}
+	*t = clockTime(total)
+	return nil
+}
+
+// insightsOutput 模型返回的扩展分析结果
+type insightsOutput struct {
+	OpenQuestions []string `json:"openQuestions"`
+	Risks         []struct {
+		Description string `json:"description"`
+		Kind        string `json:"kind"`
+		Owner       string `json:"owner"`
+	} `json:"risks"`
+	Topics []struct {
+		Title   string    `json:"title"`
+		Summary string    `json:"summary"`
+		Start   clockTime `json:"start"`
+		End     clockTime `json:"end"`
+	} `json:"topics"`
+	Keywords []string `json:"keywords"`
+	Speakers []struct {
+		Speaker    string `json:"speaker"`
+		Sentiment  string `json:"sentiment"`
+		Engagement string `json:"engagement"`
+		Note       string `json:"note"`
+	} `json:"speakers"`
+}
+
+// apply 整理模型返回的内容并写入分析结果：去掉空条目，无法识别的类型和态度使用默认值，
+// 结束时间不晚于开始时间的主题不保留时间范围，关键词去重
+func (out *insightsOutput) apply(analysis *Analysis) {
+	for _, question := range out.OpenQuestions {
+		if question = strings.TrimSpace(question); question != "" {
+			analysis.OpenQuestions = append(analysis.OpenQuestions, question)
+		}
+	}
+
+	for _, risk := range out.Risks {
+		if strings.TrimSpace(risk.Description) == "" {
+			continue
+		}
+		kind := strings.ToLower(strings.TrimSpace(risk.Kind))
+		if kind != models.RiskKindBlocker {
+			kind = models.RiskKindRisk
+		}
+		analysis.Risks = append(analysis.Risks, models.Risk{
+			Description: strings.TrimSpace(risk.Description),
+			Kind:        kind,
+			Owner:       strings.TrimSpace(risk.Owner),
+		})
+	}
+
+	for _, topic := range out.Topics {
+		if strings.TrimSpace(topic.Title) == "" {
+			continue
+		}
+		t := models.Topic{Title: strings.TrimSpace(topic.Title), Summary: strings.TrimSpace(topic.Summary)}
+		if topic.End > topic.Start && topic.Start >= 0 {
+			t.StartTime, t.EndTime = float64(topic.Start), float64(topic.End)
+		}
+		analysis.Topics = append(analysis.Topics, t)
+	}
+
+	seen := map[string]bool{}
+	for _, keyword := range out.Keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" || seen[strings.ToLower(keyword)] || len(analysis.Keywords) == maxKeywords {
+			continue
+		}
+		seen[strings.ToLower(keyword)] = true
+		analysis.Keywords = append(analysis.Keywords, keyword)
+	}
+
+	for _, speaker := range out.Speakers {
+		if strings.TrimSpace(speaker.Speaker) == "" {
+			continue
+		}
+		insight := models.SpeakerInsight{
+			Speaker:    strings.TrimSpace(speaker.Speaker),
+			Sentiment:  strings.ToLower(strings.TrimSpace(speaker.Sentiment)),
+			Engagement: strings.ToLower(strings.TrimSpace(speaker.Engagement)),
+			Note:       strings.TrimSpace(speaker.Note),
+		}
+		switch insight.Sentiment {
+		case models.SentimentPositive, models.SentimentNeutral, models.SentimentNegative:
+		default:
+			insight.Sentiment = models.SentimentNeutral
+		}
+		switch insight.Engagement {
+		case models.EngagementHigh, models.EngagementMedium, models.EngagementLow:
+		default:
+			insight.Engagement = models.EngagementMedium
+		}
+		analysis.SpeakerInsights = append(analysis.SpeakerInsights, insight)
+	}
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"meeting-mm/config"
+)
+
+// 会议邀请相关的错误
+var (
+	ErrInvalidInvite         = errors.New("无效的会议邀请")
+	ErrInviteNotFound        = errors.New("日历中没有该会议")
+	ErrCalendarNotConfigured = errors.New("未配置日历目录（CALENDAR_DIR）")
+)
+
+// Invite 从iCalendar会议邀请中读取的会议信息
+type Invite struct {
+	UID       string    `json:"uid"`
+	Title     string    `json:"title"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	Attendees []string  `json:"attendees"` // 组织者和接受或未答复的参会人，不含会议室等资源
+	Agenda    string    `json:"agenda,omitempty"`
+}
+
+// Context 返回会议邀请提供的分析上下文，没有会议邀请时为空
+func (inv *Invite) Context() MeetingContext {
+	if inv == nil {
+		return MeetingContext{}
+	}
+	return MeetingContext{Date: inv.Start, Participants: inv.Attendees, Agenda: inv.Agenda}
+}
+
+// ParseInvites 解析iCalendar（RFC 5545）中的全部VEVENT。
+// 重复会议不展开，使用第一次的时间；没有时区的时间按服务器本地时间处理
+func ParseInvites(data []byte) ([]*Invite, error) {
+	var invites []*Invite
+	var current *Invite
+	depth := 0 // 当前VEVENT内嵌套组件（如VALARM）的层数
+	for _, line := range unfoldICS(string(data)) {
+		name, params, value, ok := parseICSLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && current == nil:
+			current = &Invite{Attendees: []string{}}
+		case current == nil:
+		case name == "BEGIN":
+			depth++
+		case name == "END" && depth > 0:
+			depth--
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			invites = append(invites, current)
+			current = nil
+		case depth > 0:
+		default:
+			if err := current.set(name, params, value); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidInvite, name, err)
+			}
+		}
+	}
+	if len(invites) == 0 {
+		return nil, fmt.Errorf("%w: 没有VEVENT", ErrInvalidInvite)
+	}
+	return invites, nil
+}
+
+// ParseInvite 解析会议邀请；uid不为空时选择该UID的会议，否则选择第一个
+func ParseInvite(data []byte, uid string) (*Invite, error) {
+	invites, err := ParseInvites(data)
+	if err != nil {
+		return nil, err
+	}
+	if uid == "" {
+		return invites[0], nil
+	}
+	for _, invite := range invites {
+		if invite.UID == uid {
+			return invite, nil
+		}
+	}
+	return nil, ErrInviteNotFound
+}
+
+func (inv *Invite) set(name string, params map[string]string, value string) error {
+	var err error
+	switch name {
+	case "UID":
+		inv.UID = value
+	case "SUMMARY":
+		inv.Title = strings.TrimSpace(unescapeICS(value))
+	case "DESCRIPTION":
+		inv.Agenda = strings.TrimSpace(strings.ReplaceAll(unescapeICS(value), "\r\n", "\n"))
+	case "LOCATION":
+		inv.Location = strings.TrimSpace(unescapeICS(value))
+	case "DTSTART":
+		inv.Start, err = parseICSTime(params, value)
+	case "DTEND":
+		inv.End, err = parseICSTime(params, value)
+	case "ORGANIZER":
+		inv.addAttendee(params, value)
+	case "ATTENDEE":
+		// 会议室、设备等资源和已拒绝的人不算参会人
+		switch strings.ToUpper(params["CUTYPE"]) {
+		case "ROOM", "RESOURCE":
+			return nil
+		}
+		if strings.EqualFold(params["PARTSTAT"], "DECLINED") || strings.EqualFold(params["ROLE"], "NON-PARTICIPANT") {
+			return nil
+		}
+		inv.addAttendee(params, value)
+	}
+	return err
+}
+
+// addAttendee 添加参会人：优先使用CN中的姓名，没有时使用邮箱，按名称去重
+func (inv *Invite) addAttendee(params map[string]string, value string) {
+	name := strings.TrimSpace(params["CN"])
+	if name == "" {
+		name = strings.TrimSpace(value)
+		if len(name) > len("mailto:") && strings.EqualFold(name[:len("mailto:")], "mailto:") {
+			name = name[len("mailto:"):]
+		}
+	}
+	if name == "" {
+		return
+	}
+	for _, existing := range inv.Attendees {
+		if strings.EqualFold(existing, name) {
+			return
+		}
+	}
+	inv.Attendees = append(inv.Attendees, name)
+}
+
+// unfoldICS 拆分内容行并合并以空格或制表符开头的续行
+func unfoldICS(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseICSLine 解析内容行 NAME;PARAM=VALUE:VALUE，参数值可以用双引号包含冒号和分号
+func parseICSLine(line string) (name string, params map[string]string, value string, ok bool) {
+	params = map[string]string{}
+	quoted := false
+	start := 0
+	var fields []string
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == ';':
+			fields = append(fields, line[start:i])
+			start = i + 1
+		case r == ':':
+			fields = append(fields, line[start:i])
+			name = strings.ToUpper(fields[0])
+			for _, field := range fields[1:] {
+				key, val, _ := strings.Cut(field, "=")
+				params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+			}
+			return name, params, line[i+1:], name != ""
+		}
+	}
+	return "", nil, "", false
+}
+
+// icsUnescape 还原TEXT类型的转义
+var icsUnescape = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescapeICS(value string) string {
+	return icsUnescape.Replace(value)
+}
+
+// parseICSTime 解析DATE或DATE-TIME：UTC时间以Z结尾，带TZID时按该时区，无法识别的时区和浮动时间按本地时间
+func parseICSTime(params map[string]string, value string) (time.Time, error) {
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, time.Local)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+// CalendarDirectory 本地日历目录，代替CalDAV服务器：每个工作区使用以工作区ID命名的子目录，
+// 子目录中的每个.ics文件为一个或多个会议，可以由vdirsyncer等工具从CalDAV同步，或由日历应用导出。
+// 每次查询时重新读取目录
+type CalendarDirectory struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewCalendarDirectory 创建日历目录，cfg.CalendarDir为空时未配置
+func NewCalendarDirectory(cfg *config.Config) *CalendarDirectory {
+	d := &CalendarDirectory{}
+	d.Reload(cfg)
+	return d
+}
+
+// Reload 应用重新加载的配置
+func (d *CalendarDirectory) Reload(cfg *config.Config) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dir = cfg.CalendarDir
+}
+
+// Events 返回工作区日历目录中的全部会议，按开始时间排序；无法解析的文件记录警告后跳过，
+// 工作区的子目录不存在时没有会议
+func (d *CalendarDirectory) Events(workspaceID string) ([]*Invite, error) {
+	d.mu.RLock()
+	dir := d.dir
+	d.mu.RUnlock()
+	if dir == "" {
+		return nil, ErrCalendarNotConfigured
+	}
+	if workspaceID == "" || filepath.Base(workspaceID) != workspaceID || workspaceID == "." || workspaceID == ".." {
+		return nil, fmt.Errorf("无效的工作区ID: %q", workspaceID)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, workspaceID, "*.ics"))
+	if err != nil {
+		return nil, fmt.Errorf("读取日历目录失败: %w", err)
+	}
+	events := []*Invite{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取日历目录失败: %w", err)
+		}
+		invites, err := ParseInvites(data)
+		if err != nil {
+			slog.Warn("跳过无法解析的日历文件", "file", path, "error", err)
+			continue
+		}
+		events = append(events, invites...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return events, nil
+}
+
+// Find 按UID在工作区的日历目录中查找会议
+func (d *CalendarDirectory) Find(workspaceID, uid string) (*Invite, error) {
+	events, err := d.Events(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if event.UID == uid {
+			return event, nil
+		}
+	}
+	return nil, ErrInviteNotFound
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrShuttingDown 服务正在关闭，不再接受新的任务
+var ErrShuttingDown = errors.New("服务正在关闭，请稍后重试")
+
+// Jobs 跟踪进行中的任务（请求处理、转录等）。关闭服务时先停止接受新任务并等待已有任务完成，
+// 超过期限后取消它们的ctx，由ctx结束Whisper子进程和外部API调用
+type Jobs struct {
+	mu       sync.Mutex
+	draining bool
+	running  map[*job]struct{}
+	idle     chan struct{} // 没有进行中的任务时关闭，有任务时重新创建
+}
+
+type job struct {
+	cancel context.CancelCauseFunc
+}
+
+// NewJobs 创建任务跟踪器
+func NewJobs() *Jobs {
+	idle := make(chan struct{})
+	close(idle)
+	return &Jobs{running: map[*job]struct{}{}, idle: idle}
+}
+
+// Start 登记一个任务，返回的ctx在关闭超时时被取消（context.Cause为ErrShuttingDown），
+// 任务结束后必须调用done。服务正在关闭时返回ErrShuttingDown
+func (j *Jobs) Start(ctx context.Context) (context.Context, func(), error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.draining {
+		return nil, nil, ErrShuttingDown
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	jb := &job{cancel: cancel}
+	if len(j.running) == 0 {
+		j.idle = make(chan struct{})
+	}
+	j.running[jb] = struct{}{}
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			cancel(nil)
+			j.mu.Lock()
+			defer j.mu.Unlock()
+			delete(j.running, jb)
+			if len(j.running) == 0 {
+				close(j.idle)
+			}
+		})
+	}
+	return ctx, done, nil
+}
+
+// Running 返回进行中的任务数
+func (j *Jobs) Running() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.running)
+}
+
+// Draining 返回是否已开始关闭
+func (j *Jobs) Draining() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.draining
+}
+
+// Drain 停止接受新任务并等待进行中的任务完成。ctx结束时取消剩余任务，
+// 再最多等待grace让它们清理退出，此时返回ctx的错误
+func (j *Jobs) Drain(ctx context.Context, grace time.Duration) error {
+	j.mu.Lock()
+	j.draining = true
+	idle := j.idle
+	j.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	j.mu.Lock()
+	for jb := range j.running {
+		jb.cancel(ErrShuttingDown)
+	}
+	j.mu.Unlock()
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-idle:
+	case <-timer.C:
+	}
+	return ctx.Err()
+}
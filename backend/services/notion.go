@@ -1,425 +1,408 @@
 package services
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"net/http"
+	"log/slog"
+	"mime"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"meeting-mm/apperr"
 	"meeting-mm/config"
+	"meeting-mm/metrics"
 	"meeting-mm/models"
+	"meeting-mm/notion"
+	"meeting-mm/storage"
+	"meeting-mm/tracing"
 )
 
 // NotionService 提供Notion API调用功能
 type NotionService struct {
-	apiKey     string
-	databaseID string
-	client     *http.Client
+	client            *notion.Client
+	databaseID        string
+	layout            *NotionLayout
+	users             *NotionUserDirectory
+	assigneesProperty string
+	audio             *storage.FileStore
+	audioMaxBytes     int
+	publicBaseURL     string
+	audioLinkSecret   string
+	audioLinkTTL      time.Duration
+
+	mu     sync.Mutex
+	schema *notion.Database
 }
 
 // NewNotionService 创建NotionService实例
 func NewNotionService(cfg *config.Config) *NotionService {
+	layout := DefaultNotionLayout()
+	if cfg.NotionLayoutFile != "" {
+		custom, err := LoadNotionLayout(cfg.NotionLayoutFile)
+		if err != nil {
+			slog.Warn("加载Notion布局失败，使用默认布局", "file", cfg.NotionLayoutFile, "error", err)
+		} else {
+			layout = custom
+		}
+	}
+
+	var audio *storage.FileStore
+	if cfg.KeepAudio {
+		var err error
+		if audio, err = storage.NewFileStore(storage.AudioDir(cfg.DataDir)); err != nil {
+			slog.Warn("打开录音目录失败，Notion页面将不包含录音", "error", err)
+		}
+	}
+
+	client := notion.NewClient(cfg.NotionAPIKey, cfg.NotionBaseURL)
+	// 重新加载配置时WorkspaceServices会重建服务，新的有效期对之后生成的录音链接生效
+	audioLinkTTL := cfg.AudioLinkTTL
+	if audioLinkTTL <= 0 {
+		audioLinkTTL = 30 * 24 * time.Hour
+	}
 	return &NotionService{
-		apiKey:     cfg.NotionAPIKey,
-		databaseID: cfg.NotionDatabaseID,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:            client,
+		databaseID:        cfg.NotionDatabaseID,
+		layout:            layout,
+		users:             NewNotionUserDirectory(client, cfg.NotionUserAliases),
+		assigneesProperty: cfg.NotionAssigneesProperty,
+		audio:             audio,
+		audioMaxBytes:     cfg.NotionAudioMaxBytes,
+		publicBaseURL:     strings.TrimRight(cfg.PublicBaseURL, "/"),
+		audioLinkSecret:   cfg.AuthSecret,
+		audioLinkTTL:      audioLinkTTL,
 	}
 }
 
+// Client 返回底层的Notion客户端
+func (s *NotionService) Client() *notion.Client {
+	return s.client
+}
+
 // SyncMeeting 同步会议到Notion
-func (s *NotionService) SyncMeeting(meeting *models.Meeting) error {
-	// 打印接收到的会议数据以进行调试
-	meetingBytes, _ := json.Marshal(meeting)
-	log.Printf("【调试】同步到Notion的会议数据: %s\n", string(meetingBytes))
+func (s *NotionService) SyncMeeting(ctx context.Context, meeting *models.Meeting) (err error) {
+	ctx, span := tracing.Start(ctx, "NotionService.SyncMeeting")
+	defer span.Finish(&err)
+	defer func(start time.Time) { metrics.ObserveStage(metrics.StageNotion, start, err) }(time.Now())
 
 	// 检查标题是否为空
 	if meeting == nil {
 		return fmt.Errorf("会议对象不能为nil")
 	}
 
-	// 打印会议标题
-	log.Printf("【调试】会议标题: '%s'，长度: %d\n", meeting.Title, len(meeting.Title))
-
 	// 如果Title为空，设置默认标题
 	if meeting.Title == "" {
 		meeting.Title = "未命名会议"
-		log.Printf("【调试】会议标题为空，已设置默认标题: %s\n", meeting.Title)
 	}
 
 	// 如果日期为空，使用当前日期
 	if meeting.Date.IsZero() {
 		meeting.Date = time.Now()
-		log.Printf("会议日期为空，已设置为当前日期: %s\n", meeting.Date.Format("2006-01-02"))
-	}
-
-	// 构建请求体
-	requestBody := map[string]interface{}{
-		"parent": map[string]string{
-			"database_id": s.databaseID,
-		},
-		"properties": map[string]interface{}{
-			"Name": map[string]interface{}{
-				"title": []map[string]interface{}{
-					{
-						"type": "text",
-						"text": map[string]string{
-							"content": meeting.Title,
-						},
-					},
-				},
-			},
-			"Date": map[string]interface{}{
-				"date": map[string]interface{}{
-					"start": meeting.Date.Format("2006-01-02"),
-				},
-			},
-		},
-	}
-
-	// 添加摘要
-	if meeting.Summary != "" {
-		requestBody["properties"].(map[string]interface{})["Summary"] = map[string]interface{}{
-			"rich_text": []map[string]interface{}{
-				{
-					"type": "text",
-					"text": map[string]string{
-						"content": meeting.Summary,
-					},
-				},
-			},
-		}
 	}
+	slog.DebugContext(ctx, "开始同步会议到Notion", "meeting_id", meeting.ID, "title", meeting.Title, "transcript", meeting.Transcript)
 
-	// 添加参与者
-	if len(meeting.Participants) > 0 {
-		var participants []map[string]string
-		for _, participant := range meeting.Participants {
-			participants = append(participants, map[string]string{
-				"name": participant,
-			})
-		}
-		requestBody["properties"].(map[string]interface{})["Participants"] = map[string]interface{}{
-			"multi_select": participants,
-		}
+	if !s.Configured() {
+		return errNotionNotConfigured()
 	}
 
-	// 添加页面内容块
-	var children []map[string]interface{}
-
-	// 添加摘要部分
-	if meeting.Summary != "" {
-		children = append(children,
-			map[string]interface{}{
-				"object": "block",
-				"type":   "heading_2",
-				"heading_2": map[string]interface{}{
-					"rich_text": []map[string]interface{}{
-						{
-							"type": "text",
-							"text": map[string]string{
-								"content": "会议摘要",
-							},
-						},
-					},
-					"color": "default",
-				},
-			},
-			map[string]interface{}{
-				"object": "block",
-				"type":   "paragraph",
-				"paragraph": map[string]interface{}{
-					"rich_text": []map[string]interface{}{
-						{
-							"type": "text",
-							"text": map[string]string{
-								"content": meeting.Summary,
-							},
-						},
-					},
-					"color": "default",
-				},
-			},
-		)
-	}
-
-	// 添加待办事项部分
-	if len(meeting.TodoItems) > 0 {
-		children = append(children, map[string]interface{}{
-			"object": "block",
-			"type":   "heading_2",
-			"heading_2": map[string]interface{}{
-				"rich_text": []map[string]interface{}{
-					{
-						"type": "text",
-						"text": map[string]string{
-							"content": "待办事项",
-						},
-					},
-				},
-				"color": "default",
-			},
-		})
+	db, err := s.databaseSchema()
+	if err != nil {
+		return notionError("获取Notion数据库结构失败", err)
+	}
 
-		for _, todo := range meeting.TodoItems {
-			todoBlock := map[string]interface{}{
-				"object": "block",
-				"type":   "to_do",
-				"to_do": map[string]interface{}{
-					"rich_text": []map[string]interface{}{
-						{
-							"type": "text",
-							"text": map[string]string{
-								"content": todo.Description,
-							},
-						},
-					},
-					"checked": todo.Status == "completed",
-					"color":   "default",
-				},
-			}
+	// 将参与者、负责人和决策人解析为Notion用户，解析失败的人名以文本形式写入并记录在会议上
+	mentions, unresolved, err := s.users.Resolve(meetingPeople(meeting))
+	if err != nil {
+		slog.WarnContext(ctx, "获取Notion用户列表失败，人名将以文本形式写入", "error", err)
+	}
+	meeting.UnresolvedPeople = unresolved
+	if len(unresolved) > 0 {
+		slog.InfoContext(ctx, "部分人名未能匹配到Notion用户", "people", strings.Join(unresolved, "、"))
+	}
 
-			// 如果有负责人，添加到描述中
-			if todo.Assignee != "" {
-				todoBlock["to_do"].(map[string]interface{})["rich_text"] = append(
-					todoBlock["to_do"].(map[string]interface{})["rich_text"].([]map[string]interface{}),
-					map[string]interface{}{
-						"type": "text",
-						"text": map[string]string{
-							"content": fmt.Sprintf(" (@%s)", todo.Assignee),
-						},
-					},
-				)
-			}
+	children, err := s.layout.Build(meeting, BuildOptions{
+		Mentions: mentions,
+		Audio:    s.audioBlock(meeting),
+	})
+	if err != nil {
+		return err
+	}
 
-			// 如果有截止日期，添加到描述中
-			if !todo.DueDate.IsZero() {
-				todoBlock["to_do"].(map[string]interface{})["rich_text"] = append(
-					todoBlock["to_do"].(map[string]interface{})["rich_text"].([]map[string]interface{}),
-					map[string]interface{}{
-						"type": "text",
-						"text": map[string]string{
-							"content": fmt.Sprintf(" (截止: %s)", todo.DueDate.Format("2006-01-02")),
-						},
-					},
-				)
-			}
+	properties := buildMeetingProperties(db, meeting, mentions, s.assigneesProperty)
 
-			children = append(children, todoBlock)
+	// 之前的同步已创建页面（如追加内容失败后重试）时重写该页面，避免重复创建
+	if meeting.NotionPageID != "" {
+		err := s.rewritePage(meeting.NotionPageID, properties, children)
+		var apiErr *notion.APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != "object_not_found" {
+			if err != nil {
+				return notionError("更新Notion页面失败", err)
+			}
+			span.SetAttribute("notion.page_id", meeting.NotionPageID)
+			slog.InfoContext(ctx, "会议已同步到已有的Notion页面", "meeting_id", meeting.ID, "page_id", meeting.NotionPageID)
+			return nil
 		}
+		// 页面已被删除，重新创建
+		slog.WarnContext(ctx, "会议的Notion页面不存在，将重新创建", "meeting_id", meeting.ID, "page_id", meeting.NotionPageID)
 	}
 
-	// 添加决策事项部分
-	if len(meeting.Decisions) > 0 {
-		children = append(children, map[string]interface{}{
-			"object": "block",
-			"type":   "heading_2",
-			"heading_2": map[string]interface{}{
-				"rich_text": []map[string]interface{}{
-					{
-						"type": "text",
-						"text": map[string]string{
-							"content": "决策事项",
-						},
-					},
-				},
-				"color": "default",
-			},
-		})
-
-		for _, decision := range meeting.Decisions {
-			decisionBlock := map[string]interface{}{
-				"object": "block",
-				"type":   "bulleted_list_item",
-				"bulleted_list_item": map[string]interface{}{
-					"rich_text": []map[string]interface{}{
-						{
-							"type": "text",
-							"text": map[string]string{
-								"content": decision.Description,
-							},
-						},
-					},
-					"color": "default",
-				},
-			}
+	page, err := s.client.CreatePage(&notion.CreatePageRequest{
+		Parent:     notion.Parent{DatabaseID: s.databaseID},
+		Properties: properties,
+		Children:   children,
+	})
+	if page != nil {
+		// 保存Notion页面ID，即使部分内容追加失败也能定位到页面
+		meeting.NotionPageID = page.ID
+	}
+	if err != nil {
+		return notionError("创建Notion页面失败", err)
+	}
 
-			// 如果有决策人，添加到描述中
-			if decision.MadeBy != "" {
-				decisionBlock["bulleted_list_item"].(map[string]interface{})["rich_text"] = append(
-					decisionBlock["bulleted_list_item"].(map[string]interface{})["rich_text"].([]map[string]interface{}),
-					map[string]interface{}{
-						"type": "text",
-						"text": map[string]string{
-							"content": fmt.Sprintf(" (由 %s 决定)", decision.MadeBy),
-						},
-					},
-				)
-			}
+	span.SetAttribute("notion.page_id", page.ID)
+	slog.InfoContext(ctx, "会议已同步到Notion", "meeting_id", meeting.ID, "page_id", page.ID)
+	return nil
+}
 
-			children = append(children, decisionBlock)
+// rewritePage 更新已有页面的属性，并用children替换页面的全部内容
+func (s *NotionService) rewritePage(pageID string, properties map[string]notion.PropertyValue, children []notion.Block) error {
+	if _, err := s.client.UpdatePageProperties(pageID, properties); err != nil {
+		return err
+	}
+	existing, err := s.client.RetrieveBlockChildren(pageID)
+	if err != nil {
+		return err
+	}
+	for _, block := range existing {
+		if err := s.client.DeleteBlock(block.ID); err != nil {
+			return err
 		}
 	}
+	_, err = s.client.AppendBlockChildren(pageID, children)
+	return err
+}
 
-	// 添加会议记录部分
-	if meeting.Transcript != "" {
-		children = append(children,
-			map[string]interface{}{
-				"object": "block",
-				"type":   "heading_2",
-				"heading_2": map[string]interface{}{
-					"rich_text": []map[string]interface{}{
-						{
-							"type": "text",
-							"text": map[string]string{
-								"content": "会议记录",
-							},
-						},
-					},
-					"color": "default",
-				},
-			},
-			map[string]interface{}{
-				"object": "block",
-				"type":   "paragraph",
-				"paragraph": map[string]interface{}{
-					"rich_text": []map[string]interface{}{
-						{
-							"type": "text",
-							"text": map[string]string{
-								"content": meeting.Transcript,
-							},
-						},
-					},
-					"color": "default",
-				},
-			},
-		)
-	}
-
-	// 添加内容块到请求体
-	if len(children) > 0 {
-		requestBody["children"] = children
-	}
-
-	// 转换为JSON
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("序列化请求体失败: %w", err)
+// Configured 返回是否配置了Notion令牌和数据库ID
+func (s *NotionService) Configured() bool {
+	return s.client.HasToken() && s.databaseID != ""
+}
+
+// errNotionNotConfigured 未配置Notion时返回的错误
+func errNotionNotConfigured() error {
+	return apperr.New(apperr.CodeNotionUnauthorized, "未配置Notion令牌或数据库ID")
+}
+
+// Ping 检查Notion令牌有效且数据库已共享给集成
+func (s *NotionService) Ping(ctx context.Context) error {
+	if !s.Configured() {
+		return errNotionNotConfigured()
+	}
+	if _, err := s.client.RetrieveDatabase(s.databaseID); err != nil {
+		return notionError("无法访问Notion数据库", err)
 	}
+	return nil
+}
 
-	// 打印请求体的JSON以便调试
-	log.Printf("发送到Notion的请求体: %s\n", string(jsonBody))
+// UpdateMeetingTranscript 更新会议转录内容
+func (s *NotionService) UpdateMeetingTranscript(meeting *models.Meeting) error {
+	if meeting.NotionPageID == "" || meeting.Transcript == "" {
+		return fmt.Errorf("无效的会议ID或转录内容")
+	}
 
-	// 创建请求
-	req, err := http.NewRequest("POST", "https://api.notion.com/v1/pages", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return fmt.Errorf("创建请求失败: %w", err)
+	children := append([]notion.Block{notion.Heading2("会议转录")}, transcriptBlocks(meeting)...)
+	if _, err := s.client.AppendBlockChildren(meeting.NotionPageID, children); err != nil {
+		return err
 	}
+	return nil
+}
 
-	// 设置请求头
-	req.Header.Set("Authorization", "Bearer "+s.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Notion-Version", "2022-06-28")
+// audioBlock 生成会议录音块：不超过大小上限时上传到Notion，否则（或上传失败时）改为指向本服务录音地址的签名链接
+func (s *NotionService) audioBlock(meeting *models.Meeting) *notion.Block {
+	if s.audio == nil || meeting.AudioFile == "" {
+		return nil
+	}
 
-	// 发送请求
-	resp, err := s.client.Do(req)
+	info, err := s.audio.Stat(meeting.AudioFile)
 	if err != nil {
-		return fmt.Errorf("发送请求失败: %w", err)
+		slog.Warn("读取会议录音失败", "meeting_id", meeting.ID, "error", err)
+		return nil
 	}
-	defer resp.Body.Close()
 
-	// 读取响应
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("读取响应失败: %w", err)
+	if info.Size() <= int64(s.audioMaxBytes) {
+		data, err := s.audio.Read(meeting.AudioFile)
+		if err == nil {
+			var upload *notion.FileUpload
+			upload, err = s.client.UploadFile(meeting.AudioFile, AudioContentType(meeting.AudioFile), data)
+			if err == nil {
+				block := notion.AudioUpload(upload.ID, "会议录音")
+				return &block
+			}
+		}
+		slog.Warn("上传会议录音到Notion失败，改为链接", "meeting_id", meeting.ID, "error", err)
 	}
 
-	// 检查响应状态码
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API请求失败，状态码: %d，响应: %s", resp.StatusCode, string(body))
+	if s.publicBaseURL == "" {
+		return nil
 	}
+	// Notion中的读者没有本服务的登录凭据，链接带签名以便免登录访问
+	expires := time.Now().Add(s.audioLinkTTL).Unix()
+	link := fmt.Sprintf("%s/api/public/meetings/%s/audio?exp=%d&sig=%s", s.publicBaseURL, meeting.ID, expires, AudioSignature(s.audioLinkSecret, meeting.ID, expires))
+	block := notion.Paragraph(
+		notion.StyledText("🎧 ", nil),
+		notion.LinkText(fmt.Sprintf("会议录音（%.1f MB，点击收听）", float64(info.Size())/1024/1024), link),
+	)
+	return &block
+}
+
+// audioContentTypes 常见录音格式的MIME类型，系统MIME表中通常不包含这些扩展名
+var audioContentTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".oga":  "audio/ogg",
+	".webm": "audio/webm",
+	".flac": "audio/flac",
+	".mp4":  "audio/mp4",
+	".mpga": "audio/mpeg",
+	".opus": "audio/ogg",
+}
+
+// SupportedAudio 判断文件扩展名是否为支持转录的录音格式，没有扩展名时交给转录服务判断
+func SupportedAudio(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	_, ok := audioContentTypes[ext]
+	return ext == "" || ok
+}
 
-	// 解析响应
-	var response struct {
-		ID string `json:"id"`
+// AudioContentType 根据文件扩展名推断录音的MIME类型
+func AudioContentType(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if contentType, ok := audioContentTypes[ext]; ok {
+		return contentType
 	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return fmt.Errorf("解析响应失败: %w", err)
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
 	}
+	return "audio/mpeg"
+}
 
-	// 保存Notion页面ID
-	meeting.NotionPageID = response.ID
-	log.Printf("会议已成功同步到Notion，页面ID: %s\n", response.ID)
+// databaseSchema 获取并缓存目标数据库的结构
+func (s *NotionService) databaseSchema() (*notion.Database, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return nil
+	if s.schema != nil {
+		return s.schema, nil
+	}
+
+	db, err := s.client.RetrieveDatabase(s.databaseID)
+	if err != nil {
+		return nil, err
+	}
+	s.schema = db
+	return db, nil
 }
 
-// UpdateMeetingTranscript 更新会议转录内容
-func (s *NotionService) UpdateMeetingTranscript(meeting *models.Meeting) error {
-	if meeting.NotionPageID == "" || meeting.Transcript == "" {
-		return fmt.Errorf("无效的会议ID或转录内容")
+// meetingPeople 收集会议中出现的全部人名
+func meetingPeople(meeting *models.Meeting) []string {
+	names := append([]string{}, meeting.Participants...)
+	for _, todo := range meeting.TodoItems {
+		names = append(names, todo.Assignee)
+	}
+	for _, decision := range meeting.Decisions {
+		names = append(names, decision.MadeBy)
 	}
+	return names
+}
 
-	// 生成转录内容
-	var content strings.Builder
-	content.WriteString("# 会议转录\n\n")
-	content.WriteString(meeting.Transcript)
-
-	// 构建请求体
-	requestBody := map[string]interface{}{
-		"children": []map[string]interface{}{
-			{
-				"object": "block",
-				"type":   "paragraph",
-				"paragraph": map[string]interface{}{
-					"rich_text": []map[string]interface{}{
-						{
-							"type": "text",
-							"text": map[string]string{
-								"content": content.String(),
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	// 转换为JSON
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("序列化请求体失败: %w", err)
+// resolvedIDs 返回names中已解析人名对应的去重用户ID
+func resolvedIDs(names []string, mentions map[string]string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if id, ok := mentions[name]; ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
 	}
+	return ids
+}
 
-	// 创建请求
-	req, err := http.NewRequest("PATCH", fmt.Sprintf("https://api.notion.com/v1/blocks/%s/children", meeting.NotionPageID), bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return fmt.Errorf("创建请求失败: %w", err)
+// buildMeetingProperties 根据数据库结构构建页面属性，跳过数据库中不存在或类型不匹配的字段
+func buildMeetingProperties(db *notion.Database, meeting *models.Meeting, mentions map[string]string, assigneesProperty string) map[string]notion.PropertyValue {
+	titleProp := db.TitleProperty()
+	if titleProp == "" {
+		titleProp = "Name"
 	}
 
-	// 设置请求头
-	req.Header.Set("Authorization", "Bearer "+s.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Notion-Version", "2022-06-28")
+	properties := map[string]notion.PropertyValue{
+		titleProp: notion.TitleProperty(meeting.Title),
+	}
 
-	// 发送请求
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("发送请求失败: %w", err)
+	if db.PropertyType("Date") == "date" {
+		properties["Date"] = notion.DateProperty(meeting.Date.Format("2006-01-02"))
 	}
-	defer resp.Body.Close()
 
-	// 检查响应状态
-	if resp.StatusCode != 200 {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("API请求失败，状态码：%d，响应：%s", resp.StatusCode, string(body))
+	if meeting.Summary != "" && db.PropertyType("Summary") == "rich_text" {
+		properties["Summary"] = notion.RichTextProperty(meeting.Summary)
 	}
 
-	return nil
+	// 参与者属性可以是人员类型或多选类型
+	if len(meeting.Participants) > 0 {
+		switch db.PropertyType("Participants") {
+		case "people":
+			if ids := resolvedIDs(meeting.Participants, mentions); len(ids) > 0 {
+				properties["Participants"] = notion.PeopleProperty(ids...)
+			}
+		case "multi_select":
+			properties["Participants"] = notion.MultiSelectProperty(meeting.Participants...)
+		}
+	}
+
+	// 标签属性使用关键词，Notion的选项名称中不能有逗号
+	if len(meeting.Keywords) > 0 && db.PropertyType("Tags") == "multi_select" {
+		tags := make([]string, 0, len(meeting.Keywords))
+		for _, keyword := range meeting.Keywords {
+			tags = append(tags, strings.ReplaceAll(keyword, ",", " "))
+		}
+		properties["Tags"] = notion.MultiSelectProperty(tags...)
+	}
+
+	// 负责人属性汇总所有待办事项的负责人
+	if assigneesProperty != "" && db.PropertyType(assigneesProperty) == "people" {
+		var assignees []string
+		for _, todo := range meeting.TodoItems {
+			assignees = append(assignees, todo.Assignee)
+		}
+		if ids := resolvedIDs(assignees, mentions); len(ids) > 0 {
+			properties[assigneesProperty] = notion.PeopleProperty(ids...)
+		}
+	}
+
+	return properties
+}
+
+// notionError 按Notion返回的状态码和错误码将错误转换为应用错误：
+// 属性校验失败视为数据库结构不匹配，凭据无效或无权访问不可重试，限流和服务端错误可以重试
+func notionError(message string, err error) error {
+	var apiErr *notion.APIError
+	if !errors.As(err, &apiErr) {
+		// 网络错误等未得到Notion响应的情况
+		return apperr.Wrap(apperr.CodeNotionUnavailable, message, err)
+	}
+	switch {
+	case apiErr.Code == "validation_error":
+		return apperr.Wrap(apperr.CodeNotionSchemaMismatch, "Notion数据库结构与会议属性不匹配", err)
+	case apiErr.Status == 401 || apiErr.Status == 403 || apiErr.Code == "object_not_found":
+		return apperr.Wrap(apperr.CodeNotionUnauthorized, "Notion集成令牌无效，或数据库未共享给该集成", err)
+	case apiErr.Status == 409 || apiErr.Status == 429 || apiErr.Status >= 500:
+		return apperr.Wrap(apperr.CodeNotionUnavailable, message, err)
+	default:
+		return apperr.Wrap(apperr.CodeNotionFailed, message, err)
+	}
 }
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"meeting-mm/models"
+	"meeting-mm/notion"
+)
+
+// Notion页面布局中的区块类型
+const (
+	SectionCallout    = "callout"
+	SectionHeading    = "heading"
+	SectionSummary    = "summary"
+	SectionTodos      = "todos"
+	SectionDecisions  = "decisions"
+	SectionAgenda     = "agenda"
+	SectionFields     = "fields"
+	SectionTopics     = "topics"
+	SectionQuestions  = "questions"
+	SectionRisks      = "risks"
+	SectionSpeakers   = "speakers"
+	SectionTranscript = "transcript"
+	SectionAudio      = "audio"
+	SectionDivider    = "divider"
+)
+
+// NotionLayout 描述会议在Notion页面中的区块布局，可通过JSON文件自定义
+type NotionLayout struct {
+	Sections []NotionSection `json:"sections"`
+}
+
+// NotionSection 布局中的一个区块，Title、Text和Lines均为text/template模板，数据为会议对象
+type NotionSection struct {
+	Type  string   `json:"type"`
+	Title string   `json:"title,omitempty"` // 区块标题；transcript区块中作为折叠块的标签
+	Text  string   `json:"text,omitempty"`  // callout区块的正文
+	Lines []string `json:"lines,omitempty"` // callout区块中的元数据行，渲染为空的行会被跳过
+	Icon  string   `json:"icon,omitempty"`  // callout区块的emoji图标
+	Color string   `json:"color,omitempty"` // callout区块的颜色，如 gray_background
+	Style string   `json:"style,omitempty"` // decisions: numbered|bulleted；transcript: toggle|plain
+}
+
+// 布局中有agenda区块且会议有议程时，todos和decisions区块只列出不属于任何议程项的条目
+
+// DefaultNotionLayout 默认布局：摘要与元数据标注、讨论主题、议程、待办列表、编号决策、会议类型额外提取的内容、
+// 待解决的问题、风险、发言人、折叠的会议记录
+func DefaultNotionLayout() *NotionLayout {
+	return &NotionLayout{
+		Sections: []NotionSection{
+			{
+				Type:  SectionCallout,
+				Icon:  "📝",
+				Color: "gray_background",
+				Text:  "{{.Summary}}",
+				Lines: []string{
+					`📅 会议日期：{{.Date.Format "2006-01-02"}}`,
+					`{{if .Participants}}👥 参与人员：{{join .Participants "、"}}{{end}}`,
+					`{{if .Keywords}}🏷️ 关键词：{{join .Keywords "、"}}{{end}}`,
+					`✅ 待办事项：{{len .TodoItems}} 项　📌 决策：{{len .Decisions}} 项`,
+				},
+			},
+			{Type: SectionTopics, Title: "讨论主题"},
+			{Type: SectionAgenda, Title: "议程"},
+			{Type: SectionTodos, Title: "{{if .AgendaItems}}其他待办事项{{else}}待办事项{{end}}"},
+			{Type: SectionDecisions, Title: "{{if .AgendaItems}}其他决策事项{{else}}决策事项{{end}}", Style: "numbered"},
+			{Type: SectionFields},
+			{Type: SectionQuestions, Title: "待解决的问题"},
+			{Type: SectionRisks, Title: "风险和阻塞项"},
+			{Type: SectionSpeakers, Title: "发言人"},
+			{Type: SectionAudio},
+			{Type: SectionTranscript, Title: "会议记录", Style: "toggle"},
+		},
+	}
+}
+
+// LoadNotionLayout 从JSON文件加载布局
+func LoadNotionLayout(path string) (*NotionLayout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取Notion布局文件失败: %w", err)
+	}
+
+	var layout NotionLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("解析Notion布局文件失败: %w", err)
+	}
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+	return &layout, nil
+}
+
+// Validate 检查布局中的区块类型和模板
+func (l *NotionLayout) Validate() error {
+	if len(l.Sections) == 0 {
+		return fmt.Errorf("Notion布局至少需要一个区块")
+	}
+
+	for i, section := range l.Sections {
+		switch section.Type {
+		case SectionCallout, SectionHeading, SectionSummary, SectionTodos, SectionDecisions, SectionAgenda, SectionFields,
+			SectionTopics, SectionQuestions, SectionRisks, SectionSpeakers, SectionTranscript, SectionAudio, SectionDivider:
+		default:
+			return fmt.Errorf("Notion布局第%d个区块类型无效: %q", i+1, section.Type)
+		}
+
+		for _, text := range append([]string{section.Title, section.Text}, section.Lines...) {
+			if _, err := parseLayoutTemplate(text); err != nil {
+				return fmt.Errorf("Notion布局第%d个区块模板无效: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// BuildOptions 生成页面内容时使用的外部数据
+type BuildOptions struct {
+	// Mentions 人名到Notion用户ID的映射，命中的人名以@提及形式展示
+	Mentions map[string]string
+	// Audio 会议录音块（已上传的音频或指向录音的链接），为空时audio区块不输出内容
+	Audio *notion.Block
+}
+
+// Build 按布局生成会议页面的内容块
+func (l *NotionLayout) Build(meeting *models.Meeting, opts BuildOptions) ([]notion.Block, error) {
+	byAgenda := false
+	for _, section := range l.Sections {
+		byAgenda = byAgenda || section.Type == SectionAgenda && len(meeting.AgendaItems) > 0
+	}
+
+	var blocks []notion.Block
+	for _, section := range l.Sections {
+		sectionBlocks, err := buildSection(section, meeting, opts, byAgenda)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, sectionBlocks...)
+	}
+	return blocks, nil
+}
+
+// buildSection 生成单个区块的内容块，没有内容的区块返回空；byAgenda为true时待办和决策已在议程区块中列出
+func buildSection(section NotionSection, meeting *models.Meeting, opts BuildOptions, byAgenda bool) ([]notion.Block, error) {
+	mentions := opts.Mentions
+	title, err := renderLayoutText(section.Title, meeting)
+	if err != nil {
+		return nil, err
+	}
+
+	withHeading := func(content ...notion.Block) []notion.Block {
+		if len(content) == 0 || title == "" {
+			return content
+		}
+		return append([]notion.Block{notion.Heading2(title)}, content...)
+	}
+
+	switch section.Type {
+	case SectionCallout:
+		return buildCallout(section, meeting)
+
+	case SectionHeading:
+		if title == "" {
+			return nil, nil
+		}
+		return []notion.Block{notion.Heading2(title)}, nil
+
+	case SectionDivider:
+		return []notion.Block{notion.Divider()}, nil
+
+	case SectionAudio:
+		if opts.Audio == nil {
+			return nil, nil
+		}
+		return withHeading(*opts.Audio), nil
+
+	case SectionSummary:
+		if meeting.Summary == "" {
+			return nil, nil
+		}
+		return withHeading(notion.Paragraphs(meeting.Summary)...), nil
+
+	case SectionTodos:
+		todos := meeting.TodoItems
+		if byAgenda {
+			todos = meeting.AgendaTodos("")
+		}
+		var items []notion.Block
+		for _, todo := range todos {
+			items = append(items, todoBlock(todo, mentions))
+		}
+		return withHeading(items...), nil
+
+	case SectionDecisions:
+		decisions := meeting.Decisions
+		if byAgenda {
+			decisions = meeting.AgendaDecisions("")
+		}
+		var items []notion.Block
+		for _, decision := range decisions {
+			richText := decisionRichText(decision, mentions)
+			if section.Style == "bulleted" {
+				items = append(items, notion.BulletedListItem(richText...))
+			} else {
+				items = append(items, notion.NumberedListItem(richText...))
+			}
+		}
+		return withHeading(items...), nil
+
+	case SectionAgenda:
+		var items []notion.Block
+		for i, item := range meeting.AgendaItems {
+			items = append(items, agendaBlocks(i, item, meeting, mentions)...)
+		}
+		return withHeading(items...), nil
+
+	case SectionFields:
+		// 每项有内容的额外字段一个二级标题，区块标题不使用
+		var blocks []notion.Block
+		for _, field := range meeting.ExtraFields {
+			if len(field.Items) == 0 {
+				continue
+			}
+			blocks = append(blocks, notion.Heading2(field.Label))
+			for _, item := range field.Items {
+				blocks = append(blocks, notion.BulletedListItem(notion.Text(item)...))
+			}
+		}
+		return blocks, nil
+
+	case SectionTopics:
+		var items []notion.Block
+		for _, topic := range meeting.Topics {
+			richText := []notion.RichText{notion.StyledText(topic.Title, &notion.Annotations{Bold: true})}
+			if topic.HasTimeRange() {
+				richText = append(richText, notion.StyledText(fmt.Sprintf(" %s–%s", formatSegmentTime(topic.StartTime), formatSegmentTime(topic.EndTime)), &notion.Annotations{Color: "gray"}))
+			}
+			if topic.Summary != "" {
+				richText = append(richText, notion.Text("："+topic.Summary)...)
+			}
+			items = append(items, notion.NumberedListItem(richText...))
+		}
+		return withHeading(items...), nil
+
+	case SectionQuestions:
+		var items []notion.Block
+		for _, question := range meeting.OpenQuestions {
+			items = append(items, notion.BulletedListItem(notion.Text("❓ "+question)...))
+		}
+		return withHeading(items...), nil
+
+	case SectionRisks:
+		var items []notion.Block
+		for _, risk := range meeting.Risks {
+			icon := "⚠️ "
+			if risk.Kind == models.RiskKindBlocker {
+				icon = "⛔ "
+			}
+			richText := notion.Text(icon + risk.Description)
+			if risk.Owner != "" {
+				richText = append(richText, notion.StyledText(" ", nil), personRichText(risk.Owner, mentions))
+			}
+			items = append(items, notion.BulletedListItem(richText...))
+		}
+		return withHeading(items...), nil
+
+	case SectionSpeakers:
+		var items []notion.Block
+		for _, insight := range meeting.SpeakerInsights {
+			richText := []notion.RichText{
+				personRichText(insight.Speaker, mentions),
+				notion.StyledText(fmt.Sprintf("：%s，参与度%s", models.SentimentLabel(insight.Sentiment), models.EngagementLabel(insight.Engagement)), nil),
+			}
+			if insight.Note != "" {
+				richText = append(richText, notion.Text("。"+insight.Note)...)
+			}
+			items = append(items, notion.BulletedListItem(richText...))
+		}
+		return withHeading(items...), nil
+
+	case SectionTranscript:
+		content := transcriptBlocks(meeting)
+		if len(content) == 0 {
+			return nil, nil
+		}
+		if section.Style == "plain" {
+			return withHeading(content...), nil
+		}
+		if title == "" {
+			title = "会议记录"
+		}
+		return []notion.Block{notion.Toggle(title, content...)}, nil
+	}
+
+	return nil, nil
+}
+
+// buildCallout 生成包含正文和元数据行的标注块
+func buildCallout(section NotionSection, meeting *models.Meeting) ([]notion.Block, error) {
+	text, err := renderLayoutText(section.Text, meeting)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []notion.Block
+	for _, line := range section.Lines {
+		rendered, err := renderLayoutText(line, meeting)
+		if err != nil {
+			return nil, err
+		}
+		if rendered != "" {
+			children = append(children, notion.Paragraphs(rendered)...)
+		}
+	}
+
+	if text == "" && len(children) == 0 {
+		return nil, nil
+	}
+
+	// 标注块自身的文本有片段数量限制，过长的正文放入子块
+	richText := notion.Text(text)
+	if len(richText) > 1 {
+		children = append(notion.Paragraphs(text), children...)
+		richText = notion.Text("")
+	}
+	return []notion.Block{notion.CalloutBlock(section.Icon, section.Color, richText, children...)}, nil
+}
+
+// agendaBlocks 生成一个议程项的内容：带讨论状态的三级标题、讨论摘要、该议程项的决策和待办
+func agendaBlocks(index int, item models.AgendaItem, meeting *models.Meeting, mentions map[string]string) []notion.Block {
+	status := "未分析"
+	switch item.Status {
+	case models.AgendaCovered:
+		status = "✅ 已讨论"
+	case models.AgendaDeferred:
+		status = "⏭️ 已推迟"
+	}
+	blocks := []notion.Block{notion.Heading3(fmt.Sprintf("%d. %s（%s）", index+1, item.Title, status))}
+	if item.Summary != "" {
+		blocks = append(blocks, notion.Paragraphs(item.Summary)...)
+	}
+	for _, decision := range meeting.AgendaDecisions(item.ID) {
+		richText := append([]notion.RichText{notion.StyledText("决策：", &notion.Annotations{Bold: true})}, decisionRichText(decision, mentions)...)
+		blocks = append(blocks, notion.BulletedListItem(richText...))
+	}
+	for _, todo := range meeting.AgendaTodos(item.ID) {
+		blocks = append(blocks, todoBlock(todo, mentions))
+	}
+	return blocks
+}
+
+// decisionRichText 生成决策的文本，决策人以@提及形式展示
+func decisionRichText(decision models.Decision, mentions map[string]string) []notion.RichText {
+	richText := notion.Text(decision.Description)
+	// 如果有决策人，添加到描述中
+	if decision.MadeBy != "" {
+		richText = append(richText, notion.StyledText(" (由 ", nil), personRichText(decision.MadeBy, mentions), notion.StyledText(" 决定)", nil))
+	}
+	return richText
+}
+
+// todoBlock 生成待办块，负责人以@提及形式展示
+func todoBlock(todo models.TodoItem, mentions map[string]string) notion.Block {
+	richText := notion.Text(todo.Description)
+	if label := continuityLabels[todo.Continuity]; label != "" {
+		richText = append([]notion.RichText{notion.StyledText("【"+label+"】", &notion.Annotations{Color: "gray"})}, richText...)
+	}
+
+	if todo.Assignee != "" {
+		richText = append(richText, notion.StyledText(" ", nil), personRichText(todo.Assignee, mentions))
+	}
+
+	// 如果有截止日期，添加到描述中
+	if !todo.DueDate.IsZero() {
+		richText = append(richText, notion.Text(fmt.Sprintf(" (截止: %s)", todo.DueDate.Format("2006-01-02")))...)
+	}
+
+	return notion.ToDo(todo.Status == "completed", richText...)
+}
+
+// personRichText 已解析的人名生成真正的用户提及，否则以加粗的“@人名”文本展示
+func personRichText(name string, mentions map[string]string) notion.RichText {
+	if id, ok := mentions[name]; ok {
+		return notion.UserMention(id)
+	}
+	return notion.StyledText("@"+name, &notion.Annotations{Bold: true, Color: "blue"})
+}
+
+// transcriptBlocks 生成会议记录内容：有分段时每段一个以“[mm:ss] 说话人:”开头的段落，否则按长度拆分全文
+func transcriptBlocks(meeting *models.Meeting) []notion.Block {
+	if len(meeting.Segments) == 0 {
+		if meeting.Transcript == "" {
+			return nil
+		}
+		return notion.Paragraphs(meeting.Transcript)
+	}
+
+	blocks := make([]notion.Block, 0, len(meeting.Segments))
+	for _, segment := range meeting.Segments {
+		prefix := "[" + formatSegmentTime(segment.StartTime) + "]"
+		if segment.Speaker != "" {
+			prefix += " " + segment.Speaker + ":"
+		}
+
+		richText := []notion.RichText{notion.StyledText(prefix+" ", &notion.Annotations{Bold: true})}
+		richText = append(richText, notion.Text(strings.TrimSpace(segment.Text))...)
+		blocks = append(blocks, notion.Paragraph(richText...))
+	}
+	return blocks
+}
+
+// formatSegmentTime 将秒数格式化为mm:ss，超过一小时时分钟数继续累加
+func formatSegmentTime(seconds float64) string {
+	total := int(seconds)
+	if total < 0 {
+		total = 0
+	}
+	return fmt.Sprintf("%02d:%02d", total/60, total%60)
+}
+
+var layoutFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+func parseLayoutTemplate(text string) (*template.Template, error) {
+	return template.New("layout").Funcs(layoutFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// renderLayoutText 渲染布局中的模板文本
+func renderLayoutText(text string, meeting *models.Meeting) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	tmpl, err := parseLayoutTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("解析Notion布局模板失败: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, meeting); err != nil {
+		return "", fmt.Errorf("渲染Notion布局模板失败: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
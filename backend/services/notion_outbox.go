@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"meeting-mm/apperr"
+	"meeting-mm/config"
+	"meeting-mm/models"
+	"meeting-mm/storage"
+	"meeting-mm/tracing"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSyncState 同步任务当前状态不允许该操作
+var ErrInvalidSyncState = errors.New("同步任务当前状态不允许该操作")
+
+// NotionOutbox 持久化的Notion同步发件箱，由后台worker按退避策略重试
+type NotionOutbox struct {
+	store        *storage.Store
+	services     *WorkspaceServices
+	maxDelay     time.Duration
+	pollInterval time.Duration
+	wake         chan struct{}
+
+	// enqueueMu保证同一会议最多只有一个待同步的任务
+	enqueueMu sync.Mutex
+
+	// mu保护可以在重新加载配置时修改的重试策略
+	mu          sync.Mutex
+	maxAttempts int
+	retryDelay  time.Duration
+}
+
+// NewNotionOutbox 创建NotionOutbox实例
+func NewNotionOutbox(cfg *config.Config, store *storage.Store, services *WorkspaceServices) *NotionOutbox {
+	o := &NotionOutbox{
+		store:        store,
+		services:     services,
+		maxDelay:     time.Hour,
+		pollInterval: 5 * time.Second,
+		wake:         make(chan struct{}, 1),
+	}
+	o.Reload(cfg)
+	return o
+}
+
+// Reload 更新重试次数和重试间隔，对之后的失败生效
+func (o *NotionOutbox) Reload(cfg *config.Config) {
+	maxAttempts := cfg.NotionSyncMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	retryDelay := cfg.NotionSyncRetryDelay
+	if retryDelay <= 0 {
+		retryDelay = 30 * time.Second
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.maxAttempts = maxAttempts
+	o.retryDelay = retryDelay
+}
+
+// retryPolicy 返回当前的重试次数上限和初始重试间隔
+func (o *NotionOutbox) retryPolicy() (int, time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.maxAttempts, o.retryDelay
+}
+
+// Enqueue 将会议写入发件箱，等待后台worker同步。会议已有待同步的任务时沿用该任务，不会同时执行两次同步
+func (o *NotionOutbox) Enqueue(ctx context.Context, meeting *models.Meeting) (*models.NotionSync, error) {
+	o.enqueueMu.Lock()
+	defer o.enqueueMu.Unlock()
+
+	pending, err := o.List("", models.SyncStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("读取同步发件箱失败: %w", err)
+	}
+	for _, job := range pending {
+		if job.MeetingID != meeting.ID {
+			continue
+		}
+		meeting.SyncStatus = models.SyncStatusPending
+		meeting.SyncError = ""
+		if err := o.store.Meetings.Put(meeting.ID, meeting); err != nil {
+			return nil, fmt.Errorf("保存会议失败: %w", err)
+		}
+		return job, nil
+	}
+
+	now := time.Now()
+	job := &models.NotionSync{
+		ID:            uuid.New().String(),
+		WorkspaceID:   meeting.WorkspaceID,
+		MeetingID:     meeting.ID,
+		Status:        models.SyncStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if span := tracing.FromContext(ctx); span != nil {
+		job.TraceParent = span.Traceparent()
+	}
+
+	meeting.SyncStatus = models.SyncStatusPending
+	meeting.SyncError = ""
+	if err := o.store.Meetings.Put(meeting.ID, meeting); err != nil {
+		return nil, fmt.Errorf("保存会议失败: %w", err)
+	}
+	if err := o.store.NotionSyncs.Put(job.ID, job); err != nil {
+		return nil, fmt.Errorf("写入同步发件箱失败: %w", err)
+	}
+
+	o.notify()
+	return job, nil
+}
+
+// List 列出工作区中指定状态的同步任务，workspaceID为空时不限工作区，
+// status为空时返回全部未完成（pending和failed）的任务
+func (o *NotionOutbox) List(workspaceID, status string) ([]*models.NotionSync, error) {
+	jobs, err := o.store.NotionSyncs.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*models.NotionSync
+	for _, job := range jobs {
+		if workspaceID != "" && job.WorkspaceID != workspaceID {
+			continue
+		}
+		if status == "" {
+			if job.Status != models.SyncStatusPending && job.Status != models.SyncStatusFailed {
+				continue
+			}
+		} else if job.Status != status {
+			continue
+		}
+		result = append(result, job)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// Retry 将失败或已取消的任务重新放回队列，并重置重试次数
+func (o *NotionOutbox) Retry(workspaceID, id string) (*models.NotionSync, error) {
+	job, err := o.store.NotionSyncs.Update(id, func(job *models.NotionSync) error {
+		if workspaceID != "" && job.WorkspaceID != workspaceID {
+			return storage.ErrNotFound
+		}
+		if job.Status == models.SyncStatusSynced {
+			return ErrInvalidSyncState
+		}
+		job.Status = models.SyncStatusPending
+		job.Attempts = 0
+		job.NextAttemptAt = time.Now()
+		job.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	o.updateMeeting(job.MeetingID, models.SyncStatusPending, "", nil)
+	o.notify()
+	return job, nil
+}
+
+// Cancel 取消尚未成功的同步任务
+func (o *NotionOutbox) Cancel(workspaceID, id string) (*models.NotionSync, error) {
+	job, err := o.store.NotionSyncs.Update(id, func(job *models.NotionSync) error {
+		if workspaceID != "" && job.WorkspaceID != workspaceID {
+			return storage.ErrNotFound
+		}
+		if job.Status == models.SyncStatusSynced || job.Status == models.SyncStatusCancelled {
+			return ErrInvalidSyncState
+		}
+		job.Status = models.SyncStatusCancelled
+		job.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	o.updateMeeting(job.MeetingID, models.SyncStatusFailed, "同步已取消", nil)
+	return job, nil
+}
+
+// Run 启动后台worker，直到ctx被取消。取消时等当前任务完成后返回，未开始的任务留在发件箱中，下次启动后继续
+func (o *NotionOutbox) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-o.wake:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		}
+
+		// 等到下一个任务到期，但不超过轮询间隔，以便发现其他进程写入的任务
+		wait := o.pollInterval
+		if next := o.processDue(ctx); !next.IsZero() {
+			if d := time.Until(next); d < wait {
+				wait = d
+			}
+		}
+		timer.Reset(wait)
+	}
+}
+
+// processDue 处理所有到期的待同步任务，返回剩余任务中最早的下次尝试时间
+func (o *NotionOutbox) processDue(ctx context.Context) time.Time {
+	jobs, err := o.List("", models.SyncStatusPending)
+	if err != nil {
+		slog.ErrorContext(ctx, "读取同步发件箱失败", "error", err)
+		return time.Time{}
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return time.Time{}
+		}
+		if job.NextAttemptAt.After(time.Now()) {
+			continue
+		}
+		// 服务关闭时不再开始新任务，但进行中的同步不随ctx取消，以免Notion页面只创建了一半
+		o.process(context.WithoutCancel(ctx), job)
+	}
+
+	jobs, err = o.List("", models.SyncStatusPending)
+	if err != nil {
+		return time.Time{}
+	}
+	var next time.Time
+	for _, job := range jobs {
+		if next.IsZero() || job.NextAttemptAt.Before(next) {
+			next = job.NextAttemptAt
+		}
+	}
+	return next
+}
+
+// process 执行一次同步尝试并更新任务状态
+func (o *NotionOutbox) process(ctx context.Context, job *models.NotionSync) {
+	ctx, span := tracing.Start(tracing.WithTraceparent(ctx, job.TraceParent), "NotionOutbox.process")
+	defer span.End()
+	span.SetAttribute("notion_sync.id", job.ID)
+	span.SetAttribute("notion_sync.attempt", job.Attempts+1)
+
+	meeting, err := o.store.Meetings.Get(job.MeetingID)
+	if err != nil {
+		o.finish(ctx, job.ID, nil, fmt.Errorf("读取会议失败: %w", err), true)
+		return
+	}
+
+	set, err := o.services.For(job.WorkspaceID)
+	if err != nil {
+		o.finish(ctx, job.ID, meeting, err, false)
+		return
+	}
+
+	// 数据库结构不匹配、凭据无效等不可重试的错误直接进入失败状态
+	syncErr := set.Notion.SyncMeeting(ctx, meeting)
+	span.RecordError(syncErr)
+	o.finish(ctx, job.ID, meeting, syncErr, apperr.Permanent(syncErr))
+}
+
+// finish 记录同步结果：成功则标记为synced，失败则按退避策略重排或进入失败状态
+func (o *NotionOutbox) finish(ctx context.Context, id string, meeting *models.Meeting, syncErr error, permanent bool) {
+	maxAttempts, _ := o.retryPolicy()
+	pageCreated := meeting != nil && meeting.NotionPageID != ""
+	cancelled := false
+	job, err := o.store.NotionSyncs.Update(id, func(job *models.NotionSync) error {
+		// 同步期间任务可能已被取消：不再改变任务状态，但仍保存已创建的页面ID，以免之后重试时重复创建页面
+		if job.Status != models.SyncStatusPending {
+			if !pageCreated || job.NotionPageID == meeting.NotionPageID {
+				return ErrInvalidSyncState
+			}
+			cancelled = true
+			job.NotionPageID = meeting.NotionPageID
+			job.UpdatedAt = time.Now()
+			return nil
+		}
+
+		job.Attempts++
+		job.UpdatedAt = time.Now()
+		if pageCreated {
+			job.NotionPageID = meeting.NotionPageID
+		}
+
+		if syncErr == nil {
+			job.Status = models.SyncStatusSynced
+			job.LastError = ""
+			return nil
+		}
+
+		job.LastError = syncErr.Error()
+		if permanent || job.Attempts >= maxAttempts {
+			job.Status = models.SyncStatusFailed
+		} else {
+			job.NextAttemptAt = time.Now().Add(o.backoff(job.Attempts))
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvalidSyncState) {
+			slog.ErrorContext(ctx, "更新同步任务失败", "job_id", id, "error", err)
+		}
+		return
+	}
+	if cancelled {
+		o.savePage(job.MeetingID, meeting)
+		return
+	}
+
+	switch job.Status {
+	case models.SyncStatusSynced:
+		o.updateMeeting(job.MeetingID, models.SyncStatusSynced, "", meeting)
+	case models.SyncStatusFailed:
+		slog.ErrorContext(ctx, "同步任务失败", "job_id", job.ID, "attempts", job.Attempts, "error", job.LastError)
+		o.updateMeeting(job.MeetingID, models.SyncStatusFailed, job.LastError, meeting)
+	default:
+		slog.WarnContext(ctx, "同步任务失败，将重试", "job_id", job.ID, "attempts", job.Attempts, "next_attempt_at", job.NextAttemptAt, "error", job.LastError)
+		o.updateMeeting(job.MeetingID, models.SyncStatusPending, job.LastError, meeting)
+	}
+}
+
+// backoff 计算第attempts次失败后的等待时间（指数退避，带上限）
+func (o *NotionOutbox) backoff(attempts int) time.Duration {
+	_, delay := o.retryPolicy()
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= o.maxDelay {
+			return o.maxDelay
+		}
+	}
+	return delay
+}
+
+// updateMeeting 更新会议上的同步状态，synced不为空时同时保存同步结果（页面ID和未解析的人名）。
+// 同步失败时也保存已创建的页面ID，重试时更新该页面而不是重新创建
+func (o *NotionOutbox) updateMeeting(meetingID, status, lastError string, synced *models.Meeting) {
+	_, err := o.store.Meetings.Update(meetingID, func(meeting *models.Meeting) error {
+		meeting.SyncStatus = status
+		meeting.SyncError = lastError
+		if synced != nil && synced.NotionPageID != "" {
+			meeting.NotionPageID = synced.NotionPageID
+			meeting.UnresolvedPeople = synced.UnresolvedPeople
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		slog.Error("更新会议的同步状态失败", "meeting_id", meetingID, "error", err)
+	}
+}
+
+// savePage 只保存会议的同步结果（页面ID和未解析的人名），不改变会议的同步状态
+func (o *NotionOutbox) savePage(meetingID string, synced *models.Meeting) {
+	_, err := o.store.Meetings.Update(meetingID, func(meeting *models.Meeting) error {
+		meeting.NotionPageID = synced.NotionPageID
+		meeting.UnresolvedPeople = synced.UnresolvedPeople
+		return nil
+	})
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		slog.Error("保存会议的Notion页面失败", "meeting_id", meetingID, "error", err)
+	}
+}
+
+// notify 唤醒后台worker
+func (o *NotionOutbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
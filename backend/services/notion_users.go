@@ -0,0 +1,201 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"meeting-mm/notion"
+)
+
+// fuzzyMatchThreshold 模糊匹配的最低相似度
+const fuzzyMatchThreshold = 0.8
+
+// NotionUserDirectory 将会议中的人名解析为Notion用户ID
+type NotionUserDirectory struct {
+	client  *notion.Client
+	aliases map[string]string // 规范化的人名 -> 用户ID、邮箱或Notion用户名
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	users     []notion.User
+	fetchedAt time.Time
+}
+
+// NewNotionUserDirectory 创建NotionUserDirectory实例
+func NewNotionUserDirectory(client *notion.Client, aliases map[string]string) *NotionUserDirectory {
+	normalized := make(map[string]string, len(aliases))
+	for name, target := range aliases {
+		normalized[normalizePersonName(name)] = strings.TrimSpace(target)
+	}
+	return &NotionUserDirectory{
+		client:  client,
+		aliases: normalized,
+		ttl:     10 * time.Minute,
+	}
+}
+
+// Resolve 解析一组人名，返回人名到Notion用户ID的映射以及无法解析的人名
+func (d *NotionUserDirectory) Resolve(names []string) (map[string]string, []string, error) {
+	resolved := map[string]string{}
+	var unresolved []string
+
+	users, err := d.listUsers()
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if id, ok := MatchNotionUser(name, users, d.aliases); ok {
+			resolved[name] = id
+		} else {
+			unresolved = append(unresolved, name)
+		}
+	}
+	sort.Strings(unresolved)
+	return resolved, unresolved, err
+}
+
+// listUsers 获取并缓存工作区用户（只保留真人用户）
+func (d *NotionUserDirectory) listUsers() ([]notion.User, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.users != nil && time.Since(d.fetchedAt) < d.ttl {
+		return d.users, nil
+	}
+
+	users, err := d.client.ListUsers()
+	if err != nil {
+		return d.users, err
+	}
+
+	people := make([]notion.User, 0, len(users))
+	for _, user := range users {
+		if user.Type == "" || user.Type == "person" {
+			people = append(people, user)
+		}
+	}
+	d.users = people
+	d.fetchedAt = time.Now()
+	return people, nil
+}
+
+// MatchNotionUser 按别名、精确名称、邮箱和模糊名称的顺序匹配用户，存在多个同等匹配时视为无法解析。
+// aliases的键需为规范化后的人名
+func MatchNotionUser(name string, users []notion.User, aliases map[string]string) (string, bool) {
+	key := normalizePersonName(name)
+	if key == "" {
+		return "", false
+	}
+
+	// 别名可以指向用户ID、邮箱或Notion中的用户名
+	if target, ok := aliases[key]; ok {
+		for _, user := range users {
+			if user.ID == target || (user.Person != nil && strings.EqualFold(user.Person.Email, target)) {
+				return user.ID, true
+			}
+		}
+		key = normalizePersonName(target)
+	}
+
+	bestScore := 0.0
+	bestID := ""
+	ambiguous := false
+	for _, user := range users {
+		score := personNameScore(key, user)
+		switch {
+		case score > bestScore:
+			bestScore, bestID, ambiguous = score, user.ID, false
+		case score == bestScore && score > 0 && user.ID != bestID:
+			ambiguous = true
+		}
+	}
+
+	if bestScore < fuzzyMatchThreshold || ambiguous {
+		return "", false
+	}
+	return bestID, true
+}
+
+// personNameScore 计算规范化人名与用户的匹配程度，1表示完全匹配
+func personNameScore(key string, user notion.User) float64 {
+	name := normalizePersonName(user.Name)
+	if name == key {
+		return 1
+	}
+
+	if user.Person != nil && user.Person.Email != "" {
+		email := strings.ToLower(user.Person.Email)
+		if normalizePersonName(email) == key {
+			return 1
+		}
+		if local, _, ok := strings.Cut(email, "@"); ok && normalizePersonName(local) == key {
+			return 0.95
+		}
+	}
+
+	// 名字与用户名中的某个词一致，如“Tom”对应“Tom Li”
+	for _, word := range strings.Fields(strings.ToLower(user.Name)) {
+		if len([]rune(key)) >= 2 && normalizePersonName(word) == key {
+			return 0.9
+		}
+	}
+
+	return nameSimilarity(key, name)
+}
+
+// normalizePersonName 规范化人名：转小写并去掉空白、@和常见分隔符
+func normalizePersonName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsSpace(r) || strings.ContainsRune("@._-·•", r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// nameSimilarity 基于编辑距离的相似度，范围0到1
+func nameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+		}
+		prev, curr = curr, prev
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
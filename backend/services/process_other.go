@@ -0,0 +1,10 @@
+//go:build !unix
+
+package services
+
+import "os/exec"
+
+// configureSubprocess ctx取消时结束子进程，subprocessKillDelay后不再等待其输出
+func configureSubprocess(cmd *exec.Cmd) {
+	cmd.WaitDelay = subprocessKillDelay
+}
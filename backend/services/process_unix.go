@@ -0,0 +1,18 @@
+//go:build unix
+
+package services
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureSubprocess 让子进程在独立的进程组中运行。ctx取消时向整个进程组发送SIGTERM，
+// 连同Whisper启动的ffmpeg等子进程一起结束；subprocessKillDelay后仍未退出则强制结束
+func configureSubprocess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = subprocessKillDelay
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"meeting-mm/models"
+	"meeting-mm/storage"
+
+	"github.com/google/uuid"
+)
+
+// 会议类型相关错误
+var (
+	ErrInvalidProfile  = errors.New("会议类型无效")
+	ErrProfileNotFound = errors.New("会议类型不存在")
+	ErrProfileExists   = errors.New("同名的会议类型已存在")
+)
+
+// maxProfileFields 一个会议类型最多额外提取的内容数
+const maxProfileFields = 10
+
+var (
+	profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+	fieldKeyPattern    = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]{0,39}$`)
+)
+
+// reservedFieldKeys 分析结果中已有的键，额外提取的内容不能使用
+var reservedFieldKeys = map[string]bool{
+	"summary": true, "todoItems": true, "decisions": true, "agenda": true,
+	"openQuestions": true, "risks": true, "topics": true, "keywords": true, "speakers": true, "previousItems": true,
+}
+
+// ProfileInput 创建或修改会议类型的内容，修改时整体替换
+type ProfileInput struct {
+	Name           string                `json:"name"` // 小写字母、数字、下划线和连字符，如 standup
+	Description    string                `json:"description,omitempty"`
+	SystemPrompt   string                `json:"systemPrompt,omitempty"`
+	Instructions   string                `json:"instructions,omitempty"`
+	Fields         []models.ProfileField `json:"fields,omitempty"`
+	Language       string                `json:"language,omitempty"`
+	ReportTemplate string                `json:"reportTemplate,omitempty"`
+}
+
+// validate 检查名称和额外提取的内容
+func (in *ProfileInput) validate() error {
+	if !profileNamePattern.MatchString(in.Name) {
+		return fmt.Errorf("%w: 名称只能包含小写字母、数字、下划线和连字符，不超过40个字符", ErrInvalidProfile)
+	}
+	if len(in.Fields) > maxProfileFields {
+		return fmt.Errorf("%w: 额外提取的内容不能超过%d项", ErrInvalidProfile, maxProfileFields)
+	}
+	seen := map[string]bool{}
+	for i, field := range in.Fields {
+		if !fieldKeyPattern.MatchString(field.Key) || reservedFieldKeys[field.Key] {
+			return fmt.Errorf("%w: 第%d项内容的键名无效: %q", ErrInvalidProfile, i+1, field.Key)
+		}
+		if seen[field.Key] {
+			return fmt.Errorf("%w: 键名重复: %q", ErrInvalidProfile, field.Key)
+		}
+		seen[field.Key] = true
+		if strings.TrimSpace(field.Label) == "" {
+			return fmt.Errorf("%w: 第%d项内容缺少标题", ErrInvalidProfile, i+1)
+		}
+	}
+	return nil
+}
+
+// apply 将输入写入会议类型
+func (in *ProfileInput) apply(profile *models.Profile) {
+	profile.Name = in.Name
+	profile.Description = strings.TrimSpace(in.Description)
+	profile.SystemPrompt = strings.TrimSpace(in.SystemPrompt)
+	profile.Instructions = strings.TrimSpace(in.Instructions)
+	profile.Fields = make([]models.ProfileField, len(in.Fields))
+	for i, field := range in.Fields {
+		profile.Fields[i] = models.ProfileField{
+			Key:         field.Key,
+			Label:       strings.TrimSpace(field.Label),
+			Description: strings.TrimSpace(field.Description),
+		}
+	}
+	profile.Language = strings.TrimSpace(in.Language)
+	profile.ReportTemplate = in.ReportTemplate
+	profile.UpdatedAt = time.Now()
+}
+
+// ProfileService 管理工作区的会议类型，成员可以查看和使用，只有所有者可以修改
+type ProfileService struct {
+	store *storage.Store
+	mu    sync.Mutex // 保证工作区内名称唯一
+}
+
+// NewProfileService 创建ProfileService实例
+func NewProfileService(store *storage.Store) *ProfileService {
+	return &ProfileService{store: store}
+}
+
+// List 按名称列出工作区的会议类型
+func (s *ProfileService) List(workspaceID string) ([]*models.Profile, error) {
+	all, err := s.store.Profiles.List()
+	if err != nil {
+		return nil, err
+	}
+	profiles := []*models.Profile{}
+	for _, profile := range all {
+		if profile.WorkspaceID == workspaceID {
+			profiles = append(profiles, profile)
+		}
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// Get 按名称查找工作区的会议类型
+func (s *ProfileService) Get(workspaceID, name string) (*models.Profile, error) {
+	profiles, err := s.List(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+}
+
+// Create 在调用方的工作区中创建会议类型
+func (s *ProfileService) Create(p *Principal, input ProfileInput) (*models.Profile, error) {
+	if p.Role != models.RoleOwner {
+		return nil, ErrForbidden
+	}
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.Get(p.WorkspaceID, input.Name); err == nil {
+		return nil, ErrProfileExists
+	} else if !errors.Is(err, ErrProfileNotFound) {
+		return nil, err
+	}
+
+	profile := &models.Profile{ID: uuid.New().String(), WorkspaceID: p.WorkspaceID, CreatedAt: time.Now()}
+	input.apply(profile)
+	if err := s.store.Profiles.Put(profile.ID, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Update 整体替换会议类型，input.Name与name不同时重命名
+func (s *ProfileService) Update(p *Principal, name string, input ProfileInput) (*models.Profile, error) {
+	if p.Role != models.RoleOwner {
+		return nil, ErrForbidden
+	}
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile, err := s.Get(p.WorkspaceID, name)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != name {
+		if _, err := s.Get(p.WorkspaceID, input.Name); err == nil {
+			return nil, ErrProfileExists
+		} else if !errors.Is(err, ErrProfileNotFound) {
+			return nil, err
+		}
+	}
+	return s.store.Profiles.Update(profile.ID, func(profile *models.Profile) error {
+		input.apply(profile)
+		return nil
+	})
+}
+
+// Delete 删除会议类型，已分析的会议不受影响
+func (s *ProfileService) Delete(p *Principal, name string) (*models.Profile, error) {
+	if p.Role != models.RoleOwner {
+		return nil, ErrForbidden
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile, err := s.Get(p.WorkspaceID, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Profiles.Delete(profile.ID); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// profilePrompt 返回会议类型追加在分析提示词中的要求：额外提取的内容和输出语言
+func profilePrompt(profile *models.Profile) string {
+	if profile == nil {
+		return ""
+	}
+	var b strings.Builder
+	if len(profile.Fields) > 0 {
+		b.WriteString("\n\n请同时提取以下内容，在上述JSON中增加对应的字符串数组（没有相关内容时为空数组）：\n")
+		for _, field := range profile.Fields {
+			fmt.Fprintf(&b, "- \"%s\"：%s", field.Key, field.Label)
+			if field.Description != "" {
+				fmt.Fprintf(&b, "（%s）", field.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if profile.Language != "" {
+		fmt.Fprintf(&b, "\n\n请使用%s撰写摘要、待办事项、决策点和其他内容，JSON的键名保持不变。", profile.Language)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// extraFields 从模型返回的JSON中读取会议类型额外提取的内容。
+// 每项内容应为字符串数组，也接受单个字符串；对象等其他值以JSON文本保留
+func extraFields(profile *models.Profile, content map[string]json.RawMessage) []models.ExtraField {
+	if profile == nil || len(profile.Fields) == 0 {
+		return nil
+	}
+	fields := make([]models.ExtraField, len(profile.Fields))
+	for i, field := range profile.Fields {
+		fields[i] = models.ExtraField{Key: field.Key, Label: field.Label, Items: []string{}}
+		raw := content[field.Key]
+		var single string
+		if json.Unmarshal(raw, &single) == nil {
+			if single = strings.TrimSpace(single); single != "" {
+				fields[i].Items = append(fields[i].Items, single)
+			}
+			continue
+		}
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			continue
+		}
+		for _, item := range items {
+			var text string
+			if json.Unmarshal(item, &text) != nil {
+				text = string(item)
+			}
+			if text = strings.TrimSpace(text); text != "" && text != "null" {
+				fields[i].Items = append(fields[i].Items, text)
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"meeting-mm/config"
+	"meeting-mm/metrics"
+	"meeting-mm/models"
+)
+
+// DefaultReportTemplate 未指定模板时使用的内置模板
+const DefaultReportTemplate = "default"
+
+// reportTemplateExt 模板目录中模板文件的扩展名，文件名去掉扩展名后即为模板名
+const reportTemplateExt = ".md.tmpl"
+
+// ErrUnknownReportTemplate 请求的报告模板不存在
+var ErrUnknownReportTemplate = errors.New("未知的报告模板")
+
+// builtinReportTemplates 内置的Markdown报告模板，数据为会议对象。模板目录中的同名文件会覆盖它们
+var builtinReportTemplates = map[string]string{
+	// default 完整纪要：基本信息、摘要、讨论主题、待办、决策、会议类型额外提取的内容、待解决的问题、风险、发言人和会议记录；
+	// 有议程时待办和决策按议程项整理
+	"default": `{{define "todo"}}{{checkbox .Status}} {{with continuity .Continuity}}【{{.}}】{{end}}{{.Description}}{{if .Assignee}}（负责人：{{.Assignee}}）{{end}}{{if not .DueDate.IsZero}}（截止：{{date .DueDate}}）{{end}}{{end -}}
+{{define "decision"}}{{.Description}}{{if .MadeBy}}（{{.MadeBy}}）{{end}}{{end -}}
+# {{.Title}}
+
+- **会议日期**：{{date .Date}}
+{{- if .Participants}}
+- **参与人员**：{{join .Participants "、"}}
+{{- end}}
+{{- if .Keywords}}
+- **关键词**：{{join .Keywords "、"}}
+{{- end}}
+
+## 摘要
+
+{{or .Summary "（无）"}}
+
+{{with .Topics -}}
+## 讨论主题
+
+{{range $i, $t := .}}{{inc $i}}. {{$t.Title}}{{if $t.HasTimeRange}}（{{timestamp $t.StartTime}}–{{timestamp $t.EndTime}}）{{end}}{{with $t.Summary}}：{{.}}{{end}}
+{{end}}
+{{end -}}
+{{if .AgendaItems -}}
+## 议程
+
+{{range $i, $a := .AgendaItems -}}
+### {{inc $i}}. {{$a.Title}}（{{agendaStatus $a.Status}}）
+
+{{with $a.Summary}}{{.}}
+
+{{end -}}
+{{with $.AgendaDecisions $a.ID}}**决策**
+
+{{range .}}- {{template "decision" .}}
+{{end}}
+{{end -}}
+{{with $.AgendaTodos $a.ID}}**待办**
+
+{{range .}}- {{template "todo" .}}
+{{end}}
+{{end -}}
+{{end -}}
+{{with .AgendaTodos ""}}## 其他待办事项
+
+{{range .}}- {{template "todo" .}}
+{{end}}
+{{end -}}
+{{with .AgendaDecisions ""}}## 其他决策事项
+
+{{range $i, $d := .}}{{inc $i}}. {{template "decision" $d}}
+{{end}}
+{{end -}}
+{{else -}}
+## 待办事项
+
+{{range .TodoItems -}}
+- {{template "todo" .}}
+{{else -}}
+（无）
+{{end}}
+## 决策事项
+
+{{range $i, $d := .Decisions -}}
+{{inc $i}}. {{template "decision" $d}}
+{{else -}}
+（无）
+{{end}}
+{{end -}}
+{{range .ExtraFields -}}
+## {{.Label}}
+
+{{range .Items -}}
+- {{.}}
+{{else -}}
+（无）
+{{end}}
+{{end -}}
+{{with .OpenQuestions -}}
+## 待解决的问题
+
+{{range .}}- {{.}}
+{{end}}
+{{end -}}
+{{with .Risks -}}
+## 风险和阻塞项
+
+{{range .}}- 【{{riskKind .Kind}}】{{.Description}}{{with .Owner}}（跟进：{{.}}）{{end}}
+{{end}}
+{{end -}}
+{{with .SpeakerInsights -}}
+## 发言人
+
+{{range .}}- {{.Speaker}}：{{sentiment .Sentiment}}，参与度{{engagement .Engagement}}{{with .Note}}。{{.}}{{end}}
+{{end}}
+{{end -}}
+## 会议记录
+
+{{if .Segments -}}
+{{range .Segments -}}
+**[{{timestamp .StartTime}}]{{if .Speaker}} {{.Speaker}}:{{end}}** {{trim .Text}}
+
+{{end}}
+{{- else -}}
+{{.Transcript}}
+{{- end}}
+`,
+	// brief 简要纪要：不包含会议记录，适合直接发送给参会人
+	"brief": `# {{.Title}}（{{date .Date}}）
+
+{{or .Summary "（无摘要）"}}
+{{- if .TodoItems}}
+
+**待办事项**
+
+{{range .TodoItems -}}
+- {{checkbox .Status}} {{with continuity .Continuity}}【{{.}}】{{end}}{{.Description}}{{if .Assignee}} @{{.Assignee}}{{end}}{{if not .DueDate.IsZero}} {{date .DueDate}}{{end}}
+{{end}}
+{{- end}}
+{{- if .Decisions}}
+
+**决策事项**
+
+{{range .Decisions -}}
+- {{.Description}}
+{{end}}
+{{- end}}
+{{- range .ExtraFields}}{{if .Items}}
+
+**{{.Label}}**
+
+{{range .Items -}}
+- {{.}}
+{{end}}
+{{- end}}{{end}}
+`,
+}
+
+var reportFuncs = template.FuncMap{
+	"join": strings.Join,
+	"trim": strings.TrimSpace,
+	"inc":  func(i int) int { return i + 1 },
+	// date 以YYYY-MM-DD格式显示日期，零值显示为空
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	},
+	// checkbox 待办事项的Markdown复选框，已完成时勾选
+	"checkbox": func(status string) string {
+		if status == "completed" {
+			return "[x]"
+		}
+		return "[ ]"
+	},
+	"timestamp": formatSegmentTime,
+	// agendaStatus 议程项讨论状态的显示文字
+	"agendaStatus": func(status string) string {
+		switch status {
+		case models.AgendaCovered:
+			return "已讨论"
+		case models.AgendaDeferred:
+			return "已推迟"
+		}
+		return "未分析"
+	},
+	// continuity 待办事项与之前会议中条目关系的显示文字，新的待办事项为空
+	"continuity": func(value string) string { return continuityLabels[value] },
+	// riskKind、sentiment和engagement 风险类型、发言人态度和参与度的显示文字
+	"riskKind":   models.RiskKindLabel,
+	"sentiment":  models.SentimentLabel,
+	"engagement": models.EngagementLabel,
+}
+
+// ReportTemplateInfo 可用的报告模板
+type ReportTemplateInfo struct {
+	Name    string `json:"name"`
+	Builtin bool   `json:"builtin"` // 内置模板；被模板目录中的同名文件覆盖时为false
+}
+
+// ReportRenderer 使用text/template将会议渲染为Markdown报告，相同的会议和模板总是得到相同的结果
+type ReportRenderer struct {
+	mu          sync.RWMutex
+	templates   map[string]*template.Template
+	builtin     map[string]bool
+	defaultName string
+}
+
+// NewReportRenderer 加载内置模板和cfg.ReportTemplateDir中的模板，模板无效时返回错误
+func NewReportRenderer(cfg *config.Config) (*ReportRenderer, error) {
+	r := &ReportRenderer{}
+	if err := r.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新加载模板，加载失败时保留原来的模板
+func (r *ReportRenderer) Reload(cfg *config.Config) error {
+	templates := map[string]*template.Template{}
+	builtin := map[string]bool{}
+	for name, text := range builtinReportTemplates {
+		tmpl, err := parseReportTemplate(name, text)
+		if err != nil {
+			return err
+		}
+		templates[name] = tmpl
+		builtin[name] = true
+	}
+
+	if cfg.ReportTemplateDir != "" {
+		paths, err := filepath.Glob(filepath.Join(cfg.ReportTemplateDir, "*"+reportTemplateExt))
+		if err != nil {
+			return fmt.Errorf("查找报告模板失败: %w", err)
+		}
+		for _, path := range paths {
+			name := strings.TrimSuffix(filepath.Base(path), reportTemplateExt)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("读取报告模板失败: %w", err)
+			}
+			tmpl, err := parseReportTemplate(name, string(data))
+			if err != nil {
+				return fmt.Errorf("报告模板%s无效: %w", path, err)
+			}
+			templates[name] = tmpl
+			builtin[name] = false
+		}
+	}
+
+	defaultName := cfg.ReportTemplate
+	if defaultName == "" {
+		defaultName = DefaultReportTemplate
+	}
+	if templates[defaultName] == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownReportTemplate, defaultName)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates = templates
+	r.builtin = builtin
+	r.defaultName = defaultName
+	return nil
+}
+
+// parseReportTemplate 解析模板，并用示例会议试渲染一次，使字段名拼写错误等问题在加载时就暴露出来
+func parseReportTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(reportFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(new(bytes.Buffer), sampleReportMeeting); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// sampleReportMeeting 校验模板时使用的示例会议，各个列表都有内容
+var sampleReportMeeting = &models.Meeting{
+	Title:        "示例会议",
+	Date:         time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	Participants: []string{"张三"},
+	Transcript:   "会议内容",
+	Segments:     []models.TranscriptSegment{{StartTime: 1, EndTime: 2, Speaker: "张三", Text: "会议内容"}},
+	Summary:      "摘要",
+	AgendaItems:  []models.AgendaItem{{ID: "agenda", Title: "议程", Status: models.AgendaCovered, Summary: "讨论摘要"}},
+	TodoItems: []models.TodoItem{
+		{Description: "待办", Assignee: "张三", DueDate: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Status: "pending", AgendaID: "agenda"},
+		{Description: "其他待办", Status: "pending", ActionItemID: "action", Continuity: models.ContinuityCarriedOver},
+	},
+	Decisions:     []models.Decision{{Description: "决策", MadeBy: "张三", AgendaID: "agenda"}, {Description: "其他决策"}},
+	Profile:       "standup",
+	ExtraFields:   []models.ExtraField{{Key: "followUps", Label: "跟进事项", Items: []string{"跟进"}}},
+	OpenQuestions: []string{"问题"},
+	Risks:         []models.Risk{{Description: "风险", Kind: models.RiskKindBlocker, Owner: "张三"}},
+	Topics:        []models.Topic{{Title: "主题", Summary: "主题摘要", StartTime: 1, EndTime: 2}},
+	Keywords:      []string{"关键词"},
+	SpeakerInsights: []models.SpeakerInsight{
+		{Speaker: "张三", Sentiment: models.SentimentPositive, Engagement: models.EngagementHigh, Note: "主导讨论"},
+	},
+}
+
+// Templates 按名称列出可用的模板
+func (r *ReportRenderer) Templates() []ReportTemplateInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]ReportTemplateInfo, 0, len(r.templates))
+	for name := range r.templates {
+		list = append(list, ReportTemplateInfo{Name: name, Builtin: r.builtin[name]})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Has 检查模板是否存在，name为空表示默认模板
+func (r *ReportRenderer) Has(name string) bool {
+	if name == "" {
+		return true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.templates[name] != nil
+}
+
+// DefaultTemplate 返回未指定模板时使用的模板名
+func (r *ReportRenderer) DefaultTemplate() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultName
+}
+
+// Render 使用指定模板渲染会议报告，name为空时使用默认模板
+func (r *ReportRenderer) Render(name string, meeting *models.Meeting) (markdown string, err error) {
+	defer func(start time.Time) { metrics.ObserveStage(metrics.StageReport, start, err) }(time.Now())
+
+	r.mu.RLock()
+	if name == "" {
+		name = r.defaultName
+	}
+	tmpl := r.templates[name]
+	r.mu.RUnlock()
+	if tmpl == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownReportTemplate, name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, meeting); err != nil {
+		return "", fmt.Errorf("渲染报告失败: %w", err)
+	}
+	return tidyMarkdown(buf.String()), nil
+}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// tidyMarkdown 合并连续的空行并去掉首尾空白，模板中的条件块不必精确控制换行
+func tidyMarkdown(markdown string) string {
+	return strings.TrimSpace(blankLines.ReplaceAllString(markdown, "\n\n")) + "\n"
+}
@@ -1,49 +1,131 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"meeting-mm/apperr"
 	"meeting-mm/config"
+	"meeting-mm/metrics"
+	"meeting-mm/models"
+	"meeting-mm/tracing"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// subprocessKillDelay 取消转录后等待Whisper子进程退出的时间，超时后强制结束
+	subprocessKillDelay = 5 * time.Second
+	// StaleTempAge 超过该时间未修改的临时文件视为上次运行遗留
+	StaleTempAge = time.Hour
+)
+
+// WhisperTempDir 返回转录使用的临时目录
+func WhisperTempDir() string {
+	return filepath.Join(os.TempDir(), "meeting-mm-whisper")
+}
+
+// SweepWhisperTemp 删除临时目录中超过maxAge未修改的文件（上次运行被中断时遗留），返回删除的文件数
+func SweepWhisperTemp(maxAge time.Duration) (int, error) {
+	dir := WhisperTempDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
 // WhisperService 提供语音识别功能
 type WhisperService struct {
 	modelPath       string
 	useLocalWhisper bool
+	model           string // Python Whisper的模型名称
+	language        string // 识别语言，为空时自动检测
+	python          string // 运行Whisper脚本的Python，为空时自动查找
 	tempDir         string
 }
 
 // NewWhisperService 创建WhisperService实例
 func NewWhisperService(cfg *config.Config) *WhisperService {
 	// 创建临时目录
-	tempDir := filepath.Join(os.TempDir(), "meeting-mm-whisper")
+	tempDir := WhisperTempDir()
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		log.Printf("创建临时目录失败: %v", err)
+		slog.Warn("创建临时目录失败，使用系统临时目录", "dir", tempDir, "error", err)
 		tempDir = os.TempDir()
 	}
 
+	model := cfg.WhisperModel
+	if model == "" {
+		model = "base"
+	}
+
 	return &WhisperService{
 		modelPath:       cfg.WhisperModelPath,
 		useLocalWhisper: cfg.UseLocalWhisper,
+		model:           model,
+		language:        cfg.WhisperLanguage,
+		python:          cfg.WhisperPython,
 		tempDir:         tempDir,
 	}
 }
 
-// TranscribeAudio 将音频文件转录为文本
-func (s *WhisperService) TranscribeAudio(audioData []byte) (string, error) {
+// Transcription 一次转录的结果：完整文本和带时间戳的片段
+type Transcription struct {
+	Text     string
+	Segments []models.TranscriptSegment
+}
+
+// Transcribe 将音频文件转录为文本和带时间戳的片段
+func (s *WhisperService) Transcribe(ctx context.Context, audioData []byte) (result *Transcription, err error) {
+	ctx, span := tracing.Start(ctx, "WhisperService.Transcribe")
+	defer span.Finish(&err)
+	defer func(start time.Time) { metrics.ObserveStage(metrics.StageTranscribe, start, err) }(time.Now())
+	span.SetAttribute("whisper.model", s.model)
+	span.SetAttribute("audio.bytes", len(audioData))
+
 	if !s.useLocalWhisper {
 		return s.transcribeWithAPI(audioData)
 	}
-	return s.transcribeWithPythonWhisper(audioData)
+	result, err = s.transcribeWithPythonWhisper(ctx, audioData)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeTranscriptionFailed, "转录音频失败", err)
+	}
+	span.SetAttribute("transcript.segments", len(result.Segments))
+	return result, nil
+}
+
+// TranscribeAudio 将音频文件转录为文本
+func (s *WhisperService) TranscribeAudio(ctx context.Context, audioData []byte) (string, error) {
+	result, err := s.Transcribe(ctx, audioData)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
 }
 
 // transcribeWithLocalWhisper 使用本地Whisper.cpp进行转录
@@ -87,19 +169,21 @@ func (s *WhisperService) transcribeWithLocalWhisper(audioData []byte) (string, e
 }
 
 // transcribeWithPythonWhisper 使用Python版本的Whisper模型进行转录
-func (s *WhisperService) transcribeWithPythonWhisper(audioData []byte) (string, error) {
+func (s *WhisperService) transcribeWithPythonWhisper(ctx context.Context, audioData []byte) (*Transcription, error) {
 	// 创建临时音频文件
 	audioFile := filepath.Join(s.tempDir, uuid.New().String()+".mp3")
 	if err := os.WriteFile(audioFile, audioData, 0644); err != nil {
-		return "", fmt.Errorf("保存音频文件失败: %w", err)
+		return nil, fmt.Errorf("保存音频文件失败: %w", err)
 	}
 	defer os.Remove(audioFile)
 
-	// 创建Python脚本文件
-	scriptFile := filepath.Join(s.tempDir, "whisper_transcribe.py")
+	// 创建Python脚本文件，每次转录使用独立的文件，避免并发的转录互相删除
+	scriptFile := filepath.Join(s.tempDir, uuid.New().String()+".py")
 	scriptContent := `
 import sys
 import os
+import json
+import time
 import whisper
 import torch
 
@@ -108,70 +192,217 @@ device = "cpu"
 torch.set_default_tensor_type(torch.FloatTensor)
 
 # 加载模型
-model = whisper.load_model("base", device=device)
+model = whisper.load_model(sys.argv[2], device=device)
+language = sys.argv[3] if len(sys.argv) > 3 and sys.argv[3] else None
 
-# 加载音频
+# 加载音频（通过ffmpeg解码并重采样为16kHz单声道），统计信息输出到stderr供服务记录指标
 audio_file = sys.argv[1]
+started = time.time()
 audio = whisper.load_audio(audio_file)
-audio = whisper.pad_or_trim(audio)
-
-# 生成梅尔频谱图
-mel = whisper.log_mel_spectrogram(audio, n_mels=model.dims.n_mels).to(device)
-
-# 检测语言
-_, probs = model.detect_language(mel)
-detected_language = max(probs, key=probs.get)
-print(f"检测到的语言: {detected_language}", file=sys.stderr)
+print("MM_STATS " + json.dumps({"audioSeconds": len(audio) / whisper.audio.SAMPLE_RATE, "normalizeSeconds": time.time() - started}), file=sys.stderr)
 
-# 解码音频
-options = whisper.DecodingOptions(fp16=False)  # 禁用fp16
-result = whisper.decode(model, mel, options)
+# 转录完整音频（超过30秒的部分按窗口依次解码），未指定语言时自动检测
+result = model.transcribe(audio, language=language, fp16=False)  # 禁用fp16
+print(f"检测到的语言: {result.get('language')}", file=sys.stderr)
 
-# 输出转录文本
-print(result.text)
+# 以JSON输出转录文本和带时间戳的片段
+segments = [{"start": s["start"], "end": s["end"], "text": s["text"].strip()} for s in result["segments"]]
+print(json.dumps({"text": result["text"].strip(), "segments": segments}, ensure_ascii=False))
 `
 	if err := os.WriteFile(scriptFile, []byte(scriptContent), 0644); err != nil {
-		return "", fmt.Errorf("创建Python脚本失败: %w", err)
+		return nil, fmt.Errorf("创建Python脚本失败: %w", err)
 	}
 	defer os.Remove(scriptFile)
 
-	// 明确指定使用Anaconda环境中的Python
-	pythonCmd := "/Users/xujiawei/anaconda3/bin/python"
+	pythonCmd := s.pythonCommand()
 
-	// 如果指定的Python不存在，尝试使用默认的Python
-	if _, err := os.Stat(pythonCmd); os.IsNotExist(err) {
-		// 查找可用的Python
-		paths := []string{
-			"python3",
-			"python",
+	// 执行Python脚本，转录结果以JSON输出到stdout，日志和统计信息输出到stderr。
+	// ctx取消（请求结束或服务关闭超时）时结束脚本及其子进程
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, pythonCmd, scriptFile, audioFile, s.model, s.language)
+	cmd.Stderr = &stderr
+	configureSubprocess(cmd)
+	started := time.Now()
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("执行Python Whisper失败: %w, 输出: %s", err, stderr.String())
+	}
+	s.recordStats(ctx, stderr.String(), time.Since(started))
+
+	return parseWhisperOutput(output)
+}
+
+// whisperOutput Python脚本输出的转录结果，时间以秒为单位
+type whisperOutput struct {
+	Text     string `json:"text"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+}
+
+// parseWhisperOutput 解析脚本输出的转录结果，跳过没有文字的片段；没有片段时只有完整文本
+func parseWhisperOutput(output []byte) (*Transcription, error) {
+	var parsed whisperOutput
+	if err := json.Unmarshal(bytes.TrimSpace(output), &parsed); err != nil {
+		return nil, fmt.Errorf("解析Whisper输出失败: %w", err)
+	}
+
+	result := &Transcription{Text: strings.TrimSpace(parsed.Text)}
+	now := time.Now()
+	var texts []string
+	for _, segment := range parsed.Segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
 		}
-		for _, path := range paths {
+		result.Segments = append(result.Segments, models.TranscriptSegment{
+			ID:        uuid.New().String(),
+			StartTime: segment.Start,
+			EndTime:   max(segment.End, segment.Start),
+			Text:      text,
+			Timestamp: now,
+		})
+		texts = append(texts, text)
+	}
+	if result.Text == "" {
+		result.Text = strings.Join(texts, "\n")
+	}
+	return result, nil
+}
+
+// pythonCommand 返回运行Whisper脚本的Python：优先使用配置的WHISPER_PYTHON，
+// 其次是Anaconda环境中的Python，最后在PATH中查找python3或python
+func (s *WhisperService) pythonCommand() string {
+	if s.python != "" {
+		return s.python
+	}
+
+	pythonCmd := "/Users/xujiawei/anaconda3/bin/python"
+	if _, err := os.Stat(pythonCmd); os.IsNotExist(err) {
+		for _, path := range []string{"python3", "python"} {
 			if _, err := exec.LookPath(path); err == nil {
-				pythonCmd = path
-				break
+				return path
 			}
 		}
 	}
+	return pythonCmd
+}
 
-	// 执行Python脚本
-	cmd := exec.Command(pythonCmd, scriptFile, audioFile)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("执行Python Whisper失败: %w, 输出: %s", err, string(output))
+// ErrWhisperModelMissing Whisper模型文件尚未下载，首次转录时会自动下载
+var ErrWhisperModelMissing = errors.New("Whisper模型尚未下载，首次转录时自动下载")
+
+// whisperModelFiles 模型名称对应的下载文件名（与openai-whisper的_MODELS一致），未列出的名称为"<名称>.pt"
+var whisperModelFiles = map[string]string{
+	"large":    "large-v3.pt",
+	"turbo":    "large-v3-turbo.pt",
+	"large-v3": "large-v3.pt",
+}
+
+// whisperModelNames openai-whisper可以下载的模型名称
+var whisperModelNames = map[string]bool{
+	"tiny": true, "tiny.en": true, "base": true, "base.en": true, "small": true, "small.en": true,
+	"medium": true, "medium.en": true, "large-v1": true, "large-v2": true, "large-v3": true,
+	"large": true, "large-v3-turbo": true, "turbo": true,
+}
+
+// KnownWhisperModel 判断是否为openai-whisper的模型名称（而不是模型文件路径）
+func KnownWhisperModel(name string) bool {
+	return whisperModelNames[name]
+}
+
+// CheckTranscriber 检查转录环境：Python可以导入whisper模块、ffmpeg可用、模型文件已下载。
+// 模型文件不存在时返回ErrWhisperModelMissing
+func (s *WhisperService) CheckTranscriber(ctx context.Context) error {
+	if !s.useLocalWhisper {
+		return errors.New("未启用本地Whisper，API转录尚未实现")
+	}
+
+	pythonCmd := s.pythonCommand()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, pythonCmd, "-c", "import whisper")
+	cmd.Stderr = &stderr
+	configureSubprocess(cmd)
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("Python（%s）无法导入whisper模块: %s", pythonCmd, lastLine(stderr.String()))
+		}
+		return fmt.Errorf("无法运行Python（%s）: %w", pythonCmd, err)
+	}
+
+	// whisper通过ffmpeg解码音频
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return errors.New("未找到ffmpeg")
+	}
+
+	if _, err := os.Stat(s.modelFile()); err != nil {
+		return ErrWhisperModelMissing
+	}
+	return nil
+}
+
+// modelFile 返回模型文件的路径：模型名称本身是文件路径时直接使用，否则为whisper下载目录中的文件
+func (s *WhisperService) modelFile() string {
+	if strings.HasSuffix(s.model, ".pt") {
+		return s.model
+	}
+	name, ok := whisperModelFiles[s.model]
+	if !ok {
+		name = s.model + ".pt"
+	}
+	cacheDir := os.Getenv("XDG_CACHE_HOME")
+	if cacheDir == "" {
+		home, _ := os.UserHomeDir()
+		cacheDir = filepath.Join(home, ".cache")
 	}
+	return filepath.Join(cacheDir, "whisper", name)
+}
+
+// lastLine 返回输出的最后一个非空行，通常是Python异常信息
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// whisperStats Python脚本输出的音频统计信息
+type whisperStats struct {
+	AudioSeconds     float64 `json:"audioSeconds"`
+	NormalizeSeconds float64 `json:"normalizeSeconds"`
+}
 
-	return strings.TrimSpace(string(output)), nil
+// recordStats 从脚本的stderr中取出统计信息，记录音频归一化耗时和转录的实时率
+func (s *WhisperService) recordStats(ctx context.Context, stderr string, elapsed time.Duration) {
+	for _, line := range strings.Split(stderr, "\n") {
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "MM_STATS ")
+		if !ok {
+			continue
+		}
+		var stats whisperStats
+		if err := json.Unmarshal([]byte(data), &stats); err != nil || stats.AudioSeconds <= 0 {
+			return
+		}
+		metrics.PipelineStageDuration.Observe(stats.NormalizeSeconds, metrics.StageNormalize, "ok")
+		metrics.WhisperRealTimeFactor.Observe(elapsed.Seconds()/stats.AudioSeconds, s.model)
+		if span := tracing.FromContext(ctx); span != nil {
+			span.SetAttribute("audio.seconds", stats.AudioSeconds)
+			span.SetAttribute("whisper.realtime_factor", elapsed.Seconds()/stats.AudioSeconds)
+		}
+		return
+	}
 }
 
 // transcribeWithAPI 使用API进行转录（备用方案）
-func (s *WhisperService) transcribeWithAPI(audioData []byte) (string, error) {
+func (s *WhisperService) transcribeWithAPI(audioData []byte) (*Transcription, error) {
 	// 这里可以实现调用OpenAI Whisper API或其他语音识别API的逻辑
 	// 由于我们优先使用本地Whisper，这里暂时返回错误
-	return "", errors.New("API转录功能尚未实现，请启用本地Whisper")
+	err := apperr.New(apperr.CodeTranscriptionFailed, "API转录功能尚未实现，请启用本地Whisper")
+	err.Retryable = false
+	return nil, err
 }
 
 // StreamTranscribe 流式转录音频
-func (s *WhisperService) StreamTranscribe(audioStream io.Reader) (chan string, error) {
+func (s *WhisperService) StreamTranscribe(ctx context.Context, audioStream io.Reader) (chan string, error) {
 	// 创建结果通道
 	resultChan := make(chan string)
 
@@ -206,7 +437,7 @@ func (s *WhisperService) StreamTranscribe(audioStream io.Reader) (chan string, e
 		}
 
 		// 转录音频
-		transcript, err := s.TranscribeAudio(audioData)
+		transcript, err := s.TranscribeAudio(ctx, audioData)
 		if err != nil {
 			resultChan <- fmt.Sprintf("错误: 转录失败: %v", err)
 			return
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"meeting-mm/config"
+	"meeting-mm/models"
+	"meeting-mm/storage"
+)
+
+// ServiceSet 一个工作区使用的全部外部服务
+type ServiceSet struct {
+	DeepSeek *DeepSeekService
+	Notion   *NotionService
+	Whisper  *WhisperService
+}
+
+// WorkspaceServices 按工作区构建服务：工作区设置覆盖服务器配置，未设置的项沿用服务器配置。
+// 服务器的Notion和DeepSeek凭据只提供给ServerCredentials的工作区，其他工作区未设置凭据时视为未配置
+type WorkspaceServices struct {
+	store *storage.Store
+	box   *storage.SecretBox
+
+	// mu保护服务器配置、默认服务和缓存，重新加载配置时整体替换
+	mu       sync.Mutex
+	cfg      *config.Config
+	defaults *ServiceSet
+	cache    map[string]cachedServiceSet
+}
+
+// cachedServiceSet 缓存的工作区服务，工作区设置更新后重新创建
+type cachedServiceSet struct {
+	updatedAt time.Time
+	set       *ServiceSet
+}
+
+// NewWorkspaceServices 创建WorkspaceServices实例，box用于加解密工作区保存的凭据
+func NewWorkspaceServices(cfg *config.Config, store *storage.Store, box *storage.SecretBox) *WorkspaceServices {
+	return &WorkspaceServices{
+		cfg:      cfg,
+		store:    store,
+		box:      box,
+		defaults: newServiceSet(cfg),
+		cache:    map[string]cachedServiceSet{},
+	}
+}
+
+func newServiceSet(cfg *config.Config) *ServiceSet {
+	return &ServiceSet{
+		DeepSeek: NewDeepSeekService(cfg),
+		Notion:   NewNotionService(cfg),
+		Whisper:  NewWhisperService(cfg),
+	}
+}
+
+// For 返回工作区使用的服务，workspaceID为空或工作区不存在时返回服务器配置的服务
+func (w *WorkspaceServices) For(workspaceID string) (*ServiceSet, error) {
+	if workspaceID == "" {
+		return w.defaultSet(), nil
+	}
+
+	workspace, err := w.store.Workspaces.Get(workspaceID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return w.defaultSet(), nil
+		}
+		return nil, fmt.Errorf("读取工作区失败: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if cached, ok := w.cache[workspaceID]; ok && cached.updatedAt.Equal(workspace.UpdatedAt) {
+		return cached.set, nil
+	}
+
+	cfg, err := w.workspaceConfig(workspace)
+	if err != nil {
+		return nil, err
+	}
+	set := newServiceSet(cfg)
+	w.cache[workspaceID] = cachedServiceSet{updatedAt: workspace.UpdatedAt, set: set}
+	return set, nil
+}
+
+// defaultSet 返回服务器配置的服务
+func (w *WorkspaceServices) defaultSet() *ServiceSet {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.defaults
+}
+
+// Reload 使用新的服务器配置重新创建服务，已缓存的工作区服务也会在下次使用时重新创建。
+// 进行中的请求继续使用原来的服务
+func (w *WorkspaceServices) Reload(cfg *config.Config) {
+	defaults := newServiceSet(cfg)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cfg = cfg
+	w.defaults = defaults
+	w.cache = map[string]cachedServiceSet{}
+}
+
+// workspaceConfig 将工作区设置叠加到服务器配置的副本上
+func (w *WorkspaceServices) workspaceConfig(workspace *models.Workspace) (*config.Config, error) {
+	cfg := *w.cfg
+	settings := &workspace.Settings
+	// 其他团队的会议不能同步到服务器的Notion数据库，也不能使用服务器的DeepSeek额度
+	if !workspace.ServerCredentials {
+		cfg.NotionAPIKey = ""
+		cfg.NotionDatabaseID = ""
+		cfg.DeepSeekAPIKey = ""
+	}
+
+	notionAPIKey, err := w.box.Decrypt(settings.NotionAPIKey)
+	if err != nil {
+		return nil, fmt.Errorf("解密Notion密钥失败: %w", err)
+	}
+	deepseekAPIKey, err := w.box.Decrypt(settings.DeepSeekAPIKey)
+	if err != nil {
+		return nil, fmt.Errorf("解密DeepSeek密钥失败: %w", err)
+	}
+
+	// Notion密钥和数据库ID必须成对使用
+	if notionAPIKey != "" {
+		cfg.NotionAPIKey = notionAPIKey
+		cfg.NotionDatabaseID = settings.NotionDatabaseID
+	}
+	// DeepSeek地址只与工作区自己的密钥一起使用，避免服务器的密钥被发送到工作区指定的地址
+	if deepseekAPIKey != "" {
+		cfg.DeepSeekAPIKey = deepseekAPIKey
+		override(&cfg.DeepSeekBaseURL, settings.DeepSeekBaseURL)
+	}
+	override(&cfg.DeepSeekModel, settings.DeepSeekModel)
+	override(&cfg.AnalysisSystemPrompt, settings.SystemPrompt)
+	override(&cfg.AnalysisInstructions, settings.AnalysisInstructions)
+	// 工作区只能选择Whisper的模型名称，模型文件路径只能由服务器配置指定
+	if KnownWhisperModel(settings.WhisperModel) {
+		cfg.WhisperModel = settings.WhisperModel
+	}
+	override(&cfg.WhisperLanguage, settings.WhisperLanguage)
+	return &cfg, nil
+}
+
+// override 工作区设置非空时覆盖服务器配置
+func override(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
+
+// WorkspaceSettingsUpdate 工作区设置的部分更新，nil表示保持不变，空字符串表示清除
+type WorkspaceSettingsUpdate struct {
+	NotionAPIKey         *string `json:"notionApiKey,omitempty"`
+	NotionDatabaseID     *string `json:"notionDatabaseId,omitempty"`
+	DeepSeekAPIKey       *string `json:"deepseekApiKey,omitempty"`
+	DeepSeekBaseURL      *string `json:"deepseekBaseUrl,omitempty"`
+	DeepSeekModel        *string `json:"deepseekModel,omitempty"`
+	SystemPrompt         *string `json:"systemPrompt,omitempty"`
+	AnalysisInstructions *string `json:"analysisInstructions,omitempty"`
+	WhisperModel         *string `json:"whisperModel,omitempty"`
+	WhisperLanguage      *string `json:"whisperLanguage,omitempty"`
+}
+
+// ErrInvalidSettings 工作区设置无效
+var ErrInvalidSettings = errors.New("工作区设置无效")
+
+// UpdateSettings 更新工作区设置，只有所有者可以修改；密钥加密后保存
+func (w *WorkspaceServices) UpdateSettings(p *Principal, update WorkspaceSettingsUpdate) (*models.Workspace, error) {
+	if p.Role != models.RoleOwner {
+		return nil, ErrForbidden
+	}
+
+	// 先加密密钥，避免在存储锁内做加密
+	var notionAPIKey, deepseekAPIKey string
+	var err error
+	if update.NotionAPIKey != nil {
+		if notionAPIKey, err = w.box.Encrypt(*update.NotionAPIKey); err != nil {
+			return nil, err
+		}
+	}
+	if update.DeepSeekAPIKey != nil {
+		if deepseekAPIKey, err = w.box.Encrypt(*update.DeepSeekAPIKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return w.store.Workspaces.Update(p.WorkspaceID, func(workspace *models.Workspace) error {
+		settings := &workspace.Settings
+		if update.NotionAPIKey != nil {
+			settings.NotionAPIKey = notionAPIKey
+		}
+		if update.DeepSeekAPIKey != nil {
+			settings.DeepSeekAPIKey = deepseekAPIKey
+		}
+		assign(&settings.NotionDatabaseID, update.NotionDatabaseID)
+		assign(&settings.DeepSeekBaseURL, update.DeepSeekBaseURL)
+		assign(&settings.DeepSeekModel, update.DeepSeekModel)
+		assign(&settings.SystemPrompt, update.SystemPrompt)
+		assign(&settings.AnalysisInstructions, update.AnalysisInstructions)
+		assign(&settings.WhisperModel, update.WhisperModel)
+		assign(&settings.WhisperLanguage, update.WhisperLanguage)
+
+		if settings.NotionAPIKey != "" && settings.NotionDatabaseID == "" {
+			return fmt.Errorf("%w: 设置Notion密钥时必须同时提供数据库ID", ErrInvalidSettings)
+		}
+		if settings.DeepSeekBaseURL != "" && settings.DeepSeekAPIKey == "" {
+			return fmt.Errorf("%w: 设置DeepSeek地址时必须同时提供DeepSeek密钥", ErrInvalidSettings)
+		}
+		if settings.WhisperModel != "" && !KnownWhisperModel(settings.WhisperModel) {
+			return fmt.Errorf("%w: 未知的Whisper模型%q", ErrInvalidSettings, settings.WhisperModel)
+		}
+		workspace.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// assign 非nil时写入新值
+func assign(target *string, value *string) {
+	if value != nil {
+		*target = *value
+	}
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("记录不存在")
+
+// Collection 基于JSON文件的记录集合，每条记录保存为一个文件
+type Collection[T any] struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+// NewCollection 在root目录下创建（或打开）名为name的集合
+func NewCollection[T any](root, name string) (*Collection[T], error) {
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %w", err)
+	}
+	return &Collection[T]{dir: dir}, nil
+}
+
+// Get 读取记录
+func (c *Collection[T]) Get(id string) (*T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.read(id)
+}
+
+// Put 写入记录，已存在时覆盖
+func (c *Collection[T]) Put(id string, value *T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.write(id, value)
+}
+
+// Update 在锁内读取、修改并写回记录
+func (c *Collection[T]) Update(id string, fn func(*T) error) (*T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, err := c.read(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(value); err != nil {
+		return nil, err
+	}
+	if err := c.write(id, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Delete 删除记录
+func (c *Collection[T]) Delete(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path, err := c.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("删除记录失败: %w", err)
+	}
+	return nil
+}
+
+// List 读取全部记录，按文件名排序
+func (c *Collection[T]) List() ([]*T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取存储目录失败: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(ids)
+
+	values := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		value, err := c.read(id)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (c *Collection[T]) read(id string) (*T, error) {
+	path, err := c.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("读取记录失败: %w", err)
+	}
+
+	value := new(T)
+	if err := json.Unmarshal(data, value); err != nil {
+		return nil, fmt.Errorf("解析记录%s失败: %w", id, err)
+	}
+	return value, nil
+}
+
+// write 先写入临时文件再重命名，避免进程中断时留下半截文件
+func (c *Collection[T]) write(id string, value *T) error {
+	path, err := c.path(id)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化记录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入记录失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入记录失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("保存记录失败: %w", err)
+	}
+	return nil
+}
+
+func (c *Collection[T]) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return "", fmt.Errorf("无效的记录ID: %q", id)
+	}
+	return filepath.Join(c.dir, id+".json"), nil
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package storage
+
+import "errors"
+
+// ErrFreeSpaceUnsupported 当前平台不支持查询磁盘剩余空间
+var ErrFreeSpaceUnsupported = errors.New("当前平台不支持查询磁盘剩余空间")
+
+// FreeSpace 当前平台不支持，总是返回ErrFreeSpaceUnsupported
+func FreeSpace(dir string) (uint64, error) {
+	return 0, ErrFreeSpaceUnsupported
+}
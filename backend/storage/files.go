@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore 保存二进制文件（如会议录音）的目录
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore 在dir目录下创建（或打开）文件存储
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建文件存储目录失败: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save 保存文件，已存在时覆盖
+func (s *FileStore) Save(name string, data []byte) error {
+	path, err := s.Path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("保存文件失败: %w", err)
+	}
+	return nil
+}
+
+// Path 返回文件的完整路径，name不能包含目录
+func (s *FileStore) Path(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("无效的文件名: %q", name)
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// Stat 返回文件信息，文件不存在时返回ErrNotFound
+func (s *FileStore) Stat(name string) (os.FileInfo, error) {
+	path, err := s.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("读取文件信息失败: %w", err)
+	}
+	return info, nil
+}
+
+// Read 读取文件内容
+func (s *FileStore) Read(name string) ([]byte, error) {
+	path, err := s.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	return data, nil
+}
+
+// Delete 删除文件，文件不存在时不报错
+func (s *FileStore) Delete(name string) error {
+	path, err := s.Path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+// Probe 在dataDir中写入、读回并删除一个临时文件，检查数据目录可以读写
+func Probe(dataDir string) error {
+	file, err := os.CreateTemp(dataDir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("数据目录不可写: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	want := []byte("ok")
+	_, err = file.Write(want)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("写入数据目录失败: %w", err)
+	}
+
+	got, err := os.ReadFile(file.Name())
+	if err != nil {
+		return fmt.Errorf("读取数据目录失败: %w", err)
+	}
+	if string(got) != string(want) {
+		return fmt.Errorf("数据目录读回的内容不一致")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadOrCreateSecret 读取dataDir/secrets下名为name的随机密钥，不存在时生成32字节密钥并保存
+func LoadOrCreateSecret(dataDir, name string) (string, error) {
+	dir := filepath.Join(dataDir, "secrets")
+	path := filepath.Join(dir, name)
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("读取密钥失败: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成密钥失败: %w", err)
+	}
+	secret := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("创建密钥目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(secret), 0600); err != nil {
+		return "", fmt.Errorf("保存密钥失败: %w", err)
+	}
+	return secret, nil
+}
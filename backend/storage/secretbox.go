@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix 加密值的前缀，带版本号以便将来更换算法
+const encryptedPrefix = "enc:v1:"
+
+// SecretBox 使用AES-256-GCM加密需要落盘保存的凭据
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox 根据密钥创建SecretBox，任意长度的密钥都会通过SHA-256派生为256位
+func NewSecretBox(key string) (*SecretBox, error) {
+	if key == "" {
+		return nil, errors.New("加密密钥不能为空")
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Encrypt 加密明文，空字符串保持为空
+func (b *SecretBox) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密Encrypt的结果，空字符串保持为空
+func (b *SecretBox) Decrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return "", errors.New("凭据未加密或格式无效")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < b.aead.NonceSize() {
+		return "", errors.New("凭据格式无效")
+	}
+
+	nonce, ciphertext := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("解密凭据失败，加密密钥可能已更换")
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"path/filepath"
+
+	"meeting-mm/models"
+)
+
+// Store 汇总应用使用的全部持久化集合
+type Store struct {
+	Meetings    *Collection[models.Meeting]
+	NotionSyncs *Collection[models.NotionSync]
+	Users       *Collection[models.User]
+	Workspaces  *Collection[models.Workspace]
+	APIKeys     *Collection[models.APIKey]
+	Profiles    *Collection[models.Profile]
+	SearchIndex *Collection[models.SearchIndex]
+	ActionItems *Collection[models.ActionItem]
+	Audio       *FileStore
+}
+
+// Open 在dataDir下打开存储
+func Open(dataDir string) (*Store, error) {
+	meetings, err := NewCollection[models.Meeting](dataDir, "meetings")
+	if err != nil {
+		return nil, err
+	}
+	notionSyncs, err := NewCollection[models.NotionSync](dataDir, "notion_syncs")
+	if err != nil {
+		return nil, err
+	}
+	users, err := NewCollection[models.User](dataDir, "users")
+	if err != nil {
+		return nil, err
+	}
+	workspaces, err := NewCollection[models.Workspace](dataDir, "workspaces")
+	if err != nil {
+		return nil, err
+	}
+	apiKeys, err := NewCollection[models.APIKey](dataDir, "api_keys")
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := NewCollection[models.Profile](dataDir, "profiles")
+	if err != nil {
+		return nil, err
+	}
+
+	searchIndex, err := NewCollection[models.SearchIndex](dataDir, "search_index")
+	if err != nil {
+		return nil, err
+	}
+
+	actionItems, err := NewCollection[models.ActionItem](dataDir, "action_items")
+	if err != nil {
+		return nil, err
+	}
+
+	audio, err := NewFileStore(AudioDir(dataDir))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		Meetings:    meetings,
+		NotionSyncs: notionSyncs,
+		Users:       users,
+		Workspaces:  workspaces,
+		APIKeys:     apiKeys,
+		Profiles:    profiles,
+		SearchIndex: searchIndex,
+		ActionItems: actionItems,
+		Audio:       audio,
+	}, nil
+}
+
+// AudioDir 返回dataDir下保存会议录音的目录
+func AudioDir(dataDir string) string {
+	return filepath.Join(dataDir, "audio")
+}
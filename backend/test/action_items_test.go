@@ -0,0 +1,286 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/models"
+	"meeting-mm/services"
+	"meeting-mm/storage"
+)
+
+// newMockActionItemLLM 模拟的模型服务：第一次会议返回四个待办事项；之后的会议按提示词中未完成条目的编号
+// 标注延续、更新和完成
+func newMockActionItemLLM(t *testing.T) *capturingDeepSeek {
+	return newCapturingDeepSeek(t, func(prompt string) string {
+		if !strings.Contains(prompt, "之前会议中未完成的待办事项：") {
+			return `{"summary":"摘要","decisions":[],"todoItems":[
+				{"description":"测试前端","assignee":"王五","dueDate":"2025-03-21"},
+				{"description":"整理发布文档","assignee":"李四"},
+				{"description":"联系客户确认报价","assignee":"张三"},
+				{"description":"更新定价页文案","assignee":"赵六"}]}`
+		}
+
+		// ref 返回提示词中之前条目的编号
+		ref := func(description string) string {
+			match := regexp.MustCompile(`(\d+)\. ` + regexp.QuoteMeta(description)).FindStringSubmatch(prompt)
+			require.NotNil(t, match, description)
+			return match[1]
+		}
+		// 定价页文案没有标注，由描述相似度对应
+		return `{"summary":"摘要","decisions":[],"todoItems":[
+			{"description":"测试前端","assignee":"王五","previousItem":` + ref("测试前端") + `},
+			{"description":"整理发布文档和常见问题","assignee":"李四","dueDate":"2025-03-28","previousItem":` + ref("整理发布文档") + `},
+			{"description":"更新定价页文案","assignee":"赵六","previousItem":0}],
+			"previousItems":[
+			{"item":` + ref("整理发布文档") + `,"status":"updated"},
+			{"item":` + ref("联系客户确认报价") + `,"status":"completed"}]}`
+	})
+}
+
+// 测试同一系列会议的待办事项合并为台账条目，标注延续、更新和完成，并按负责人列出未完成的条目
+func TestActionItemCarryOver(t *testing.T) {
+	mock := newMockActionItemLLM(t)
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = mock.URL
+	cfg.AuthAllowSignup = true
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "产品周会 3/14", "transcript": "第一次周会",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	first := decodeJSON(t, resp)["meeting"].(map[string]interface{})
+	assert.Equal(t, "产品周会", first["series"])
+	for _, todo := range first["todoItems"].([]interface{}) {
+		assert.Equal(t, "new", todo.(map[string]interface{})["continuity"])
+		assert.NotEmpty(t, todo.(map[string]interface{})["actionItemId"])
+	}
+
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "产品周会 3/21", "transcript": "第二次周会",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	second := decodeJSON(t, resp)["meeting"].(map[string]interface{})
+
+	prompts := mock.Prompts()
+	require.Len(t, prompts, 2)
+	assert.NotContains(t, prompts[0], "之前会议中未完成的待办事项：")
+	assert.Contains(t, prompts[1], "测试前端（负责人：王五）（截止：2025-03-21）")
+	assert.Contains(t, prompts[1], `"previousItems"`)
+
+	firstIDs := map[string]string{}
+	for _, todo := range first["todoItems"].([]interface{}) {
+		todo := todo.(map[string]interface{})
+		firstIDs[todo["description"].(string)] = todo["actionItemId"].(string)
+	}
+	todos := second["todoItems"].([]interface{})
+	require.Len(t, todos, 3)
+	expected := []struct{ firstDescription, continuity string }{
+		{"测试前端", "carried_over"},
+		{"整理发布文档", "updated"},
+		{"更新定价页文案", "carried_over"},
+	}
+	for i, want := range expected {
+		todo := todos[i].(map[string]interface{})
+		assert.Equal(t, want.continuity, todo["continuity"], todo["description"])
+		assert.Equal(t, firstIDs[want.firstDescription], todo["actionItemId"], todo["description"])
+	}
+
+	// 条目保留每次会议中的变化
+	resp = doJSON(t, srv, "GET", "/api/action-items/"+firstIDs["整理发布文档"], token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	item := decodeJSON(t, resp)
+	assert.Equal(t, "整理发布文档和常见问题", item["description"])
+	assert.Equal(t, "open", item["status"])
+	assert.Equal(t, "产品周会", item["series"])
+	assert.Equal(t, first["id"], item["firstMeetingId"])
+	assert.Equal(t, second["id"], item["lastMeetingId"])
+	history := item["history"].([]interface{})
+	require.Len(t, history, 2)
+	assert.Equal(t, "new", history[0].(map[string]interface{})["kind"])
+	assert.Equal(t, "updated", history[1].(map[string]interface{})["kind"])
+	assert.Equal(t, "产品周会 3/21", history[1].(map[string]interface{})["meetingTitle"])
+
+	// 没有再次列出但确认完成的条目关闭
+	resp = doJSON(t, srv, "GET", "/api/action-items/"+firstIDs["联系客户确认报价"], token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	item = decodeJSON(t, resp)
+	assert.Equal(t, "completed", item["status"])
+	assert.Len(t, item["history"], 2)
+
+	resp = doJSON(t, srv, "GET", "/api/action-items/open", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assignees := decodeJSON(t, resp)["assignees"].([]interface{})
+	var names []string
+	for _, group := range assignees {
+		group := group.(map[string]interface{})
+		names = append(names, group["assignee"].(string))
+		assert.Len(t, group["items"], 1)
+	}
+	assert.Equal(t, []string{"李四", "王五", "赵六"}, names)
+
+	resp = doJSON(t, srv, "GET", "/api/action-items/open?assignee=王五", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assignees = decodeJSON(t, resp)["assignees"].([]interface{})
+	require.Len(t, assignees, 1)
+	items := assignees[0].(map[string]interface{})["items"].([]interface{})
+	assert.Equal(t, firstIDs["测试前端"], items[0].(map[string]interface{})["id"])
+
+	// 其他工作区看不到这些条目
+	other := registerAndLogin(t, srv, "other@example.com")
+	resp = doJSON(t, srv, "GET", "/api/action-items/"+firstIDs["测试前端"], other, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = doJSON(t, srv, "GET", "/api/action-items/open", other, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, decodeJSON(t, resp)["assignees"])
+}
+
+// 测试同一系列的两次会议基于相同的未完成条目同时分析时，保存不会覆盖彼此的历史记录
+func TestActionItemConcurrentReconcile(t *testing.T) {
+	store, err := storage.Open(t.TempDir())
+	require.NoError(t, err)
+	actionItems := services.NewActionItemService(store)
+
+	first := &models.Meeting{ID: "m1", WorkspaceID: "w1", Series: "周会", Title: "周会",
+		TodoItems: []models.TodoItem{{ID: "t1", Description: "测试前端", Assignee: "王五", Status: "pending"}}}
+	require.NoError(t, actionItems.Save(actionItems.Reconcile(context.Background(), nil, first, nil, &services.Analysis{})))
+	open, err := actionItems.OpenItems("w1", "周会")
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+
+	// 两次分析都读取到同一个未完成条目，一次认为仍未完成，另一次认为已完成
+	carried := &models.Meeting{ID: "m2", WorkspaceID: "w1", Series: "周会", Title: "周会",
+		TodoItems: []models.TodoItem{{ID: "t2", Description: "测试前端", Assignee: "王五", Status: "pending"}}}
+	completed := &models.Meeting{ID: "m3", WorkspaceID: "w1", Series: "周会", Title: "周会"}
+	carriedItems := actionItems.Reconcile(context.Background(), nil, carried, open, &services.Analysis{})
+	completedItems := actionItems.Reconcile(context.Background(), nil, completed, open, &services.Analysis{PreviousItems: []string{models.ContinuityCompleted}})
+	require.NoError(t, actionItems.Save(completedItems))
+	require.NoError(t, actionItems.Save(carriedItems))
+
+	item, err := actionItems.Get("w1", open[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ActionItemCompleted, item.Status)
+	var kinds []string
+	for _, event := range item.History {
+		kinds = append(kinds, event.Kind)
+	}
+	assert.Equal(t, []string{models.ContinuityNew, models.ContinuityCompleted, models.ContinuityCarriedOver}, kinds)
+}
+
+// 测试负责人的待办日历按台账条目生成：延续的条目只出现一次，台账中已完成的条目标记为完成
+func TestActionItemTodoFeed(t *testing.T) {
+	mock := newMockActionItemLLM(t)
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = mock.URL
+	cfg.AuthAllowSignup = true
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	ids := map[string]string{}
+	for _, title := range []string{"产品周会 3/14", "产品周会 3/21"} {
+		resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+			"title": title, "transcript": title,
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		meeting := decodeJSON(t, resp)["meeting"].(map[string]interface{})
+		for _, todo := range meeting["todoItems"].([]interface{}) {
+			todo := todo.(map[string]interface{})
+			if _, ok := ids[todo["assignee"].(string)]; !ok {
+				ids[todo["assignee"].(string)] = todo["actionItemId"].(string)
+			}
+		}
+	}
+
+	feed := func(assignee string) string {
+		resp := doJSON(t, srv, "GET", "/api/todos/feed/"+url.PathEscape(assignee)+".ics", token, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		data, _ := io.ReadAll(resp.Body)
+		return string(data)
+	}
+
+	// 两次会议都列出的条目只有一个日历条目，内容以台账为准
+	data := feed("李四")
+	assert.Equal(t, 1, strings.Count(data, "BEGIN:VTODO"))
+	assert.Contains(t, data, "UID:"+ids["李四"]+"@meeting-mm\r\n")
+	assert.Contains(t, data, "整理发布文档和常见问题")
+	assert.Contains(t, data, "STATUS:NEEDS-ACTION\r\n")
+
+	// 第二次会议确认完成的条目关闭第一次会议中的日历条目
+	data = feed("张三")
+	assert.Equal(t, 1, strings.Count(data, "BEGIN:VTODO"))
+	assert.Contains(t, data, "UID:"+ids["张三"]+"@meeting-mm\r\n")
+	assert.Contains(t, data, "STATUS:COMPLETED\r\n")
+
+	resp := doJSON(t, srv, "GET", "/api/todos/feeds", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	todos := map[string]float64{}
+	for _, feed := range decodeJSON(t, resp)["feeds"].([]interface{}) {
+		feed := feed.(map[string]interface{})
+		todos[feed["assignee"].(string)] = feed["todos"].(float64)
+	}
+	assert.Equal(t, map[string]float64{"王五": 1, "李四": 1, "张三": 0, "赵六": 1}, todos)
+}
+
+// 测试模型没有标注时，配置了向量模型的情况下换了说法的同一件事也认定为延续；未配置时按文本相似度认定为新的条目
+func TestActionItemParaphrasedCarryOver(t *testing.T) {
+	for _, tc := range []struct {
+		embeddingModel string
+		continuity     string
+	}{
+		{"text-embedding", models.ContinuityCarriedOver},
+		{"", models.ContinuityNew},
+	} {
+		mock := newCapturingDeepSeek(t, func(prompt string) string {
+			if !strings.Contains(prompt, "之前会议中未完成的待办事项：") {
+				return `{"summary":"摘要","decisions":[],"todoItems":[{"description":"更新定价页文案","assignee":"赵六"}]}`
+			}
+			return `{"summary":"摘要","decisions":[],"todoItems":[{"description":"重写价格页面上的介绍文字","assignee":"赵六","previousItem":0}]}`
+		})
+		// 提到定价或价格的文本向量相同
+		mock.mux.HandleFunc("/embeddings", func(w http.ResponseWriter, r *http.Request) {
+			var request struct {
+				Input []string `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			var data []map[string]interface{}
+			for i, text := range request.Input {
+				vector := []float32{0, 1}
+				if strings.Contains(text, "定价") || strings.Contains(text, "价格") {
+					vector = []float32{1, 0.05}
+				}
+				data = append(data, map[string]interface{}{"index": i, "embedding": vector})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		})
+		cfg := testConfig(t)
+		cfg.DeepSeekBaseURL = mock.URL
+		cfg.EmbeddingModel = tc.embeddingModel
+		srv := newTestServer(t, cfg)
+		token := registerAndLogin(t, srv, "owner@example.com")
+
+		var ids []string
+		var second map[string]interface{}
+		for _, title := range []string{"产品周会 3/14", "产品周会 3/21"} {
+			resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+				"title": title, "transcript": title,
+			})
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			second = decodeJSON(t, resp)["meeting"].(map[string]interface{})["todoItems"].([]interface{})[0].(map[string]interface{})
+			ids = append(ids, second["actionItemId"].(string))
+		}
+		assert.Equal(t, tc.continuity, second["continuity"], tc.embeddingModel)
+		assert.Equal(t, tc.continuity == models.ContinuityCarriedOver, ids[0] == ids[1], tc.embeddingModel)
+	}
+}
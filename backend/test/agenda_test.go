@@ -0,0 +1,82 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/services"
+)
+
+// 测试从议程文本中识别议程项
+func TestParseAgenda(t *testing.T) {
+	text := "请准时参加\n1. 上周待办回顾\n2、发布计划\n（3）预算\n- 其他事项\n\n会议室：3楼"
+
+	var titles []string
+	for _, item := range services.ParseAgenda(text, true) {
+		assert.NotEmpty(t, item.ID)
+		titles = append(titles, item.Title)
+	}
+	assert.Equal(t, []string{"上周待办回顾", "发布计划", "预算", "其他事项"}, titles)
+
+	// 表单中的议程每个非空行都是一项
+	assert.Len(t, services.ParseAgenda(text, false), 6)
+	assert.Empty(t, services.ParseAgenda("请准时参加", true))
+}
+
+// agendaAnalysis 按议程整理的模拟分析结果
+const agendaAnalysis = `{"summary":"讨论发布计划",
+	"agenda":[{"item":1,"status":"covered","summary":"确定下周一发布"},{"item":"2","status":"deferred","summary":"财务未到场"},{"item":9,"status":"covered"}],
+	"todoItems":[{"description":"测试前端","assignee":"王五","dueDate":"2025-03-21","agendaItem":1},{"description":"整理文档","agendaItem":null}],
+	"decisions":[{"description":"下周一发布","madeBy":"张三","agendaItem":"1"}]}`
+
+// 测试按议程分析会议：议程项记录讨论情况，待办和决策关联到议程项，报告按议程整理
+func TestAnalyzeWithAgenda(t *testing.T) {
+	deepseek := newCapturingDeepSeek(t, func(string) string { return agendaAnalysis })
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = deepseek.URL
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "周会", "transcript": "内容",
+		"agenda": []map[string]string{{"title": "发布计划", "description": "确认日期"}, {"title": "预算"}},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body := decodeJSON(t, resp)
+	meeting := body["meeting"].(map[string]interface{})
+
+	agenda := meeting["agendaItems"].([]interface{})
+	require.Len(t, agenda, 2)
+	first, second := agenda[0].(map[string]interface{}), agenda[1].(map[string]interface{})
+	assert.Equal(t, "covered", first["status"])
+	assert.Equal(t, "确定下周一发布", first["summary"])
+	assert.Equal(t, "deferred", second["status"])
+
+	todos := meeting["todoItems"].([]interface{})
+	assert.Equal(t, first["id"], todos[0].(map[string]interface{})["agendaItemId"])
+	assert.Nil(t, todos[1].(map[string]interface{})["agendaItemId"])
+	assert.Equal(t, first["id"], meeting["decisions"].([]interface{})[0].(map[string]interface{})["agendaItemId"])
+
+	report := body["markdownReport"].(string)
+	assert.Contains(t, report, "### 1. 发布计划（已讨论）\n\n确定下周一发布\n\n**决策**\n\n- 下周一发布（张三）")
+	assert.Contains(t, report, "### 2. 预算（已推迟）\n\n财务未到场")
+	assert.Contains(t, report, "## 其他待办事项\n\n- [ ] 整理文档")
+
+	prompt := deepseek.Prompt()
+	assert.Contains(t, prompt, "会议议程：\n1. 发布计划：确认日期\n2. 预算\n")
+	assert.Contains(t, prompt, `"agendaItem"`)
+
+	// 没有议程时不要求模型按议程整理
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{"title": "周会", "transcript": "内容"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, decodeJSON(t, resp)["meeting"].(map[string]interface{})["agendaItems"])
+	assert.NotContains(t, deepseek.Prompt(), `"agendaItem"`)
+
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "周会", "transcript": "内容", "agenda": []map[string]string{{"title": " "}},
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
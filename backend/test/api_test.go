@@ -10,81 +10,217 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
-	"strings"
+	"sync"
 	"testing"
 
-	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
-	"meeting-mm/api"
 	"meeting-mm/config"
-	"meeting-mm/services"
+	"meeting-mm/server"
 )
 
-// 设置测试环境
-func setupTestEnv() (*gin.Engine, error) {
-	// 设置测试模式
-	gin.SetMode(gin.TestMode)
+// mockAnalysis 模拟DeepSeek返回的固定分析结果
+const mockAnalysis = `{"summary":"讨论项目进度，决定下周一发布",
+	"todoItems":[{"description":"测试前端","assignee":"王五","dueDate":"2025-03-21"}],
+	"decisions":[{"description":"下周一发布第一个版本","madeBy":"张三"}]}`
+
+// newMockDeepSeek 模拟DeepSeek聊天接口，返回固定的分析结果
+func newMockDeepSeek(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// 就绪检查请求模型列表
+		if r.Method == http.MethodGet && r.URL.Path == "/models" {
+			w.Write([]byte(`{"object":"list","data":[{"id":"deepseek-chat","object":"model"}]}`))
+			return
+		}
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"index": 0, "message": map[string]string{"role": "assistant", "content": "```json\n" + mockAnalysis + "\n```"}},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
 
-	// 加载测试配置
-	cfg, err := config.LoadConfig("../.env.test")
-	if err != nil {
-		// 如果测试配置文件不存在，使用默认配置
-		cfg = &config.Config{
-			Port:             "8080",
-			Env:              "test",
-			DeepSeekAPIKey:   "test_key",
-			DeepSeekBaseURL:  "https://api.deepseek.com/v1",
-			NotionAPIKey:     "test_key",
-			NotionDatabaseID: "test_db",
-			WhisperModelPath: "../whisper/models/ggml-base.bin",
-			UseLocalWhisper:  true,
+// capturingDeepSeek 记录提示词的模拟DeepSeek聊天接口，其他接口可以通过mux添加
+type capturingDeepSeek struct {
+	*httptest.Server
+	mux *http.ServeMux
+
+	mu      sync.Mutex
+	systems []string
+	prompts []string
+}
+
+// newCapturingDeepSeek 返回模拟的DeepSeek：respond根据最后一条消息（提示词）返回模型输出，每次请求的提示词都被记录
+func newCapturingDeepSeek(t *testing.T, respond func(prompt string) string) *capturingDeepSeek {
+	mock := &capturingDeepSeek{mux: http.NewServeMux()}
+	mock.mux.HandleFunc("/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Messages []struct{ Content string } `json:"messages"`
 		}
-	}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		prompt := request.Messages[len(request.Messages)-1].Content
+		mock.mu.Lock()
+		mock.systems = append(mock.systems, request.Messages[0].Content)
+		mock.prompts = append(mock.prompts, prompt)
+		mock.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"index": 0, "message": map[string]string{"role": "assistant", "content": respond(prompt)}},
+			},
+		})
+	})
+	mock.Server = httptest.NewServer(mock.mux)
+	t.Cleanup(mock.Close)
+	return mock
+}
 
-	// 创建服务
-	deepseekService := services.NewDeepSeekService(cfg)
-	notionService := services.NewNotionService(cfg)
-	whisperService := services.NewWhisperService(cfg)
+// Prompts 返回全部请求的提示词
+func (m *capturingDeepSeek) Prompts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.prompts...)
+}
 
-	// 创建路由
-	router := gin.New()
-	router.Use(gin.Recovery())
+// Prompt 返回最后一次请求的提示词
+func (m *capturingDeepSeek) Prompt() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.prompts) == 0 {
+		return ""
+	}
+	return m.prompts[len(m.prompts)-1]
+}
 
-	// 注册API路由
-	handler := api.NewHandler(deepseekService, notionService, whisperService)
-	api.RegisterRoutes(router, handler)
+// SystemPrompt 返回最后一次请求的系统提示词
+func (m *capturingDeepSeek) SystemPrompt() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.systems) == 0 {
+		return ""
+	}
+	return m.systems[len(m.systems)-1]
+}
 
-	return router, nil
+// 设置测试环境，返回服务器和已登录用户的令牌
+func setupTestEnv(t *testing.T) (*server.Server, string) {
+	srv := newTestServer(t, testConfig(t))
+	return srv, registerAndLogin(t, srv, "owner@example.com")
 }
 
-// 测试健康检查API
-func TestHealthCheck(t *testing.T) {
-	router, err := setupTestEnv()
+// testConfig 返回使用模拟DeepSeek的测试配置
+func testConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		Port:             "8080",
+		Env:              "test",
+		DataDir:          t.TempDir(),
+		DeepSeekAPIKey:   "test_key",
+		DeepSeekBaseURL:  newMockDeepSeek(t).URL,
+		NotionAPIKey:     "test_key",
+		NotionDatabaseID: "test_db",
+		WhisperModelPath: "../whisper/models/ggml-base.bin",
+		UseLocalWhisper:  true,
+	}
+}
+
+func newTestServer(t *testing.T, cfg *config.Config) *server.Server {
+	srv, err := server.New(cfg)
 	if err != nil {
 		t.Fatalf("无法设置测试环境: %v", err)
 	}
+	return srv
+}
 
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/api/health", nil)
-	router.ServeHTTP(w, req)
+// doJSON 通过Fiber发送JSON请求，token不为空时携带登录令牌
+func doJSON(t *testing.T, srv *server.Server, method, path, token string, body interface{}) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		assert.NoError(t, err)
+		checkRequest(t, method, path, data)
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := srv.App().Test(req, -1)
+	if err != nil {
+		t.Fatalf("请求%s %s失败: %v", method, path, err)
+	}
+	checkResponse(t, method, path, resp)
+	return resp
+}
 
-	assert.Equal(t, http.StatusOK, w.Code)
+// registerAndLogin 注册用户并登录，返回登录令牌
+func registerAndLogin(t *testing.T, srv *server.Server, email string) string {
+	resp := doJSON(t, srv, "POST", "/api/auth/register", "", map[string]string{"email": email, "password": "password123"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 
-	var response map[string]interface{}
-	err = json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
+	resp = doJSON(t, srv, "POST", "/api/auth/login", "", map[string]string{"email": email, "password": "password123"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	token, _ := decodeJSON(t, resp)["token"].(string)
+	return token
+}
 
-	assert.Equal(t, "ok", response["status"])
-	assert.Contains(t, response, "time")
+// transports 以Fiber和net/http两种方式执行同一个请求，请求携带登录令牌
+func transports(t *testing.T, srv *server.Server, token string) map[string]func(*http.Request) *http.Response {
+	httpServer := httptest.NewServer(srv.Handler())
+	t.Cleanup(httpServer.Close)
+
+	return map[string]func(*http.Request) *http.Response{
+		server.TransportFiber: func(req *http.Request) *http.Response {
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := srv.App().Test(req, -1)
+			assert.NoError(t, err)
+			checkResponse(t, req.Method, req.URL.Path, resp)
+			return resp
+		},
+		server.TransportHTTP: func(req *http.Request) *http.Response {
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.URL.Scheme, req.URL.Host, req.RequestURI = "http", httpServer.Listener.Addr().String(), ""
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			checkResponse(t, req.Method, req.URL.Path, resp)
+			return resp
+		},
+	}
+}
+
+func decodeJSON(t *testing.T, resp *http.Response) map[string]interface{} {
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return body
+}
+
+// 测试健康检查API
+func TestHealthCheck(t *testing.T) {
+	srv, token := setupTestEnv(t)
+
+	for name, do := range transports(t, srv, token) {
+		t.Run(name, func(t *testing.T) {
+			resp := do(httptest.NewRequest("GET", "/api/health", nil))
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			response := decodeJSON(t, resp)
+			assert.Equal(t, "ok", response["status"])
+			assert.Contains(t, response, "time")
+		})
+	}
 }
 
 // 测试转录分析API
 func TestAnalyzeTranscript(t *testing.T) {
-	router, err := setupTestEnv()
-	if err != nil {
-		t.Fatalf("无法设置测试环境: %v", err)
-	}
+	srv, token := setupTestEnv(t)
 
 	// 准备测试数据
 	testData := map[string]interface{}{
@@ -100,14 +236,44 @@ func TestAnalyzeTranscript(t *testing.T) {
 	jsonData, err := json.Marshal(testData)
 	assert.NoError(t, err)
 
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("POST", "/api/meetings/analyze", bytes.NewBuffer(jsonData))
-	req.Header.Set("Content-Type", "application/json")
-	router.ServeHTTP(w, req)
+	for name, do := range transports(t, srv, token) {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/meetings/analyze", bytes.NewReader(jsonData))
+			req.Header.Set("Content-Type", "application/json")
+			resp := do(req)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			body := decodeJSON(t, resp)
+			assert.Contains(t, body, "markdownReport")
+			meeting, _ := body["meeting"].(map[string]interface{})
+			assert.Equal(t, "讨论项目进度，决定下周一发布", meeting["summary"])
+			assert.Len(t, meeting["todoItems"], 1)
+			assert.Len(t, meeting["decisions"], 1)
+		})
+	}
+}
 
-	// 由于DeepSeek服务在测试环境中是模拟的，我们只检查状态码
-	// 在实际集成测试中，可以检查更多的响应内容
-	assert.Equal(t, http.StatusOK, w.Code)
+// 测试两种服务方式的错误响应一致
+func TestTransportParity(t *testing.T) {
+	srv, token := setupTestEnv(t)
+
+	for name, do := range transports(t, srv, token) {
+		t.Run(name, func(t *testing.T) {
+			resp := do(httptest.NewRequest("GET", "/api/meetings/missing", nil))
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			assert.Equal(t, "会议不存在", decodeJSON(t, resp)["error"])
+
+			req := httptest.NewRequest("POST", "/api/meetings/analyze", bytes.NewBufferString("{"))
+			req.Header.Set("Content-Type", "application/json")
+			resp = do(req)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			resp.Body.Close()
+
+			resp = do(httptest.NewRequest("GET", "/api/unknown", nil))
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+			assert.Contains(t, decodeJSON(t, resp)["error"], "Cannot GET /api/unknown")
+		})
+	}
 }
 
 // 测试音频上传API
@@ -117,10 +283,7 @@ func TestUploadAudio(t *testing.T) {
 		t.Skip("跳过音频上传测试。设置 RUN_UPLOAD_TEST=true 环境变量以启用此测试。")
 	}
 
-	router, err := setupTestEnv()
-	if err != nil {
-		t.Fatalf("无法设置测试环境: %v", err)
-	}
+	srv, token := setupTestEnv(t)
 
 	// 准备测试音频文件
 	testAudioPath := "../test/testdata/test_audio.wav"
@@ -135,7 +298,7 @@ func TestUploadAudio(t *testing.T) {
 	w := multipart.NewWriter(&b)
 
 	// 添加标题字段
-	err = w.WriteField("title", "测试音频上传")
+	err := w.WriteField("title", "测试音频上传")
 	assert.NoError(t, err)
 
 	// 添加音频文件
@@ -155,16 +318,16 @@ func TestUploadAudio(t *testing.T) {
 	w.Close()
 
 	// 发送请求
-	req, err := http.NewRequest("POST", "/api/audio/upload", &b)
-	assert.NoError(t, err)
+	req := httptest.NewRequest("POST", "/api/audio/upload", &b)
 	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	// 执行请求
-	resp := httptest.NewRecorder()
-	router.ServeHTTP(resp, req)
+	resp, err := srv.App().Test(req, -1)
+	assert.NoError(t, err)
 
 	// 检查响应
-	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
 // 创建测试目录
@@ -179,32 +342,6 @@ func TestMain(m *testing.M) {
 		}
 	}
 
-	// 创建测试环境文件
-	testEnvPath := "../.env.test"
-	if _, err := os.Stat(testEnvPath); os.IsNotExist(err) {
-		testEnvContent := strings.Join([]string{
-			"PORT=8080",
-			"ENV=test",
-			"DEEPSEEK_API_KEY=test_key",
-			"DEEPSEEK_BASE_URL=https://api.deepseek.com/v1",
-			"NOTION_API_KEY=test_key",
-			"NOTION_DATABASE_ID=test_db",
-			"WHISPER_MODEL_PATH=../whisper/models/ggml-base.bin",
-			"USE_LOCAL_WHISPER=true",
-		}, "\n")
-
-		err = os.WriteFile(testEnvPath, []byte(testEnvContent), 0644)
-		if err != nil {
-			fmt.Printf("无法创建测试环境文件: %v\n", err)
-			os.Exit(1)
-		}
-	}
-
 	// 运行测试
-	code := m.Run()
-
-	// 清理（可选）
-	// os.Remove(testEnvPath)
-
-	os.Exit(code)
+	os.Exit(m.Run())
 }
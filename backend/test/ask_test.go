@@ -0,0 +1,161 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/server"
+)
+
+// mockAskLLM 模拟的模型服务：分析请求返回固定的分析结果，问答请求返回带引用的回答，
+// /embeddings按文本是否提到定价和发布返回向量
+type mockAskLLM struct {
+	*capturingDeepSeek
+	embedded       int64 // 计算过向量的文本数量
+	failEmbeddings atomic.Bool
+}
+
+func newMockAskLLM(t *testing.T) *mockAskLLM {
+	mock := &mockAskLLM{}
+	mock.capturingDeepSeek = newCapturingDeepSeek(t, func(prompt string) string {
+		if strings.Contains(prompt, "检索到的片段") {
+			return "定价页改为三档套餐[1]，下周上线[1, 7]。"
+		}
+		return `{"summary":"摘要","todoItems":[],"decisions":[]}`
+	})
+	mock.mux.HandleFunc("/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		if mock.failEmbeddings.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var request struct {
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		atomic.AddInt64(&mock.embedded, int64(len(request.Input)))
+		var data []map[string]interface{}
+		for i, text := range request.Input {
+			vector := []float32{0, 0, 0.1}
+			if strings.Contains(text, "定价") {
+				vector[0] = 1
+			}
+			if strings.Contains(text, "发布") {
+				vector[1] = 1
+			}
+			data = append(data, map[string]interface{}{"index": i, "embedding": vector})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	})
+	return mock
+}
+
+// createAskMeetings 创建讨论定价页和发布计划的两个会议，返回定价会议的ID
+func createAskMeetings(t *testing.T, srv *server.Server, token string) string {
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "定价评审",
+		"segments": []map[string]interface{}{
+			{"startTime": 65, "endTime": 80, "speaker": "张三", "text": "定价页改成三档套餐，下周上线"},
+		},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	pricingID := decodeJSON(t, resp)["meeting"].(map[string]interface{})["id"].(string)
+
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "发布周会", "transcript": "发布时间定在周一\n前端测试由王五负责",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return pricingID
+}
+
+// 测试没有配置向量模型时按BM25检索会议记录，回答引用会议和片段时间
+func TestAskBM25(t *testing.T) {
+	mock := newMockAskLLM(t)
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = mock.URL
+	cfg.AuthAllowSignup = true
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+	pricingID := createAskMeetings(t, srv, token)
+
+	resp := doJSON(t, srv, "POST", "/api/ask", token, map[string]interface{}{"question": "定价页我们决定了什么？"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body := decodeJSON(t, resp)
+	assert.Equal(t, "bm25", body["retrieval"])
+	assert.Equal(t, "定价页改为三档套餐[1]，下周上线[1, 7]。", body["answer"])
+
+	// 超出范围的编号被忽略
+	citations := body["citations"].([]interface{})
+	require.Len(t, citations, 1)
+	citation := citations[0].(map[string]interface{})
+	assert.Equal(t, 1.0, citation["index"])
+	assert.Equal(t, pricingID, citation["meetingId"])
+	assert.Equal(t, "定价评审", citation["meetingTitle"])
+	assert.Equal(t, 65.0, citation["startTime"])
+	assert.Equal(t, 80.0, citation["endTime"])
+	assert.Equal(t, "张三: 定价页改成三档套餐，下周上线", citation["text"])
+
+	prompt := mock.Prompt()
+	assert.Contains(t, prompt, "[1] 会议《定价评审》")
+	assert.Contains(t, prompt, "，01:05–01:20）\n张三: 定价页改成三档套餐，下周上线\n")
+	assert.Contains(t, prompt, "请根据以上片段回答问题：定价页我们决定了什么？")
+
+	// 没有相关片段时不调用模型
+	resp = doJSON(t, srv, "POST", "/api/ask", token, map[string]interface{}{"question": "unrelated"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body = decodeJSON(t, resp)
+	assert.Equal(t, "没有在会议记录中找到相关内容。", body["answer"])
+	assert.Empty(t, body["citations"])
+
+	resp = doJSON(t, srv, "POST", "/api/ask", token, map[string]interface{}{"question": " "})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// 其他工作区的会议不参与检索
+	other := registerAndLogin(t, srv, "other@example.com")
+	resp = doJSON(t, srv, "POST", "/api/ask", other, map[string]interface{}{"question": "定价页我们决定了什么？"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, decodeJSON(t, resp)["citations"])
+}
+
+// 测试配置向量模型时按向量检索，片段向量保存在数据目录中并在会议记录不变时复用；向量接口失败时改用BM25
+func TestAskEmbedding(t *testing.T) {
+	mock := newMockAskLLM(t)
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = mock.URL
+	cfg.EmbeddingModel = "text-embedding"
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+	pricingID := createAskMeetings(t, srv, token)
+
+	resp := doJSON(t, srv, "POST", "/api/ask", token, map[string]interface{}{"question": "定价", "topK": 1})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body := decodeJSON(t, resp)
+	assert.Equal(t, "embedding", body["retrieval"])
+	citations := body["citations"].([]interface{})
+	require.Len(t, citations, 1)
+	assert.Equal(t, pricingID, citations[0].(map[string]interface{})["meetingId"])
+
+	_, err := os.Stat(filepath.Join(cfg.DataDir, "search_index", pricingID+".json"))
+	assert.NoError(t, err)
+
+	// 第二次提问只计算问题的向量
+	embedded := atomic.LoadInt64(&mock.embedded)
+	resp = doJSON(t, srv, "POST", "/api/ask", token, map[string]interface{}{"question": "定价"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, embedded+1, atomic.LoadInt64(&mock.embedded))
+
+	mock.failEmbeddings.Store(true)
+	resp = doJSON(t, srv, "POST", "/api/ask", token, map[string]interface{}{"question": "定价页"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body = decodeJSON(t, resp)
+	assert.Equal(t, "bm25", body["retrieval"])
+	assert.Len(t, body["citations"], 1)
+}
@@ -0,0 +1,212 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/config"
+	"meeting-mm/models"
+	"meeting-mm/services"
+	"meeting-mm/storage"
+)
+
+// 测试未登录请求被拒绝，注册只对第一个用户开放
+func TestAuthRequired(t *testing.T) {
+	srv, token := setupTestEnv(t)
+
+	resp := doJSON(t, srv, "GET", "/api/meetings", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = doJSON(t, srv, "GET", "/api/meetings", "not-a-token", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = doJSON(t, srv, "GET", "/api/auth/me", token, nil)
+	if assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		me := decodeJSON(t, resp)
+		assert.Equal(t, "owner@example.com", me["user"].(map[string]interface{})["email"])
+		assert.NotContains(t, me["user"], "passwordHash")
+	}
+
+	resp = doJSON(t, srv, "POST", "/api/auth/register", "", map[string]string{"email": "other@example.com", "password": "password123"})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = doJSON(t, srv, "POST", "/api/auth/login", "", map[string]string{"email": "owner@example.com", "password": "wrong-password"})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// 所有者可以添加成员，成员可以登录
+	resp = doJSON(t, srv, "POST", "/api/workspace/members", token, map[string]string{"email": "member@example.com", "password": "password123"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp = doJSON(t, srv, "POST", "/api/auth/login", "", map[string]string{"email": "member@example.com", "password": "password123"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// 测试API密钥的创建、使用、列表和吊销
+func TestAPIKeys(t *testing.T) {
+	srv, token := setupTestEnv(t)
+
+	resp := doJSON(t, srv, "POST", "/api/auth/keys", token, map[string]string{"name": "脚本"})
+	if !assert.Equal(t, http.StatusCreated, resp.StatusCode) {
+		return
+	}
+	created := decodeJSON(t, resp)
+	key := created["key"].(string)
+	assert.Contains(t, key, services.APIKeyPrefix)
+
+	req := httptest.NewRequest("GET", "/api/meetings", nil)
+	req.Header.Set("X-API-Key", key)
+	resp, err := srv.App().Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doJSON(t, srv, "GET", "/api/auth/keys", key, nil)
+	keys := decodeJSON(t, resp)["keys"].([]interface{})
+	if assert.Len(t, keys, 1) {
+		listed := keys[0].(map[string]interface{})
+		assert.Equal(t, created["id"], listed["id"])
+		assert.NotContains(t, listed, "key")
+		assert.NotContains(t, listed, "hash")
+		assert.Contains(t, listed, "lastUsedAt")
+	}
+
+	resp = doJSON(t, srv, "DELETE", "/api/auth/keys/"+created["id"].(string), token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doJSON(t, srv, "GET", "/api/meetings", key, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+// 测试会议和同步任务按工作区隔离，录音签名链接无需登录
+func TestWorkspaceScoping(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.AuthAllowSignup = true
+	cfg.AuthSecret = "secret"
+	srv := newTestServer(t, cfg)
+	tokenA := registerAndLogin(t, srv, "a@example.com")
+	tokenB := registerAndLogin(t, srv, "b@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", tokenA, map[string]string{"title": "A的会议", "transcript": "内容"})
+	if !assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		return
+	}
+	meeting := decodeJSON(t, resp)["meeting"].(map[string]interface{})
+	id := meeting["id"].(string)
+
+	resp = doJSON(t, srv, "GET", "/api/meetings/"+id, tokenA, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doJSON(t, srv, "GET", "/api/meetings/"+id, tokenB, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = doJSON(t, srv, "GET", "/api/meetings", tokenB, nil)
+	assert.Empty(t, decodeJSON(t, resp)["meetings"])
+
+	// 直接写入A工作区的录音和同步任务
+	store, err := storage.Open(cfg.DataDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = store.Meetings.Update(id, func(m *models.Meeting) error {
+		m.AudioFile = id + ".mp3"
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, store.Audio.Save(id+".mp3", []byte("fake mp3 data")))
+	assert.NoError(t, store.NotionSyncs.Put("job-a", &models.NotionSync{
+		ID: "job-a", WorkspaceID: meeting["workspaceId"].(string), MeetingID: id,
+		Status: models.SyncStatusFailed, CreatedAt: time.Now(),
+	}))
+
+	resp = doJSON(t, srv, "POST", "/api/notion/syncs/job-a/retry", tokenB, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = doJSON(t, srv, "GET", "/api/notion/syncs", tokenB, nil)
+	assert.Empty(t, decodeJSON(t, resp)["syncs"])
+
+	resp = doJSON(t, srv, "GET", "/api/public/meetings/"+id+"/audio?sig=bad", "", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	expires := time.Now().Add(time.Hour).Unix()
+	resp = doJSON(t, srv, "GET", fmt.Sprintf("/api/public/meetings/%s/audio?exp=%d&sig=%s", id, expires, services.AudioSignature("secret", id, expires)), "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "audio/mpeg", resp.Header.Get("Content-Type"))
+
+	// 修改过期时间后签名不再匹配，过期的链接不能访问
+	resp = doJSON(t, srv, "GET", fmt.Sprintf("/api/public/meetings/%s/audio?exp=%d&sig=%s", id, expires+3600, services.AudioSignature("secret", id, expires)), "", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	expired := time.Now().Add(-time.Minute).Unix()
+	resp = doJSON(t, srv, "GET", fmt.Sprintf("/api/public/meetings/%s/audio?exp=%d&sig=%s", id, expired, services.AudioSignature("secret", id, expired)), "", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+// 测试退出登录后令牌失效，角色和成员变更立即生效，不必等到令牌过期
+func TestLogoutAndRoleChanges(t *testing.T) {
+	cfg := testConfig(t)
+	srv := newTestServer(t, cfg)
+	ownerToken := registerAndLogin(t, srv, "owner@example.com")
+	resp := doJSON(t, srv, "POST", "/api/workspace/members", ownerToken, map[string]string{"email": "member@example.com", "password": "password123"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	memberID := decodeJSON(t, resp)["id"].(string)
+	login := func(email string) string {
+		resp := doJSON(t, srv, "POST", "/api/auth/login", "", map[string]string{"email": email, "password": "password123"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeJSON(t, resp)["token"].(string)
+	}
+
+	memberToken := login("member@example.com")
+	resp = doJSON(t, srv, "POST", "/api/auth/logout", memberToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doJSON(t, srv, "GET", "/api/auth/me", memberToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	memberToken = login("member@example.com")
+	resp = doJSON(t, srv, "GET", "/api/auth/me", memberToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// 直接修改保存的用户：所有者降为成员后不能再添加成员，成员被删除后不能再访问
+	store, err := storage.Open(cfg.DataDir)
+	require.NoError(t, err)
+	users, err := store.Users.List()
+	require.NoError(t, err)
+	for _, user := range users {
+		if user.Email == "owner@example.com" {
+			_, err := store.Users.Update(user.ID, func(u *models.User) error {
+				u.Role = models.RoleMember
+				return nil
+			})
+			require.NoError(t, err)
+		}
+	}
+	resp = doJSON(t, srv, "POST", "/api/workspace/members", ownerToken, map[string]string{"email": "third@example.com", "password": "password123"})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	require.NoError(t, store.Users.Delete(memberID))
+	resp = doJSON(t, srv, "GET", "/api/auth/me", memberToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+// 测试之前以随机ID保存的API密钥在启动时迁移为按哈希保存，迁移后仍然可用
+func TestAPIKeyMigration(t *testing.T) {
+	store, err := storage.Open(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Users.Put("u1", &models.User{ID: "u1", WorkspaceID: "w1", Email: "owner@example.com", Role: models.RoleOwner}))
+	key := services.APIKeyPrefix + "legacy-key"
+	sum := sha256.Sum256([]byte(key))
+	require.NoError(t, store.APIKeys.Put("legacy-id", &models.APIKey{
+		ID: "legacy-id", WorkspaceID: "w1", UserID: "u1", Hash: hex.EncodeToString(sum[:]), CreatedAt: time.Now(),
+	}))
+
+	auth := services.NewAuthService(&config.Config{AuthSecret: "secret"}, store)
+	p, err := auth.Authenticate(key)
+	require.NoError(t, err)
+	assert.Equal(t, "w1", p.WorkspaceID)
+	assert.Equal(t, hex.EncodeToString(sum[:])[:32], p.APIKeyID)
+	_, err = store.APIKeys.Get("legacy-id")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	_, err = auth.Authenticate(services.APIKeyPrefix + "unknown")
+	assert.ErrorIs(t, err, services.ErrUnauthorized)
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/export"
+	"meeting-mm/models"
+)
+
+// 测试iCalendar的格式：CRLF、转义、折行和两种组件
+func TestRenderCalendar(t *testing.T) {
+	meeting := reportMeeting()
+	meeting.ID = "m1"
+	meeting.UpdatedAt = time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	meeting.TodoItems[0].Description = "测试前端;检查登录, 注册和" + strings.Repeat("设置页面", 10)
+
+	data := string(export.RenderCalendar(export.MeetingCalendarItems(meeting), export.CalendarOptions{
+		Name:       "周会 待办事项",
+		MeetingURL: func(m *models.Meeting) string { return "https://mm.example.com/m/" + m.ID },
+	}))
+	assert.True(t, strings.HasPrefix(data, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(data, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(data, "BEGIN:VTODO"))
+	assert.Contains(t, data, "UID:m1-0@meeting-mm\r\n")
+	assert.Contains(t, data, "DTSTAMP:20250314T120000Z\r\n")
+	assert.Contains(t, data, "DUE;VALUE=DATE:20250321\r\n")
+	assert.Contains(t, data, "STATUS:COMPLETED\r\n")
+	assert.Contains(t, data, "URL:https://mm.example.com/m/m1\r\n")
+
+	// 折行后每行不超过75字节，且不拆开多字节字符
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line), line)
+	}
+	unfolded := strings.ReplaceAll(data, "\r\n ", "")
+	assert.Contains(t, unfolded, `SUMMARY:测试前端\;检查登录\, 注册和设置页面`)
+	assert.Contains(t, unfolded, `DESCRIPTION:负责人：王五\n会议：周会（2025-03-14）\nhttps://mm.example.com/m/m1`+"\r\n")
+
+	// VEVENT为截止日期当天的全天事件，跳过没有截止日期的待办事项
+	data = string(export.RenderCalendar(export.MeetingCalendarItems(meeting), export.CalendarOptions{Component: export.ComponentEvent}))
+	assert.Equal(t, 1, strings.Count(data, "BEGIN:VEVENT"))
+	assert.Contains(t, data, "DTSTART;VALUE=DATE:20250321\r\nDTEND;VALUE=DATE:20250322\r\n")
+	assert.NotContains(t, data, "URL:")
+}
+
+// 测试会议待办日历和个人订阅
+func TestTodoCalendarAPI(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.AuthSecret = "secret"
+	cfg.PublicBaseURL = "https://mm.example.com"
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "周会", "transcript": "会议内容",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	meetingID := decodeJSON(t, resp)["meeting"].(map[string]interface{})["id"].(string)
+
+	resp = doJSON(t, srv, "GET", "/api/meetings/"+meetingID+"/todos.ics", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+	data, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(data), "SUMMARY:测试前端\r\n")
+	assert.Contains(t, strings.ReplaceAll(string(data), "\r\n ", ""), "URL:https://mm.example.com/api/meetings/"+meetingID+"/export?format=html\r\n")
+
+	resp = doJSON(t, srv, "GET", "/api/meetings/"+meetingID+"/todos.ics?component=vjournal", token, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// 订阅列表给出带签名的链接，日历应用凭链接免登录订阅
+	resp = doJSON(t, srv, "GET", "/api/todos/feeds", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	feeds := decodeJSON(t, resp)["feeds"].([]interface{})
+	require.Len(t, feeds, 1)
+	feed := feeds[0].(map[string]interface{})
+	assert.Equal(t, "王五", feed["assignee"])
+	assert.Equal(t, float64(1), feed["todos"])
+	link, err := url.Parse(feed["url"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/todos/feed/王五.ics", link.Path)
+
+	resp = doJSON(t, srv, "GET", link.RequestURI()+"&component=vevent", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, _ = io.ReadAll(resp.Body)
+	assert.Contains(t, string(data), "X-WR-CALNAME:王五的待办事项\r\n")
+	assert.Contains(t, string(data), "BEGIN:VEVENT")
+
+	// 签名与负责人绑定
+	query := link.Query()
+	resp = doJSON(t, srv, "GET", "/api/todos/feed/"+url.PathEscape("张三")+".ics?"+query.Encode(), "", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// 不带签名时需要登录
+	resp = doJSON(t, srv, "GET", "/api/todos/feed/"+url.PathEscape("王五")+".ics", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = doJSON(t, srv, "GET", "/api/todos/feed/"+url.PathEscape("王五")+".ics", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, 1, strings.Count(string(data), "BEGIN:VTODO"))
+}
+
+// 测试单独更换或停用一个负责人的订阅链接，不影响其他负责人的链接
+func TestTodoFeedRotation(t *testing.T) {
+	mock := newMockActionItemLLM(t)
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = mock.URL
+	cfg.PublicBaseURL = "https://mm.example.com"
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "产品周会 3/14", "transcript": "第一次周会",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	feeds := func() map[string]map[string]interface{} {
+		resp := doJSON(t, srv, "GET", "/api/todos/feeds", token, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		result := map[string]map[string]interface{}{}
+		for _, feed := range decodeJSON(t, resp)["feeds"].([]interface{}) {
+			feed := feed.(map[string]interface{})
+			result[feed["assignee"].(string)] = feed
+		}
+		return result
+	}
+	status := func(link string) int {
+		u, err := url.Parse(link)
+		require.NoError(t, err)
+		return doJSON(t, srv, "GET", u.RequestURI(), "", nil).StatusCode
+	}
+
+	before := feeds()
+	require.Len(t, before, 4)
+	// 链接在再次列出时保持不变
+	assert.Equal(t, before, feeds())
+	wangwu, lisi := before["王五"]["url"].(string), before["李四"]["url"].(string)
+	assert.Equal(t, http.StatusOK, status(wangwu))
+
+	// 更换后旧链接失效，新链接可用，其他负责人的链接不变
+	resp = doJSON(t, srv, "POST", "/api/todos/feeds/"+url.PathEscape("王五")+"/rotate", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	rotated := decodeJSON(t, resp)
+	assert.Equal(t, float64(1), rotated["todos"])
+	assert.NotEqual(t, wangwu, rotated["url"])
+	assert.Equal(t, http.StatusForbidden, status(wangwu))
+	assert.Equal(t, http.StatusOK, status(rotated["url"].(string)))
+	assert.Equal(t, http.StatusOK, status(lisi))
+
+	// 停用后链接失效，登录后仍可访问
+	resp = doJSON(t, srv, "DELETE", "/api/todos/feeds/"+url.PathEscape("王五"), token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, true, decodeJSON(t, resp)["revoked"])
+	assert.Equal(t, http.StatusForbidden, status(rotated["url"].(string)))
+	assert.Equal(t, http.StatusOK, status(lisi))
+	after := feeds()
+	assert.Equal(t, true, after["王五"]["revoked"])
+	assert.NotContains(t, after["王五"], "url")
+	assert.Equal(t, lisi, after["李四"]["url"])
+	resp = doJSON(t, srv, "GET", "/api/todos/feed/"+url.PathEscape("王五")+".ics", token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/config"
+)
+
+// clearConfigEnv 清除可能影响加载结果的环境变量，并切换到没有.env的临时目录
+func clearConfigEnv(t *testing.T) string {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+	for _, key := range []string{"CONFIG_FILE", "PORT", "DATA_DIR", "SHUTDOWN_TIMEOUT", "DEEPSEEK_API_KEY",
+		"DEEPSEEK_MODEL", "NOTION_API_KEY", "NOTION_DATABASE_ID", "NOTION_USER_ALIASES", "LOG_LEVEL", "PUBLIC_BASE_URL"} {
+		t.Setenv(key, "")
+	}
+	return dir
+}
+
+// 测试配置按默认值、配置文件、.env、环境变量、参数的顺序覆盖
+func TestConfigLayers(t *testing.T) {
+	dir := clearConfigEnv(t)
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+port: "9000"
+deepseek_model: from-file
+shutdown_timeout: 45s
+notion_user_aliases:
+  小王: wang@example.com
+log_level: warn
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("DEEPSEEK_MODEL=from-dotenv\nLOG_LEVEL=error\n"), 0644))
+	t.Setenv("LOG_LEVEL", "debug")
+
+	cfg, err := config.Load(config.Options{File: file, Flags: map[string]string{"port": "9100"}})
+	require.NoError(t, err)
+	assert.Equal(t, "9100", cfg.Port)
+	assert.Equal(t, "from-dotenv", cfg.DeepSeekModel)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, 45*time.Second, cfg.ShutdownTimeout)
+	assert.Equal(t, map[string]string{"小王": "wang@example.com"}, cfg.NotionUserAliases)
+	// 未覆盖的项使用默认值，录音链接地址默认跟随端口
+	assert.Equal(t, 5, cfg.NotionSyncMaxAttempts)
+	assert.Equal(t, "http://localhost:9100", cfg.PublicBaseURL)
+
+	// .env中的变量不写入进程环境
+	assert.Empty(t, os.Getenv("DEEPSEEK_MODEL"))
+}
+
+// 测试配置错误在加载时一次全部列出
+func TestConfigValidation(t *testing.T) {
+	dir := clearConfigEnv(t)
+	t.Setenv("NOTION_DATABASE_ID", "not-a-database")
+	t.Setenv("SHUTDOWN_TIMEOUT", "30")
+	t.Setenv("LOG_LEVEL", "verbose")
+
+	_, err := config.Load(config.Options{Flags: map[string]string{"deepseek_base_url": "api.deepseek.com", "prot": "80"}})
+	var invalid *config.ValidationError
+	require.ErrorAs(t, err, &invalid)
+	assert.Len(t, invalid.Problems, 5)
+	assert.Contains(t, err.Error(), "SHUTDOWN_TIMEOUT")
+	assert.Contains(t, err.Error(), "未知的配置项: prot")
+	assert.Contains(t, err.Error(), "DEEPSEEK_BASE_URL")
+	assert.Contains(t, err.Error(), "NOTION_DATABASE_ID")
+	assert.Contains(t, err.Error(), "LOG_LEVEL")
+
+	// 配置文件中拼错的键名同样是错误
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("notion_databse_id: abc\n"), 0644))
+	t.Setenv("NOTION_DATABASE_ID", "")
+	t.Setenv("SHUTDOWN_TIMEOUT", "")
+	t.Setenv("LOG_LEVEL", "")
+	_, err = config.Load(config.Options{File: file})
+	assert.ErrorContains(t, err, "notion_databse_id")
+
+	// 带连字符的数据库ID有效
+	t.Setenv("NOTION_DATABASE_ID", "0123abcd-0123-abcd-0123-0123456789ab")
+	_, err = config.Load(config.Options{})
+	assert.NoError(t, err)
+}
+
+// 测试输出配置时遮盖密钥
+func TestConfigRedacted(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DEEPSEEK_API_KEY", "sk-very-secret")
+	t.Setenv("NOTION_API_KEY", "secret_notion")
+	t.Setenv("NOTION_DATABASE_ID", "0123456789abcdef0123456789abcdef")
+
+	cfg, err := config.Load(config.Options{})
+	require.NoError(t, err)
+	data, err := cfg.YAML()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "sk-very-secret")
+	assert.NotContains(t, string(data), "secret_notion")
+	assert.Contains(t, string(data), "deepseek_api_key: '"+config.Masked+"'")
+	assert.Contains(t, string(data), "notion_database_id: 0123456789abcdef0123456789abcdef")
+	// 原配置不受影响
+	assert.Equal(t, "sk-very-secret", cfg.DeepSeekAPIKey)
+}
+
+// 测试重新加载时只应用可以热更新的配置项
+func TestServerReload(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.AnalysisInstructions = "旧要求"
+	cfg.ShutdownTimeout = time.Second
+	srv := newTestServer(t, cfg)
+
+	next := testConfig(t)
+	next.DataDir = cfg.DataDir
+	next.DeepSeekBaseURL = cfg.DeepSeekBaseURL
+	next.AnalysisInstructions = "新要求"
+	next.ShutdownTimeout = 2 * time.Second
+	next.AudioLinkTTL = time.Hour
+	next.Port = "9000"
+
+	applied, ignored := srv.Reload(next)
+	assert.ElementsMatch(t, []string{"analysis_instructions", "shutdown_timeout", "audio_link_ttl"}, applied)
+	assert.Equal(t, []string{"port"}, ignored)
+
+	running := srv.Config()
+	assert.Equal(t, "新要求", running.AnalysisInstructions)
+	assert.Equal(t, 2*time.Second, running.ShutdownTimeout)
+	assert.Equal(t, time.Hour, running.AudioLinkTTL)
+	assert.Equal(t, "8080", running.Port)
+	// 启动时生成的认证密钥保留
+	assert.NotEmpty(t, running.AuthSecret)
+
+	// 再次加载相同的配置时没有变化
+	applied, ignored = srv.Reload(next)
+	assert.Empty(t, applied)
+	assert.Empty(t, ignored)
+}
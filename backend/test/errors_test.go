@@ -0,0 +1,139 @@
+package test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/models"
+)
+
+// 测试错误响应包含错误码、状态码、是否可重试和请求ID
+func TestErrorResponseShape(t *testing.T) {
+	srv, token := setupTestEnv(t)
+
+	resp := doJSON(t, srv, "GET", "/api/meetings/missing", token, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	requestID := resp.Header.Get("X-Request-ID")
+	body := decodeJSON(t, resp)
+	assert.Equal(t, "NOT_FOUND", body["code"])
+	assert.Equal(t, "会议不存在", body["error"])
+	assert.Equal(t, float64(http.StatusNotFound), body["status"])
+	assert.Equal(t, false, body["retryable"])
+	assert.NotEmpty(t, requestID)
+	assert.Equal(t, requestID, body["requestId"])
+
+	// 未登录
+	resp = doJSON(t, srv, "GET", "/api/meetings", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "UNAUTHORIZED", decodeJSON(t, resp)["code"])
+
+	// 偏好英文时返回英文信息
+	req := httptest.NewRequest("GET", "/api/meetings/missing", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	resp, err := srv.App().Test(req, -1)
+	assert.NoError(t, err)
+	body = decodeJSON(t, resp)
+	assert.Equal(t, "NOT_FOUND", body["code"])
+	assert.Equal(t, "The requested resource was not found.", body["error"])
+}
+
+// 测试DeepSeek不可用或返回无法解析的结果时的错误码，且不泄露上游响应
+func TestLLMErrorCodes(t *testing.T) {
+	cases := map[string]struct {
+		status    int
+		content   string
+		code      string
+		retryable bool
+	}{
+		"upstream down":  {http.StatusInternalServerError, "", "LLM_UNAVAILABLE", true},
+		"bad api key":    {http.StatusUnauthorized, "", "LLM_UNAVAILABLE", false},
+		"invalid output": {http.StatusOK, "这不是JSON", "LLM_BAD_OUTPUT", true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if tc.status != http.StatusOK {
+					w.WriteHeader(tc.status)
+					w.Write([]byte(`{"error":"secret upstream detail"}`))
+					return
+				}
+				w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"` + tc.content + `"}}]}`))
+			}))
+			defer llm.Close()
+
+			cfg := testConfig(t)
+			cfg.DeepSeekBaseURL = llm.URL
+			srv := newTestServer(t, cfg)
+			token := registerAndLogin(t, srv, "owner@example.com")
+
+			resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]string{"title": "周会", "transcript": "内容"})
+			assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+			body := decodeJSON(t, resp)
+			assert.Equal(t, tc.code, body["code"])
+			assert.Equal(t, tc.retryable, body["retryable"])
+			assert.NotContains(t, body["error"], "secret upstream detail")
+		})
+	}
+}
+
+// 测试Notion属性校验失败时返回NOTION_SCHEMA_MISMATCH
+func TestNotionSchemaMismatch(t *testing.T) {
+	notionServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/databases/"):
+			w.Write([]byte(`{"object":"database","id":"test_db","properties":{"Name":{"name":"Name","type":"title"}}}`))
+		case r.URL.Path == "/v1/users":
+			w.Write([]byte(`{"results":[],"has_more":false}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"object":"error","status":400,"code":"validation_error","message":"Name is not a property that exists."}`))
+		}
+	}))
+	defer notionServer.Close()
+
+	cfg := testConfig(t)
+	cfg.NotionBaseURL = notionServer.URL + "/v1"
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/sync-notion", token, map[string]interface{}{
+		"meeting": models.Meeting{ID: "m1", Title: "周会", Date: time.Now()},
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	body := decodeJSON(t, resp)
+	assert.Equal(t, "NOTION_SCHEMA_MISMATCH", body["code"])
+	assert.Equal(t, false, body["retryable"])
+}
+
+// 测试上传不支持的文件格式时返回AUDIO_UNSUPPORTED
+func TestUploadUnsupportedAudio(t *testing.T) {
+	srv, token := setupTestEnv(t)
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	assert.NoError(t, w.WriteField("title", "周会"))
+	fw, err := w.CreateFormFile("audio", "notes.txt")
+	assert.NoError(t, err)
+	fw.Write([]byte("not audio"))
+	assert.NoError(t, w.Close())
+
+	req := httptest.NewRequest("POST", "/api/audio/upload", &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := srv.App().Test(req, -1)
+	assert.NoError(t, err)
+	checkResponse(t, "POST", "/api/audio/upload", resp)
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	assert.Equal(t, "AUDIO_UNSUPPORTED", decodeJSON(t, resp)["code"])
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/export"
+)
+
+// 测试各格式的导出内容
+func TestExportFormats(t *testing.T) {
+	meeting := reportMeeting()
+	meeting.Summary = "讨论<项目>进度"
+
+	file, err := export.Render(export.FormatMarkdown, meeting, export.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "周会-2025-03-14.md", file.Name)
+	assert.Contains(t, string(file.Data), "| 测试前端 | 王五 | 2025-03-21 | 待办 |")
+	assert.Contains(t, string(file.Data), "1. 下周一发布（张三）")
+	assert.NotContains(t, string(file.Data), "附录")
+
+	file, err = export.Render(export.FormatHTML, meeting, export.Options{IncludeTranscript: true})
+	require.NoError(t, err)
+	html := string(file.Data)
+	assert.Contains(t, html, `<html lang="zh-CN">`)
+	assert.Contains(t, html, "讨论&lt;项目&gt;进度")
+	assert.Contains(t, html, "<strong>[00:00] 张三:</strong> 大家好。")
+
+	file, err = export.Render(export.FormatDOCX, meeting, export.Options{IncludeTranscript: true})
+	require.NoError(t, err)
+	assert.Equal(t, "周会-2025-03-14.docx", file.Name)
+	archive, err := zip.NewReader(bytes.NewReader(file.Data), int64(len(file.Data)))
+	require.NoError(t, err)
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		parts[f.Name] = string(data)
+	}
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts["word/styles.xml"], `w:eastAsia="宋体"`)
+	assert.Contains(t, parts["word/document.xml"], "讨论&lt;项目&gt;进度")
+	assert.Contains(t, parts["word/document.xml"], "<w:tblHeader/>")
+	assert.Contains(t, parts["word/document.xml"], "附录：会议记录")
+
+	// 相同的会议导出相同的文件
+	again, err := export.Render(export.FormatDOCX, meeting, export.Options{IncludeTranscript: true})
+	require.NoError(t, err)
+	assert.Equal(t, file.Data, again.Data)
+
+	file, err = export.Render(export.FormatPDF, meeting, export.Options{})
+	require.NoError(t, err)
+	pdf := string(file.Data)
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4"))
+	assert.Contains(t, pdf, "/BaseFont /STSong-Light")
+	assert.Contains(t, pdf, "/Encoding /UniGB-UCS2-H")
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+
+	_, err = export.Render("xlsx", meeting, export.Options{})
+	assert.ErrorIs(t, err, export.ErrUnsupportedFormat)
+}
+
+// 测试长会议的PDF分页
+func TestExportPDFPages(t *testing.T) {
+	meeting := reportMeeting()
+	meeting.Summary = strings.Repeat("很长的摘要内容 with some English words. ", 400)
+
+	file, err := export.Render(export.FormatPDF, meeting, export.Options{})
+	require.NoError(t, err)
+	pages := strings.Count(string(file.Data), "/Type /Page ")
+	assert.Greater(t, pages, 1)
+	assert.Contains(t, string(file.Data), fmt.Sprintf("/Count %d", pages))
+}
+
+// 测试导出接口
+func TestExportMeetingAPI(t *testing.T) {
+	srv, token := setupTestEnv(t)
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "周会", "transcript": "会议内容",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	meetingID := decodeJSON(t, resp)["meeting"].(map[string]interface{})["id"].(string)
+	path := "/api/meetings/" + meetingID + "/export"
+
+	resp = doJSON(t, srv, "GET", path, token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/markdown; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), `attachment; filename="__-`)
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "filename*=UTF-8''%E5%91%A8%E4%BC%9A-")
+	data, _ := io.ReadAll(resp.Body)
+	assert.NotContains(t, string(data), "会议内容")
+
+	resp = doJSON(t, srv, "GET", path+"?format=html&transcript=true", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	data, _ = io.ReadAll(resp.Body)
+	assert.Contains(t, string(data), "会议内容")
+
+	for _, format := range []string{export.FormatDOCX, export.FormatPDF} {
+		resp = doJSON(t, srv, "GET", path+"?format="+format, token, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, export.ContentType(format), resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "."+format)
+	}
+
+	resp = doJSON(t, srv, "GET", path+"?format=xlsx", token, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "BAD_REQUEST", decodeJSON(t, resp)["code"])
+
+	resp = doJSON(t, srv, "GET", "/api/meetings/missing/export", token, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
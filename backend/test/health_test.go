@@ -0,0 +1,108 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/config"
+)
+
+// fakeTranscriber 在临时目录中生成模拟的Python、ffmpeg和Whisper模型文件，
+// pythonExit为Python导入whisper模块时的退出码
+func fakeTranscriber(t *testing.T, cfg *config.Config, pythonExit string) {
+	bin := t.TempDir()
+	python := filepath.Join(bin, "python")
+	script := "#!/bin/sh\necho \"ModuleNotFoundError: No module named 'whisper'\" >&2\nexit " + pythonExit + "\n"
+	assert.NoError(t, os.WriteFile(python, []byte(script), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "ffmpeg"), []byte("#!/bin/sh\n"), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	assert.NoError(t, os.MkdirAll(filepath.Join(cache, "whisper"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(cache, "whisper", "base.pt"), []byte("model"), 0644))
+
+	cfg.WhisperPython = python
+	cfg.WhisperModel = "base"
+}
+
+// components 按名称整理就绪检查中的组件
+func components(body map[string]interface{}) map[string]map[string]interface{} {
+	result := map[string]map[string]interface{}{}
+	list, _ := body["components"].([]interface{})
+	for _, item := range list {
+		component := item.(map[string]interface{})
+		result[component["name"].(string)] = component
+	}
+	return result
+}
+
+// 测试存活检查不依赖外部服务
+func TestHealthLive(t *testing.T) {
+	srv, token := setupTestEnv(t)
+
+	for name, do := range transports(t, srv, token) {
+		t.Run(name, func(t *testing.T) {
+			resp := do(httptest.NewRequest("GET", "/api/health/live", nil))
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "ok", decodeJSON(t, resp)["status"])
+		})
+	}
+}
+
+// 测试全部依赖可用时就绪检查通过，并报告每个组件的状态和耗时
+func TestHealthReady(t *testing.T) {
+	notionMock, notionServer := newMockNotion(t)
+	cfg := testConfig(t)
+	cfg.NotionBaseURL = notionServer.URL + "/v1"
+	fakeTranscriber(t, cfg, "0")
+	srv := newTestServer(t, cfg)
+
+	resp := doJSON(t, srv, "GET", "/api/health/ready", "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body := decodeJSON(t, resp)
+	assert.Equal(t, "ok", body["status"])
+
+	byName := components(body)
+	for _, name := range []string{"server", "storage", "disk", "transcriber", "llm", "notion"} {
+		if assert.Contains(t, byName, name) {
+			assert.Equal(t, "ok", byName[name]["status"], name)
+			assert.Contains(t, byName[name], "latencyMs")
+		}
+	}
+
+	// 外部服务的检查结果被缓存，重复探测不再请求Notion
+	resp = doJSON(t, srv, "GET", "/api/health/ready", "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	notionMock.mu.Lock()
+	assert.Len(t, notionMock.requests["GET /v1/databases/test_db"], 1)
+	notionMock.mu.Unlock()
+}
+
+// 测试依赖不可用时就绪检查返回503，并指出失败的组件
+func TestHealthReadyFailures(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.DeepSeekAPIKey = ""
+	cfg.NotionAPIKey = ""
+	fakeTranscriber(t, cfg, "1")
+	srv := newTestServer(t, cfg)
+
+	resp := doJSON(t, srv, "GET", "/api/health/ready", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	body := decodeJSON(t, resp)
+	assert.Equal(t, "fail", body["status"])
+
+	byName := components(body)
+	assert.Equal(t, "ok", byName["storage"]["status"])
+	assert.Equal(t, "fail", byName["transcriber"]["status"])
+	assert.Contains(t, byName["transcriber"]["message"], "No module named 'whisper'")
+	assert.Equal(t, "fail", byName["llm"]["status"])
+	assert.Equal(t, "LLM_UNAVAILABLE", byName["llm"]["code"])
+	assert.Equal(t, "未配置DeepSeek API密钥", byName["llm"]["message"])
+	assert.Equal(t, "NOTION_UNAUTHORIZED", byName["notion"]["code"])
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/models"
+	"meeting-mm/services"
+)
+
+// insightsAnalysis 包含扩展分析内容的模拟分析结果
+const insightsAnalysis = `{"summary":"讨论发布计划",
+	"todoItems":[],"decisions":[{"description":"下周一发布","madeBy":"张三"}],
+	"openQuestions":["预算由谁审批"," "],
+	"risks":[{"description":"测试环境不稳定","kind":"Blocker","owner":"王五"},{"description":"人手不足","kind":"unknown"}],
+	"topics":[{"title":"发布计划","summary":"确定日期","start":"00:05","end":"01:10"},{"title":"预算","start":null,"end":null},{"title":"其他","start":"02:00","end":"01:00"}],
+	"keywords":["发布","预算","发布"," "],
+	"speakers":[{"speaker":"张三","sentiment":"positive","engagement":"high","note":"主导讨论"},{"speaker":"王五","sentiment":"angry","engagement":""}]}`
+
+// 测试扩展分析：问题、风险、主题、关键词和发言人态度写入会议，并出现在报告和导出文件中
+func TestAnalyzeInsights(t *testing.T) {
+	deepseek := newCapturingDeepSeek(t, func(string) string { return insightsAnalysis })
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = deepseek.URL
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "周会",
+		"segments": []map[string]interface{}{
+			{"startTime": 5, "endTime": 30, "speaker": "张三", "text": "先看发布计划"},
+			{"startTime": 65, "endTime": 70, "speaker": "王五", "text": "测试环境还不稳定"},
+		},
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body := decodeJSON(t, resp)
+	meeting := body["meeting"].(map[string]interface{})
+
+	assert.Equal(t, []interface{}{"预算由谁审批"}, meeting["openQuestions"])
+	assert.Equal(t, []interface{}{"发布", "预算"}, meeting["keywords"])
+	risks := meeting["risks"].([]interface{})
+	require.Len(t, risks, 2)
+	assert.Equal(t, "blocker", risks[0].(map[string]interface{})["kind"])
+	assert.Equal(t, "risk", risks[1].(map[string]interface{})["kind"])
+
+	topics := meeting["topics"].([]interface{})
+	require.Len(t, topics, 3)
+	assert.Equal(t, 5.0, topics[0].(map[string]interface{})["startTime"])
+	assert.Equal(t, 70.0, topics[0].(map[string]interface{})["endTime"])
+	assert.Equal(t, 0.0, topics[2].(map[string]interface{})["endTime"])
+
+	speakers := meeting["speakerInsights"].([]interface{})
+	require.Len(t, speakers, 2)
+	assert.Equal(t, "neutral", speakers[1].(map[string]interface{})["sentiment"])
+	assert.Equal(t, "medium", speakers[1].(map[string]interface{})["engagement"])
+	assert.Len(t, meeting["segments"], 2)
+
+	report := body["markdownReport"].(string)
+	assert.Contains(t, report, "- **关键词**：发布、预算\n")
+	assert.Contains(t, report, "## 讨论主题\n\n1. 发布计划（00:05–01:10）：确定日期\n2. 预算\n3. 其他\n")
+	assert.Contains(t, report, "## 待解决的问题\n\n- 预算由谁审批\n")
+	assert.Contains(t, report, "## 风险和阻塞项\n\n- 【阻塞】测试环境不稳定（跟进：王五）\n- 【风险】人手不足\n")
+	assert.Contains(t, report, "## 发言人\n\n- 张三：积极，参与度高。主导讨论\n- 王五：中立，参与度中\n")
+
+	prompt := deepseek.Prompt()
+	assert.Contains(t, prompt, "[00:05] 张三: 先看发布计划\n[01:05] 王五: 测试环境还不稳定\n")
+	assert.Contains(t, prompt, `"openQuestions"`)
+
+	resp = doJSON(t, srv, "GET", "/api/meetings/"+meeting["id"].(string)+"/export", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, _ := io.ReadAll(resp.Body)
+	markdown := string(data)
+	assert.Contains(t, markdown, "- **关键词**：发布、预算\n")
+	assert.Contains(t, markdown, "## 讨论主题\n\n- 发布计划（00:05–01:10）：确定日期\n- 预算\n")
+	assert.Contains(t, markdown, "## 风险和阻塞项\n\n- 【阻塞】测试环境不稳定（跟进：王五）\n")
+	assert.Contains(t, markdown, "## 发言人\n\n- 张三：积极，参与度高。主导讨论\n")
+
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title":    "周会",
+		"segments": []map[string]interface{}{{"startTime": 10, "endTime": 5, "text": "内容"}},
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// 测试Notion默认布局中的主题、问题、风险和发言人区块
+func TestNotionLayoutInsights(t *testing.T) {
+	meeting := &models.Meeting{
+		Date:          time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		Keywords:      []string{"发布"},
+		Topics:        []models.Topic{{Title: "发布计划", StartTime: 5, EndTime: 70}},
+		OpenQuestions: []string{"预算由谁审批"},
+		Risks:         []models.Risk{{Description: "测试环境不稳定", Kind: models.RiskKindBlocker, Owner: "王五"}},
+		SpeakerInsights: []models.SpeakerInsight{
+			{Speaker: "王五", Sentiment: models.SentimentNegative, Engagement: models.EngagementLow},
+		},
+	}
+
+	blocks, err := services.DefaultNotionLayout().Build(meeting, services.BuildOptions{Mentions: map[string]string{"王五": "u-wang"}})
+	require.NoError(t, err)
+
+	var types []string
+	for _, block := range blocks {
+		types = append(types, block.Type)
+	}
+	assert.Equal(t, []string{
+		"callout", "heading_2", "numbered_list_item", "heading_2", "bulleted_list_item",
+		"heading_2", "bulleted_list_item", "heading_2", "bulleted_list_item",
+	}, types)
+	assert.Equal(t, "🏷️ 关键词：发布", plainText(blocks[0].Callout.Children[1].Paragraph.RichText))
+	assert.Equal(t, "发布计划 00:05–01:10", plainText(blocks[2].NumberedListItem.RichText))
+	assert.Equal(t, "⛔ 测试环境不稳定 ", plainText(blocks[6].BulletedListItem.RichText))
+	assert.Equal(t, "u-wang", blocks[6].BulletedListItem.RichText[2].Mention.User.ID)
+	assert.Equal(t, "：消极，参与度低", plainText(blocks[8].BulletedListItem.RichText[1:]))
+}
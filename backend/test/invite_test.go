@@ -0,0 +1,178 @@
+package test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/services"
+)
+
+// weeklyInvite 日历应用导出的会议邀请：含折行、转义、时区、资源和提醒
+const weeklyInvite = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Calendar//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:weekly-2025-03-14@example.com\r\n" +
+	"SUMMARY:产品周会\r\n" +
+	"DTSTART;TZID=Asia/Shanghai:20250314T100000\r\n" +
+	"DTEND;TZID=Asia/Shanghai:20250314T110000\r\n" +
+	"LOCATION:3楼会议室\\, A区\r\n" +
+	"ORGANIZER;CN=张三:mailto:zhangsan@example.com\r\n" +
+	"ATTENDEE;CN=\"Li, Si\";PARTSTAT=ACCEPTED:mailto:lisi@example.com\r\n" +
+	"ATTENDEE;PARTSTAT=NEEDS-ACTION:mailto:wangwu@example.com\r\n" +
+	"ATTENDEE;CN=赵六;PARTSTAT=DECLINED:mailto:zhaoliu@example.com\r\n" +
+	"ATTENDEE;CUTYPE=ROOM;CN=3楼会议室:mailto:room3@example.com\r\n" +
+	"ATTENDEE;CN=张三:mailto:zhangsan@example.com\r\n" +
+	"DESCRIPTION:1. 上周待办回顾\\n2. 发布计划\\; 确认日期\\n3. 前端测试进\r\n" +
+	" 度\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:提醒\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"SUMMARY:站会\r\n" +
+	"DTSTART:20250315T013000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+// 测试解析会议邀请
+func TestParseInvite(t *testing.T) {
+	invite, err := services.ParseInvite([]byte(weeklyInvite), "")
+	require.NoError(t, err)
+	assert.Equal(t, "weekly-2025-03-14@example.com", invite.UID)
+	assert.Equal(t, "产品周会", invite.Title)
+	assert.Equal(t, "3楼会议室, A区", invite.Location)
+	assert.Equal(t, []string{"张三", "Li, Si", "wangwu@example.com"}, invite.Attendees)
+	assert.Equal(t, "1. 上周待办回顾\n2. 发布计划; 确认日期\n3. 前端测试进度", invite.Agenda)
+	assert.True(t, invite.Start.Equal(time.Date(2025, 3, 14, 2, 0, 0, 0, time.UTC)), invite.Start)
+	assert.Equal(t, time.Hour, invite.End.Sub(invite.Start))
+
+	standup, err := services.ParseInvite([]byte(weeklyInvite), "standup@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "站会", standup.Title)
+	assert.True(t, standup.Start.Equal(time.Date(2025, 3, 15, 1, 30, 0, 0, time.UTC)))
+
+	_, err = services.ParseInvite([]byte(weeklyInvite), "missing")
+	assert.ErrorIs(t, err, services.ErrInviteNotFound)
+	_, err = services.ParseInvite([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"), "")
+	assert.ErrorIs(t, err, services.ErrInvalidInvite)
+	_, err = services.ParseInvite([]byte("BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n"), "")
+	assert.ErrorIs(t, err, services.ErrInvalidInvite)
+}
+
+// 测试用日历目录中的会议邀请填写会议信息，议程作为分析的上下文
+func TestAnalyzeWithCalendarEvent(t *testing.T) {
+	deepseek := newCapturingDeepSeek(t, func(string) string { return mockAnalysis })
+
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = deepseek.URL
+	cfg.CalendarDir = t.TempDir()
+	cfg.AuthAllowSignup = true
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "GET", "/api/workspace", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	dir := filepath.Join(cfg.CalendarDir, decodeJSON(t, resp)["id"].(string))
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "weekly.ics"), []byte(weeklyInvite), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.ics"), []byte("not a calendar"), 0o644))
+
+	resp = doJSON(t, srv, "GET", "/api/calendar/events?date=2025-03-14", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	events := decodeJSON(t, resp)["events"].([]interface{})
+	require.Len(t, events, 1)
+	assert.Equal(t, "产品周会", events[0].(map[string]interface{})["title"])
+
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"transcript": "会议内容", "calendarEventUid": "weekly-2025-03-14@example.com",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	meeting := decodeJSON(t, resp)["meeting"].(map[string]interface{})
+	assert.Equal(t, "产品周会", meeting["title"])
+	assert.Equal(t, []interface{}{"张三", "Li, Si", "wangwu@example.com"}, meeting["participants"])
+	assert.Equal(t, "weekly-2025-03-14@example.com", meeting["calendarEventUid"])
+	date, err := time.Parse(time.RFC3339, meeting["date"].(string))
+	require.NoError(t, err)
+	assert.True(t, date.Equal(time.Date(2025, 3, 14, 2, 0, 0, 0, time.UTC)))
+
+	prompt := deepseek.Prompt()
+	assert.Contains(t, prompt, "会议标题：产品周会\n会议时间：2025-03-14 10:00\n参会人员：张三、Li, Si、wangwu@example.com\n")
+	assert.Contains(t, prompt, "会议议程：\n1. 上周待办回顾\n2. 发布计划; 确认日期\n3. 前端测试进度\n")
+
+	// 不使用会议邀请时提示词中没有背景信息
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{"title": "周会", "transcript": "内容"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, deepseek.Prompt(), "会议标题：周会\n\n会议记录：\n内容")
+
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"transcript": "会议内容", "calendarEventUid": "missing",
+	})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// 其他工作区看不到这个工作区的日历
+	other := registerAndLogin(t, srv, "other@example.com")
+	resp = doJSON(t, srv, "GET", "/api/calendar/events", other, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, decodeJSON(t, resp)["events"])
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", other, map[string]interface{}{
+		"transcript": "会议内容", "calendarEventUid": "weekly-2025-03-14@example.com",
+	})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// 测试上传音频时的会议邀请和议程在转录之前校验
+func TestUploadInviteValidation(t *testing.T) {
+	srv, token := setupTestEnv(t)
+
+	upload := func(fields map[string]string, invite string) *http.Response {
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		for name, value := range fields {
+			w.WriteField(name, value)
+		}
+		if invite != "" {
+			fw, _ := w.CreateFormFile("invite", "invite.ics")
+			fw.Write([]byte(invite))
+		}
+		fw, _ := w.CreateFormFile("audio", "meeting.mp3")
+		fw.Write([]byte("not audio"))
+		w.Close()
+
+		req := httptest.NewRequest("POST", "/api/audio/upload", &b)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := srv.App().Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := upload(nil, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, decodeJSON(t, resp)["error"], "会议标题不能为空")
+
+	resp = upload(nil, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, decodeJSON(t, resp)["error"], "无效的会议邀请")
+
+	// 未配置日历目录时不能按UID选择会议
+	resp = upload(map[string]string{"calendarEventUid": "weekly"}, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, decodeJSON(t, resp)["error"], "CALENDAR_DIR")
+
+	resp = upload(map[string]string{"title": "周会", "agenda": strings.Repeat("- 议题\n", 51)}, "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, decodeJSON(t, resp)["error"], "议程项不能超过50项")
+}
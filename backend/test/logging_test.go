@@ -0,0 +1,114 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/logging"
+	"meeting-mm/models"
+)
+
+// logLines 解析JSON格式的日志输出
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// 测试密钥总是被遮盖，会议内容只在开启采集时保留
+func TestLogRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Options{Level: "debug", Format: "json"})
+	assert.NoError(t, err)
+
+	logger.Info("调用失败 Authorization: Bearer abc.def.ghi",
+		"api_key", "sk-1234567890abcdef",
+		"notion_token", "secret_abcdefghijklmnop",
+		"error", errors.New("密钥ntn_abcdefghijklmnop无效"),
+		"transcript", "张三：这是机密内容",
+		"attempts", 2,
+	)
+	out := buf.String()
+	for _, leaked := range []string{"abc.def.ghi", "sk-1234567890abcdef", "secret_abcdefghijklmnop", "ntn_abcdefghijklmnop", "机密内容"} {
+		assert.NotContains(t, out, leaked)
+	}
+	entry := logLines(t, &buf)[0]
+	assert.Equal(t, "调用失败 Authorization: Bearer ***", entry["msg"])
+	assert.Equal(t, logging.Masked, entry["api_key"])
+	assert.Equal(t, "密钥ntn_***无效", entry["error"])
+	assert.Equal(t, "[已隐藏 9 字]", entry["transcript"])
+	assert.Equal(t, float64(2), entry["attempts"])
+
+	buf.Reset()
+	logger, err = logging.New(&buf, logging.Options{Level: "info", Format: "json", CaptureContent: true})
+	assert.NoError(t, err)
+	logger.Debug("不输出")
+	logger.Info("分析完成", "transcript", "张三：这是机密内容", "token", "mm_abcdefghijkl")
+	entry = logLines(t, &buf)[0]
+	assert.Equal(t, "张三：这是机密内容", entry["transcript"])
+	assert.Equal(t, logging.Masked, entry["token"])
+
+	_, err = logging.New(&buf, logging.Options{Level: "verbose"})
+	assert.Error(t, err)
+}
+
+// 测试请求处理过程中的日志带有请求ID，且不包含会议内容和凭据
+func TestRequestLogCorrelation(t *testing.T) {
+	_, notionServer := newMockNotion(t)
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Options{Level: "debug", Format: "json"})
+	assert.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+
+	cfg := testConfig(t)
+	cfg.NotionBaseURL = notionServer.URL + "/v1"
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"meeting": models.Meeting{ID: "m1", Title: "周会", Date: time.Now(), Transcript: "张三：预算是三百万"},
+	})
+	req := httptest.NewRequest("POST", "/api/meetings/sync-notion", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Request-ID", "req-123")
+	resp, err := srv.App().Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	out := buf.String()
+	assert.NotContains(t, out, "预算是三百万")
+	assert.NotContains(t, out, token)
+
+	messages := map[string]map[string]interface{}{}
+	for _, entry := range logLines(t, &buf) {
+		if entry["request_id"] == "req-123" {
+			messages[entry["msg"].(string)] = entry
+		}
+	}
+	assert.Contains(t, messages, "收到Notion同步请求")
+	assert.Contains(t, messages, "会议已同步到Notion")
+	if access, ok := messages["请求完成"]; assert.True(t, ok) {
+		assert.Equal(t, float64(http.StatusOK), access["status"])
+		assert.Equal(t, "/api/meetings/sync-notion", access["path"])
+	}
+}
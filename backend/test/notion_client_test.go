@@ -0,0 +1,235 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/config"
+	"meeting-mm/models"
+	"meeting-mm/notion"
+	"meeting-mm/services"
+	"meeting-mm/storage"
+)
+
+// mockNotion 模拟Notion API，记录收到的请求体
+type mockNotion struct {
+	mu       sync.Mutex
+	requests map[string][]map[string]interface{}
+}
+
+func newMockNotion(t *testing.T) (*mockNotion, *httptest.Server) {
+	mock := &mockNotion{requests: map[string][]map[string]interface{}{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test_key", r.Header.Get("Authorization"))
+		assert.Equal(t, notion.APIVersion, r.Header.Get("Notion-Version"))
+
+		var body map[string]interface{}
+		raw, _ := io.ReadAll(r.Body)
+		if len(raw) > 0 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			if err := json.Unmarshal(raw, &body); err != nil {
+				t.Errorf("请求体不是合法JSON: %v", err)
+			}
+		}
+
+		key := r.Method + " " + r.URL.Path
+		mock.mu.Lock()
+		mock.requests[key] = append(mock.requests[key], body)
+		mock.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/databases/"):
+			w.Write([]byte(`{"object":"database","id":"test_db","properties":{
+				"Name":{"id":"title","name":"Name","type":"title"},
+				"Date":{"id":"d","name":"Date","type":"date"},
+				"Summary":{"id":"s","name":"Summary","type":"rich_text"},
+				"Tags":{"id":"t","name":"Tags","type":"multi_select"}}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/users":
+			w.Write([]byte(`{"object":"list","results":[
+				{"object":"user","id":"u-wang","type":"person","name":"Wang Wu","person":{"email":"wangwu@example.com"}},
+				{"object":"user","id":"u-bot","type":"bot","name":"Meeting Bot"}],"has_more":false}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/file_uploads":
+			w.Write([]byte(`{"object":"file_upload","id":"fu-1","status":"pending"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/file_uploads/fu-1/send":
+			w.Write([]byte(`{"object":"file_upload","id":"fu-1","status":"uploaded"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/pages":
+			w.Write([]byte(`{"object":"page","id":"page-123"}`))
+		case r.Method == http.MethodPatch && strings.HasSuffix(r.URL.Path, "/children"):
+			// 按追加的子块数量返回新建块，ID为 blk-<序号>
+			children, _ := body["children"].([]interface{})
+			results := make([]map[string]string, len(children))
+			for i := range children {
+				results[i] = map[string]string{"object": "block", "id": fmt.Sprintf("blk-%d", i), "type": "paragraph"}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "results": results})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"object":"error","status":404,"code":"object_not_found","message":"not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return mock, server
+}
+
+func (m *mockNotion) count(key string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.requests[key])
+}
+
+func (m *mockNotion) first(key string) map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.requests[key]) == 0 {
+		return nil
+	}
+	return m.requests[key][0]
+}
+
+// 测试同步会议时特殊字符被正确编码，长内容被分批追加
+func TestNotionSyncMeeting(t *testing.T) {
+	mock, server := newMockNotion(t)
+
+	notionService := services.NewNotionService(&config.Config{
+		NotionAPIKey:     "test_key",
+		NotionDatabaseID: "test_db",
+		NotionBaseURL:    server.URL + "/v1",
+	})
+
+	todos := make([]models.TodoItem, 150)
+	for i := range todos {
+		todos[i] = models.TodoItem{Description: fmt.Sprintf("待办%d", i), Status: "pending"}
+	}
+
+	meeting := &models.Meeting{
+		ID:      "m1",
+		Title:   `发布"v1"讨论`,
+		Date:    time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		Summary: `决定使用 "灰度" 发布 $HOME \n 不展开`,
+		// 选项名称中的逗号替换为空格
+		Keywords: []string{"灰度发布", "v1,v2"},
+		// 超过100个顶层块，需要分批追加
+		TodoItems: todos,
+		// 2000字符一段，折叠块中会有超过100个嵌套子块
+		Transcript: strings.Repeat("字", 2000*120),
+	}
+
+	err := notionService.SyncMeeting(context.Background(), meeting)
+	assert.NoError(t, err)
+	assert.Equal(t, "page-123", meeting.NotionPageID)
+
+	page := mock.first("POST /v1/pages")
+	if assert.NotNil(t, page) {
+		props := page["properties"].(map[string]interface{})
+		title := props["Name"].(map[string]interface{})["title"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, `发布"v1"讨论`, title["text"].(map[string]interface{})["content"])
+
+		summary := props["Summary"].(map[string]interface{})["rich_text"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, meeting.Summary, summary["text"].(map[string]interface{})["content"])
+
+		tags := props["Tags"].(map[string]interface{})["multi_select"].([]interface{})
+		assert.Equal(t, "v1 v2", tags[1].(map[string]interface{})["name"])
+
+		assert.Len(t, page["children"], 100)
+	}
+
+	// 剩余的顶层块（52个待办和折叠块）追加到页面
+	appended := mock.first("PATCH /v1/blocks/page-123/children")
+	if assert.NotNil(t, appended) {
+		children := appended["children"].([]interface{})
+		assert.Len(t, children, 53)
+		toggle := children[52].(map[string]interface{})["toggle"].(map[string]interface{})
+		assert.Len(t, toggle["children"], 100)
+	}
+
+	// 折叠块中超出的20段追加到新建的折叠块下
+	overflow := mock.first("PATCH /v1/blocks/blk-52/children")
+	if assert.NotNil(t, overflow) {
+		assert.Len(t, overflow["children"], 20)
+	}
+}
+
+// 测试保留的录音：未超过大小上限时上传到Notion，超过时改为链接
+func TestNotionSyncMeetingAudio(t *testing.T) {
+	mock, server := newMockNotion(t)
+
+	dataDir := t.TempDir()
+	audio, err := storage.NewFileStore(storage.AudioDir(dataDir))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, audio.Save("m1.mp3", []byte("fake mp3 data")))
+
+	newService := func(maxBytes int) *services.NotionService {
+		return services.NewNotionService(&config.Config{
+			NotionAPIKey:        "test_key",
+			NotionDatabaseID:    "test_db",
+			NotionBaseURL:       server.URL + "/v1",
+			DataDir:             dataDir,
+			KeepAudio:           true,
+			NotionAudioMaxBytes: maxBytes,
+			PublicBaseURL:       "https://mm.example.com/",
+			AuthSecret:          "secret",
+		})
+	}
+	audioChild := func(page map[string]interface{}) map[string]interface{} {
+		for _, child := range page["children"].([]interface{}) {
+			block := child.(map[string]interface{})
+			if block["type"] == "audio" || strings.Contains(fmt.Sprint(block), "/audio") {
+				return block
+			}
+		}
+		return nil
+	}
+
+	meeting := &models.Meeting{ID: "m1", Title: "周会", AudioFile: "m1.mp3"}
+	assert.NoError(t, newService(1024).SyncMeeting(context.Background(), meeting))
+	assert.Equal(t, 1, mock.count("POST /v1/file_uploads/fu-1/send"))
+	uploaded := audioChild(mock.first("POST /v1/pages"))
+	if assert.NotNil(t, uploaded) {
+		assert.Equal(t, "fu-1", uploaded["audio"].(map[string]interface{})["file_upload"].(map[string]interface{})["id"])
+	}
+
+	// 超过上限时不上传，页面中写入指向本服务的录音链接
+	mock.requests = map[string][]map[string]interface{}{}
+	assert.NoError(t, newService(4).SyncMeeting(context.Background(), meeting))
+	assert.Equal(t, 0, mock.count("POST /v1/file_uploads"))
+	linked := audioChild(mock.first("POST /v1/pages"))
+	if assert.NotNil(t, linked) {
+		assert.Equal(t, "paragraph", linked["type"])
+		match := regexp.MustCompile(`https://mm\.example\.com/api/public/meetings/m1/audio\?exp=(\d+)&sig=([\w-]+)`).FindStringSubmatch(fmt.Sprint(linked))
+		if assert.NotNil(t, match) {
+			expires, _ := strconv.ParseInt(match[1], 10, 64)
+			assert.InDelta(t, time.Now().Add(30*24*time.Hour).Unix(), expires, 60)
+			assert.Equal(t, services.AudioSignature("secret", "m1", expires), match[2])
+		}
+	}
+	assert.Equal(t, "audio/mpeg", services.AudioContentType(filepath.Join("x", "m1.MP3")))
+}
+
+// 测试Notion错误响应被解析为APIError
+func TestNotionClientAPIError(t *testing.T) {
+	_, server := newMockNotion(t)
+
+	client := notion.NewClient("test_key", server.URL+"/v1")
+	_, err := client.RetrievePage("missing")
+
+	apiErr, ok := err.(*notion.APIError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusNotFound, apiErr.Status)
+		assert.Equal(t, "object_not_found", apiErr.Code)
+	}
+}
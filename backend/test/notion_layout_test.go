@@ -0,0 +1,134 @@
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/models"
+	"meeting-mm/notion"
+	"meeting-mm/services"
+)
+
+func plainText(richText []notion.RichText) string {
+	var b strings.Builder
+	for _, rt := range richText {
+		if rt.Text != nil {
+			b.WriteString(rt.Text.Content)
+		}
+	}
+	return b.String()
+}
+
+// 测试默认布局生成标注、待办、编号决策和带时间戳的折叠会议记录
+func TestDefaultNotionLayout(t *testing.T) {
+	meeting := &models.Meeting{
+		Title:        "周会",
+		Date:         time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		Participants: []string{"张三", "李四"},
+		Summary:      "讨论发布计划",
+		TodoItems:    []models.TodoItem{{Description: "完成测试", Assignee: "王五", Status: "pending"}},
+		Decisions:    []models.Decision{{Description: "下周一发布"}},
+		Segments: []models.TranscriptSegment{
+			{StartTime: 5, Speaker: "张三", Text: "大家好"},
+			{StartTime: 3725, Speaker: "李四", Text: "前端已完成"},
+			{StartTime: 3800, Text: "（无说话人）"},
+		},
+	}
+
+	blocks, err := services.DefaultNotionLayout().Build(meeting, services.BuildOptions{})
+	assert.NoError(t, err)
+
+	var types []string
+	for _, block := range blocks {
+		types = append(types, block.Type)
+	}
+	assert.Equal(t, []string{"callout", "heading_2", "to_do", "heading_2", "numbered_list_item", "toggle"}, types)
+
+	callout := blocks[0].Callout
+	assert.Equal(t, "讨论发布计划", plainText(callout.RichText))
+	assert.Equal(t, "👥 参与人员：张三、李四", plainText(callout.Children[1].Paragraph.RichText))
+
+	assert.Contains(t, plainText(blocks[2].ToDo.RichText), "@王五")
+
+	transcript := blocks[5].Toggle.Children
+	if assert.Len(t, transcript, 3) {
+		assert.True(t, strings.HasPrefix(plainText(transcript[0].Paragraph.RichText), "[00:05] 张三: 大家好"))
+		assert.True(t, strings.HasPrefix(plainText(transcript[1].Paragraph.RichText), "[62:05] 李四: "))
+		assert.True(t, strings.HasPrefix(plainText(transcript[2].Paragraph.RichText), "[63:20] （无说话人）"))
+	}
+}
+
+// 测试从文件加载自定义布局
+func TestLoadNotionLayout(t *testing.T) {
+	layout, err := services.LoadNotionLayout(filepath.Join("..", "..", "docs", "notion_layout.example.json"))
+	if assert.NoError(t, err) {
+		blocks, err := layout.Build(&models.Meeting{Transcript: "全文", Date: time.Now()}, services.BuildOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, "toggle", blocks[len(blocks)-1].Type)
+		assert.Equal(t, "会议记录（0 段）", plainText(blocks[len(blocks)-1].Toggle.RichText))
+	}
+
+	bad := &services.NotionLayout{Sections: []services.NotionSection{{Type: "table"}}}
+	assert.Error(t, bad.Validate())
+}
+
+// 测试有议程时默认布局按议程项列出讨论情况、决策和待办，其余条目放在议程之后
+func TestNotionLayoutAgenda(t *testing.T) {
+	meeting := &models.Meeting{
+		Title: "周会",
+		Date:  time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		AgendaItems: []models.AgendaItem{
+			{ID: "a1", Title: "发布计划", Status: models.AgendaCovered, Summary: "确定发布时间"},
+			{ID: "a2", Title: "预算", Status: models.AgendaDeferred},
+		},
+		TodoItems: []models.TodoItem{
+			{Description: "完成测试", Status: "pending", AgendaID: "a1"},
+			{Description: "整理文档", Status: "pending"},
+		},
+		Decisions: []models.Decision{{Description: "下周一发布", AgendaID: "a1"}},
+	}
+
+	blocks, err := services.DefaultNotionLayout().Build(meeting, services.BuildOptions{})
+	assert.NoError(t, err)
+
+	var types []string
+	for _, block := range blocks {
+		types = append(types, block.Type)
+	}
+	assert.Equal(t, []string{
+		"callout",
+		"heading_2", "heading_3", "paragraph", "bulleted_list_item", "to_do", "heading_3",
+		"heading_2", "to_do",
+	}, types)
+	assert.Equal(t, "1. 发布计划（✅ 已讨论）", plainText(blocks[2].Heading3.RichText))
+	assert.Equal(t, "决策：下周一发布", plainText(blocks[4].BulletedListItem.RichText))
+	assert.Equal(t, "2. 预算（⏭️ 已推迟）", plainText(blocks[6].Heading3.RichText))
+	assert.Equal(t, "其他待办事项", plainText(blocks[7].Heading2.RichText))
+	assert.Equal(t, "整理文档", plainText(blocks[8].ToDo.RichText))
+}
+
+// 测试默认布局为有内容的额外提取字段各生成一节
+func TestNotionLayoutExtraFields(t *testing.T) {
+	meeting := &models.Meeting{
+		Date: time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+		ExtraFields: []models.ExtraField{
+			{Key: "risks", Label: "风险", Items: []string{"发布延期", "人手不足"}},
+			{Key: "asks", Label: "客户需求", Items: []string{}},
+		},
+	}
+
+	blocks, err := services.DefaultNotionLayout().Build(meeting, services.BuildOptions{})
+	assert.NoError(t, err)
+
+	var types []string
+	for _, block := range blocks {
+		types = append(types, block.Type)
+	}
+	assert.Equal(t, []string{"callout", "heading_2", "bulleted_list_item", "bulleted_list_item"}, types)
+	assert.Equal(t, "风险", plainText(blocks[1].Heading2.RichText))
+	assert.Equal(t, "人手不足", plainText(blocks[3].BulletedListItem.RichText))
+}
@@ -0,0 +1,275 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/config"
+	"meeting-mm/models"
+	"meeting-mm/services"
+	"meeting-mm/storage"
+)
+
+// newFlakyNotion 模拟前failures次创建页面失败的Notion服务
+func newFlakyNotion(t *testing.T, failures int32) *httptest.Server {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Path, "/v1/databases/") {
+			w.Write([]byte(`{"object":"database","id":"test_db","properties":{"Name":{"name":"Name","type":"title"}}}`))
+			return
+		}
+		if r.URL.Path != "/v1/pages" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"object":"error","status":404,"code":"object_not_found","message":"not found"}`))
+			return
+		}
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`{"object":"error","status":502,"code":"bad_gateway","message":"upstream"}`))
+			return
+		}
+		w.Write([]byte(`{"object":"page","id":"page-ok"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestOutbox(t *testing.T, serverURL string, maxAttempts int) (*services.NotionOutbox, *storage.Store) {
+	cfg := &config.Config{
+		NotionAPIKey:          "test_key",
+		NotionDatabaseID:      "test_db",
+		NotionBaseURL:         serverURL + "/v1",
+		NotionSyncMaxAttempts: maxAttempts,
+		NotionSyncRetryDelay:  10 * time.Millisecond,
+	}
+	store, err := storage.Open(t.TempDir())
+	assert.NoError(t, err)
+	box, err := storage.NewSecretBox("test")
+	assert.NoError(t, err)
+	return services.NewNotionOutbox(cfg, store, services.NewWorkspaceServices(cfg, store, box)), store
+}
+
+// waitForMeetingStatus 等待会议同步状态变为期望值
+func waitForMeetingStatus(t *testing.T, store *storage.Store, id, status string) *models.Meeting {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		meeting, err := store.Meetings.Get(id)
+		if err == nil && meeting.SyncStatus == status {
+			return meeting
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("会议%s未在规定时间内进入%s状态", id, status)
+	return nil
+}
+
+// 测试发件箱在失败后重试直到成功
+func TestNotionOutboxRetriesUntilSynced(t *testing.T) {
+	outbox, store := newTestOutbox(t, newFlakyNotion(t, 2).URL, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go outbox.Run(ctx)
+
+	job, err := outbox.Enqueue(context.Background(), &models.Meeting{ID: "m1", Title: "周会", Date: time.Now()})
+	assert.NoError(t, err)
+
+	meeting := waitForMeetingStatus(t, store, "m1", models.SyncStatusSynced)
+	assert.Equal(t, "page-ok", meeting.NotionPageID)
+	assert.Empty(t, meeting.SyncError)
+
+	saved, err := store.NotionSyncs.Get(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, saved.Attempts)
+}
+
+// 测试超过最大重试次数后进入失败状态，并可手动重试或取消
+func TestNotionOutboxDeadLetter(t *testing.T) {
+	outbox, store := newTestOutbox(t, newFlakyNotion(t, 3).URL, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go outbox.Run(ctx)
+
+	job, err := outbox.Enqueue(context.Background(), &models.Meeting{ID: "m2", Title: "周会", Date: time.Now()})
+	assert.NoError(t, err)
+
+	meeting := waitForMeetingStatus(t, store, "m2", models.SyncStatusFailed)
+	assert.Contains(t, meeting.SyncError, "502")
+
+	failed, err := outbox.List("", models.SyncStatusFailed)
+	assert.NoError(t, err)
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, job.ID, failed[0].ID)
+	}
+
+	// 第三次调用仍失败，第四次成功
+	_, err = outbox.Retry("", job.ID)
+	assert.NoError(t, err)
+	waitForMeetingStatus(t, store, "m2", models.SyncStatusSynced)
+
+	_, err = outbox.Cancel("", job.ID)
+	assert.ErrorIs(t, err, services.ErrInvalidSyncState)
+}
+
+// 测试页面已创建但追加内容失败时，重试重写同一个页面而不是重新创建
+func TestNotionOutboxRetryReusesPage(t *testing.T) {
+	var mu sync.Mutex
+	var creates, appends int
+	var deleted []string
+	notion := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/databases/"):
+			w.Write([]byte(`{"object":"database","id":"test_db","properties":{"Name":{"name":"Name","type":"title"}}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/pages":
+			creates++
+			w.Write([]byte(`{"object":"page","id":"page-1"}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/v1/pages/page-1":
+			w.Write([]byte(`{"object":"page","id":"page-1"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/blocks/page-1/children":
+			w.Write([]byte(`{"object":"list","results":[{"object":"block","id":"old-1","type":"paragraph"}],"has_more":false}`))
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/blocks/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/v1/blocks/"))
+			w.Write([]byte(`{"object":"block"}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/v1/blocks/page-1/children":
+			appends++
+			if appends == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				w.Write([]byte(`{"object":"error","status":502,"code":"bad_gateway","message":"upstream"}`))
+				return
+			}
+			w.Write([]byte(`{"object":"list","results":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"object":"error","status":404,"code":"object_not_found","message":"not found"}`))
+		}
+	}))
+	t.Cleanup(notion.Close)
+	outbox, store := newTestOutbox(t, notion.URL, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go outbox.Run(ctx)
+
+	// 超过单次请求上限的内容在创建页面后追加
+	meeting := &models.Meeting{ID: "m3", Title: "周会", Date: time.Now()}
+	for i := 0; i < 150; i++ {
+		meeting.TodoItems = append(meeting.TodoItems, models.TodoItem{ID: fmt.Sprint(i), Description: fmt.Sprintf("待办%d", i)})
+	}
+	_, err := outbox.Enqueue(context.Background(), meeting)
+	assert.NoError(t, err)
+
+	synced := waitForMeetingStatus(t, store, "m3", models.SyncStatusSynced)
+	assert.Equal(t, "page-1", synced.NotionPageID)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, creates)
+	assert.Equal(t, []string{"old-1"}, deleted)
+}
+
+// 测试同步期间任务被取消时仍保存已创建的页面，之后重试时更新该页面而不是重新创建
+func TestNotionOutboxCancelDuringSync(t *testing.T) {
+	var mu sync.Mutex
+	var creates, rewrites int
+	created := make(chan struct{})
+	release := make(chan struct{})
+	notion := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/databases/"):
+			w.Write([]byte(`{"object":"database","id":"test_db","properties":{"Name":{"name":"Name","type":"title"}}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/pages":
+			mu.Lock()
+			creates++
+			mu.Unlock()
+			// 页面创建后等任务被取消再返回
+			close(created)
+			<-release
+			w.Write([]byte(`{"object":"page","id":"page-1"}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/v1/pages/page-1":
+			mu.Lock()
+			rewrites++
+			mu.Unlock()
+			w.Write([]byte(`{"object":"page","id":"page-1"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/blocks/page-1/children":
+			w.Write([]byte(`{"object":"list","results":[],"has_more":false}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/v1/blocks/page-1/children":
+			w.Write([]byte(`{"object":"list","results":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"object":"error","status":404,"code":"object_not_found","message":"not found"}`))
+		}
+	}))
+	t.Cleanup(notion.Close)
+	outbox, store := newTestOutbox(t, notion.URL, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go outbox.Run(ctx)
+
+	job, err := outbox.Enqueue(context.Background(), &models.Meeting{ID: "m4", Title: "周会", Date: time.Now()})
+	assert.NoError(t, err)
+	<-created
+	_, err = outbox.Cancel("", job.ID)
+	assert.NoError(t, err)
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if meeting, err := store.Meetings.Get("m4"); err == nil && meeting.NotionPageID != "" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	meeting, err := store.Meetings.Get("m4")
+	assert.NoError(t, err)
+	assert.Equal(t, "page-1", meeting.NotionPageID)
+	assert.Equal(t, models.SyncStatusFailed, meeting.SyncStatus)
+	saved, err := store.NotionSyncs.Get(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.SyncStatusCancelled, saved.Status)
+	assert.Equal(t, "page-1", saved.NotionPageID)
+
+	_, err = outbox.Retry("", job.ID)
+	assert.NoError(t, err)
+	waitForMeetingStatus(t, store, "m4", models.SyncStatusSynced)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, creates)
+	assert.Equal(t, 1, rewrites)
+}
+
+// 测试会议已有待同步的任务时再次加入发件箱沿用该任务
+func TestNotionOutboxEnqueueReusesPendingJob(t *testing.T) {
+	outbox, store := newTestOutbox(t, newFlakyNotion(t, 0).URL, 5)
+
+	meeting := &models.Meeting{ID: "m5", Title: "周会", Date: time.Now()}
+	first, err := outbox.Enqueue(context.Background(), meeting)
+	assert.NoError(t, err)
+	meeting.Summary = "更新后的摘要"
+	second, err := outbox.Enqueue(context.Background(), meeting)
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+
+	pending, err := outbox.List("", models.SyncStatusPending)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	saved, err := store.Meetings.Get("m5")
+	assert.NoError(t, err)
+	assert.Equal(t, "更新后的摘要", saved.Summary)
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/config"
+	"meeting-mm/models"
+	"meeting-mm/notion"
+	"meeting-mm/services"
+)
+
+var testNotionUsers = []notion.User{
+	{ID: "u-zhang", Type: "person", Name: "张三", Person: &notion.Person{Email: "zhangsan@example.com"}},
+	{ID: "u-tom", Type: "person", Name: "Tom Li", Person: &notion.Person{Email: "tom.li@example.com"}},
+	{ID: "u-jerry", Type: "person", Name: "Jerry Li"},
+	{ID: "u-jerry2", Type: "person", Name: "Jerry Wu"},
+}
+
+// 测试人名匹配的各种规则
+func TestMatchNotionUser(t *testing.T) {
+	// 别名的键为规范化后的人名（小写、无空白）
+	aliases := map[string]string{"老张": "zhangsan@example.com", "tommy": "Tom Li"}
+
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"张三", "u-zhang"},
+		{"老张", "u-zhang"},      // 别名指向邮箱
+		{"Tommy", "u-tom"},     // 别名指向用户名
+		{"tom li", "u-tom"},    // 大小写和空格不敏感
+		{"TomLi", "u-tom"},     // 去掉空格后完全一致
+		{"tom.li", "u-tom"},    // 邮箱前缀
+		{"Tom", "u-tom"},       // 用户名中的一个词
+		{"Jery Li", "u-jerry"}, // 拼写错误的模糊匹配
+		{"Jerry", ""},          // 两个用户同等匹配，视为无法解析
+		{"李四", ""},             // 不存在的用户
+		{"三", ""},              // 单字不做部分匹配
+	}
+	for _, c := range cases {
+		id, ok := services.MatchNotionUser(c.name, testNotionUsers, aliases)
+		assert.Equal(t, c.want, id, c.name)
+		assert.Equal(t, c.want != "", ok, c.name)
+	}
+}
+
+// 测试同步时负责人被转换为真正的Notion提及，未解析的人名被记录
+func TestNotionSyncMentions(t *testing.T) {
+	mock, server := newMockNotion(t)
+
+	notionService := services.NewNotionService(&config.Config{
+		NotionAPIKey:     "test_key",
+		NotionDatabaseID: "test_db",
+		NotionBaseURL:    server.URL + "/v1",
+	})
+
+	meeting := &models.Meeting{
+		Title:        "周会",
+		Participants: []string{"王五", "赵六"},
+		TodoItems: []models.TodoItem{
+			{Description: "完成测试", Assignee: "wangwu", Status: "pending"},
+			{Description: "整理文档", Assignee: "赵六", Status: "pending"},
+		},
+	}
+	assert.NoError(t, notionService.SyncMeeting(context.Background(), meeting))
+	assert.Equal(t, []string{"王五", "赵六"}, meeting.UnresolvedPeople)
+
+	page := mock.first("POST /v1/pages")
+	children := page["children"].([]interface{})
+
+	var mentions, texts []string
+	for _, child := range children {
+		block := child.(map[string]interface{})
+		if block["type"] != "to_do" {
+			continue
+		}
+		for _, rt := range block["to_do"].(map[string]interface{})["rich_text"].([]interface{}) {
+			item := rt.(map[string]interface{})
+			if item["type"] == "mention" {
+				user := item["mention"].(map[string]interface{})["user"].(map[string]interface{})
+				mentions = append(mentions, user["id"].(string))
+			} else {
+				texts = append(texts, item["text"].(map[string]interface{})["content"].(string))
+			}
+		}
+	}
+	assert.Equal(t, []string{"u-wang"}, mentions)
+	assert.Contains(t, texts, "@赵六")
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/tracing"
+)
+
+// 测试 /metrics 导出请求耗时和流水线阶段指标，配置令牌后需要认证
+func TestMetricsEndpoint(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.MetricsToken = "scrape-secret"
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]string{"title": "周会", "transcript": "内容"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err := srv.App().Test(httptest.NewRequest("GET", "/metrics", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scrape-secret")
+	resp, err = srv.App().Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/plain; version=0.0.4")
+	data, _ := io.ReadAll(resp.Body)
+	body := string(data)
+
+	assert.Contains(t, body, `# TYPE mm_http_request_duration_seconds histogram`)
+	assert.Contains(t, body, `mm_http_request_duration_seconds_count{method="POST",route="/api/meetings/analyze",status="200"}`)
+	assert.Contains(t, body, `mm_pipeline_stage_duration_seconds_bucket{stage="analyze",outcome="ok",le="+Inf"}`)
+	assert.Contains(t, body, `mm_pipeline_stage_duration_seconds_count{stage="report",outcome="ok"}`)
+	assert.Contains(t, body, `mm_llm_tokens_total{model="deepseek-chat",kind="prompt"}`)
+}
+
+// 测试一次请求的span按父子关系导出到OTLP端点，并延续上游的traceparent
+func TestTracingExport(t *testing.T) {
+	var mu sync.Mutex
+	spans := map[string]map[string]interface{}{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		var payload struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []map[string]interface{} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rs := range payload.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					spans[span["name"].(string)] = span
+				}
+			}
+		}
+	}))
+	defer collector.Close()
+
+	srv, token := setupTestEnv(t)
+	exporter := tracing.NewExporter(collector.URL, "meeting-mm-test")
+	tracing.SetExporter(exporter)
+	defer tracing.SetExporter(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go exporter.Run(ctx)
+
+	const upstreamTrace = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const upstreamSpan = "00f067aa0ba902b7"
+	req := httptest.NewRequest("POST", "/api/meetings/analyze", strings.NewReader(`{"title":"周会","transcript":"内容","polishReport":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("traceparent", "00-"+upstreamTrace+"-"+upstreamSpan+"-01")
+	resp, err := srv.App().Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	flushCtx, flushCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer flushCancel()
+	exporter.Flush(flushCtx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	server := spans["POST /api/meetings/analyze"]
+	analyze := spans["DeepSeekService.AnalyzeTranscript"]
+	chat := spans["DeepSeek chat/completions"]
+	report := spans["DeepSeekService.PolishReport"]
+	if !assert.NotNil(t, server) || !assert.NotNil(t, analyze) || !assert.NotNil(t, chat) || !assert.NotNil(t, report) {
+		return
+	}
+	for _, span := range []map[string]interface{}{server, analyze, chat, report} {
+		assert.Equal(t, upstreamTrace, span["traceId"])
+	}
+	assert.Equal(t, upstreamSpan, server["parentSpanId"])
+	assert.Equal(t, server["spanId"], analyze["parentSpanId"])
+	assert.Equal(t, server["spanId"], report["parentSpanId"])
+	assert.Equal(t, float64(tracing.KindServer), server["kind"])
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/api"
+	"meeting-mm/client"
+	"meeting-mm/openapi"
+)
+
+// spec 返回OpenAPI文档，生成失败时终止测试
+func spec(t *testing.T) *openapi.Document {
+	doc, err := api.OpenAPI()
+	if err != nil {
+		t.Fatalf("生成OpenAPI文档失败: %v", err)
+	}
+	return doc
+}
+
+// checkRequest 校验测试发送的JSON请求体符合OpenAPI文档
+func checkRequest(t *testing.T, method, path string, body []byte) {
+	doc := spec(t)
+	op, ok := doc.FindOperation(method, stripQuery(path))
+	if !ok {
+		return
+	}
+	if err := doc.ValidateRequest(op, "application/json", body); err != nil {
+		t.Errorf("请求%s %s与OpenAPI文档不一致: %v", method, path, err)
+	}
+}
+
+// checkResponse 校验响应的状态码和响应体符合OpenAPI文档，校验后恢复响应体供测试继续读取
+func checkResponse(t *testing.T, method, path string, resp *http.Response) {
+	doc := spec(t)
+	op, ok := doc.FindOperation(method, stripQuery(path))
+	if !ok {
+		// 未注册的路径由Fiber直接返回404
+		return
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	if err := doc.ValidateResponse(op, resp.StatusCode, resp.Header.Get("Content-Type"), data); err != nil {
+		t.Errorf("响应%s %s与OpenAPI文档不一致: %v\n%s", method, path, err, data)
+	}
+}
+
+func stripQuery(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	return path
+}
+
+// 测试OpenAPI文档可以访问，覆盖全部路由并声明认证方式
+func TestOpenAPISpec(t *testing.T) {
+	srv, _ := setupTestEnv(t)
+
+	resp := doJSON(t, srv, "GET", "/api/openapi.json", "", nil)
+	if !assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		return
+	}
+	body := decodeJSON(t, resp)
+	assert.Equal(t, openapi.Version, body["openapi"])
+
+	doc := spec(t)
+	for _, route := range api.Routes(&api.Handler{}) {
+		op, ok := doc.FindOperation(route.Method, route.Path)
+		if assert.True(t, ok, "%s %s 不在OpenAPI文档中", route.Method, route.Path) {
+			assert.Equal(t, route.Public, len(op.Security) == 0, "%s %s 的认证声明不正确", route.Method, route.Path)
+		}
+	}
+
+	// 旧版同步请求使用的todo_items不在文档中，会被校验拒绝
+	op, _ := doc.FindOperation("POST", "/meetings/sync-notion")
+	assert.Error(t, doc.ValidateRequest(op, "application/json", []byte(`{"meeting":{"todo_items":[]}}`)))
+}
+
+// 测试生成的Go客户端与当前的OpenAPI文档一致
+func TestOpenAPIClientUpToDate(t *testing.T) {
+	generated, err := openapi.GenerateClient(spec(t), "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+	current, err := os.ReadFile("../client/client_gen.go")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, bytes.Equal(generated, current), "client/client_gen.go 已过期，请运行 go generate ./client")
+}
+
+// 测试生成的Go客户端可以调用服务器
+func TestGeneratedClient(t *testing.T) {
+	srv, token := setupTestEnv(t)
+	httpServer := httptest.NewServer(srv.Handler())
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	c := client.New(httpServer.URL, client.WithToken(token))
+
+	result, err := c.AnalyzeTranscript(ctx, &client.AnalyzeRequest{Title: "客户端会议", Transcript: "内容"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "客户端会议", result.Meeting.Title)
+	assert.Len(t, result.Meeting.TodoItems, 1)
+
+	meeting, err := c.GetMeeting(ctx, result.Meeting.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Meeting.ID, meeting.ID)
+
+	_, err = c.GetMeeting(ctx, "missing")
+	if apiErr, ok := err.(*client.APIError); assert.True(t, ok) {
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "NOT_FOUND", apiErr.Code)
+		assert.NotEmpty(t, apiErr.RequestID)
+	}
+
+	_, err = client.New(httpServer.URL).ListMeetings(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standupProfile 站会的会议类型
+var standupProfile = map[string]interface{}{
+	"name":           "standup",
+	"description":    "每日站会",
+	"systemPrompt":   "You summarize daily standups.",
+	"instructions":   "每人的进展单独列出。",
+	"language":       "English",
+	"reportTemplate": "brief",
+	"fields": []map[string]string{
+		{"key": "blockers", "label": "阻塞项", "description": "影响进度、需要他人协助的问题"},
+		{"key": "followUps", "label": "跟进事项"},
+	},
+}
+
+// 测试会议类型的创建、修改、删除和校验，只有所有者可以修改
+func TestProfiles(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.AuthAllowSignup = true
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/profiles", token, standupProfile)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	profile := decodeJSON(t, resp)
+	assert.Equal(t, "standup", profile["name"])
+	assert.Len(t, profile["fields"], 2)
+
+	resp = doJSON(t, srv, "POST", "/api/profiles", token, standupProfile)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	for _, invalid := range []map[string]interface{}{
+		{"name": "Sales Call"},
+		{"name": "sales", "fields": []map[string]string{{"key": "summary", "label": "摘要"}}},
+		{"name": "sales", "fields": []map[string]string{{"key": "risks", "label": "风险"}}},
+		{"name": "sales", "fields": []map[string]string{{"key": "previousItems", "label": "之前的待办"}}},
+		{"name": "sales", "fields": []map[string]string{{"key": "asks", "label": ""}}},
+		{"name": "sales", "reportTemplate": "missing"},
+	} {
+		resp = doJSON(t, srv, "POST", "/api/profiles", token, invalid)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, invalid)
+	}
+
+	// 修改时整体替换，可以重命名
+	resp = doJSON(t, srv, "PUT", "/api/profiles/standup", token, map[string]interface{}{"name": "daily", "language": "日语"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	profile = decodeJSON(t, resp)
+	assert.Equal(t, "daily", profile["name"])
+	assert.Nil(t, profile["fields"])
+
+	resp = doJSON(t, srv, "GET", "/api/profiles/standup", token, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = doJSON(t, srv, "GET", "/api/profiles", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, decodeJSON(t, resp)["profiles"], 1)
+
+	// 成员可以查看，不能修改
+	resp = doJSON(t, srv, "POST", "/api/workspace/members", token, map[string]string{"email": "member@example.com", "password": "password123"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp = doJSON(t, srv, "POST", "/api/auth/login", "", map[string]string{"email": "member@example.com", "password": "password123"})
+	memberToken := decodeJSON(t, resp)["token"].(string)
+	resp = doJSON(t, srv, "GET", "/api/profiles/daily", memberToken, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doJSON(t, srv, "DELETE", "/api/profiles/daily", memberToken, nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// 其他工作区看不到
+	otherToken := registerAndLogin(t, srv, "other@example.com")
+	resp = doJSON(t, srv, "GET", "/api/profiles/daily", otherToken, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = doJSON(t, srv, "DELETE", "/api/profiles/daily", token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doJSON(t, srv, "GET", "/api/profiles", token, nil)
+	assert.Empty(t, decodeJSON(t, resp)["profiles"])
+}
+
+// 测试按会议类型分析：使用其系统提示词、额外要求、输出语言和报告模板，并提取额外内容
+func TestAnalyzeWithProfile(t *testing.T) {
+	analysis := `{"summary":"Progress reviewed",
+		"todoItems":[],"decisions":[],
+		"blockers":["Waiting for API keys"],"followUps":"Sync with design"}`
+	deepseek := newCapturingDeepSeek(t, func(string) string { return analysis })
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = deepseek.URL
+	cfg.AnalysisInstructions = "使用简洁的语言。"
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+	resp := doJSON(t, srv, "POST", "/api/profiles", token, standupProfile)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "站会", "transcript": "内容", "profile": "standup",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body := decodeJSON(t, resp)
+	meeting := body["meeting"].(map[string]interface{})
+	assert.Equal(t, "standup", meeting["profile"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"key": "blockers", "label": "阻塞项", "items": []interface{}{"Waiting for API keys"}},
+		map[string]interface{}{"key": "followUps", "label": "跟进事项", "items": []interface{}{"Sync with design"}},
+	}, meeting["extraFields"])
+
+	// 会议类型的报告模板是brief
+	assert.Equal(t, "# 站会（"+meeting["date"].(string)[:10]+"）\n\nProgress reviewed\n\n**阻塞项**\n\n- Waiting for API keys\n\n**跟进事项**\n\n- Sync with design\n", body["markdownReport"])
+
+	prompt := deepseek.Prompt()
+	assert.Equal(t, "You summarize daily standups.", deepseek.SystemPrompt())
+	assert.Contains(t, prompt, "- \"blockers\"：阻塞项（影响进度、需要他人协助的问题）\n- \"followUps\"：跟进事项")
+	assert.Contains(t, prompt, "请使用English撰写")
+	assert.Contains(t, prompt, "额外要求：\n使用简洁的语言。\n每人的进展单独列出。")
+
+	// 完整报告中每项额外内容一节
+	resp = doJSON(t, srv, "GET", "/api/meetings/"+meeting["id"].(string)+"/report?template=default", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, decodeJSON(t, resp)["markdown"], "## 阻塞项\n\n- Waiting for API keys\n\n## 跟进事项\n\n- Sync with design\n\n## 会议记录")
+
+	// 不存在的会议类型在调用模型之前拒绝
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "站会", "transcript": "内容", "profile": "sales",
+	})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Len(t, deepseek.Prompts(), 1)
+}
@@ -0,0 +1,220 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/config"
+	"meeting-mm/models"
+	"meeting-mm/services"
+)
+
+// reportMeeting 渲染报告用的会议
+func reportMeeting() *models.Meeting {
+	return &models.Meeting{
+		Title:        "周会",
+		Date:         time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC),
+		Participants: []string{"张三", "李四"},
+		Summary:      "讨论项目进度",
+		TodoItems: []models.TodoItem{
+			{Description: "测试前端", Assignee: "王五", DueDate: time.Date(2025, 3, 21, 0, 0, 0, 0, time.UTC), Status: "pending"},
+			{Description: "更新文档", Status: "completed"},
+		},
+		Decisions: []models.Decision{{Description: "下周一发布", MadeBy: "张三"}},
+		Segments: []models.TranscriptSegment{
+			{StartTime: 0, Speaker: "张三", Text: " 大家好。"},
+			{StartTime: 65, Text: "开始讨论。"},
+		},
+	}
+}
+
+// 测试内置模板的渲染结果固定
+func TestReportRendererBuiltin(t *testing.T) {
+	renderer, err := services.NewReportRenderer(&config.Config{})
+	require.NoError(t, err)
+
+	markdown, err := renderer.Render("", reportMeeting())
+	require.NoError(t, err)
+	assert.Equal(t, `# 周会
+
+- **会议日期**：2025-03-14
+- **参与人员**：张三、李四
+
+## 摘要
+
+讨论项目进度
+
+## 待办事项
+
+- [ ] 测试前端（负责人：王五）（截止：2025-03-21）
+- [x] 更新文档
+
+## 决策事项
+
+1. 下周一发布（张三）
+
+## 会议记录
+
+**[00:00] 张三:** 大家好。
+
+**[01:05]** 开始讨论。
+`, markdown)
+
+	brief, err := renderer.Render("brief", &models.Meeting{Title: "空会议", Date: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	assert.Equal(t, "# 空会议（2025-03-14）\n\n（无摘要）\n", brief)
+
+	_, err = renderer.Render("missing", reportMeeting())
+	assert.ErrorIs(t, err, services.ErrUnknownReportTemplate)
+}
+
+// 测试有议程时内置模板按议程项整理待办和决策，不属于任何议程项的放在最后
+func TestReportRendererAgenda(t *testing.T) {
+	renderer, err := services.NewReportRenderer(&config.Config{})
+	require.NoError(t, err)
+
+	meeting := reportMeeting()
+	meeting.Segments = nil
+	meeting.Transcript = "内容"
+	meeting.AgendaItems = []models.AgendaItem{
+		{ID: "a1", Title: "项目进度", Status: models.AgendaCovered, Summary: "前端完成八成"},
+		{ID: "a2", Title: "预算", Status: models.AgendaDeferred, Summary: "财务未到场"},
+	}
+	meeting.TodoItems[0].AgendaID = "a1"
+	meeting.Decisions[0].AgendaID = "a1"
+
+	markdown, err := renderer.Render("", meeting)
+	require.NoError(t, err)
+	assert.Equal(t, `# 周会
+
+- **会议日期**：2025-03-14
+- **参与人员**：张三、李四
+
+## 摘要
+
+讨论项目进度
+
+## 议程
+
+### 1. 项目进度（已讨论）
+
+前端完成八成
+
+**决策**
+
+- 下周一发布（张三）
+
+**待办**
+
+- [ ] 测试前端（负责人：王五）（截止：2025-03-21）
+
+### 2. 预算（已推迟）
+
+财务未到场
+
+## 其他待办事项
+
+- [x] 更新文档
+
+## 会议记录
+
+内容
+`, markdown)
+}
+
+// 测试模板目录中的模板覆盖内置模板，无效的模板在加载时报错
+func TestReportRendererCustomTemplates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default.md.tmpl"), []byte("# {{.Title}}\n{{range .TodoItems}}- {{.Description}}\n{{end}}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md.tmpl"), []byte("{{.Summary}}"), 0644))
+	cfg := &config.Config{ReportTemplateDir: dir, ReportTemplate: "notes"}
+
+	renderer, err := services.NewReportRenderer(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "notes", renderer.DefaultTemplate())
+	assert.Equal(t, []services.ReportTemplateInfo{
+		{Name: "brief", Builtin: true},
+		{Name: "default", Builtin: false},
+		{Name: "notes", Builtin: false},
+	}, renderer.Templates())
+
+	markdown, err := renderer.Render("default", reportMeeting())
+	require.NoError(t, err)
+	assert.Equal(t, "# 周会\n- 测试前端\n- 更新文档\n", markdown)
+	markdown, err = renderer.Render("", reportMeeting())
+	require.NoError(t, err)
+	assert.Equal(t, "讨论项目进度\n", markdown)
+
+	// 字段名拼写错误在加载时发现，重新加载失败时保留原来的模板
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md.tmpl"), []byte("{{.Sumary}}"), 0644))
+	assert.ErrorContains(t, renderer.Reload(cfg), "notes.md.tmpl")
+	markdown, err = renderer.Render("", reportMeeting())
+	require.NoError(t, err)
+	assert.Equal(t, "讨论项目进度\n", markdown)
+}
+
+// 测试分析会议时按模板生成报告，只有要求润色时才再次调用模型
+func TestAnalyzeReportTemplates(t *testing.T) {
+	target, _ := url.Parse(newMockDeepSeek(t).URL)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	var calls atomic.Int32
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(counting.Close)
+
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = counting.URL
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "周会", "transcript": "内容", "reportTemplate": "brief",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body := decodeJSON(t, resp)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Contains(t, body["markdownReport"], "# 周会（")
+	assert.Contains(t, body["markdownReport"], "- [ ] 测试前端 @王五 2025-03-21")
+	meetingID := body["meeting"].(map[string]interface{})["id"].(string)
+
+	// 润色时将渲染结果交给模型
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "周会", "transcript": "内容", "polishReport": true,
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// 未知的模板在分析之前拒绝
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "周会", "transcript": "内容", "reportTemplate": "missing",
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "BAD_REQUEST", decodeJSON(t, resp)["code"])
+	assert.Equal(t, int32(3), calls.Load())
+
+	// 已保存的会议可以按其他模板重新渲染，不调用模型
+	resp = doJSON(t, srv, "GET", "/api/meetings/"+meetingID+"/report", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body = decodeJSON(t, resp)
+	assert.Equal(t, "default", body["template"])
+	assert.Contains(t, body["markdown"], "## 会议记录\n\n内容")
+	assert.Equal(t, int32(3), calls.Load())
+
+	resp = doJSON(t, srv, "GET", "/api/reports/templates", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body = decodeJSON(t, resp)
+	assert.Equal(t, "default", body["default"])
+	assert.Len(t, body["templates"], 2)
+}
@@ -0,0 +1,193 @@
+package test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"meeting-mm/server"
+	"meeting-mm/services"
+)
+
+// newSlowDeepSeek 模拟在release关闭前不返回的DeepSeek，收到请求时向entered发送信号
+func newSlowDeepSeek(t *testing.T, release <-chan struct{}) (*httptest.Server, <-chan struct{}) {
+	target, _ := url.Parse(newMockDeepSeek(t).URL)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	entered := make(chan struct{}, 10)
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		select {
+		case <-release:
+			proxy.ServeHTTP(w, r)
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(slow.Close)
+	return slow, entered
+}
+
+// startServer 在空闲端口上运行服务器，返回地址和ListenAndServe的结果通道
+func startServer(t *testing.T, ctx context.Context, srv *server.Server) (string, <-chan error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("获取空闲端口失败: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- srv.ListenAndServe(ctx, server.TransportFiber, ":"+strconv.Itoa(port)) }()
+
+	base := "http://127.0.0.1:" + strconv.Itoa(port)
+	for i := 0; i < 100; i++ {
+		if resp, err := http.Get(base + "/metrics"); err == nil {
+			resp.Body.Close()
+			return base, done
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("服务器未在端口%d上启动", port)
+	return "", nil
+}
+
+// postAnalyze 通过网络发送分析请求
+func postAnalyze(base, token string) (*http.Response, error) {
+	req, _ := http.NewRequest("POST", base+"/api/meetings/analyze", strings.NewReader(`{"title":"周会","transcript":"内容"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return http.DefaultClient.Do(req)
+}
+
+// 测试收到退出信号后等待进行中的请求完成再退出
+func TestGracefulShutdownDrainsRequests(t *testing.T) {
+	release := make(chan struct{})
+	deepseek, entered := newSlowDeepSeek(t, release)
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = deepseek.URL
+	cfg.ShutdownTimeout = 10 * time.Second
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	base, done := startServer(t, ctx, srv)
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := postAnalyze(base, token)
+		assert.NoError(t, err)
+		responses <- resp
+	}()
+	<-entered
+
+	cancel()
+	select {
+	case err := <-done:
+		t.Fatalf("进行中的请求未完成时服务器已退出: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	resp := <-responses
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+	}
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("服务器未在请求完成后退出")
+	}
+}
+
+// 测试关闭超时后取消进行中的请求，并返回可重试的503
+func TestShutdownTimeoutCancelsRequests(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	deepseek, entered := newSlowDeepSeek(t, release)
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = deepseek.URL
+	cfg.ShutdownTimeout = 200 * time.Millisecond
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	base, done := startServer(t, ctx, srv)
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := postAnalyze(base, token)
+		assert.NoError(t, err)
+		responses <- resp
+	}()
+	<-entered
+	cancel()
+
+	resp := <-responses
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		body := decodeJSON(t, resp)
+		assert.Equal(t, "SERVICE_UNAVAILABLE", body["code"])
+		assert.Equal(t, true, body["retryable"])
+	}
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("服务器未在关闭期限后退出")
+	}
+}
+
+// 测试开始关闭后不再接受新任务，超时后取消进行中的任务
+func TestJobsDrain(t *testing.T) {
+	jobs := services.NewJobs()
+	jobCtx, done, err := jobs.Start(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, jobs.Running())
+
+	go func() {
+		<-jobCtx.Done()
+		done()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, jobs.Drain(ctx, time.Second), context.DeadlineExceeded)
+	assert.ErrorIs(t, context.Cause(jobCtx), services.ErrShuttingDown)
+	assert.Equal(t, 0, jobs.Running())
+
+	_, _, err = jobs.Start(context.Background())
+	assert.ErrorIs(t, err, services.ErrShuttingDown)
+}
+
+// 测试启动时清理上次运行遗留的转录临时文件
+func TestSweepWhisperTemp(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	dir := services.WhisperTempDir()
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+
+	stale := filepath.Join(dir, "stale.mp3")
+	fresh := filepath.Join(dir, "fresh.mp3")
+	assert.NoError(t, os.WriteFile(stale, []byte("audio"), 0644))
+	assert.NoError(t, os.WriteFile(fresh, []byte("audio"), 0644))
+	old := time.Now().Add(-2 * services.StaleTempAge)
+	assert.NoError(t, os.Chtimes(stale, old, old))
+
+	removed, err := services.SweepWhisperTemp(services.StaleTempAge)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.NoFileExists(t, stale)
+	assert.FileExists(t, fresh)
+}
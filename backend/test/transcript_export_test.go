@@ -0,0 +1,186 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/export"
+	"meeting-mm/models"
+	"meeting-mm/storage"
+)
+
+// subtitleMeeting 带时间戳片段的会议
+func subtitleMeeting() *models.Meeting {
+	return &models.Meeting{
+		Title: "周会",
+		Date:  time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC),
+		Segments: []models.TranscriptSegment{
+			{StartTime: 0.5, EndTime: 2.25, Speaker: "张三", Text: "大家好，<开始>开会。"},
+			{StartTime: 3, EndTime: 9, Text: "我们来讨论一下本周的项目进度以及下周的发布计划，请各位依次汇报各自负责的模块。"},
+			{StartTime: 3661, EndTime: 3662.5, Speaker: "李四", Text: "OK"},
+		},
+	}
+}
+
+// 测试SRT和WebVTT字幕的格式、折行和说话人
+func TestRenderSubtitles(t *testing.T) {
+	file, err := export.RenderTranscript(export.TranscriptVTT, subtitleMeeting(), export.TranscriptOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "周会-2025-03-14.vtt", file.Name)
+	assert.Equal(t, `WEBVTT
+
+1
+00:00:00.500 --> 00:00:02.250
+<v 张三>大家好，&lt;开始&gt;开会。</v>
+
+2
+00:00:03.000 --> 00:00:09.000
+我们来讨论一下本周的项目进度以及下周的发布
+计划，请各位依次汇报各自负责的模块。
+
+3
+01:01:01.000 --> 01:01:02.500
+<v 李四>OK</v>
+
+`, string(file.Data))
+
+	// 超过两行的片段拆成多条字幕，时间按文字长度分配；SRT在文字前加说话人
+	file, err = export.RenderTranscript(export.TranscriptSRT, subtitleMeeting(), export.TranscriptOptions{LineLength: 20})
+	require.NoError(t, err)
+	assert.Equal(t, `1
+00:00:00,500 --> 00:00:02,250
+张三: 大家好，<开始>
+开会。
+
+2
+00:00:03,000 --> 00:00:06,077
+我们来讨论一下本周的
+项目进度以及下周的发
+
+3
+00:00:06,077 --> 00:00:09,000
+布计划，请各位依次汇
+报各自负责的模块。
+
+4
+01:01:01,000 --> 01:01:02,500
+李四: OK
+
+`, string(file.Data))
+
+	// 偏移为负时丢弃结束时间不大于0的片段，开始时间不早于0
+	file, err = export.RenderTranscript(export.TranscriptJSON, subtitleMeeting(), export.TranscriptOptions{Offset: -4 * time.Second})
+	require.NoError(t, err)
+	var doc export.TranscriptDocument
+	require.NoError(t, json.Unmarshal(file.Data, &doc))
+	require.Len(t, doc.Segments, 2)
+	assert.Equal(t, export.Cue{Start: 0, End: 5, Text: subtitleMeeting().Segments[1].Text}, doc.Segments[0])
+	assert.Equal(t, 3657.0, doc.Segments[1].Start)
+
+	file, err = export.RenderTranscript(export.TranscriptText, subtitleMeeting(), export.TranscriptOptions{})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(file.Data), "[00:00:00] 张三: 大家好，<开始>开会。\n[00:00:03] 我们"))
+
+	_, err = export.RenderTranscript(export.TranscriptSRT, &models.Meeting{Transcript: "内容"}, export.TranscriptOptions{})
+	assert.ErrorIs(t, err, export.ErrNoSegments)
+}
+
+// 测试导出会议记录的接口
+func TestExportTranscriptAPI(t *testing.T) {
+	cfg := testConfig(t)
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	resp := doJSON(t, srv, "POST", "/api/meetings/analyze", token, map[string]interface{}{
+		"title": "周会", "transcript": "会议内容",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	meetingID := decodeJSON(t, resp)["meeting"].(map[string]interface{})["id"].(string)
+	path := "/api/meetings/" + meetingID + "/transcript"
+
+	// 没有分段时只能导出纯文本和JSON
+	resp = doJSON(t, srv, "GET", path, token, nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp = doJSON(t, srv, "GET", path+"?format=txt", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "会议内容\n", string(data))
+
+	store, err := storage.Open(cfg.DataDir)
+	require.NoError(t, err)
+	_, err = store.Meetings.Update(meetingID, func(m *models.Meeting) error {
+		m.Segments = subtitleMeeting().Segments
+		return nil
+	})
+	require.NoError(t, err)
+
+	resp = doJSON(t, srv, "GET", path+"?format=vtt&offsetMs=1500", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/vtt; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), ".vtt")
+	data, _ = io.ReadAll(resp.Body)
+	assert.Contains(t, string(data), "00:00:02.000 --> 00:00:03.750\n<v 张三>")
+
+	resp = doJSON(t, srv, "GET", path+"?format=json", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, decodeJSON(t, resp)["segments"], 3)
+
+	resp = doJSON(t, srv, "GET", path+"?lineLength=5", token, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = doJSON(t, srv, "GET", path+"?format=ass", token, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// 测试上传录音时保存Whisper输出的带时间戳片段，分析时使用带时间的会议记录，可以导出字幕
+func TestUploadAudioSegments(t *testing.T) {
+	mock := newCapturingDeepSeek(t, func(string) string {
+		return `{"summary":"摘要","todoItems":[],"decisions":[]}`
+	})
+	cfg := testConfig(t)
+	cfg.DeepSeekBaseURL = mock.URL
+	python := filepath.Join(t.TempDir(), "python")
+	script := "#!/bin/sh\n" +
+		"echo 'MM_STATS {\"audioSeconds\": 65, \"normalizeSeconds\": 0.1}' >&2\n" +
+		"echo '{\"text\": \" 大家好 我们开始 \", \"segments\": [{\"start\": 0.5, \"end\": 2.25, \"text\": \" 大家好\"}, " +
+		"{\"start\": 2.25, \"end\": 2.5, \"text\": \" \"}, {\"start\": 61, \"end\": 65, \"text\": \" 我们开始\"}]}'\n"
+	require.NoError(t, os.WriteFile(python, []byte(script), 0755))
+	cfg.WhisperPython = python
+	srv := newTestServer(t, cfg)
+	token := registerAndLogin(t, srv, "owner@example.com")
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	require.NoError(t, w.WriteField("title", "周会"))
+	fw, err := w.CreateFormFile("audio", "weekly.mp3")
+	require.NoError(t, err)
+	fw.Write([]byte("audio"))
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest("POST", "/api/audio/upload", &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := srv.App().Test(req, -1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	meeting := decodeJSON(t, resp)["meeting"].(map[string]interface{})
+	assert.Equal(t, "大家好 我们开始", meeting["transcript"])
+	require.Len(t, meeting["segments"], 2)
+	assert.Contains(t, mock.Prompt(), "[00:00] 大家好\n[01:01] 我们开始\n")
+
+	resp = doJSON(t, srv, "GET", "/api/meetings/"+meeting["id"].(string)+"/transcript?format=srt", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "1\n00:00:00,500 --> 00:00:02,250\n大家好\n\n2\n00:01:01,000 --> 00:01:05,000\n我们开始\n\n", string(data))
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"meeting-mm/config"
+	"meeting-mm/models"
+	"meeting-mm/services"
+	"meeting-mm/storage"
+)
+
+// 测试工作区使用自己的DeepSeek凭据和提示词，密钥加密保存且不会返回给客户端
+func TestWorkspaceSettings(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.AuthAllowSignup = true
+	srv := newTestServer(t, cfg)
+	tokenA := registerAndLogin(t, srv, "a@example.com")
+	tokenB := registerAndLogin(t, srv, "b@example.com")
+
+	// 工作区B使用自己的DeepSeek服务，记录收到的密钥和提示词
+	var gotKey string
+	var gotSystem []string
+	teamDeepSeek := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		gotKey = r.Header.Get("Authorization")
+		gotSystem = append(gotSystem, request.Messages[0].Content)
+		assert.Equal(t, "team-model", request.Model)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"index": 0, "message": map[string]string{"role": "assistant", "content": `{"summary":"团队摘要","todoItems":[],"decisions":[]}`}},
+			},
+		})
+	}))
+	defer teamDeepSeek.Close()
+
+	resp := doJSON(t, srv, "PUT", "/api/workspace/settings", tokenB, map[string]string{
+		"deepseekApiKey":  "sk-team-secret",
+		"deepseekBaseUrl": teamDeepSeek.URL,
+		"deepseekModel":   "team-model",
+		"systemPrompt":    "你是团队助手",
+	})
+	if !assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		return
+	}
+	settings := decodeJSON(t, resp)
+	assert.Equal(t, true, settings["deepseekApiKeySet"])
+	assert.NotContains(t, settings, "deepseekApiKey")
+
+	// 只有Notion密钥没有数据库ID时拒绝
+	resp = doJSON(t, srv, "PUT", "/api/workspace/settings", tokenB, map[string]string{"notionApiKey": "secret"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// 只有DeepSeek地址没有密钥时拒绝，以免服务器的密钥被发送到该地址；Whisper模型只能是模型名称
+	resp = doJSON(t, srv, "PUT", "/api/workspace/settings", tokenA, map[string]string{"deepseekBaseUrl": teamDeepSeek.URL})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = doJSON(t, srv, "PUT", "/api/workspace/settings", tokenB, map[string]string{"whisperModel": "/tmp/evil.pt"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = doJSON(t, srv, "PUT", "/api/workspace/settings", tokenB, map[string]string{"whisperModel": "small"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", tokenB, map[string]string{"title": "B的会议", "transcript": "内容"})
+	if assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		meeting := decodeJSON(t, resp)["meeting"].(map[string]interface{})
+		assert.Equal(t, "团队摘要", meeting["summary"])
+	}
+	assert.Equal(t, "Bearer sk-team-secret", gotKey)
+	if assert.NotEmpty(t, gotSystem) {
+		assert.Equal(t, "你是团队助手", gotSystem[0])
+	}
+
+	// 工作区A仍然使用服务器配置
+	resp = doJSON(t, srv, "POST", "/api/meetings/analyze", tokenA, map[string]string{"title": "A的会议", "transcript": "内容"})
+	if assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		meeting := decodeJSON(t, resp)["meeting"].(map[string]interface{})
+		assert.Equal(t, "讨论项目进度，决定下周一发布", meeting["summary"])
+	}
+
+	// 落盘的工作区记录中不包含明文密钥
+	files, err := filepath.Glob(filepath.Join(cfg.DataDir, "workspaces", "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "sk-team-secret")
+	}
+}
+
+// 测试只有第一个工作区使用服务器配置的Notion和DeepSeek凭据，其他未设置凭据的工作区不会同步到服务器的Notion数据库
+func TestWorkspaceServerCredentials(t *testing.T) {
+	mock, notionServer := newMockNotion(t)
+	cfg := &config.Config{
+		NotionAPIKey:     "test_key",
+		NotionDatabaseID: "test_db",
+		NotionBaseURL:    notionServer.URL + "/v1",
+		DeepSeekAPIKey:   "sk-server",
+	}
+	store, err := storage.Open(t.TempDir())
+	require.NoError(t, err)
+	box, err := storage.NewSecretBox("test")
+	require.NoError(t, err)
+	for _, workspace := range []*models.Workspace{
+		{ID: "owner", ServerCredentials: true},
+		{ID: "team-a"},
+		{ID: "team-b"},
+	} {
+		require.NoError(t, store.Workspaces.Put(workspace.ID, workspace))
+	}
+	workspaces := services.NewWorkspaceServices(cfg, store, box)
+
+	set, err := workspaces.For("owner")
+	require.NoError(t, err)
+	require.NoError(t, set.Notion.SyncMeeting(context.Background(), &models.Meeting{ID: "m0", WorkspaceID: "owner", Title: "周会"}))
+	assert.Equal(t, 1, mock.count("POST /v1/pages"))
+
+	for _, id := range []string{"team-a", "team-b"} {
+		set, err := workspaces.For(id)
+		require.NoError(t, err)
+		assert.Error(t, set.Notion.Ping(context.Background()), id)
+		assert.Error(t, set.Notion.SyncMeeting(context.Background(), &models.Meeting{ID: "m-" + id, WorkspaceID: id, Title: "周会"}), id)
+		assert.Error(t, set.DeepSeek.Ping(context.Background()), id)
+	}
+	assert.Equal(t, 1, mock.count("POST /v1/pages"))
+}
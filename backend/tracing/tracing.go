@@ -0,0 +1,183 @@
+// Package tracing 提供最小化的分布式追踪：span通过context.Context传递父子关系，
+// 结束的span由Exporter按OTLP/HTTP（JSON编码）批量发送到OpenTelemetry Collector
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID 追踪ID
+type TraceID [16]byte
+
+// SpanID span ID
+type SpanID [8]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+func (id SpanID) String() string  { return hex.EncodeToString(id[:]) }
+
+// IsValid ID不全为零
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// IsValid ID不全为零
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// Span 的类型，取值与OTLP一致
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// Span 一次操作的追踪记录
+type Span struct {
+	exporter *Exporter
+
+	mu       sync.Mutex
+	name     string
+	kind     int
+	traceID  TraceID
+	spanID   SpanID
+	parentID SpanID
+	start    time.Time
+	end      time.Time
+	attrs    map[string]interface{}
+	errMsg   string
+	ended    bool
+}
+
+// TraceID 返回span所属的追踪ID
+func (s *Span) TraceID() TraceID { return s.traceID }
+
+// SpanID 返回span ID
+func (s *Span) SpanID() SpanID { return s.spanID }
+
+// SetName 修改span名称，例如路由匹配后使用路由模式命名
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttribute 设置属性，值可以是字符串、整数、浮点数或布尔值
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attrs == nil {
+		s.attrs = map[string]interface{}{}
+	}
+	s.attrs[key] = value
+}
+
+// RecordError 将span标记为失败，err为nil时不做任何事
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errMsg = err.Error()
+}
+
+// End 结束span并交给导出器，重复调用无效
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if s.exporter != nil {
+		s.exporter.enqueue(s)
+	}
+}
+
+// Finish 记录*err（如果有）并结束span，用于 defer span.Finish(&err)
+func (s *Span) Finish(err *error) {
+	if err != nil {
+		s.RecordError(*err)
+	}
+	s.End()
+}
+
+// exporter 当前使用的导出器，为nil时span只在进程内传递追踪ID而不导出
+var exporter atomic.Pointer[Exporter]
+
+// SetExporter 设置全局导出器，传入nil关闭导出
+func SetExporter(e *Exporter) {
+	exporter.Store(e)
+}
+
+type spanKey struct{}
+
+// Start 开始一个内部span，ctx中已有span时作为其子span
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return StartKind(ctx, name, KindInternal)
+}
+
+// StartKind 开始指定类型的span
+func StartKind(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	span := &Span{
+		exporter: exporter.Load(),
+		name:     name,
+		kind:     kind,
+		start:    time.Now(),
+	}
+	if parent := FromContext(ctx); parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else if remote, ok := ctx.Value(remoteKey{}).(remoteParent); ok {
+		span.traceID = remote.traceID
+		span.parentID = remote.spanID
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// FromContext 返回ctx中的当前span，没有时返回nil
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+type remoteKey struct{}
+
+// remoteParent 从请求头中解析出的上游span
+type remoteParent struct {
+	traceID TraceID
+	spanID  SpanID
+}
+
+// WithTraceparent 解析W3C traceparent请求头，之后开始的span作为上游span的子span；请求头无效时原样返回ctx
+func WithTraceparent(ctx context.Context, header string) context.Context {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ctx
+	}
+	var remote remoteParent
+	if _, err := hex.Decode(remote.traceID[:], []byte(parts[1])); err != nil {
+		return ctx
+	}
+	if _, err := hex.Decode(remote.spanID[:], []byte(parts[2])); err != nil {
+		return ctx
+	}
+	if !remote.traceID.IsValid() || !remote.spanID.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, remote)
+}
+
+// Traceparent 返回span对应的W3C traceparent请求头，用于向下游传递追踪
+func (s *Span) Traceparent() string {
+	return "00-" + s.traceID.String() + "-" + s.spanID.String() + "-01"
+}
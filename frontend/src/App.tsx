@@ -1,10 +1,12 @@
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
 import { Box, CssBaseline } from '@mui/material';
 import { ThemeProvider, createTheme } from '@mui/material/styles';
 import HomePage from './pages/HomePage';
 import SettingsPage from './pages/SettingsPage';
+import LoginPage from './pages/LoginPage';
 import Navigation from './components/Navigation';
 import Footer from './components/Footer';
+import { AUTH_REQUIRED_EVENT, getCurrentUser } from './services/api';
 
 // 创建主题
 const theme = createTheme({
@@ -20,6 +22,18 @@ const theme = createTheme({
 
 const App: React.FC = () => {
   const [currentPage, setCurrentPage] = useState<'home' | 'settings'>('home');
+  // null表示尚未确认登录状态
+  const [authenticated, setAuthenticated] = useState<boolean | null>(null);
+
+  useEffect(() => {
+    getCurrentUser()
+      .then(() => setAuthenticated(true))
+      .catch(() => setAuthenticated(false));
+
+    const handleAuthRequired = () => setAuthenticated(false);
+    window.addEventListener(AUTH_REQUIRED_EVENT, handleAuthRequired);
+    return () => window.removeEventListener(AUTH_REQUIRED_EVENT, handleAuthRequired);
+  }, []);
 
   const handleNavigate = (page: 'home' | 'settings') => {
     setCurrentPage(page);
@@ -31,8 +45,9 @@ const App: React.FC = () => {
       <Box sx={{ display: 'flex', flexDirection: 'column', minHeight: '100vh' }}>
         <Navigation currentPage={currentPage} onNavigate={handleNavigate} />
         <Box component="main" sx={{ flexGrow: 1, py: 2 }}>
-          {currentPage === 'home' && <HomePage />}
-          {currentPage === 'settings' && <SettingsPage />}
+          {authenticated === false && <LoginPage onLogin={() => setAuthenticated(true)} />}
+          {authenticated && currentPage === 'home' && <HomePage />}
+          {authenticated && currentPage === 'settings' && <SettingsPage />}
         </Box>
         <Footer />
       </Box>
@@ -1,15 +1,23 @@
+// 与后端 /api/openapi.json 中的 Meeting 保持一致
 export interface Meeting {
   id: string;
+  workspaceId?: string;
+  createdBy?: string;
   title: string;
   date: string;
-  participants: string[];
+  participants: string[] | null;
   transcript: string;
+  segments?: TranscriptSegment[];
   summary: string;
   todoItems: TodoItem[];
   decisions: Decision[];
   createdAt: string;
   updatedAt: string;
   notionPageId?: string;
+  audioFile?: string;
+  syncStatus?: 'pending' | 'synced' | 'failed';
+  syncError?: string;
+  unresolvedPeople?: string[];
 }
 
 export interface TodoItem {
@@ -46,10 +54,17 @@ export interface TranscriptResponse {
 }
 
 export interface NotionSyncResponse {
-  notionPageId: string;
+  status: string;
+  notionPageId?: string;
+  unresolvedPeople?: string[];
 }
 
+// 服务器错误响应，code为稳定的错误码（如 TRANSCRIPTION_FAILED、LLM_BAD_OUTPUT），retryable表示稍后重试可能成功
 export interface ApiError {
   error: string;
+  code?: string;
+  status?: number;
+  retryable?: boolean;
+  requestId?: string;
   message?: string;
 } 
\ No newline at end of file
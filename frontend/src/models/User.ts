@@ -0,0 +1,53 @@
+export interface User {
+  id: string;
+  workspaceId: string;
+  email: string;
+  name: string;
+  role: 'owner' | 'member';
+  createdAt: string;
+}
+
+// 工作区设置，密钥只返回是否已设置
+export interface WorkspaceSettings {
+  notionApiKeySet: boolean;
+  notionDatabaseId: string;
+  deepseekApiKeySet: boolean;
+  deepseekBaseUrl: string;
+  deepseekModel: string;
+  systemPrompt: string;
+  analysisInstructions: string;
+  whisperModel: string;
+  whisperLanguage: string;
+}
+
+// 更新工作区设置，未提供的字段保持不变，空字符串表示改用服务器默认配置
+export type WorkspaceSettingsUpdate = Partial<{
+  notionApiKey: string;
+  notionDatabaseId: string;
+  deepseekApiKey: string;
+  deepseekBaseUrl: string;
+  deepseekModel: string;
+  systemPrompt: string;
+  analysisInstructions: string;
+  whisperModel: string;
+  whisperLanguage: string;
+}>;
+
+export interface Workspace {
+  id: string;
+  name: string;
+  settings: WorkspaceSettings;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface LoginResponse {
+  token: string;
+  expiresAt: string;
+  user: User;
+}
+
+export interface CurrentUserResponse {
+  user: User;
+  workspace: Workspace;
+}
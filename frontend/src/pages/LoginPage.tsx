@@ -0,0 +1,63 @@
+import React, { useState } from 'react';
+import { Container, Typography, TextField, Button, Paper, Alert } from '@mui/material';
+import { login } from '../services/api';
+import { getErrorMessage } from '../utils/errorHandler';
+
+interface LoginPageProps {
+  onLogin: () => void;
+}
+
+const LoginPage: React.FC<LoginPageProps> = ({ onLogin }) => {
+  const [email, setEmail] = useState('');
+  const [password, setPassword] = useState('');
+  const [error, setError] = useState<string | null>(null);
+  const [loading, setLoading] = useState(false);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    setError(null);
+    try {
+      await login(email, password);
+      onLogin();
+    } catch (err) {
+      setError(getErrorMessage(err));
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <Container maxWidth="xs">
+      <Paper elevation={3} sx={{ p: 4, mt: 8 }} component="form" onSubmit={handleSubmit}>
+        <Typography variant="h5" component="h1" gutterBottom align="center">
+          登录
+        </Typography>
+        {error && <Alert severity="error" sx={{ mb: 2 }}>{error}</Alert>}
+        <TextField
+          label="邮箱"
+          type="email"
+          value={email}
+          onChange={(e) => setEmail(e.target.value)}
+          fullWidth
+          margin="normal"
+          required
+        />
+        <TextField
+          label="密码"
+          type="password"
+          value={password}
+          onChange={(e) => setPassword(e.target.value)}
+          fullWidth
+          margin="normal"
+          required
+        />
+        <Button type="submit" variant="contained" fullWidth sx={{ mt: 2 }} disabled={loading}>
+          {loading ? '登录中...' : '登录'}
+        </Button>
+      </Paper>
+    </Container>
+  );
+};
+
+export default LoginPage;
@@ -8,73 +8,103 @@ import {
   Box,
   Snackbar,
   Alert,
-  Switch,
-  FormControlLabel,
   Divider,
 } from '@mui/material';
+import { getWorkspaceSettings, updateWorkspaceSettings } from '../services/api';
+import { WorkspaceSettings, WorkspaceSettingsUpdate } from '../models/User';
 
-interface Settings {
+// 表单中的设置，密钥留空表示保持不变
+interface SettingsForm {
   deepseekApiKey: string;
+  deepseekBaseUrl: string;
+  deepseekModel: string;
+  systemPrompt: string;
+  analysisInstructions: string;
   notionApiKey: string;
   notionDatabaseId: string;
-  useLocalWhisper: boolean;
-  whisperModelPath: string;
+  whisperModel: string;
+  whisperLanguage: string;
 }
 
+const toForm = (settings: WorkspaceSettings): SettingsForm => ({
+  deepseekApiKey: '',
+  deepseekBaseUrl: settings.deepseekBaseUrl,
+  deepseekModel: settings.deepseekModel,
+  systemPrompt: settings.systemPrompt,
+  analysisInstructions: settings.analysisInstructions,
+  notionApiKey: '',
+  notionDatabaseId: settings.notionDatabaseId,
+  whisperModel: settings.whisperModel,
+  whisperLanguage: settings.whisperLanguage,
+});
+
+const emptyForm: SettingsForm = {
+  deepseekApiKey: '',
+  deepseekBaseUrl: '',
+  deepseekModel: '',
+  systemPrompt: '',
+  analysisInstructions: '',
+  notionApiKey: '',
+  notionDatabaseId: '',
+  whisperModel: '',
+  whisperLanguage: '',
+};
+
 const SettingsPage: React.FC = () => {
-  const [settings, setSettings] = useState<Settings>({
-    deepseekApiKey: '',
-    notionApiKey: '',
-    notionDatabaseId: '',
-    useLocalWhisper: true,
-    whisperModelPath: '../whisper/models/ggml-base.bin',
-  });
+  const [settings, setSettings] = useState<WorkspaceSettings | null>(null);
+  const [form, setForm] = useState<SettingsForm>(emptyForm);
   const [error, setError] = useState<string | null>(null);
   const [success, setSuccess] = useState<string | null>(null);
 
-  // 从localStorage加载设置
+  // 从服务器加载工作区设置
   useEffect(() => {
-    const savedSettings = localStorage.getItem('meetingMmSettings');
-    if (savedSettings) {
-      try {
-        const parsedSettings = JSON.parse(savedSettings);
-        setSettings(parsedSettings);
-      } catch (err) {
-        console.error('解析设置失败:', err);
-      }
-    }
+    getWorkspaceSettings()
+      .then((loaded) => {
+        setSettings(loaded);
+        setForm(toForm(loaded));
+      })
+      .catch((err) => {
+        console.error('加载设置失败:', err);
+        setError('加载设置失败');
+      });
   }, []);
 
-  const handleChange = (e: React.ChangeEvent<HTMLInputElement>) => {
-    const { name, value, type, checked } = e.target;
-    setSettings({
-      ...settings,
-      [name]: type === 'checkbox' ? checked : value,
+  const handleChange = (e: React.ChangeEvent<HTMLInputElement | HTMLTextAreaElement>) => {
+    const { name, value } = e.target;
+    setForm({
+      ...form,
+      [name]: value,
     });
   };
 
-  const handleSave = () => {
+  const save = async (update: WorkspaceSettingsUpdate, message: string) => {
     try {
-      // 保存到localStorage
-      localStorage.setItem('meetingMmSettings', JSON.stringify(settings));
-      setSuccess('设置已保存');
-    } catch (err) {
+      const saved = await updateWorkspaceSettings(update);
+      setSettings(saved);
+      setForm(toForm(saved));
+      setSuccess(message);
+    } catch (err: any) {
       console.error('保存设置失败:', err);
-      setError('保存设置失败');
+      setError(err.response?.data?.error || '保存设置失败，只有工作区所有者可以修改设置');
+    }
+  };
+
+  const handleSave = () => {
+    const { deepseekApiKey, notionApiKey, ...rest } = form;
+    const update: WorkspaceSettingsUpdate = { ...rest };
+    // 密钥只在填写时更新
+    if (deepseekApiKey) {
+      update.deepseekApiKey = deepseekApiKey;
+    }
+    if (notionApiKey) {
+      update.notionApiKey = notionApiKey;
     }
+    save(update, '设置已保存');
   };
 
   const handleReset = () => {
-    const defaultSettings: Settings = {
-      deepseekApiKey: '',
-      notionApiKey: '',
-      notionDatabaseId: '',
-      useLocalWhisper: true,
-      whisperModelPath: '../whisper/models/ggml-base.bin',
-    };
-    setSettings(defaultSettings);
-    localStorage.removeItem('meetingMmSettings');
-    setSuccess('设置已重置');
+    // 全部清空，改用服务器的默认配置
+    save({ ...emptyForm }, '设置已重置');
   };
 
   const handleCloseSnackbar = () => {
@@ -82,10 +112,13 @@ const SettingsPage: React.FC = () => {
     setSuccess(null);
   };
 
+  const secretHelper = (isSet: boolean | undefined, text: string) =>
+    isSet ? `${text}（已设置，留空保持不变）` : `${text}（未设置时使用服务器配置）`;
+
   return (
     <Container maxWidth="md">
       <Typography variant="h4" component="h1" gutterBottom align="center" sx={{ mt: 4 }}>
-        设置
+        工作区设置
       </Typography>
 
       <Paper elevation={3} sx={{ p: 4, mb: 4 }}>
@@ -96,12 +129,50 @@ const SettingsPage: React.FC = () => {
           <TextField
             label="DeepSeek API密钥"
             name="deepseekApiKey"
-            value={settings.deepseekApiKey}
+            value={form.deepseekApiKey}
             onChange={handleChange}
             fullWidth
             margin="normal"
             type="password"
-            helperText="用于分析会议内容，提取待办事项和决策点"
+            helperText={secretHelper(settings?.deepseekApiKeySet, '用于分析会议内容，提取待办事项和决策点')}
+          />
+          <TextField
+            label="API地址"
+            name="deepseekBaseUrl"
+            value={form.deepseekBaseUrl}
+            onChange={handleChange}
+            fullWidth
+            margin="normal"
+          />
+          <TextField
+            label="模型"
+            name="deepseekModel"
+            value={form.deepseekModel}
+            onChange={handleChange}
+            fullWidth
+            margin="normal"
+            helperText="例如 deepseek-chat"
+          />
+          <TextField
+            label="系统提示词"
+            name="systemPrompt"
+            value={form.systemPrompt}
+            onChange={handleChange}
+            fullWidth
+            multiline
+            minRows={2}
+            margin="normal"
+          />
+          <TextField
+            label="额外分析要求"
+            name="analysisInstructions"
+            value={form.analysisInstructions}
+            onChange={handleChange}
+            fullWidth
+            multiline
+            minRows={2}
+            margin="normal"
+            helperText="追加在分析提示词末尾，例如团队的术语或格式要求"
           />
         </Box>
 
@@ -114,17 +185,17 @@ const SettingsPage: React.FC = () => {
           <TextField
             label="Notion API密钥"
             name="notionApiKey"
-            value={settings.notionApiKey}
+            value={form.notionApiKey}
             onChange={handleChange}
             fullWidth
             margin="normal"
             type="password"
-            helperText="用于将会议纪要同步到Notion"
+            helperText={secretHelper(settings?.notionApiKeySet, '用于将会议纪要同步到Notion')}
           />
           <TextField
             label="Notion数据库ID"
             name="notionDatabaseId"
-            value={settings.notionDatabaseId}
+            value={form.notionDatabaseId}
             onChange={handleChange}
             fullWidth
             margin="normal"
@@ -138,27 +209,24 @@ const SettingsPage: React.FC = () => {
           <Typography variant="h6" gutterBottom>
             Whisper设置
           </Typography>
-          <FormControlLabel
-            control={
-              <Switch
-                checked={settings.useLocalWhisper}
-                onChange={handleChange}
-                name="useLocalWhisper"
-              />
-            }
-            label="使用本地Whisper模型（推荐，保护隐私）"
+          <TextField
+            label="Whisper模型"
+            name="whisperModel"
+            value={form.whisperModel}
+            onChange={handleChange}
+            fullWidth
+            margin="normal"
+            helperText="Python Whisper的模型名称，例如 base、small、medium"
+          />
+          <TextField
+            label="识别语言"
+            name="whisperLanguage"
+            value={form.whisperLanguage}
+            onChange={handleChange}
+            fullWidth
+            margin="normal"
+            helperText="例如 zh、en，留空时自动检测"
           />
-          {settings.useLocalWhisper && (
-            <TextField
-              label="Whisper模型路径"
-              name="whisperModelPath"
-              value={settings.whisperModelPath}
-              onChange={handleChange}
-              fullWidth
-              margin="normal"
-              helperText="本地Whisper模型的路径"
-            />
-          )}
         </Box>
 
         <Box sx={{ display: 'flex', justifyContent: 'flex-end', mt: 2 }}>
@@ -195,4 +263,4 @@ const SettingsPage: React.FC = () => {
   );
 };
 
-export default SettingsPage; 
\ No newline at end of file
+export default SettingsPage;
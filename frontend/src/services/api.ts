@@ -1,16 +1,63 @@
 import axios from 'axios';
 import { Meeting, MeetingResponse, NotionSyncResponse, TranscriptResponse } from '../models/Meeting';
+import { CurrentUserResponse, LoginResponse, WorkspaceSettings, WorkspaceSettingsUpdate } from '../models/User';
 
 const API_URL = '/api';
 
 // 创建axios实例
 const api = axios.create({
   baseURL: API_URL,
+  // 登录令牌保存在Cookie中
+  withCredentials: true,
   headers: {
     'Content-Type': 'application/json',
   },
 });
 
+// 登录失效时通知界面回到登录页
+export const AUTH_REQUIRED_EVENT = 'auth:required';
+
+api.interceptors.response.use(
+  (response) => response,
+  (error) => {
+    if (error.response?.status === 401) {
+      window.dispatchEvent(new Event(AUTH_REQUIRED_EVENT));
+    }
+    return Promise.reject(error);
+  }
+);
+
+// 登录
+export const login = async (email: string, password: string): Promise<LoginResponse> => {
+  const response = await api.post<LoginResponse>('/auth/login', { email, password });
+  return response.data;
+};
+
+// 退出登录
+export const logout = async (): Promise<void> => {
+  await api.post('/auth/logout');
+};
+
+// 获取当前用户
+export const getCurrentUser = async (): Promise<CurrentUserResponse> => {
+  const response = await api.get<CurrentUserResponse>('/auth/me');
+  return response.data;
+};
+
+// 获取工作区设置
+export const getWorkspaceSettings = async (): Promise<WorkspaceSettings> => {
+  const response = await api.get<WorkspaceSettings>('/workspace/settings');
+  return response.data;
+};
+
+// 更新工作区设置
+export const updateWorkspaceSettings = async (
+  update: WorkspaceSettingsUpdate
+): Promise<WorkspaceSettings> => {
+  const response = await api.put<WorkspaceSettings>('/workspace/settings', update);
+  return response.data;
+};
+
 // 上传音频文件
 export const uploadAudio = async (
   audioBlob: Blob,
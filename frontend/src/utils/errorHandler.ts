@@ -12,7 +12,11 @@ export const getErrorMessage = (error: unknown): string => {
     if (error.response) {
       // 服务器返回了错误响应
       const data = error.response.data as ApiError | string;
-      if (typeof data === 'object' && data.message) {
+      if (typeof data === 'object' && data.code) {
+        // 结构化错误：附上请求ID便于排查，可重试的错误提示用户稍后再试
+        const hint = data.retryable ? '，请稍后重试' : '';
+        return `${data.error}${hint}（${data.code}，请求ID: ${data.requestId}）`;
+      } else if (typeof data === 'object' && data.message) {
         return `${error.response.status}: ${data.message}`;
       } else if (typeof data === 'object' && data.error) {
         return `${error.response.status}: ${data.error}`;
@@ -36,6 +40,32 @@ export const getErrorMessage = (error: unknown): string => {
   return String(error);
 };
 
+/**
+ * 返回服务器错误响应中的错误码，非服务器错误返回undefined
+ * @param error 错误对象
+ */
+export const getErrorCode = (error: unknown): string | undefined => {
+  if (axios.isAxiosError(error) && error.response && typeof error.response.data === 'object') {
+    return (error.response.data as ApiError).code;
+  }
+  return undefined;
+};
+
+/**
+ * 判断错误是否可以重试：网络错误或服务器标记为可重试的错误
+ * @param error 错误对象
+ */
+export const isRetryable = (error: unknown): boolean => {
+  if (!axios.isAxiosError(error)) {
+    return false;
+  }
+  if (!error.response) {
+    return true;
+  }
+  const data = error.response.data as ApiError | string;
+  return typeof data === 'object' && data.retryable === true;
+};
+
 /**
  * 显示错误通知
  * @param error 错误对象或错误消息
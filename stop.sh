@@ -26,9 +26,20 @@ main() {
   
   if [[ -n "$BACKEND_PROCESSES" ]]; then
     print_yellow "找到后端进程: $BACKEND_PROCESSES"
-    print_yellow "正在停止后端服务..."
-    echo "$BACKEND_PROCESSES" | xargs kill -9 2>/dev/null
-    if [[ $? -eq 0 ]]; then
+    print_yellow "正在停止后端服务（等待进行中的请求完成）..."
+    echo "$BACKEND_PROCESSES" | xargs kill -TERM 2>/dev/null
+    # 后端收到SIGTERM后最多等待SHUTDOWN_TIMEOUT（默认30秒），超过40秒仍未退出时强制结束
+    for _ in $(seq 1 40); do
+      if ! echo "$BACKEND_PROCESSES" | xargs kill -0 2>/dev/null; then
+        break
+      fi
+      sleep 1
+    done
+    if echo "$BACKEND_PROCESSES" | xargs kill -0 2>/dev/null; then
+      print_yellow "后端服务未按时退出，强制结束..."
+      echo "$BACKEND_PROCESSES" | xargs kill -9 2>/dev/null
+    fi
+    if ! echo "$BACKEND_PROCESSES" | xargs kill -0 2>/dev/null; then
       print_green "✅ 后端服务已停止"
     else
       print_red "❌ 停止后端服务失败"